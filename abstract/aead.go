@@ -0,0 +1,100 @@
+package abstract
+
+import (
+	"crypto/cipher"
+	"errors"
+
+	"github.com/dedis/crypto/subtle"
+	"github.com/dedis/crypto/util"
+)
+
+// NewAEAD returns the standard cipher.AEAD interface implemented over the
+// message cipher of the suite, keyed with key, for callers who only need to
+// seal and open messages (see CipherAEAD). The key must be at least as long as
+// the KeySize of the suite's cipher.
+func NewAEAD(suite Suite, key []byte) (cipher.AEAD, error) {
+	if key == nil {
+		return nil, errors.New("abstract: nil AEAD key")
+	}
+	c := suite.Cipher(key)
+	if len(key) < c.KeySize() {
+		return nil, errors.New("abstract: AEAD key too short")
+	}
+	return CipherAEAD(c), nil
+}
+
+type cipherAEAD struct {
+	c Cipher
+}
+
+// CipherAEAD wraps the keyed message Cipher c into the standard cipher.AEAD
+// interface. Every message is processed by a clone of c that absorbs the nonce
+// and the additional data, so that c itself is never updated and the AEAD can
+// be used concurrently. Both the nonces and the authenticators are KeySize
+// bytes long, so that nonces may be picked at random; as with any AEAD, a
+// nonce must never be used twice with the same key.
+func CipherAEAD(c Cipher) cipher.AEAD {
+	return &cipherAEAD{c}
+}
+
+func (a *cipherAEAD) NonceSize() int {
+	return a.c.KeySize()
+}
+
+func (a *cipherAEAD) Overhead() int {
+	return a.c.KeySize()
+}
+
+// start forks off the Cipher state of a message, indexed by its nonce and
+// additional data.
+func (a *cipherAEAD) start(nonce, data []byte) Cipher {
+	if len(nonce) != a.NonceSize() {
+		panic("abstract: incorrect nonce length given to AEAD")
+	}
+	ct := a.c.Clone()
+	ct.Message(nil, nil, nonce)
+	ct.Message(nil, nil, data)
+	return ct
+}
+
+// Seal encrypts and authenticates plaintext, authenticates data, and appends
+// the result to dst. To reuse the storage of plaintext, use plaintext[:0] as
+// dst.
+func (a *cipherAEAD) Seal(dst, nonce, plaintext, data []byte) []byte {
+	ct := a.start(nonce, data)
+	l := len(plaintext)
+	dst, out := util.Grow(dst, l+a.Overhead())
+	ct.Message(out[:l], plaintext, out[:l]) // Encrypt and absorb ciphertext
+	ct.Message(out[l:], nil, nil)           // Append authenticator
+	return dst
+}
+
+// Open decrypts and authenticates ciphertext, authenticates data, and appends
+// the plaintext to dst. To reuse the storage of ciphertext, use
+// ciphertext[:0] as dst. The ciphertext is never modified otherwise, and the
+// decrypted bytes are zeroed if authentication fails.
+func (a *cipherAEAD) Open(dst, nonce, ciphertext, data []byte) ([]byte, error) {
+	ct := a.start(nonce, data)
+	l := len(ciphertext) - a.Overhead()
+	if l < 0 {
+		return nil, errors.New("abstract: AEAD ciphertext too short")
+	}
+	auth := make([]byte, a.Overhead())
+	ret, out := util.Grow(dst, l)
+	if l > 0 && &out[0] == &ciphertext[0] {
+		// The ciphertext must be absorbed before it is overwritten
+		tmp := make([]byte, l)
+		ct.Message(tmp, ciphertext[:l], ciphertext[:l])
+		copy(out, tmp)
+	} else {
+		ct.Message(out, ciphertext[:l], ciphertext[:l]) // Decrypt and absorb
+	}
+	ct.Message(auth, nil, nil) // Recompute authenticator
+	if subtle.ConstantTimeCompare(auth, ciphertext[l:]) != 1 {
+		for i := range out {
+			out[i] = 0
+		}
+		return nil, errors.New("abstract: AEAD authentication failed")
+	}
+	return ret, nil
+}
@@ -0,0 +1,88 @@
+package abstract_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/ed25519"
+	"github.com/dedis/crypto/nist"
+	"github.com/dedis/crypto/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAEAD(t *testing.T) {
+	for _, suite := range []abstract.Suite{
+		ed25519.NewAES128SHA256Ed25519(false),
+		nist.NewAES128SHA256P256(),
+	} {
+		key := random.Bytes(suite.Cipher(abstract.NoKey).KeySize(), random.Stream)
+		aead, err := abstract.NewAEAD(suite, key)
+		require.Nil(t, err)
+		nonce := random.Bytes(aead.NonceSize(), random.Stream)
+		msg := []byte("the share of insurer 3")
+		data := []byte("deal 42")
+
+		sealed := aead.Seal(nil, nonce, msg, data)
+		require.Equal(t, len(msg)+aead.Overhead(), len(sealed))
+		opened, err := aead.Open(nil, nonce, sealed, data)
+		require.Nil(t, err)
+		require.Equal(t, msg, opened)
+
+		// The same key opens messages sealed by another instance
+		other, _ := abstract.NewAEAD(suite, key)
+		opened, err = other.Open([]byte("prefix"), nonce, sealed, data)
+		require.Nil(t, err)
+		require.Equal(t, append([]byte("prefix"), msg...), opened)
+
+		// The nonce, the additional data and the ciphertext are authenticated
+		nonce2 := append([]byte(nil), nonce...)
+		nonce2[0] ^= 1
+		_, err = aead.Open(nil, nonce2, sealed, data)
+		require.Error(t, err)
+		_, err = aead.Open(nil, nonce, sealed, []byte("deal 43"))
+		require.Error(t, err)
+		for _, k := range []int{0, len(msg), len(sealed) - 1} {
+			bad := append([]byte(nil), sealed...)
+			bad[k] ^= 1
+			_, err = aead.Open(nil, nonce, bad, data)
+			require.Error(t, err)
+		}
+		_, err = aead.Open(nil, nonce, sealed[:aead.Overhead()-1], data)
+		require.Error(t, err)
+		require.NotEqual(t, sealed, aead.Seal(nil, nonce2, msg, data))
+
+		// Opening does not modify the ciphertext
+		copied := append([]byte(nil), sealed...)
+		aead.Open(nil, nonce, sealed, nil)
+		require.Equal(t, copied, sealed)
+
+		// In place, and with empty messages
+		buf := append([]byte(nil), msg...)
+		inPlace := aead.Seal(buf[:0], nonce, buf, data)
+		require.Equal(t, sealed, inPlace)
+		opened, err = aead.Open(inPlace[:0], nonce, inPlace, data)
+		require.Nil(t, err)
+		require.Equal(t, msg, opened)
+		empty := aead.Seal(nil, nonce, nil, nil)
+		opened, err = aead.Open(nil, nonce, empty, nil)
+		require.Nil(t, err)
+		require.Equal(t, 0, len(opened))
+
+		require.Panics(t, func() { aead.Seal(nil, nonce[1:], msg, data) })
+		_, err = abstract.NewAEAD(suite, key[1:])
+		require.Error(t, err)
+		_, err = abstract.NewAEAD(suite, nil)
+		require.Error(t, err)
+	}
+}
+
+func TestAEADDistinctKeys(t *testing.T) {
+	suite := ed25519.NewAES128SHA256Ed25519(false)
+	a1, _ := abstract.NewAEAD(suite, bytes.Repeat([]byte{1}, 16))
+	a2, _ := abstract.NewAEAD(suite, bytes.Repeat([]byte{2}, 16))
+	nonce := make([]byte, a1.NonceSize())
+	sealed := a1.Seal(nil, nonce, []byte("msg"), nil)
+	_, err := a2.Open(nil, nonce, sealed, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,29 @@
+package abstract
+
+// BatchInvert returns the modular inverses of all the given scalars using
+// Montgomery's trick, i.e., with a single inversion and 3(n-1)
+// multiplications instead of n inversions. The input scalars are left
+// unchanged. All scalars must be non-zero and belong to the same group;
+// otherwise the result is undefined.
+func BatchInvert(scalars []Scalar) []Scalar {
+	n := len(scalars)
+	if n == 0 {
+		return nil
+	}
+
+	// prods[i] = scalars[0] * ... * scalars[i]
+	prods := make([]Scalar, n)
+	prods[0] = scalars[0].Clone()
+	for i := 1; i < n; i++ {
+		prods[i] = scalars[i].Clone().Mul(prods[i-1], scalars[i])
+	}
+
+	inv := scalars[0].Clone().Inv(prods[n-1])
+	res := make([]Scalar, n)
+	for i := n - 1; i > 0; i-- {
+		res[i] = inv.Clone().Mul(inv, prods[i-1])
+		inv.Mul(inv, scalars[i])
+	}
+	res[0] = inv
+	return res
+}
@@ -0,0 +1,39 @@
+package abstract_test
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/random"
+)
+
+func TestBatchInvert(t *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	n := 10
+	scalars := make([]abstract.Scalar, n)
+	for i := range scalars {
+		scalars[i] = suite.Scalar().Pick(random.Stream)
+	}
+	orig := make([]abstract.Scalar, n)
+	for i := range scalars {
+		orig[i] = scalars[i].Clone()
+	}
+
+	invs := abstract.BatchInvert(scalars)
+	if len(invs) != n {
+		t.Fatal("wrong number of inverses")
+	}
+	for i := range invs {
+		if !invs[i].Equal(suite.Scalar().Inv(scalars[i])) {
+			t.Fatalf("wrong inverse at index %d", i)
+		}
+		if !scalars[i].Equal(orig[i]) {
+			t.Fatalf("input scalar %d modified", i)
+		}
+	}
+
+	if abstract.BatchInvert(nil) != nil {
+		t.Fatal("inverses of an empty slice")
+	}
+}
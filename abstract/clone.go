@@ -0,0 +1,50 @@
+package abstract
+
+// Points and Scalars are mutable: arithmetic methods overwrite their receiver
+// and return it, so that a Point or Scalar referenced from two places, such as
+// a protocol struct and its caller, changes in both places at once and races
+// when used from several goroutines. Protocol structs should therefore keep
+// their own copies of the Points and Scalars they receive or hand out, made
+// with the helpers below. A copy made with Clone or Set never shares memory
+// with the original.
+
+// ClonePoint returns a deep copy of p, or nil if p is nil.
+func ClonePoint(p Point) Point {
+	if p == nil {
+		return nil
+	}
+	return p.Clone()
+}
+
+// CloneScalar returns a deep copy of s, or nil if s is nil.
+func CloneScalar(s Scalar) Scalar {
+	if s == nil {
+		return nil
+	}
+	return s.Clone()
+}
+
+// ClonePoints returns a deep copy of a slice of points, nil elements included.
+func ClonePoints(points []Point) []Point {
+	if points == nil {
+		return nil
+	}
+	c := make([]Point, len(points))
+	for i, p := range points {
+		c[i] = ClonePoint(p)
+	}
+	return c
+}
+
+// CloneScalars returns a deep copy of a slice of scalars,
+// nil elements included.
+func CloneScalars(scalars []Scalar) []Scalar {
+	if scalars == nil {
+		return nil
+	}
+	c := make([]Scalar, len(scalars))
+	for i, s := range scalars {
+		c[i] = CloneScalar(s)
+	}
+	return c
+}
@@ -0,0 +1,35 @@
+package abstract_test
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/ed25519"
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/nist"
+	"github.com/dedis/crypto/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	for _, suite := range []abstract.Suite{
+		ed25519.NewAES128SHA256Ed25519(false),
+		edwards.NewAES128SHA256Ed25519(false),
+		nist.NewAES128SHA256P256(),
+	} {
+		P := suite.Point().Mul(nil, suite.Scalar().Pick(random.Stream))
+		s := suite.Scalar().Pick(random.Stream)
+		points := abstract.ClonePoints([]abstract.Point{P, nil})
+		scalars := abstract.CloneScalars([]abstract.Scalar{s, nil})
+		require.True(t, points[0].Equal(P))
+		require.True(t, scalars[0].Equal(s))
+		require.Nil(t, points[1])
+		require.Nil(t, scalars[1])
+
+		// Modifying the copies must not modify the originals
+		points[0].Add(points[0], suite.Point().Base())
+		scalars[0].Add(scalars[0], suite.Scalar().One())
+		require.False(t, points[0].Equal(P), suite.String())
+		require.False(t, scalars[0].Equal(s), suite.String())
+	}
+}
@@ -162,8 +162,13 @@ func (e BinaryEncoding) Read(r io.Reader, objs ...interface{}) error {
 
 func (de *decoder) value(v reflect.Value, depth int) error {
 
-	// Does the object support our self-decoding interface?
+	// Does the object support our self-decoding interfaces?
 	obj := v.Interface()
+	if m, ok := obj.(SuiteMarshaler); ok {
+		if s, ok := de.c.(Suite); ok {
+			return m.UnmarshalSuite(de.r, s)
+		}
+	}
 	if e, ok := obj.(Marshaling); ok {
 		_, err := e.UnmarshalFrom(de.r)
 		//prindent(depth, "decode: %s\n", e.String())
@@ -236,6 +241,7 @@ func (de *decoder) value(v reflect.Value, depth int) error {
 }
 
 type encoder struct {
+	c Constructor
 	w io.Writer
 }
 
@@ -250,7 +256,7 @@ type encoder struct {
 // XXX now this code could/should be moved into a separate package
 // relatively independent from this crypto code.
 func (e BinaryEncoding) Write(w io.Writer, objs ...interface{}) error {
-	en := encoder{w}
+	en := encoder{e.Constructor, w}
 	for i := 0; i < len(objs); i++ {
 		if err := en.value(objs[i], 0); err != nil {
 			return err
@@ -261,7 +267,12 @@ func (e BinaryEncoding) Write(w io.Writer, objs ...interface{}) error {
 
 func (en *encoder) value(obj interface{}, depth int) error {
 
-	// Does the object support our self-decoding interface?
+	// Does the object support our self-encoding interfaces?
+	if m, ok := obj.(SuiteMarshaler); ok {
+		if s, ok := en.c.(Suite); ok {
+			return m.MarshalSuite(en.w, s)
+		}
+	}
 	if e, ok := obj.(Marshaling); ok {
 		//prindent(depth, "encode: %s\n", e.String())
 		_, err := e.MarshalTo(en.w)
@@ -17,10 +17,11 @@ type Scalar interface {
 	// Equality test for two Scalars derived from the same Group
 	Equal(s2 Scalar) bool
 
-	// Set equal to another Scalar a
+	// Set equal to another Scalar a, copying its value,
+	// so that later changes to a do not affect this Scalar.
 	Set(a Scalar) Scalar
 
-	// Clone creates a new Scalar with same value
+	// Clone creates a new Scalar with same value, sharing no memory with it.
 	Clone() Scalar
 
 	// Set to a small integer value
@@ -89,10 +90,11 @@ type Point interface {
 	// in a single group element via Pick().
 	PickLen() int
 
-	// Set equal to another Point p.
+	// Set equal to another Point p, copying its value,
+	// so that later changes to p do not affect this Point.
 	Set(p Point) Point
 
-	// Clone clones the underlying point.
+	// Clone returns a deep copy of the point, sharing no memory with it.
 	Clone() Point
 
 	// Extract data embedded in a point chosen via Embed().
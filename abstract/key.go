@@ -0,0 +1,35 @@
+package abstract
+
+import "crypto/sha256"
+
+// PointKeySize is the size in bytes of the keys returned by PointKey.
+const PointKeySize = sha256.Size
+
+// PointKey returns a canonical key identifying the point P, which can be used
+// as a map key, e.g. to index protocol state by public key, instead of the
+// String method of P, whose format depends on the group. The key is the
+// SHA-256 hash of the canonical encoding of P, so that equal points have
+// equal keys whatever their internal representation, and keys have the same
+// size in all groups. Points of different groups may have the same encoding,
+// so a map should only hold keys of points of a single group.
+//
+// PointKey panics if P cannot be marshalled, which no Point of this library
+// does.
+func PointKey(P Point) [PointKeySize]byte {
+	buf, err := P.MarshalBinary()
+	if err != nil {
+		panic("abstract: point cannot be marshalled: " + err.Error())
+	}
+	return sha256.Sum256(buf)
+}
+
+// PointKeys returns a map from the PointKey of each of the points to its
+// position in points. If a point appears several times, the map holds its
+// first position.
+func PointKeys(points []Point) map[[PointKeySize]byte]int {
+	m := make(map[[PointKeySize]byte]int, len(points))
+	for i := len(points) - 1; i >= 0; i-- {
+		m[PointKey(points[i])] = i
+	}
+	return m
+}
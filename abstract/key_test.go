@@ -0,0 +1,36 @@
+package abstract_test
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/ed25519"
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/nist"
+	"github.com/dedis/crypto/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointKey(t *testing.T) {
+	for _, suite := range []abstract.Suite{
+		ed25519.NewAES128SHA256Ed25519(false),
+		edwards.NewAES128SHA256Ed25519(false),
+		nist.NewAES128SHA256P256(),
+	} {
+		x := suite.Scalar().Pick(random.Stream)
+		P := suite.Point().Mul(nil, x)
+
+		// The same point computed differently has the same key
+		Q := suite.Point().Add(suite.Point().Mul(nil, suite.Scalar().Add(x,
+			suite.Scalar().One())), suite.Point().Neg(suite.Point().Base()))
+		require.Equal(t, abstract.PointKey(P), abstract.PointKey(Q))
+		require.NotEqual(t, abstract.PointKey(P),
+			abstract.PointKey(suite.Point().Base()))
+
+		points := []abstract.Point{suite.Point().Base(), P, Q}
+		keys := abstract.PointKeys(points)
+		require.Equal(t, 2, len(keys))
+		require.Equal(t, 0, keys[abstract.PointKey(suite.Point().Base())])
+		require.Equal(t, 1, keys[abstract.PointKey(Q)])
+	}
+}
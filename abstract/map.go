@@ -0,0 +1,38 @@
+package abstract
+
+// Domain separation tag of the generic map to points.
+const mapToPointTag = "abstract.MapToPoint"
+
+// PointMapper is implemented by groups providing a dedicated map
+// from byte strings to points, such as the Elligator map of ristretto255.
+// The map must have the properties documented for MapToPoint.
+type PointMapper interface {
+
+	// MapToPoint deterministically maps data to a point of the group.
+	MapToPoint(data []byte) Point
+}
+
+// MapToPoint deterministically maps data to a point of the suite,
+// e.g. to derive generators independent of the standard base point,
+// or to hash messages to points in VRFs and OPRFs. The map:
+//
+//   - is deterministic: the same data always yields the same point,
+//     on all platforms and in all versions of the suite;
+//   - behaves as a random oracle: nobody knows the discrete logarithm
+//     of its output with respect to the base point or to other outputs;
+//   - outputs points of the group in use, i.e., of the prime-order subgroup
+//     of curves with a cofactor, which pass IsInCorrectSubgroup;
+//   - is a hash and not an encoding: the data cannot be recovered from the
+//     point, and distinct data collide with negligible probability only.
+//
+// Suites whose group implements PointMapper use their own map. Otherwise,
+// the point is picked with Pick using the cipher of the suite keyed
+// with the hash of data, whose output only depends on data.
+func MapToPoint(suite Suite, data []byte) Point {
+	if m, ok := suite.(PointMapper); ok {
+		return m.MapToPoint(data)
+	}
+	key := Sum(suite, []byte(mapToPointTag), data)
+	P, _ := suite.Point().Pick(nil, suite.Cipher(key))
+	return P
+}
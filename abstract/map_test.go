@@ -0,0 +1,33 @@
+package abstract_test
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/ed25519"
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/nist"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapToPoint(t *testing.T) {
+	for _, suite := range []abstract.Suite{
+		ed25519.NewAES128SHA256Ed25519(false),
+		edwards.NewAES128SHA256Ed25519(false),
+		nist.NewAES128SHA256P256(),
+		ed25519.NewRistretto255(),
+	} {
+		P := abstract.MapToPoint(suite, []byte("data"))
+		Q := abstract.MapToPoint(suite, []byte("data"))
+		R := abstract.MapToPoint(suite, []byte("other data"))
+		require.True(t, P.Equal(Q))
+		require.False(t, P.Equal(R))
+		require.False(t, P.Equal(suite.Point().Null()))
+		require.True(t, abstract.IsInCorrectSubgroup(P))
+
+		// Encodings are identical too
+		pb, _ := P.MarshalBinary()
+		qb, _ := Q.MarshalBinary()
+		require.Equal(t, pb, qb)
+	}
+}
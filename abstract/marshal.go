@@ -0,0 +1,129 @@
+package abstract
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+/*
+SuiteMarshaler is implemented by structures containing Points and Scalars
+that encode themselves for a given ciphersuite, without reflection.
+It is an alternative to the reflective BinaryEncoding,
+which is slow and requires the receiver to size all slices
+and instantiate all interfaces before decoding.
+An UnmarshalSuite implementation reads whatever metadata it needs,
+such as slice lengths, from the stream,
+and uses the suite to instantiate the Points and Scalars it decodes.
+
+BinaryEncoding uses these methods whenever an object implements them
+and its Constructor is a Suite, so that suite.Write and suite.Read
+transparently benefit from them.
+The helpers of this file (WriteInts, ReadPoints, etc.) ease implementations.
+*/
+type SuiteMarshaler interface {
+
+	// Encode the object for the given suite and write it to an io.Writer.
+	MarshalSuite(w io.Writer, s Suite) error
+
+	// Decode the object for the given suite by reading from an io.Reader.
+	UnmarshalSuite(r io.Reader, s Suite) error
+}
+
+// MarshalSuite encodes a list of SuiteMarshalers into a byte slice.
+func MarshalSuite(s Suite, objs ...SuiteMarshaler) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, o := range objs {
+		if err := o.MarshalSuite(&buf, s); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalSuite decodes a list of SuiteMarshalers from a byte slice,
+// which must be consumed entirely.
+func UnmarshalSuite(s Suite, buf []byte, objs ...SuiteMarshaler) error {
+	r := bytes.NewReader(buf)
+	for _, o := range objs {
+		if err := o.UnmarshalSuite(r, s); err != nil {
+			return err
+		}
+	}
+	if r.Len() != 0 {
+		return errors.New("trailing data after encoded objects")
+	}
+	return nil
+}
+
+// WriteInts writes non-negative integers as big-endian uint32s,
+// the encoding BinaryEncoding uses for ints.
+func WriteInts(w io.Writer, ints ...int) error {
+	buf := make([]byte, 4*len(ints))
+	for i, v := range ints {
+		if v < 0 || int64(v) > int64(^uint32(0)) {
+			return errors.New("integer out of range")
+		}
+		binary.BigEndian.PutUint32(buf[4*i:], uint32(v))
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadInts reads n integers written by WriteInts.
+func ReadInts(r io.Reader, n int) ([]int, error) {
+	buf := make([]byte, 4*n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	ints := make([]int, n)
+	for i := range ints {
+		ints[i] = int(binary.BigEndian.Uint32(buf[4*i:]))
+	}
+	return ints, nil
+}
+
+// WritePoints writes a list of points with their MarshalTo method.
+func WritePoints(w io.Writer, points ...Point) error {
+	for _, p := range points {
+		if _, err := p.MarshalTo(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadPoints reads n points of group g written by WritePoints.
+func ReadPoints(r io.Reader, g Group, n int) ([]Point, error) {
+	points := make([]Point, n)
+	for i := range points {
+		points[i] = g.Point()
+		if _, err := points[i].UnmarshalFrom(r); err != nil {
+			return nil, err
+		}
+	}
+	return points, nil
+}
+
+// WriteScalars writes a list of scalars with their MarshalTo method.
+func WriteScalars(w io.Writer, scalars ...Scalar) error {
+	for _, s := range scalars {
+		if _, err := s.MarshalTo(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadScalars reads n scalars of group g written by WriteScalars.
+func ReadScalars(r io.Reader, g Group, n int) ([]Scalar, error) {
+	scalars := make([]Scalar, n)
+	for i := range scalars {
+		scalars[i] = g.Scalar()
+		if _, err := scalars[i].UnmarshalFrom(r); err != nil {
+			return nil, err
+		}
+	}
+	return scalars, nil
+}
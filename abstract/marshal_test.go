@@ -0,0 +1,69 @@
+package abstract_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/nist"
+	"github.com/dedis/crypto/random"
+	"github.com/stretchr/testify/require"
+)
+
+// pair is a minimal SuiteMarshaler holding a variable number of points.
+type pair struct {
+	P []abstract.Point
+	S abstract.Scalar
+}
+
+func (p *pair) MarshalSuite(w io.Writer, s abstract.Suite) error {
+	if err := abstract.WriteInts(w, len(p.P)); err != nil {
+		return err
+	}
+	if err := abstract.WritePoints(w, p.P...); err != nil {
+		return err
+	}
+	return abstract.WriteScalars(w, p.S)
+}
+
+func (p *pair) UnmarshalSuite(r io.Reader, s abstract.Suite) error {
+	n, err := abstract.ReadInts(r, 1)
+	if err != nil {
+		return err
+	}
+	if p.P, err = abstract.ReadPoints(r, s, n[0]); err != nil {
+		return err
+	}
+	scalars, err := abstract.ReadScalars(r, s, 1)
+	if err != nil {
+		return err
+	}
+	p.S = scalars[0]
+	return nil
+}
+
+func TestSuiteMarshaler(t *testing.T) {
+	suite := nist.NewAES128SHA256P256()
+	x := suite.Scalar().Pick(random.Stream)
+	p := &pair{[]abstract.Point{suite.Point().Mul(nil, x), suite.Point().Base()}, x}
+
+	// Through the suite's reflective encoding
+	var buf bytes.Buffer
+	require.Nil(t, suite.Write(&buf, p))
+	q := &pair{}
+	require.Nil(t, suite.Read(&buf, q))
+	require.Equal(t, 2, len(q.P))
+	require.True(t, q.P[0].Equal(p.P[0]) && q.P[1].Equal(p.P[1]))
+	require.True(t, q.S.Equal(p.S))
+
+	// Through the helpers
+	b, err := abstract.MarshalSuite(suite, p)
+	require.Nil(t, err)
+	q = &pair{}
+	require.Nil(t, abstract.UnmarshalSuite(suite, b, q))
+	require.True(t, q.S.Equal(p.S))
+	require.NotNil(t, abstract.UnmarshalSuite(suite, b[:len(b)-1], q))
+
+	require.NotNil(t, abstract.WriteInts(&buf, -1))
+}
@@ -0,0 +1,61 @@
+package abstract
+
+import (
+	"math/big"
+)
+
+// GroupOrder is implemented by groups able to report their order.
+// Most elliptic curves, such as twisted Edwards curves, have a cofactor:
+// the full group of points has order Cofactor()*Order(),
+// and cryptographic operations take place in the subgroup of prime order
+// Order() generated by the standard base point.
+type GroupOrder interface {
+
+	// Order returns the prime order of the subgroup used for cryptography.
+	Order() *big.Int
+
+	// Cofactor returns the cofactor of that subgroup,
+	// which is 1 for prime-order curves.
+	Cofactor() *big.Int
+}
+
+// SubgroupChecker is implemented by Points of groups with a cofactor.
+// Decoding a point from its binary encoding checks that it lies on the curve,
+// but not that it lies in the prime-order subgroup in use.
+// Points received from untrusted parties must therefore be checked
+// with IsInCorrectSubgroup to prevent small-subgroup attacks,
+// which could otherwise leak bits of secrets multiplied with such points.
+type SubgroupChecker interface {
+
+	// IsInCorrectSubgroup returns true iff the point is an element
+	// of the group in use, normally the prime-order subgroup.
+	IsInCorrectSubgroup() bool
+}
+
+// Order returns the order of the prime-order subgroup used in group g,
+// or nil if g does not implement GroupOrder.
+func Order(g Group) *big.Int {
+	if o, ok := g.(GroupOrder); ok {
+		return o.Order()
+	}
+	return nil
+}
+
+// Cofactor returns the cofactor of group g,
+// or 1 if g does not implement GroupOrder.
+func Cofactor(g Group) *big.Int {
+	if o, ok := g.(GroupOrder); ok {
+		return o.Cofactor()
+	}
+	return big.NewInt(1)
+}
+
+// IsInCorrectSubgroup returns true iff point p belongs to the group in use.
+// Points that do not implement SubgroupChecker belong to prime-order groups
+// and always pass the check.
+func IsInCorrectSubgroup(p Point) bool {
+	if c, ok := p.(SubgroupChecker); ok {
+		return c.IsInCorrectSubgroup()
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+package abstract_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/ed25519"
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/nist"
+	"github.com/dedis/crypto/random"
+	"github.com/stretchr/testify/require"
+)
+
+// lowOrderPoint returns the point (0,-1) of order 2 of an Edwards curve.
+func lowOrderPoint(t *testing.T, g abstract.Group, p *big.Int) abstract.Point {
+	y := new(big.Int).Sub(p, big.NewInt(1)).Bytes()
+	b := make([]byte, g.PointLen())
+	for i := range y {
+		b[i] = y[len(y)-1-i] // little-endian
+	}
+	P := g.Point()
+	require.Nil(t, P.UnmarshalBinary(b))
+	return P
+}
+
+func TestSubgroup(t *testing.T) {
+	p25519, _ := new(big.Int).SetString("57896044618658097711785492504343953926634992332820282019728792003956564819949", 10)
+	for _, suite := range []abstract.Suite{
+		ed25519.NewAES128SHA256Ed25519(false),
+		edwards.NewAES128SHA256Ed25519(false),
+	} {
+		require.Equal(t, int64(8), abstract.Cofactor(suite).Int64())
+		require.Equal(t, 253, abstract.Order(suite).BitLen())
+
+		B := suite.Point().Mul(nil, suite.Scalar().Pick(random.Stream))
+		require.True(t, abstract.IsInCorrectSubgroup(B))
+		L := lowOrderPoint(t, suite, p25519)
+		require.False(t, abstract.IsInCorrectSubgroup(L))
+		require.False(t, abstract.IsInCorrectSubgroup(suite.Point().Add(B, L)))
+	}
+
+	suite := nist.NewAES128SHA256P256()
+	require.Equal(t, int64(1), abstract.Cofactor(suite).Int64())
+	require.Equal(t, 256, abstract.Order(suite).BitLen())
+	require.True(t, abstract.IsInCorrectSubgroup(suite.Point().Base()))
+}
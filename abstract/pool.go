@@ -0,0 +1,58 @@
+package abstract
+
+import "sync"
+
+// Protocol loops often allocate many short-lived Points and Scalars, e.g. as
+// temporaries of a polynomial evaluation. The pools below let such loops reuse
+// them instead, to relieve the garbage collector. A Point or Scalar obtained
+// with Get holds an unspecified value, and must not be used anymore, nor be
+// referenced from elsewhere, once released with Release. Pools are safe for
+// concurrent use.
+
+// PointPool is a pool of reusable Points of a Group.
+type PointPool struct {
+	pool sync.Pool
+}
+
+// NewPointPool returns a pool allocating the Points of the group g.
+func NewPointPool(g Group) *PointPool {
+	p := new(PointPool)
+	p.pool.New = func() interface{} { return g.Point() }
+	return p
+}
+
+// Get returns a Point of the pool, or a new one if the pool is empty.
+func (p *PointPool) Get() Point {
+	return p.pool.Get().(Point)
+}
+
+// Release returns the Point P to the pool for later reuse.
+func (p *PointPool) Release(P Point) {
+	if P != nil {
+		p.pool.Put(P)
+	}
+}
+
+// ScalarPool is a pool of reusable Scalars of a Group.
+type ScalarPool struct {
+	pool sync.Pool
+}
+
+// NewScalarPool returns a pool allocating the Scalars of the group g.
+func NewScalarPool(g Group) *ScalarPool {
+	p := new(ScalarPool)
+	p.pool.New = func() interface{} { return g.Scalar() }
+	return p
+}
+
+// Get returns a Scalar of the pool, or a new one if the pool is empty.
+func (p *ScalarPool) Get() Scalar {
+	return p.pool.Get().(Scalar)
+}
+
+// Release returns the Scalar s to the pool for later reuse.
+func (p *ScalarPool) Release(s Scalar) {
+	if s != nil {
+		p.pool.Put(s)
+	}
+}
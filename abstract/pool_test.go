@@ -0,0 +1,34 @@
+package abstract_test
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/random"
+)
+
+func TestPools(t *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	points := abstract.NewPointPool(suite)
+	scalars := abstract.NewScalarPool(suite)
+
+	s := scalars.Get().Pick(random.Stream)
+	P := points.Get().Mul(nil, s)
+	if !P.Equal(suite.Point().Mul(nil, s)) {
+		t.Fatal("Pooled objects compute wrong results")
+	}
+	points.Release(P)
+	scalars.Release(s)
+	points.Release(nil)
+	scalars.Release(nil)
+
+	// Released objects are reused or replaced by fresh ones
+	for i := 0; i < 10; i++ {
+		Q := points.Get().Base()
+		if !Q.Equal(suite.Point().Base()) {
+			t.Fatal("Pooled point cannot be reset")
+		}
+		points.Release(Q)
+	}
+}
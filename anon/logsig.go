@@ -0,0 +1,271 @@
+package anon
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+// logarithmic-size unlinkable ring signature
+type logSig struct {
+	CL []abstract.Point  // commitments to the bits of the signer's index
+	CA []abstract.Point  // commitments to the blinding values a_j
+	CB []abstract.Point  // commitments to l_j*a_j
+	CD []abstract.Point  // commitments to the polynomial coefficients
+	F  []abstract.Scalar // responses f_j = l_j*x + a_j
+	ZA []abstract.Scalar // responses for CL and CA
+	ZB []abstract.Scalar // responses for CL and CB
+	ZD abstract.Scalar   // response for the private key
+}
+
+// logSigBits returns the number of bits m needed to index a set of size n.
+func logSigBits(n int) int {
+	m := 1
+	for 1<<uint(m) < n {
+		m++
+	}
+	return m
+}
+
+// logSigRing pads the anonymity set to a power of two
+// by repeating its last key.
+func logSigRing(set Set, m int) []abstract.Point {
+	N := 1 << uint(m)
+	L := make([]abstract.Point, N)
+	copy(L, set)
+	for i := len(set); i < N; i++ {
+		L[i] = set[len(set)-1]
+	}
+	return L
+}
+
+// logSigH returns the second Pedersen commitment base,
+// whose discrete logarithm with respect to the standard base is unknown.
+func logSigH(suite abstract.Suite) abstract.Point {
+	H, _ := suite.Point().Pick(nil, suite.Cipher([]byte("anon.LogSign")))
+	return H
+}
+
+// commit computes the Pedersen commitment m*H + r*G.
+func commit(suite abstract.Suite, H abstract.Point, m, r abstract.Scalar) abstract.Point {
+	P := suite.Point().Mul(H, m)
+	return P.Add(P, suite.Point().Mul(nil, r))
+}
+
+func logSigChallenge(suite abstract.Suite, message []byte, L []abstract.Point,
+	sig *logSig) abstract.Scalar {
+	H := suite.Cipher(message)
+	for _, P := range L {
+		b, _ := P.MarshalBinary()
+		H.Write(b)
+	}
+	for _, Ps := range [][]abstract.Point{sig.CL, sig.CA, sig.CB, sig.CD} {
+		for _, P := range Ps {
+			b, _ := P.MarshalBinary()
+			H.Write(b)
+		}
+	}
+	H.Message(nil, nil, nil) // finish message absorption
+	return suite.Scalar().Pick(H)
+}
+
+// LogSign creates an unlinkable anonymous signature on a given message,
+// whose size is logarithmic in the size of the anonymity set.
+// It offers the same anonymity guarantees as an unlinkable signature
+// produced by Sign, but the signature consists of O(log n) group elements
+// instead of n, making it suitable for large anonymity sets.
+// Signing and verification still take O(n) point multiplications.
+// The signature can be verified with LogVerify.
+//
+// The construction is the one-out-of-many proof of Groth and Kohlweiss,
+// "One-out-of-Many Proofs: Or How to Leak a Secret and Spend a Coin" at
+// https://eprint.iacr.org/2014/764.pdf,
+// made non-interactive with the Fiat-Shamir heuristic over the message.
+//
+// Returns an error if the anonymity set is empty
+// or if mine is not an index in the set.
+func LogSign(suite abstract.Suite, random cipher.Stream, message []byte,
+	anonymitySet Set, mine int, privateKey abstract.Scalar) ([]byte, error) {
+
+	if len(anonymitySet) == 0 {
+		return nil, errors.New("empty anonymity set")
+	}
+	if mine < 0 || mine >= len(anonymitySet) {
+		return nil, errors.New("signer index out of range")
+	}
+
+	m := logSigBits(len(anonymitySet))
+	N := 1 << uint(m)
+	L := logSigRing(anonymitySet, m)
+	H := logSigH(suite)
+
+	sig := logSig{
+		CL: make([]abstract.Point, m),
+		CA: make([]abstract.Point, m),
+		CB: make([]abstract.Point, m),
+		CD: make([]abstract.Point, m),
+		F:  make([]abstract.Scalar, m),
+		ZA: make([]abstract.Scalar, m),
+		ZB: make([]abstract.Scalar, m),
+	}
+
+	// Commit to the bits l_j of our index
+	l := make([]abstract.Scalar, m)
+	a := make([]abstract.Scalar, m)
+	r := make([]abstract.Scalar, m)
+	s := make([]abstract.Scalar, m)
+	t := make([]abstract.Scalar, m)
+	rho := make([]abstract.Scalar, m)
+	for j := 0; j < m; j++ {
+		l[j] = suite.Scalar().SetInt64(int64((mine >> uint(j)) & 1))
+		a[j] = suite.Scalar().Pick(random)
+		r[j] = suite.Scalar().Pick(random)
+		s[j] = suite.Scalar().Pick(random)
+		t[j] = suite.Scalar().Pick(random)
+		rho[j] = suite.Scalar().Pick(random)
+		sig.CL[j] = commit(suite, H, l[j], r[j])
+		sig.CA[j] = commit(suite, H, a[j], s[j])
+		sig.CB[j] = commit(suite, H, suite.Scalar().Mul(l[j], a[j]), t[j])
+	}
+
+	// Compute the coefficients p_{i,k} of the polynomials
+	// p_i(x) = prod_j f_{j,i_j}(x), where f_{j,1}(x) = l_j*x + a_j
+	// and f_{j,0}(x) = x - f_{j,1}(x), so that p_i has degree m iff i == mine.
+	one := suite.Scalar().One()
+	p := make([][]abstract.Scalar, N)
+	for i := 0; i < N; i++ {
+		p[i] = []abstract.Scalar{suite.Scalar().One()}
+		for j := 0; j < m; j++ {
+			var c0, c1 abstract.Scalar // f(x) = c1*x + c0
+			if (i>>uint(j))&1 == 1 {
+				c0, c1 = a[j], l[j]
+			} else {
+				c0 = suite.Scalar().Neg(a[j])
+				c1 = suite.Scalar().Sub(one, l[j])
+			}
+			q := make([]abstract.Scalar, len(p[i])+1)
+			for k := range q {
+				q[k] = suite.Scalar().Zero()
+			}
+			for k, pk := range p[i] {
+				q[k].Add(q[k], suite.Scalar().Mul(pk, c0))
+				q[k+1].Add(q[k+1], suite.Scalar().Mul(pk, c1))
+			}
+			p[i] = q
+		}
+	}
+	for k := 0; k < m; k++ {
+		D := suite.Point().Mul(nil, rho[k])
+		for i := 0; i < N; i++ {
+			D.Add(D, suite.Point().Mul(L[i], p[i][k]))
+		}
+		sig.CD[k] = D
+	}
+
+	// Fiat-Shamir challenge
+	x := logSigChallenge(suite, message, L, &sig)
+
+	// Responses
+	tmp := suite.Scalar()
+	for j := 0; j < m; j++ {
+		sig.F[j] = suite.Scalar().Mul(l[j], x)
+		sig.F[j].Add(sig.F[j], a[j])
+		sig.ZA[j] = suite.Scalar().Mul(r[j], x)
+		sig.ZA[j].Add(sig.ZA[j], s[j])
+		sig.ZB[j] = suite.Scalar().Mul(r[j], tmp.Sub(x, sig.F[j]))
+		sig.ZB[j].Add(sig.ZB[j], t[j])
+	}
+	xk := suite.Scalar().One()
+	sig.ZD = suite.Scalar().Zero()
+	for k := 0; k < m; k++ {
+		sig.ZD.Sub(sig.ZD, tmp.Mul(rho[k], xk))
+		xk.Mul(xk, x)
+	}
+	sig.ZD.Add(sig.ZD, tmp.Mul(privateKey, xk)) // xk == x^m
+
+	buf := bytes.Buffer{}
+	if err := suite.Write(&buf, &sig); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// LogVerify checks a signature generated by LogSign
+// for the given message and anonymity set.
+// Returns nil if the signature is valid, or an error otherwise.
+func LogVerify(suite abstract.Suite, message []byte, anonymitySet Set,
+	signatureBuffer []byte) error {
+
+	if len(anonymitySet) == 0 {
+		return errors.New("empty anonymity set")
+	}
+	m := logSigBits(len(anonymitySet))
+	N := 1 << uint(m)
+	L := logSigRing(anonymitySet, m)
+	H := logSigH(suite)
+
+	// Decode the signature
+	sig := logSig{
+		CL: make([]abstract.Point, m),
+		CA: make([]abstract.Point, m),
+		CB: make([]abstract.Point, m),
+		CD: make([]abstract.Point, m),
+		F:  make([]abstract.Scalar, m),
+		ZA: make([]abstract.Scalar, m),
+		ZB: make([]abstract.Scalar, m),
+	}
+	buf := bytes.NewBuffer(signatureBuffer)
+	if err := suite.Read(buf, &sig); err != nil {
+		return err
+	}
+	if buf.Len() != 0 {
+		return errors.New("invalid signature length")
+	}
+
+	x := logSigChallenge(suite, message, L, &sig)
+	zero := suite.Scalar().Zero()
+	tmp := suite.Scalar()
+
+	// Check the bit commitments
+	f0 := make([]abstract.Scalar, m) // f_{j,0} = x - f_j
+	for j := 0; j < m; j++ {
+		f0[j] = suite.Scalar().Sub(x, sig.F[j])
+
+		left := suite.Point().Mul(sig.CL[j], x)
+		left.Add(left, sig.CA[j])
+		if !left.Equal(commit(suite, H, sig.F[j], sig.ZA[j])) {
+			return errors.New("invalid signature")
+		}
+
+		left.Mul(sig.CL[j], f0[j])
+		left.Add(left, sig.CB[j])
+		if !left.Equal(commit(suite, H, zero, sig.ZB[j])) {
+			return errors.New("invalid signature")
+		}
+	}
+
+	// Check sum_i p_i(x)*L_i - sum_k x^k*CD_k == ZD*G
+	left := suite.Point().Null()
+	for i := 0; i < N; i++ {
+		pi := suite.Scalar().One()
+		for j := 0; j < m; j++ {
+			if (i>>uint(j))&1 == 1 {
+				pi.Mul(pi, sig.F[j])
+			} else {
+				pi.Mul(pi, f0[j])
+			}
+		}
+		left.Add(left, suite.Point().Mul(L[i], pi))
+	}
+	xk := suite.Scalar().One()
+	for k := 0; k < m; k++ {
+		left.Sub(left, suite.Point().Mul(sig.CD[k], xk))
+		xk.Mul(xk, x)
+	}
+	if !left.Equal(commit(suite, H, tmp.Zero(), sig.ZD)) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
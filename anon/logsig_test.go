@@ -0,0 +1,87 @@
+package anon
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/nist"
+)
+
+func testLogSign(t *testing.T, suite abstract.Suite, n int) {
+	rand := suite.Cipher([]byte("logsig"))
+	X := make(Set, n)
+	for i := range X {
+		X[i], _ = suite.Point().Pick(nil, rand)
+	}
+	mine := n / 2
+	x := suite.Scalar().Pick(rand)
+	X[mine] = suite.Point().Mul(nil, x)
+
+	M := []byte("Hello World!")
+	sig, err := LogSign(suite, rand, M, X, mine, x)
+	if err != nil {
+		t.Fatalf("n=%d: %v", n, err)
+	}
+	if err := LogVerify(suite, M, X, sig); err != nil {
+		t.Fatalf("n=%d: %v", n, err)
+	}
+	if err := LogVerify(suite, []byte("Goodbye world!"), X, sig); err == nil {
+		t.Fatalf("n=%d: signature verified against wrong message", n)
+	}
+	if err := LogVerify(suite, M, X, sig[:len(sig)-1]); err == nil {
+		t.Fatalf("n=%d: truncated signature verified", n)
+	}
+
+	// Signing with a key not in the set must not verify
+	y := suite.Scalar().Pick(rand)
+	bad, err := LogSign(suite, rand, M, X, mine, y)
+	if err != nil {
+		t.Fatalf("n=%d: %v", n, err)
+	}
+	if err := LogVerify(suite, M, X, bad); err == nil {
+		t.Fatalf("n=%d: signature by outsider verified", n)
+	}
+}
+
+func TestLogSign(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 8, 13} {
+		testLogSign(t, nist.NewAES128SHA256P256(), n)
+		testLogSign(t, edwards.NewAES128SHA256Ed25519(false), n)
+	}
+}
+
+func TestLogSignInvalid(t *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	rand := suite.Cipher([]byte("logsig"))
+	x := suite.Scalar().Pick(rand)
+	if _, err := LogSign(suite, rand, nil, Set{}, 0, x); err == nil {
+		t.Fatal("signature with an empty anonymity set")
+	}
+	X := Set{suite.Point().Mul(nil, x)}
+	for _, mine := range []int{-1, 1} {
+		if _, err := LogSign(suite, rand, nil, X, mine, x); err == nil {
+			t.Fatalf("signature with signer index %d out of range", mine)
+		}
+	}
+}
+
+func TestLogSignSize(t *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	rand := suite.Cipher([]byte("logsig"))
+	size := func(n int) int {
+		X := make(Set, n)
+		for i := range X {
+			X[i], _ = suite.Point().Pick(nil, rand)
+		}
+		sig, err := LogSign(suite, rand, nil, X, 0, suite.Scalar().Pick(rand))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(sig)
+	}
+	// Doubling the set adds a constant number of elements
+	if size(64)-size(32) != size(32)-size(16) {
+		t.Fatal("signature size is not logarithmic in the set size")
+	}
+}
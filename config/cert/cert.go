@@ -0,0 +1,224 @@
+// Package cert implements minimal certificates binding the long-term public
+// keys of participants, such as the insurers of a Deal, to names. A
+// certificate holds a subject name, a public key and a validity period, and is
+// signed by the key of its issuer with a Schnorr signature bound to the
+// context of this package. Certificates form chains, from the certificate of a
+// participant up to a certificate issued by a trusted root key, which
+// VerifyChain checks. Only the keys of CA certificates, created with NewCA, may
+// issue the other certificates of a chain, so that the holder of a leaf
+// certificate cannot issue certificates for any name.
+//
+// NewRoster builds a config.Roster from verified certificate chains, so that
+// the keys of a group of participants can be bound to their names.
+package cert
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/sign"
+)
+
+// Domain of the signatures of certificates, see sign.SignWithDomain.
+const signDomain = "config/cert"
+
+// Some error definitions.
+var errorValidity = errors.New("cert: certificate not valid at this time")
+var errorIssuer = errors.New("cert: certificate not issued by the expected key")
+var errorEmptyChain = errors.New("cert: empty certificate chain")
+var errorUntrusted = errors.New("cert: chain not issued by a trusted root")
+var errorFormat = errors.New("cert: invalid certificate encoding")
+var errorDifferentLengths = errors.New("cert: inputs of different lengths")
+var errorNotCA = errors.New("cert: certificate issued by a non-CA certificate")
+
+// Certificate binds a public key to the name of its owner for a validity
+// period. It is signed by the key of its issuer, which is the certified key
+// itself for self-signed certificates.
+type Certificate struct {
+	Subject   string         // Name of the owner of the key
+	Public    abstract.Point // Certified public key
+	NotBefore time.Time      // Start of the validity period
+	NotAfter  time.Time      // End of the validity period
+	IsCA      bool           // Whether the key may issue certificates
+	Issuer    abstract.Point // Public key of the issuer
+	Signature []byte         // Signature of the issuer
+}
+
+// New creates a leaf certificate for the given subject name and public key,
+// valid from notBefore to notAfter, and signs it with the key pair of the
+// issuer. The certified key may not issue certificates, see NewCA.
+// Validity times are kept with a precision of one second.
+func New(subject string, public abstract.Point, notBefore, notAfter time.Time, issuer *config.KeyPair) (*Certificate, error) {
+	return newCertificate(subject, public, notBefore, notAfter, false, issuer)
+}
+
+// NewCA creates a certificate as New, whose key may issue the certificates
+// of a chain.
+func NewCA(subject string, public abstract.Point, notBefore, notAfter time.Time, issuer *config.KeyPair) (*Certificate, error) {
+	return newCertificate(subject, public, notBefore, notAfter, true, issuer)
+}
+
+func newCertificate(subject string, public abstract.Point, notBefore, notAfter time.Time, isCA bool, issuer *config.KeyPair) (*Certificate, error) {
+	c := &Certificate{
+		Subject:   subject,
+		Public:    public,
+		NotBefore: time.Unix(notBefore.Unix(), 0),
+		NotAfter:  time.Unix(notAfter.Unix(), 0),
+		IsCA:      isCA,
+		Issuer:    issuer.Public,
+	}
+	msg, err := c.content()
+	if err != nil {
+		return nil, err
+	}
+	c.Signature, err = sign.SignWithDomain(issuer.Suite, issuer.Secret, signDomain, msg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Verify checks that the certificate is valid at time now and is signed by
+// its issuer. It returns nil iff the certificate is valid.
+func (c *Certificate) Verify(suite abstract.Suite, now time.Time) error {
+	if now.Before(c.NotBefore) || now.After(c.NotAfter) {
+		return errorValidity
+	}
+	msg, err := c.content()
+	if err != nil {
+		return err
+	}
+	return sign.VerifyWithDomain(suite, c.Issuer, signDomain, msg, c.Signature)
+}
+
+// VerifyChain checks a certificate chain at time now, where chain[0] is the
+// certificate to verify and each certificate is issued by the key certified by
+// the next one, which must be a CA certificate (see NewCA). The last
+// certificate must be issued by one of the trusted root keys. The function
+// returns nil iff the whole chain is valid.
+func VerifyChain(suite abstract.Suite, chain []*Certificate, roots []abstract.Point, now time.Time) error {
+	if len(chain) == 0 {
+		return errorEmptyChain
+	}
+	for k, c := range chain {
+		if k > 0 && !c.IsCA {
+			return errorNotCA
+		}
+		if k+1 < len(chain) && !c.Issuer.Equal(chain[k+1].Public) {
+			return errorIssuer
+		}
+		if err := c.Verify(suite, now); err != nil {
+			return err
+		}
+	}
+	last := chain[len(chain)-1]
+	for _, root := range roots {
+		if last.Issuer.Equal(root) {
+			return nil
+		}
+	}
+	return errorUntrusted
+}
+
+// NewRoster verifies the certificate chains of the members of a group at time
+// now and creates their roster, with the given network endpoints. The order
+// of the chains defines the index of the members, and the subject of each
+// member's certificate becomes its description.
+func NewRoster(suite abstract.Suite, chains [][]*Certificate, roots []abstract.Point, addresses []string, now time.Time) (*config.Roster, error) {
+	if len(chains) != len(addresses) {
+		return nil, errorDifferentLengths
+	}
+	publics := make([]abstract.Point, len(chains))
+	for i, chain := range chains {
+		if err := VerifyChain(suite, chain, roots, now); err != nil {
+			return nil, err
+		}
+		publics[i] = chain[0].Public
+	}
+	roster, err := config.NewRoster(suite, publics, addresses)
+	if err != nil {
+		return nil, err
+	}
+	for i, chain := range chains {
+		roster.Members[i].Description = chain[0].Subject
+	}
+	return roster, nil
+}
+
+// MarshalBinary encodes the certificate as
+// ||len(subject)||subject||public||notBefore||notAfter||isCA||issuer||signature||,
+// with the length as a uint32, the times as int64 Unix times and isCA as a
+// byte, 1 for CA certificates and 0 otherwise.
+func (c *Certificate) MarshalBinary() ([]byte, error) {
+	msg, err := c.content()
+	if err != nil {
+		return nil, err
+	}
+	return append(msg, c.Signature...), nil
+}
+
+// Unmarshal decodes a certificate of the given suite encoded by
+// MarshalBinary. The signature is not verified.
+func Unmarshal(suite abstract.Suite, buf []byte) (*Certificate, error) {
+	r := bytes.NewReader(buf)
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, errorFormat
+	}
+	if int64(n) > int64(r.Len()) {
+		return nil, errorFormat
+	}
+	subject := make([]byte, n)
+	r.Read(subject)
+	c := &Certificate{Subject: string(subject)}
+	c.Public = suite.Point()
+	if _, err := c.Public.UnmarshalFrom(r); err != nil {
+		return nil, err
+	}
+	var times [2]int64
+	if err := binary.Read(r, binary.BigEndian, &times); err != nil {
+		return nil, errorFormat
+	}
+	c.NotBefore = time.Unix(times[0], 0)
+	c.NotAfter = time.Unix(times[1], 0)
+	isCA, err := r.ReadByte()
+	if err != nil || isCA > 1 {
+		return nil, errorFormat
+	}
+	c.IsCA = isCA == 1
+	c.Issuer = suite.Point()
+	if _, err := c.Issuer.UnmarshalFrom(r); err != nil {
+		return nil, err
+	}
+	if r.Len() != suite.PointLen()+suite.ScalarLen() {
+		return nil, errorFormat
+	}
+	c.Signature = make([]byte, r.Len())
+	r.Read(c.Signature)
+	return c, nil
+}
+
+// content returns the encoding of the certificate without its signature,
+// which is the message signed by the issuer.
+func (c *Certificate) content() ([]byte, error) {
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, uint32(len(c.Subject)))
+	b.WriteString(c.Subject)
+	if _, err := c.Public.MarshalTo(&b); err != nil {
+		return nil, err
+	}
+	binary.Write(&b, binary.BigEndian, [2]int64{c.NotBefore.Unix(), c.NotAfter.Unix()})
+	if c.IsCA {
+		b.WriteByte(1)
+	} else {
+		b.WriteByte(0)
+	}
+	if _, err := c.Issuer.MarshalTo(&b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,131 @@
+package cert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/edwards"
+)
+
+func TestChain(t *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	now := time.Now()
+	later := now.Add(time.Hour)
+	root := config.NewKeyPair(suite)
+	ca := config.NewKeyPair(suite)
+	node := config.NewKeyPair(suite)
+
+	caCert, err := NewCA("ca", ca.Public, now, later, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodeCert, err := New("node", node.Public, now, later, ca)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := []*Certificate{nodeCert, caCert}
+	roots := []abstract.Point{root.Public}
+	if err := VerifyChain(suite, chain, roots, now); err != nil {
+		t.Fatal("Valid chain rejected:", err)
+	}
+
+	// Encoding round trip
+	buf, err := nodeCert.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Unmarshal(suite, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyChain(suite, []*Certificate{decoded, caCert}, roots, now); err != nil {
+		t.Fatal("Decoded certificate rejected:", err)
+	}
+	if decoded.Subject != "node" || !decoded.Public.Equal(node.Public) ||
+		decoded.IsCA {
+		t.Fatal("Certificate differs after decoding")
+	}
+	if _, err := Unmarshal(suite, buf[:len(buf)-1]); err != errorFormat {
+		t.Fatal("Truncated certificate decoded:", err)
+	}
+
+	// Invalid chains
+	if err := VerifyChain(suite, chain, roots, later.Add(time.Second)); err != errorValidity {
+		t.Fatal("Expired chain accepted:", err)
+	}
+	if err := VerifyChain(suite, chain, []abstract.Point{ca.Public}, now); err != errorUntrusted {
+		t.Fatal("Untrusted chain accepted:", err)
+	}
+	if err := VerifyChain(suite, []*Certificate{nodeCert, nodeCert}, roots, now); err != errorIssuer {
+		t.Fatal("Broken chain accepted:", err)
+	}
+	if err := VerifyChain(suite, nil, roots, now); err != errorEmptyChain {
+		t.Fatal("Empty chain accepted:", err)
+	}
+	forged := *nodeCert
+	forged.Subject = "other"
+	if err := VerifyChain(suite, []*Certificate{&forged, caCert}, roots, now); err == nil {
+		t.Fatal("Forged certificate accepted")
+	}
+
+	// The holder of a leaf certificate cannot issue certificates
+	rogue := config.NewKeyPair(suite)
+	rogueCert, err := New("ca", rogue.Public, now, later, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafChain := []*Certificate{rogueCert, nodeCert, caCert}
+	if err := VerifyChain(suite, leafChain, roots, now); err != errorNotCA {
+		t.Fatal("Certificate issued by a leaf accepted:", err)
+	}
+	// Nor turn its certificate into a CA one, which the signature covers
+	promoted := *nodeCert
+	promoted.IsCA = true
+	leafChain[1] = &promoted
+	if err := VerifyChain(suite, leafChain, roots, now); err == nil {
+		t.Fatal("Certificate promoted to CA accepted")
+	}
+	caBuf, _ := caCert.MarshalBinary()
+	decodedCA, err := Unmarshal(suite, caBuf)
+	if err != nil || !decodedCA.IsCA {
+		t.Fatal("CA certificate differs after decoding", err)
+	}
+}
+
+func TestNewRoster(t *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	now := time.Now()
+	root := config.NewKeyPair(suite)
+	names := []string{"alice", "bob"}
+	chains := make([][]*Certificate, len(names))
+	publics := make([]abstract.Point, len(names))
+	for i, name := range names {
+		publics[i] = config.NewKeyPair(suite).Public
+		c, err := New(name, publics[i], now, now.Add(time.Hour), root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chains[i] = []*Certificate{c}
+	}
+	addrs := []string{"127.0.0.1:2000", "127.0.0.1:2001"}
+	roster, err := NewRoster(suite, chains, []abstract.Point{root.Public}, addrs, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, pubs, err := roster.Publics(map[string]abstract.Suite{suite.String(): suite})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range pubs {
+		if !pubs[i].Equal(publics[i]) || roster.Members[i].Description != names[i] {
+			t.Fatal("Wrong roster member", i)
+		}
+	}
+
+	other := config.NewKeyPair(suite)
+	if _, err := NewRoster(suite, chains, []abstract.Point{other.Public}, addrs, now); err != errorUntrusted {
+		t.Fatal("Roster of untrusted members created:", err)
+	}
+}
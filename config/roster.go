@@ -0,0 +1,181 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/base64"
+	"github.com/dedis/crypto/util"
+)
+
+// Prefix of the digests of Rosters, see Roster.Hash
+var rosterHashMsg = []byte("Roster")
+
+// Roster represents the configuration of a group of participants,
+// such as the insurers of a Deal or the co-signers of a CoSi round,
+// as stored in a TOML-format or JSON-format roster file.
+// All public keys of a roster belong to the same ciphersuite,
+// and the order of the members defines their index in the group.
+type Roster struct {
+	Suite   string         // Name of the ciphersuite of all public keys
+	Members []RosterMember // Participants, in index order
+}
+
+// RosterMember represents a single participant of a Roster.
+type RosterMember struct {
+	Public      string // Base64-encoded public key
+	Address     string // Network endpoint, e.g., "host:port"
+	Description string // Optional human-readable description
+}
+
+// NewRoster creates a roster for the given public keys and endpoints,
+// which must be of the same length.
+func NewRoster(suite abstract.Suite, publics []abstract.Point,
+	addresses []string) (*Roster, error) {
+	if len(publics) != len(addresses) {
+		return nil, errors.New("Different number of public keys and addresses")
+	}
+	r := &Roster{Suite: suite.String()}
+	for i := range publics {
+		buf, err := publics[i].MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		r.Members = append(r.Members, RosterMember{
+			Public:  base64.StdEncoding.EncodeToString(buf),
+			Address: addresses[i],
+		})
+	}
+	return r, nil
+}
+
+// ReadRoster reads a TOML-format roster from an io.Reader.
+func ReadRoster(r io.Reader) (*Roster, error) {
+	roster := new(Roster)
+	if _, err := toml.DecodeReader(r, roster); err != nil {
+		return nil, err
+	}
+	return roster, nil
+}
+
+// ReadRosterJSON reads a JSON-format roster from an io.Reader.
+func ReadRosterJSON(r io.Reader) (*Roster, error) {
+	roster := new(Roster)
+	if err := json.NewDecoder(r).Decode(roster); err != nil {
+		return nil, err
+	}
+	return roster, nil
+}
+
+// LoadRoster reads a roster file,
+// in JSON format if its name ends in ".json" and in TOML format otherwise.
+func LoadRoster(filename string) (*Roster, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if isJSON(filename) {
+		return ReadRosterJSON(f)
+	}
+	return ReadRoster(f)
+}
+
+// Write encodes the roster in TOML format to an io.Writer.
+func (r *Roster) Write(w io.Writer) error {
+	return toml.NewEncoder(w).Encode(r)
+}
+
+// WriteJSON encodes the roster in JSON format to an io.Writer.
+func (r *Roster) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(r)
+}
+
+// Save writes the roster to a file, in the format selected by its name
+// as for LoadRoster, atomically replacing any existing file of the same name.
+func (r *Roster) Save(filename string) error {
+	rep := util.Replacer{}
+	if err := rep.Open(filename); err != nil {
+		return err
+	}
+	defer rep.Abort()
+
+	write := r.Write
+	if isJSON(filename) {
+		write = r.WriteJSON
+	}
+	if err := write(rep.File); err != nil {
+		return err
+	}
+	return rep.Commit()
+}
+
+func isJSON(filename string) bool {
+	return filepath.Ext(filename) == ".json"
+}
+
+// Hash returns a digest identifying the group of the roster, e.g.,
+// as input to the session identifiers of the protocols it runs.
+// The digest covers the name of the ciphersuite and the binary encoding
+// of the public keys, in index order, but neither the addresses
+// nor the descriptions of the members, which may change
+// while the group stays the same. It does not depend on the format
+// the roster was read from or written in.
+func (r *Roster) Hash() ([]byte, error) {
+	h := sha256.New()
+	h.Write(rosterHashMsg)
+	binary.Write(h, binary.BigEndian, uint32(len(r.Suite)))
+	h.Write([]byte(r.Suite))
+	binary.Write(h, binary.BigEndian, uint32(len(r.Members)))
+	for _, m := range r.Members {
+		buf, err := base64.StdEncoding.DecodeString(m.Public)
+		if err != nil {
+			return nil, err
+		}
+		binary.Write(h, binary.BigEndian, uint32(len(buf)))
+		h.Write(buf)
+	}
+	return h.Sum(nil), nil
+}
+
+// Publics looks up the roster's ciphersuite in the given suites map
+// and decodes the public keys of all members, in index order.
+func (r *Roster) Publics(suites map[string]abstract.Suite) (abstract.Suite,
+	[]abstract.Point, error) {
+
+	suite := suites[r.Suite]
+	if suite == nil {
+		return nil, nil, errors.New("Unsupported ciphersuite '" + r.Suite + "'")
+	}
+
+	publics := make([]abstract.Point, len(r.Members))
+	for i, m := range r.Members {
+		buf, err := base64.StdEncoding.DecodeString(m.Public)
+		if err != nil {
+			return nil, nil, err
+		}
+		publics[i] = suite.Point()
+		if err := publics[i].UnmarshalBinary(buf); err != nil {
+			return nil, nil, err
+		}
+	}
+	return suite, publics, nil
+}
+
+// Addresses returns the network endpoints of all members, in index order.
+func (r *Roster) Addresses() []string {
+	addrs := make([]string, len(r.Members))
+	for i, m := range r.Members {
+		addrs[i] = m.Address
+	}
+	return addrs
+}
@@ -0,0 +1,142 @@
+package config_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/nist"
+)
+
+func TestRoster(t *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	n := 5
+	publics := make([]abstract.Point, n)
+	addrs := make([]string, n)
+	for i := range publics {
+		publics[i] = config.NewKeyPair(suite).Public
+		addrs[i] = fmt.Sprintf("127.0.0.1:%d", 2000+i)
+	}
+
+	if _, err := config.NewRoster(suite, publics, addrs[1:]); err == nil {
+		t.Fatal("Roster with mismatched lengths accepted")
+	}
+
+	roster, err := config.NewRoster(suite, publics, addrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := roster.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := config.ReadRoster(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suites := map[string]abstract.Suite{suite.String(): suite}
+	s, pubs, err := decoded.Publics(suites)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != suite || len(pubs) != n {
+		t.Fatal("Wrong suite or number of public keys")
+	}
+	for i := range pubs {
+		if !pubs[i].Equal(publics[i]) {
+			t.Fatal("Public keys differ at index", i)
+		}
+		if decoded.Addresses()[i] != addrs[i] {
+			t.Fatal("Addresses differ at index", i)
+		}
+	}
+
+	// The JSON encoding yields the same roster
+	buf.Reset()
+	if err := roster.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := config.ReadRosterJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromJSON, roster) {
+		t.Fatal("Roster changed by the JSON encoding")
+	}
+
+	other := nist.NewAES128SHA256P256()
+	if _, _, err := decoded.Publics(map[string]abstract.Suite{other.String(): other}); err == nil {
+		t.Fatal("Roster decoded with unknown suite")
+	}
+}
+
+func TestRosterHash(t *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	n := 4
+	publics := make([]abstract.Point, n)
+	addrs := make([]string, n)
+	for i := range publics {
+		publics[i] = config.NewKeyPair(suite).Public
+		addrs[i] = fmt.Sprintf("127.0.0.1:%d", 2000+i)
+	}
+	roster, _ := config.NewRoster(suite, publics, addrs)
+	h, err := roster.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The hash does not depend on the encoding of the roster file
+	dir, err := ioutil.TempDir("", "roster")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"roster.toml", "roster.json"} {
+		filename := filepath.Join(dir, name)
+		if err := roster.Save(filename); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := config.LoadRoster(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hl, err := loaded.Hash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(h, hl) {
+			t.Fatal("Hash of the roster changed by", name)
+		}
+	}
+	data, _ := ioutil.ReadFile(filepath.Join(dir, "roster.json"))
+	if len(data) == 0 || data[0] != '{' {
+		t.Fatal("Roster not saved in JSON format")
+	}
+
+	// nor on the addresses or descriptions of the members
+	moved, _ := config.NewRoster(suite, publics, make([]string, n))
+	moved.Members[0].Description = "moved"
+	if hm, _ := moved.Hash(); !bytes.Equal(h, hm) {
+		t.Fatal("Hash depends on the addresses")
+	}
+
+	// but it depends on the order of the keys
+	publics[0], publics[1] = publics[1], publics[0]
+	swapped, _ := config.NewRoster(suite, publics, addrs)
+	if hs, _ := swapped.Hash(); bytes.Equal(h, hs) {
+		t.Fatal("Hash does not depend on the order of the keys")
+	}
+
+	swapped.Members[0].Public = "not base64!"
+	if _, err := swapped.Hash(); err == nil {
+		t.Fatal("Hash of an invalid key")
+	}
+}
@@ -0,0 +1,99 @@
+// Package dh implements elliptic-curve Diffie-Hellman key agreement.
+//
+// ECDH works over any abstract.Group, such as the group of a ciphersuite,
+// and guards against small-subgroup attacks by clearing the cofactor of the
+// group (see abstract.Cofactor) and rejecting the identity as a shared secret.
+// X25519 and X448 implement the Diffie-Hellman functions of RFC 7748
+// on raw byte strings, for interoperability with other implementations.
+package dh
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/ed25519"
+)
+
+// X25519Basepoint is the u-coordinate of the base point of Curve25519.
+var X25519Basepoint = []byte{9, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+
+var errorZero = errors.New("dh: shared secret is the identity")
+var errorLength = errors.New("dh: invalid input length")
+
+// mulCofactor returns h*P for the cofactor h of group g. It uses point
+// additions, since scalars are reduced modulo the prime order.
+// Not all groups support aliased arguments to Add.
+func mulCofactor(g abstract.Group, P abstract.Point) abstract.Point {
+	h := abstract.Cofactor(g)
+	if h.Cmp(big.NewInt(1)) == 0 {
+		return P
+	}
+	R := g.Point().Null()
+	for i := h.BitLen() - 1; i >= 0; i-- {
+		R = g.Point().Add(R, R)
+		if h.Bit(i) != 0 {
+			R = g.Point().Add(R, P)
+		}
+	}
+	return R
+}
+
+// ECDH computes the Diffie-Hellman shared secret between a private key and
+// a peer's public key in group g, and returns its binary encoding.
+// The shared secret is h*private*peer, where h is the cofactor of g,
+// so that a peer key with a small-order component cannot leak bits of the
+// private key. Both parties therefore obtain the same value.
+// An error is returned if the shared secret is the identity,
+// as happens when the peer key has small order.
+func ECDH(g abstract.Group, private abstract.Scalar, peer abstract.Point) ([]byte, error) {
+	S := mulCofactor(g, g.Point().Mul(peer, private))
+	if S.Equal(g.Point().Null()) {
+		return nil, errorZero
+	}
+	return S.MarshalBinary()
+}
+
+// X25519 computes the X25519 function of RFC 7748 on a 32-byte scalar and
+// a 32-byte u-coordinate. Use X25519Basepoint as u to compute a
+// public key. An error is returned if the output is all zeroes, which
+// happens when the peer's u-coordinate is of small order.
+func X25519(scalar, u []byte) ([]byte, error) {
+	if len(scalar) != 32 || len(u) != 32 {
+		return nil, errorLength
+	}
+	var dst, s, p [32]byte
+	copy(s[:], scalar)
+	copy(p[:], u)
+	ed25519.X25519(&dst, &s, &p)
+	if allZero(dst[:]) {
+		return nil, errorZero
+	}
+	return dst[:], nil
+}
+
+// X448 computes the X448 function of RFC 7748 on a 56-byte scalar and
+// a 56-byte u-coordinate. Use X448Basepoint as u to compute a public key.
+// An error is returned if the output is all zeroes.
+//
+// This implementation uses math/big and does not run in constant time.
+func X448(scalar, u []byte) ([]byte, error) {
+	if len(scalar) != x448Size || len(u) != x448Size {
+		return nil, errorLength
+	}
+	dst := x448(scalar, u)
+	if allZero(dst) {
+		return nil, errorZero
+	}
+	return dst, nil
+}
+
+// allZero checks in constant time whether b consists only of zero bytes.
+func allZero(b []byte) bool {
+	var acc byte
+	for _, v := range b {
+		acc |= v
+	}
+	return acc == 0
+}
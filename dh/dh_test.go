@@ -0,0 +1,125 @@
+package dh
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/ed25519"
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/nist"
+	"github.com/dedis/crypto/random"
+	"github.com/stretchr/testify/require"
+)
+
+func fromHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func testECDH(t *testing.T, suite abstract.Suite) {
+	a := suite.Scalar().Pick(random.Stream)
+	b := suite.Scalar().Pick(random.Stream)
+	A := suite.Point().Mul(nil, a)
+	B := suite.Point().Mul(nil, b)
+
+	sa, err := ECDH(suite, a, B)
+	require.Nil(t, err)
+	sb, err := ECDH(suite, b, A)
+	require.Nil(t, err)
+	require.Equal(t, sa, sb)
+
+	_, err = ECDH(suite, a, suite.Point().Null())
+	require.Equal(t, errorZero, err)
+}
+
+func TestECDH(t *testing.T) {
+	testECDH(t, nist.NewAES128SHA256P256())
+	testECDH(t, nist.NewAES128SHA256QR512())
+	testECDH(t, ed25519.NewAES128SHA256Ed25519(false))
+	testECDH(t, ed25519.NewSHA3Ed25519())
+	testECDH(t, edwards.NewAES128SHA256Ed25519(false))
+}
+
+// The torsion component of a peer key is cleared in every Ed25519 suite,
+// whatever its name.
+func TestECDHTorsion(t *testing.T) {
+	// (0, -1), the point of order 2 of Ed25519
+	buf := bytes.Repeat([]byte{0xff}, 32)
+	buf[0], buf[31] = 0xec, 0x7f
+	for _, suite := range []abstract.Suite{
+		ed25519.NewAES128SHA256Ed25519(false),
+		ed25519.NewSHA3Ed25519(),
+		edwards.NewAES128SHA256Ed25519(false),
+	} {
+		T := suite.Point()
+		require.Nil(t, T.UnmarshalBinary(buf))
+		a := suite.Scalar().Pick(random.Stream)
+		B := suite.Point().Mul(nil, suite.Scalar().Pick(random.Stream))
+		s1, err := ECDH(suite, a, B)
+		require.Nil(t, err)
+		s2, err := ECDH(suite, a, suite.Point().Add(B, T))
+		require.Nil(t, err)
+		require.Equal(t, s1, s2, suite.String())
+
+		_, err = ECDH(suite, a, T)
+		require.Equal(t, errorZero, err)
+	}
+}
+
+// RFC 7748, section 5.2 and 6.1
+func TestX25519(t *testing.T) {
+	out, err := X25519(
+		fromHex("a546e36bf0527c9d3b16154b82465edd62144c0ac1fc5a18506a2244ba449ac4"),
+		fromHex("e6db6867583030db3594c1a424b15f7c726624ec26b3353b10a903a6d0ab1c4c"))
+	require.Nil(t, err)
+	require.Equal(t, fromHex("c3da55379de9c6908e94ea4df28d084f32eccf03491c71f754b4075577a28552"), out)
+
+	alice := fromHex("77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+	bob := fromHex("5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb")
+	alicePub, err := X25519(alice, X25519Basepoint)
+	require.Nil(t, err)
+	require.Equal(t, fromHex("8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a"), alicePub)
+	bobPub, err := X25519(bob, X25519Basepoint)
+	require.Nil(t, err)
+	s1, err := X25519(alice, bobPub)
+	require.Nil(t, err)
+	s2, err := X25519(bob, alicePub)
+	require.Nil(t, err)
+	require.Equal(t, fromHex("4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742"), s1)
+	require.Equal(t, s1, s2)
+
+	// Small-order points are rejected
+	_, err = X25519(alice, make([]byte, 32))
+	require.Equal(t, errorZero, err)
+	_, err = X25519(alice, X25519Basepoint[1:])
+	require.Equal(t, errorLength, err)
+}
+
+// RFC 7748, section 5.2 and 6.2
+func TestX448(t *testing.T) {
+	out, err := X448(
+		fromHex("3d262fddf9ec8e88495266fea19a34d28882acef045104d0d1aae121700a779c984c24f8cdd78fbff44943eba368f54b29259a4f1c600ad3"),
+		fromHex("06fce640fa3487bfda5f6cf2d5263f8aad88334cbd07437f020f08f9814dc031ddbdc38c19c6da2583fa5429db94ada18aa7a7fb4ef8a086"))
+	require.Nil(t, err)
+	require.Equal(t, fromHex("ce3e4ff95a60dc6697da1db1d85e6afbdf79b50a2412d7546d5f239fe14fbaadeb445fc66a01b0779d98223961111e21766282f73dd96b6f"), out)
+
+	alice := fromHex("9a8f4925d1519f5775cf46b04b5800d4ee9ee8bae8bc5565d498c28dd9c9baf574a9419744897391006382a6f127ab1d9ac2d8c0a598726b")
+	bob := fromHex("1c306a7ac2a0e2e0990b294470cba339e6453772b075811d8fad0d1d6927c120bb5ee8972b0d3e21374c9c921b09d1b0366f10b65173992d")
+	alicePub, err := X448(alice, X448Basepoint)
+	require.Nil(t, err)
+	bobPub, err := X448(bob, X448Basepoint)
+	require.Nil(t, err)
+	s1, err := X448(alice, bobPub)
+	require.Nil(t, err)
+	s2, err := X448(bob, alicePub)
+	require.Nil(t, err)
+	require.Equal(t, s1, s2)
+
+	_, err = X448(alice, make([]byte, 56))
+	require.Equal(t, errorZero, err)
+}
@@ -0,0 +1,85 @@
+package dh
+
+import (
+	"math/big"
+)
+
+const x448Size = 56
+
+// X448Basepoint is the u-coordinate of the base point of Curve448.
+var X448Basepoint = []byte{5, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+
+// p448 = 2^448 - 2^224 - 1
+var p448 = new(big.Int).Sub(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 448),
+	new(big.Int).Lsh(big.NewInt(1), 224)), big.NewInt(1))
+
+var a24x448 = big.NewInt(39081)
+
+// reverse returns a copy of b in reverse byte order,
+// converting between little-endian and math/big's big-endian.
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+// x448 runs the Montgomery ladder of RFC 7748, section 5, for Curve448.
+func x448(scalar, u []byte) []byte {
+	k := make([]byte, x448Size)
+	copy(k, scalar)
+	k[0] &= 252
+	k[55] |= 128
+
+	p := p448
+	x1 := new(big.Int).SetBytes(reverse(u))
+	x1.Mod(x1, p)
+	x2, z2 := big.NewInt(1), big.NewInt(0)
+	x3, z3 := new(big.Int).Set(x1), big.NewInt(1)
+	a, aa, b, bb, e := new(big.Int), new(big.Int), new(big.Int), new(big.Int), new(big.Int)
+	c, d, da, cb := new(big.Int), new(big.Int), new(big.Int), new(big.Int)
+
+	swap := uint(0)
+	for t := 447; t >= 0; t-- {
+		kt := uint(k[t/8]>>uint(t&7)) & 1
+		swap ^= kt
+		if swap == 1 {
+			x2, x3 = x3, x2
+			z2, z3 = z3, z2
+		}
+		swap = kt
+
+		a.Add(x2, z2)
+		aa.Mul(a, a).Mod(aa, p)
+		b.Sub(x2, z2)
+		bb.Mul(b, b).Mod(bb, p)
+		e.Sub(aa, bb)
+		c.Add(x3, z3)
+		d.Sub(x3, z3)
+		da.Mul(d, a).Mod(da, p)
+		cb.Mul(c, b).Mod(cb, p)
+		x3.Add(da, cb)
+		x3.Mul(x3, x3).Mod(x3, p)
+		z3.Sub(da, cb)
+		z3.Mul(z3, z3).Mul(z3, x1).Mod(z3, p)
+		x2.Mul(aa, bb).Mod(x2, p)
+		z2.Mul(a24x448, e).Add(z2, aa).Mul(z2, e).Mod(z2, p)
+	}
+	if swap == 1 {
+		x2, z2 = x3, z3
+	}
+
+	if z2.Sign() == 0 {
+		return make([]byte, x448Size)
+	}
+	z2.ModInverse(z2, p)
+	x2.Mul(x2, z2).Mod(x2, p)
+
+	out := make([]byte, x448Size)
+	xb := x2.Bytes()
+	copy(out[x448Size-len(xb):], xb)
+	return reverse(out)
+}
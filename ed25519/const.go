@@ -25,6 +25,18 @@ var fullOrder = new(big.Int).Mul(&primeOrder.V, &cofactor.V)
 // identity point
 var nullPoint = new(point).Null()
 
+// prime order of base point, as a little-endian scalar
+var primeOrderBytes = func() (b [32]byte) {
+	be := primeOrder.V.Bytes()
+	for i := range be {
+		b[i] = be[len(be)-1-i]
+	}
+	return
+}()
+
+// encoding of the identity point
+var identityBytes = [32]byte{1}
+
 var d = fieldElement{
 	-10913610, 13857413, -15372611, 6949391, 114729, -8787816, -6275908, -3247719, -18696448, -12055116,
 }
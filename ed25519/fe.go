@@ -247,15 +247,19 @@ func feToBytes(s *[32]byte, h *fieldElement) {
 	s[31] = byte(h[9] >> 18)
 }
 
+// feIsNegative and feIsNonZero leave f untouched: feToBytes reduces its
+// input in place, into limbs too large for further additions.
 func feIsNegative(f *fieldElement) byte {
 	var s [32]byte
-	feToBytes(&s, f)
+	t := *f
+	feToBytes(&s, &t)
 	return s[0] & 1
 }
 
 func feIsNonZero(f *fieldElement) int32 {
 	var s [32]byte
-	feToBytes(&s, f)
+	t := *f
+	feToBytes(&s, &t)
 	var x uint8
 	for _, b := range s {
 		x |= b
@@ -19,6 +19,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"io"
+	"math/big"
 
 	"github.com/dedis/crypto/abstract"
 	"github.com/dedis/crypto/group"
@@ -52,6 +53,16 @@ func (P *point) UnmarshalBinary(b []byte) error {
 	return nil
 }
 
+// Check whether the point is in the prime-order subgroup, by multiplying
+// it by the subgroup order. FromBytes only checks that it lies on the curve.
+func (P *point) IsInCorrectSubgroup() bool {
+	var Q extendedGroupElement
+	geScalarMult(&Q, &primeOrderBytes, &P.ge)
+	var b [32]byte
+	Q.ToBytes(&b)
+	return b == identityBytes
+}
+
 func (P *point) MarshalTo(w io.Writer) (int, error) {
 	return group.PointMarshalTo(P, w)
 }
@@ -245,6 +256,16 @@ func (c *Curve) PrimeOrder() bool {
 	return true
 }
 
+// Returns the order of the prime-order subgroup of Ed25519.
+func (c *Curve) Order() *big.Int {
+	return new(big.Int).Set(&primeOrder.V)
+}
+
+// Returns 8, the cofactor of Ed25519.
+func (c *Curve) Cofactor() *big.Int {
+	return new(big.Int).Set(&cofactor.V)
+}
+
 // Return the name of the curve, "Ed25519".
 func (c *Curve) String() string {
 	return "Ed25519"
@@ -0,0 +1,449 @@
+package ed25519
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"math/big"
+	"reflect"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/cipher/sha3"
+	"github.com/dedis/crypto/group"
+	"github.com/dedis/crypto/random"
+)
+
+// This file implements the ristretto255 prime-order group of
+// RFC 9496, https://www.rfc-editor.org/rfc/rfc9496,
+// on top of the Ed25519 field and curve arithmetic.
+// Ristretto points are represented by Ed25519 points,
+// each ristretto255 element corresponding to a coset of
+// the 4-torsion subgroup of the curve.
+// The encoding of an element is canonical,
+// and does not depend on the point chosen to represent it,
+// so that ristretto255 has none of the cofactor pitfalls of Ed25519.
+
+var errInvalidRistretto = errors.New("invalid ristretto255 encoding")
+
+// Constants of RFC 9496, section 4.1.
+var sqrtADMinusOne = feFromDecimal("25063068953384623474111414158702152701244531502492656460079210482610430750235")
+var invSqrtAMinusD = feFromDecimal("54469307008909316920995813868745141605393597292927456921205312896311721017578")
+var oneMinusDSq = feFromDecimal("1159843021668779879193775521855586647937357759715417654439879720876111806838")
+var dMinusOneSq = feFromDecimal("40440834346308536858101042469323190826248399146238708352240133220865137265952")
+
+func feFromDecimal(s string) (fe fieldElement) {
+	v, _ := new(big.Int).SetString(s, 10)
+	var b [32]byte
+	vb := v.Bytes()
+	for i := range vb {
+		b[len(vb)-1-i] = vb[i]
+	}
+	feFromBytes(&fe, b[:])
+	return
+}
+
+// feEqual returns 1 if f == g, and 0 otherwise.
+func feEqual(f, g *fieldElement) int32 {
+	var h fieldElement
+	feSub(&h, f, g)
+	return 1 - feIsNonZero(&h)
+}
+
+// feAbs sets h to the non-negative one of f and -f.
+func feAbs(h, f *fieldElement) {
+	var n fieldElement
+	feNeg(&n, f)
+	feCopy(h, f)
+	feCMove(h, &n, int32(feIsNegative(f)))
+}
+
+// sqrtRatioM1 sets r to the non-negative square root of u/v if it exists,
+// and to the one of i*u/v otherwise, where i is a square root of -1.
+// It returns 1 if u/v is square, and 0 otherwise.
+func sqrtRatioM1(r, u, v *fieldElement) int32 {
+	var v3, v7, t, check, negU, negUi, rPrime fieldElement
+	feSquare(&v3, v)
+	feMul(&v3, &v3, v) // v^3
+	feSquare(&v7, &v3)
+	feMul(&v7, &v7, v) // v^7
+	feMul(&t, u, &v7)
+	fePow22523(&check, &t) // (u*v^7)^((p-5)/8)
+	feMul(&t, &check, &v3)
+	feMul(r, &t, u)
+
+	feSquare(&check, r)
+	feMul(&check, &check, v)
+	feNeg(&negU, u)
+	feMul(&negUi, &negU, &sqrtM1)
+	correct := feEqual(&check, u)
+	flipped := feEqual(&check, &negU)
+	flippedI := feEqual(&check, &negUi)
+
+	feMul(&rPrime, r, &sqrtM1)
+	feCMove(r, &rPrime, flipped|flippedI)
+	feAbs(r, r)
+	return correct | flipped
+}
+
+type ristrettoPoint struct {
+	p point
+}
+
+func (P *ristrettoPoint) String() string {
+	var b [32]byte
+	P.encode(&b)
+	return hex.EncodeToString(b[:])
+}
+
+func (P *ristrettoPoint) MarshalSize() int {
+	return 32
+}
+
+func (P *ristrettoPoint) MarshalBinary() ([]byte, error) {
+	var b [32]byte
+	P.encode(&b)
+	return b[:], nil
+}
+
+// Encode the point as in RFC 9496, section 4.3.2.
+func (P *ristrettoPoint) encode(s *[32]byte) {
+	ge := &P.p.ge
+	var one, t, zy, u1, u2, invsqrt, den1, den2, zInv fieldElement
+	var ix, iy, enchanted, x, y, denInv fieldElement
+	feOne(&one)
+	feAdd(&t, &ge.Z, &ge.Y)
+	feSub(&zy, &ge.Z, &ge.Y)
+	feMul(&u1, &t, &zy)
+	feMul(&u2, &ge.X, &ge.Y)
+	feSquare(&t, &u2)
+	feMul(&t, &t, &u1)
+	sqrtRatioM1(&invsqrt, &one, &t)
+	feMul(&den1, &invsqrt, &u1)
+	feMul(&den2, &invsqrt, &u2)
+	feMul(&zInv, &den1, &den2)
+	feMul(&zInv, &zInv, &ge.T)
+
+	feMul(&ix, &ge.X, &sqrtM1)
+	feMul(&iy, &ge.Y, &sqrtM1)
+	feMul(&enchanted, &den1, &invSqrtAMinusD)
+	feMul(&t, &ge.T, &zInv)
+	rotate := int32(feIsNegative(&t))
+	feCopy(&x, &ge.X)
+	feCopy(&y, &ge.Y)
+	feCopy(&denInv, &den2)
+	feCMove(&x, &iy, rotate)
+	feCMove(&y, &ix, rotate)
+	feCMove(&denInv, &enchanted, rotate)
+
+	feMul(&t, &x, &zInv)
+	feNeg(&zy, &y)
+	feCMove(&y, &zy, int32(feIsNegative(&t)))
+
+	feSub(&t, &ge.Z, &y)
+	feMul(&t, &denInv, &t)
+	feAbs(&t, &t)
+	feToBytes(s, &t)
+}
+
+// Decode a point as in RFC 9496, section 4.3.1,
+// rejecting any non-canonical encoding.
+func (P *ristrettoPoint) UnmarshalBinary(b []byte) error {
+	if len(b) != 32 {
+		return errInvalidRistretto
+	}
+	var s fieldElement
+	var c [32]byte
+	feFromBytes(&s, b)
+	feToBytes(&c, &s)
+	if !bytes.Equal(c[:], b) || feIsNegative(&s) == 1 {
+		return errInvalidRistretto
+	}
+
+	var one, ss, u1, u2, u2sq, v, t, invsqrt, denX, denY, x, y, xy fieldElement
+	feOne(&one)
+	feSquare(&ss, &s)
+	feSub(&u1, &one, &ss)
+	feAdd(&u2, &one, &ss)
+	feSquare(&u2sq, &u2)
+	feSquare(&t, &u1)
+	feMul(&t, &t, &d)
+	feNeg(&t, &t)
+	feSub(&v, &t, &u2sq) // -(d*u1^2) - u2^2
+	feMul(&t, &v, &u2sq)
+	wasSquare := sqrtRatioM1(&invsqrt, &one, &t)
+
+	feMul(&denX, &invsqrt, &u2)
+	feMul(&denY, &invsqrt, &denX)
+	feMul(&denY, &denY, &v)
+	feAdd(&t, &s, &s)
+	feMul(&x, &t, &denX)
+	feAbs(&x, &x)
+	feMul(&y, &u1, &denY)
+	feMul(&xy, &x, &y)
+	if wasSquare == 0 || feIsNegative(&xy) == 1 || feIsNonZero(&y) == 0 {
+		return errInvalidRistretto
+	}
+	P.p.ge = extendedGroupElement{x, y, one, xy}
+	return nil
+}
+
+func (P *ristrettoPoint) MarshalTo(w io.Writer) (int, error) {
+	return group.PointMarshalTo(P, w)
+}
+
+func (P *ristrettoPoint) UnmarshalFrom(r io.Reader) (int, error) {
+	return group.PointUnmarshalFrom(P, r)
+}
+
+// Equality test for two ristretto255 elements,
+// which holds for any of the points representing them.
+func (P *ristrettoPoint) Equal(P2 abstract.Point) bool {
+	g1 := &P.p.ge
+	g2 := &P2.(*ristrettoPoint).p.ge
+	var a, b fieldElement
+	feMul(&a, &g1.X, &g2.Y)
+	feMul(&b, &g1.Y, &g2.X)
+	e := feEqual(&a, &b)
+	feMul(&a, &g1.Y, &g2.Y)
+	feMul(&b, &g1.X, &g2.X)
+	return e|feEqual(&a, &b) == 1
+}
+
+func (P *ristrettoPoint) Set(P2 abstract.Point) abstract.Point {
+	P.p.ge = P2.(*ristrettoPoint).p.ge
+	return P
+}
+
+func (P *ristrettoPoint) Clone() abstract.Point {
+	return &ristrettoPoint{p: P.p}
+}
+
+func (P *ristrettoPoint) Null() abstract.Point {
+	P.p.Null()
+	return P
+}
+
+// Set to the standard base point, which is the one of Ed25519.
+func (P *ristrettoPoint) Base() abstract.Point {
+	P.p.Base()
+	return P
+}
+
+func (P *ristrettoPoint) PickLen() int {
+	// The least-significant bit of the encoding must be zero,
+	// and the 7 bits above it hold the embedded data length,
+	// leaving at least 16 bits of randomness.
+	return (255 - 8 - 16) / 8
+}
+
+func (P *ristrettoPoint) Pick(data []byte, rand cipher.Stream) (abstract.Point, []byte) {
+	if data == nil {
+		// Map 64 random bytes uniformly to the group
+		var b [64]byte
+		rand.XORKeyStream(b[:], b[:])
+		P.fromUniformBytes(&b)
+		return P, nil
+	}
+
+	dl := P.PickLen()
+	if dl > len(data) {
+		dl = len(data)
+	}
+	for {
+		// Every canonical encoding that decodes successfully
+		// represents an element, so we retry until one does.
+		var b [32]byte
+		rand.XORKeyStream(b[:], b[:])
+		b[0] = byte(dl) << 1
+		copy(b[1:1+dl], data)
+		b[31] &= 0x7f
+		if P.UnmarshalBinary(b[:]) == nil {
+			return P, data[dl:]
+		}
+	}
+}
+
+// Extract embedded data from a point group element
+func (P *ristrettoPoint) Data() ([]byte, error) {
+	var b [32]byte
+	P.encode(&b)
+	dl := int(b[0] >> 1)
+	if dl > P.PickLen() {
+		return nil, errors.New("invalid embedded data length")
+	}
+	return b[1 : 1+dl], nil
+}
+
+func (P *ristrettoPoint) Add(P1, P2 abstract.Point) abstract.Point {
+	P.p.Add(&P1.(*ristrettoPoint).p, &P2.(*ristrettoPoint).p)
+	return P
+}
+
+func (P *ristrettoPoint) Sub(P1, P2 abstract.Point) abstract.Point {
+	P.p.Sub(&P1.(*ristrettoPoint).p, &P2.(*ristrettoPoint).p)
+	return P
+}
+
+func (P *ristrettoPoint) Neg(A abstract.Point) abstract.Point {
+	P.p.Neg(&A.(*ristrettoPoint).p)
+	return P
+}
+
+func (P *ristrettoPoint) Mul(A abstract.Point, s abstract.Scalar) abstract.Point {
+	if A == nil {
+		P.p.Mul(nil, s)
+	} else {
+		P.p.Mul(&A.(*ristrettoPoint).p, s)
+	}
+	return P
+}
+
+// Set the point to the sum of the images of both halves of b
+// by the ristretto255 Elligator map, as in RFC 9496, section 4.3.4.
+func (P *ristrettoPoint) fromUniformBytes(b *[64]byte) {
+	var P1, P2 point
+	ristrettoMap(&P1.ge, b[:32])
+	ristrettoMap(&P2.ge, b[32:])
+	P.p.Add(&P1, &P2)
+}
+
+// The ristretto255 Elligator map of RFC 9496, section 4.3.4.
+func ristrettoMap(ge *extendedGroupElement, b []byte) {
+	var t, one, r, u, v, s, sPrime, c, n, w0, w1, w2, w3, tmp, tmp2 fieldElement
+	feFromBytes(&t, b) // ignores the most significant bit
+	feOne(&one)
+	feSquare(&r, &t)
+	feMul(&r, &r, &sqrtM1)
+	feAdd(&u, &r, &one)
+	feMul(&u, &u, &oneMinusDSq)
+	feMul(&tmp, &r, &d)
+	feNeg(&tmp, &tmp)
+	feSub(&tmp, &tmp, &one)
+	feAdd(&tmp2, &r, &d)
+	feMul(&v, &tmp, &tmp2) // (-1 - r*d) * (r + d)
+
+	wasSquare := sqrtRatioM1(&s, &u, &v)
+	feMul(&sPrime, &s, &t)
+	feAbs(&sPrime, &sPrime)
+	feNeg(&sPrime, &sPrime)
+	feCMove(&s, &sPrime, 1-wasSquare)
+	feNeg(&c, &one)
+	feCMove(&c, &r, 1-wasSquare)
+
+	feSub(&tmp, &r, &one)
+	feMul(&n, &c, &tmp)
+	feMul(&n, &n, &dMinusOneSq)
+	feSub(&n, &n, &v)
+
+	feMul(&w0, &s, &v)
+	feAdd(&w0, &w0, &w0)
+	feMul(&w1, &n, &sqrtADMinusOne)
+	feSquare(&tmp, &s)
+	feSub(&w2, &one, &tmp)
+	feAdd(&w3, &one, &tmp)
+	feMul(&ge.X, &w0, &w3)
+	feMul(&ge.Y, &w2, &w1)
+	feMul(&ge.Z, &w1, &w3)
+	feMul(&ge.T, &w0, &w2)
+}
+
+// Ristretto represents the ristretto255 group,
+// a prime-order group built from the Ed25519 curve.
+// As for Curve, no parameters or initialization are required.
+type Ristretto struct {
+}
+
+func (g *Ristretto) PrimeOrder() bool {
+	return true
+}
+
+// Returns the order of the group, the prime order of the subgroup of Ed25519.
+func (g *Ristretto) Order() *big.Int {
+	return new(big.Int).Set(&primeOrder.V)
+}
+
+// Returns 1, as the group has prime order.
+func (g *Ristretto) Cofactor() *big.Int {
+	return big.NewInt(1)
+}
+
+// Return the name of the group, "Ristretto255".
+func (g *Ristretto) String() string {
+	return "Ristretto255"
+}
+
+// Returns 32, the size in bytes of an encoded Scalar.
+func (g *Ristretto) ScalarLen() int {
+	return 32
+}
+
+// Create a new Scalar, modulo the order of the group,
+// which is the one of the prime-order subgroup of Ed25519.
+func (g *Ristretto) Scalar() abstract.Scalar {
+	return new(Curve).Scalar()
+}
+
+// Returns 32, the size in bytes of an encoded Point.
+func (g *Ristretto) PointLen() int {
+	return 32
+}
+
+// Create a new ristretto255 Point.
+func (g *Ristretto) Point() abstract.Point {
+	return new(ristrettoPoint)
+}
+
+// MapToPoint maps data to an element as in the hash-to-group examples of
+// RFC 9496, appendix A.3: the SHA-512 digest of data is mapped to the group
+// by the ristretto255 one-way map. It implements abstract.PointMapper.
+func (g *Ristretto) MapToPoint(data []byte) abstract.Point {
+	b := sha512.Sum512(data)
+	P := new(ristrettoPoint)
+	P.fromUniformBytes(&b)
+	return P
+}
+
+type suiteRistretto255 struct {
+	Ristretto
+}
+
+// SHA256 hash function
+func (s *suiteRistretto255) Hash() hash.Hash {
+	return sha256.New()
+}
+
+// SHA3/SHAKE128 Sponge Cipher
+func (s *suiteRistretto255) Cipher(key []byte, options ...interface{}) abstract.Cipher {
+	return sha3.NewShakeCipher128(key, options...)
+}
+
+func (s *suiteRistretto255) Read(r io.Reader, objs ...interface{}) error {
+	return abstract.SuiteRead(s, r, objs)
+}
+
+func (s *suiteRistretto255) Write(w io.Writer, objs ...interface{}) error {
+	return abstract.SuiteWrite(s, w, objs)
+}
+
+func (s *suiteRistretto255) New(t reflect.Type) interface{} {
+	return abstract.SuiteNew(s, t)
+}
+
+// NewKey returns a uniformly random private key. Unlike Ed25519 keys,
+// it needs no clamping since the group has no small subgroup.
+func (s *suiteRistretto255) NewKey(stream cipher.Stream) abstract.Scalar {
+	if stream == nil {
+		stream = random.Stream
+	}
+	return s.Scalar().Pick(stream)
+}
+
+// Ciphersuite based on SHA-256, SHAKE128, and the ristretto255 group.
+func NewRistretto255() abstract.Suite {
+	return new(suiteRistretto255)
+}
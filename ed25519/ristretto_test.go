@@ -0,0 +1,156 @@
+package ed25519
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/test"
+)
+
+var ristrettoSuite = NewRistretto255()
+
+func TestRistrettoSuite(t *testing.T) { test.TestSuite(ristrettoSuite) }
+
+func TestRistrettoConstants(t *testing.T) {
+	var one, t1, t2 fieldElement
+	feOne(&one)
+
+	// sqrtADMinusOne^2 == -d-1
+	feSquare(&t1, &sqrtADMinusOne)
+	feNeg(&t2, &d)
+	feSub(&t2, &t2, &one)
+	if feEqual(&t1, &t2) != 1 {
+		t.Error("wrong SQRT_AD_MINUS_ONE")
+	}
+	// invSqrtAMinusD^2 * (-1-d) == 1
+	feSquare(&t1, &invSqrtAMinusD)
+	feMul(&t1, &t1, &t2)
+	if feEqual(&t1, &one) != 1 {
+		t.Error("wrong INVSQRT_A_MINUS_D")
+	}
+	// oneMinusDSq == 1-d^2
+	feSquare(&t1, &d)
+	feSub(&t1, &one, &t1)
+	if feEqual(&t1, &oneMinusDSq) != 1 {
+		t.Error("wrong ONE_MINUS_D_SQ")
+	}
+	// dMinusOneSq == (d-1)^2
+	feSub(&t1, &d, &one)
+	feSquare(&t1, &t1)
+	if feEqual(&t1, &dMinusOneSq) != 1 {
+		t.Error("wrong D_MINUS_ONE_SQ")
+	}
+}
+
+// Encodings of the multiples of the base point, from RFC 9496, appendix A.1.
+var ristrettoMultiples = []string{
+	"0000000000000000000000000000000000000000000000000000000000000000",
+	"e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76",
+	"6a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919",
+	"94741f5d5d52755ece4f23f044ee27d5d1ea1e2bd196b462166b16152a9d0259",
+	"da80862773358b466ffadfe0b3293ab3d9fd53c5ea6c955358f568322daf6a57",
+}
+
+func TestRistrettoEncoding(t *testing.T) {
+	P := ristrettoSuite.Point().Null()
+	B := ristrettoSuite.Point().Base()
+	for i, enc := range ristrettoMultiples {
+		b, _ := P.MarshalBinary()
+		if hex.EncodeToString(b) != enc {
+			t.Errorf("wrong encoding of %d*B: %x", i, b)
+		}
+		Q := ristrettoSuite.Point()
+		if err := Q.UnmarshalBinary(b); err != nil || !Q.Equal(P) {
+			t.Errorf("wrong decoding of %d*B: %v", i, err)
+		}
+		P.Add(P, B)
+	}
+}
+
+func TestRistrettoInvalidEncodings(t *testing.T) {
+	// Non-canonical and negative field elements, from RFC 9496, appendix A.2.
+	for _, enc := range []string{
+		"00ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		"f3ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		"0100000000000000000000000000000000000000000000000000000000000000",
+		"01ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	} {
+		b, _ := hex.DecodeString(enc)
+		if ristrettoSuite.Point().UnmarshalBinary(b) == nil {
+			t.Errorf("invalid encoding %s accepted", enc)
+		}
+	}
+}
+
+func TestRistrettoTorsion(t *testing.T) {
+	// Points differing by an 8-torsion point of Ed25519
+	// are the same ristretto255 element, with the same encoding.
+	var T point
+	T.ge.FromBytes([]byte{0xec, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f})
+	P, _ := ristrettoSuite.Point().Pick(nil, ristrettoSuite.Cipher(abstract.RandomKey))
+	Q := ristrettoSuite.Point().(*ristrettoPoint)
+	Q.p.Add(&P.(*ristrettoPoint).p, &T)
+	if !P.Equal(Q) || P.String() != Q.String() {
+		t.Error("torsion component not eliminated")
+	}
+}
+
+func TestRistrettoOrder(t *testing.T) {
+	l := abstract.Order(ristrettoSuite)
+	if l == nil || l.Cmp(&primeOrder.V) != 0 || !l.ProbablyPrime(20) {
+		t.Fatal("wrong order", l)
+	}
+	if abstract.Cofactor(ristrettoSuite).Cmp(big.NewInt(1)) != 0 {
+		t.Error("ristretto255 should have cofactor 1")
+	}
+	P, _ := ristrettoSuite.Point().Pick(nil, ristrettoSuite.Cipher(abstract.RandomKey))
+	// (l-1)*P = -P, as l*P is the neutral element
+	b := new(big.Int).Sub(l, big.NewInt(1)).Bytes()
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	s := ristrettoSuite.Scalar().SetBytes(b)
+	if !ristrettoSuite.Point().Mul(P, s).Equal(ristrettoSuite.Point().Neg(P)) {
+		t.Error("the order should annihilate every element")
+	}
+}
+
+func TestRistrettoDecodedArithmetic(t *testing.T) {
+	// Decoded points must support the same arithmetic as the originals
+	rand := ristrettoSuite.Cipher(abstract.RandomKey)
+	for i := 0; i < 100; i++ {
+		P, _ := ristrettoSuite.Point().Pick(nil, rand)
+		b, _ := P.MarshalBinary()
+		D := ristrettoSuite.Point()
+		if err := D.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		x := ristrettoSuite.Scalar().Pick(rand)
+		Q := ristrettoSuite.Point().Add(P, ristrettoSuite.Point().Base())
+		if !ristrettoSuite.Point().Mul(P, x).Equal(ristrettoSuite.Point().Mul(D, x)) ||
+			!ristrettoSuite.Point().Add(D, ristrettoSuite.Point().Base()).Equal(Q) {
+			t.Fatal("arithmetic on decoded point differs", i)
+		}
+	}
+}
+
+// Hash-to-group vectors of RFC 9496, appendix A.3.
+func TestRistrettoMapToPoint(t *testing.T) {
+	vectors := []struct{ in, out string }{
+		{"Ristretto is traditionally a short shot of espresso coffee",
+			"3066f82a1a747d45120d1740f14358531a8f04bbffe6a819f86dfe50f44a0a46"},
+	}
+	for _, v := range vectors {
+		P := abstract.MapToPoint(ristrettoSuite, []byte(v.in))
+		b, _ := P.MarshalBinary()
+		if hex.EncodeToString(b) != v.out {
+			t.Errorf("MapToPoint(%q) = %x, expected %s", v.in, b, v.out)
+		}
+	}
+}
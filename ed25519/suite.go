@@ -62,3 +62,39 @@ func NewAES128SHA256Ed25519(fullGroup bool) abstract.Suite {
 	suite := new(suiteEd25519)
 	return suite
 }
+
+type suiteEd25519SHA3 struct {
+	suiteEd25519
+}
+
+func (s *suiteEd25519SHA3) String() string {
+	return "Ed25519-SHA3"
+}
+
+// SHA3-256 hash function
+func (s *suiteEd25519SHA3) Hash() hash.Hash {
+	return sha3.New256()
+}
+
+// SHA3/SHAKE256 Sponge Cipher
+func (s *suiteEd25519SHA3) Cipher(key []byte, options ...interface{}) abstract.Cipher {
+	return sha3.NewShakeCipher256(key, options...)
+}
+
+func (s *suiteEd25519SHA3) Read(r io.Reader, objs ...interface{}) error {
+	return abstract.SuiteRead(s, r, objs)
+}
+
+func (s *suiteEd25519SHA3) Write(w io.Writer, objs ...interface{}) error {
+	return abstract.SuiteWrite(s, w, objs)
+}
+
+func (s *suiteEd25519SHA3) New(t reflect.Type) interface{} {
+	return abstract.SuiteNew(s, t)
+}
+
+// Ciphersuite based on SHA3-256, SHAKE256, and the Ed25519 curve,
+// for designs that avoid the SHA-2 family.
+func NewSHA3Ed25519() abstract.Suite {
+	return new(suiteEd25519SHA3)
+}
@@ -0,0 +1,66 @@
+package ed25519
+
+// feCSwap swaps f and g if b == 1 and leaves them unchanged if b == 0,
+// in constant time.
+//
+// Preconditions: b in {0,1}.
+func feCSwap(f, g *fieldElement, b int32) {
+	var t fieldElement
+	feCopy(&t, f)
+	feCMove(f, g, b)
+	feCMove(g, &t, b)
+}
+
+// X25519 computes the Curve25519 Diffie-Hellman function of RFC 7748,
+// setting dst to the u-coordinate of scalar times the point whose
+// u-coordinate is point. The scalar is clamped as specified by RFC 7748
+// and the most significant bit of point is ignored.
+// The computation is a constant-time Montgomery ladder.
+func X25519(dst, scalar, point *[32]byte) {
+	var e [32]byte
+	copy(e[:], scalar[:])
+	e[0] &= 248
+	e[31] &= 127
+	e[31] |= 64
+
+	var x1, x2, z2, x3, z3, tmp0, tmp1 fieldElement
+	var a24 = fieldElement{121666}
+	feFromBytes(&x1, point[:])
+	feOne(&x2)
+	feCopy(&x3, &x1)
+	feOne(&z3)
+
+	swap := int32(0)
+	for pos := 254; pos >= 0; pos-- {
+		b := int32(e[pos/8]>>uint(pos&7)) & 1
+		swap ^= b
+		feCSwap(&x2, &x3, swap)
+		feCSwap(&z2, &z3, swap)
+		swap = b
+
+		feSub(&tmp0, &x3, &z3)
+		feSub(&tmp1, &x2, &z2)
+		feAdd(&x2, &x2, &z2)
+		feAdd(&z2, &x3, &z3)
+		feMul(&z3, &tmp0, &x2)
+		feMul(&z2, &z2, &tmp1)
+		feSquare(&tmp0, &tmp1)
+		feSquare(&tmp1, &x2)
+		feAdd(&x3, &z3, &z2)
+		feSub(&z2, &z3, &z2)
+		feMul(&x2, &tmp1, &tmp0)
+		feSub(&tmp1, &tmp1, &tmp0)
+		feSquare(&z2, &z2)
+		feMul(&z3, &tmp1, &a24)
+		feSquare(&x3, &x3)
+		feAdd(&tmp0, &tmp0, &z3)
+		feMul(&z3, &x1, &z2)
+		feMul(&z2, &tmp1, &tmp0)
+	}
+	feCSwap(&x2, &x3, swap)
+	feCSwap(&z2, &z3, swap)
+
+	feInvert(&z2, &z2)
+	feMul(&x2, &x2, &z2)
+	feToBytes(dst, &x2)
+}
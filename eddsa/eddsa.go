@@ -9,6 +9,7 @@ import (
 	"github.com/dedis/crypto/abstract"
 	"github.com/dedis/crypto/ed25519"
 	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/sign"
 )
 
 var suite = ed25519.NewAES128SHA256Ed25519(false)
@@ -133,11 +134,46 @@ func (e *EdDSA) Sign(msg []byte) ([]byte, error) {
 
 // Verify takes a signature issued by EdDSA.Sign and
 // return nil if it is a valid signature, or an error otherwise
+// It checks the cofactorless equation s*B == R + h*A, see VerifyCofactored.
 // Takes:
 //  - public key used in signing
 //  - msg is the message to sign
 //  - sig is the signature return by EdDSA.Sign
 func Verify(public abstract.Point, msg, sig []byte) error {
+	return verify(public, msg, sig, false)
+}
+
+// VerifyCofactored verifies a signature as Verify does, but checks the
+// cofactored equation 8*s*B == 8*R + 8*h*A, which also accepts signatures
+// whose R or public key have a small-order component. Nodes that must agree
+// on the validity of signatures have to use the same equation, see
+// VerifyWithMode and NewBatchVerifier.
+func VerifyCofactored(public abstract.Point, msg, sig []byte) error {
+	return verify(public, msg, sig, true)
+}
+
+// VerifyWithMode verifies a signature with the equation selected by mode:
+// sign.Cofactorless as Verify, or sign.Cofactored as VerifyCofactored.
+func VerifyWithMode(public abstract.Point, msg, sig []byte, mode sign.VerifyMode) error {
+	return verify(public, msg, sig, mode == sign.Cofactored)
+}
+
+// NewBatchVerifier returns a verifier for batches of EdDSA signatures, given
+// as sign.SchnorrItems, that checks the equation selected by mode: it accepts
+// a batch iff every signature verifies with VerifyWithMode in the same mode,
+// except with negligible probability. The coefficients of the linear
+// combinations are drawn from rand, or from random.Stream if rand is nil.
+//
+// EdDSA signatures are the Schnorr signatures of package sign over Ed25519,
+// so that the verifier is a sign.BatchVerifier.
+func NewBatchVerifier(rand cipher.Stream, mode sign.VerifyMode) *sign.BatchVerifier {
+	if rand == nil {
+		rand = random.Stream
+	}
+	return sign.NewBatchVerifier(suite, rand).SetMode(mode)
+}
+
+func verify(public abstract.Point, msg, sig []byte, cofactored bool) error {
 	if len(sig) != 64 {
 		return errors.New("signature length invalid")
 	}
@@ -166,6 +202,11 @@ func Verify(public abstract.Point, msg, sig []byte) error {
 	hA := suite.Point().Mul(public, h)
 	RhA := suite.Point().Add(R, hA)
 
+	if cofactored {
+		// compare 8*(S - RhA) to the identity
+		S = suite.Point().Mul(S.Sub(S, RhA), suite.Scalar().SetInt64(8))
+		RhA.Null()
+	}
 	if !RhA.Equal(S) {
 		return errors.New("reconstructed S is not equal to signature")
 	}
@@ -1,10 +1,15 @@
 package eddsa
 
 import (
+	"bytes"
 	"crypto/cipher"
+	"crypto/sha512"
 	"encoding/hex"
 	"testing"
 
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/sign"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -82,6 +87,88 @@ func TestEdDSASigning(t *testing.T) {
 	}
 }
 
+func TestEdDSACompatibility(t *testing.T) {
+	msg := []byte("Hello Schnorr")
+	kp := config.NewKeyPair(suite)
+
+	s, err := sign.Schnorr(suite, kp.Secret, msg)
+	if err != nil {
+		t.Fatalf("Couldn't sign msg: %s: %v", msg, err)
+	}
+	err = Verify(kp.Public, msg, s)
+	if err != nil {
+		t.Fatalf("Couldn't verify signature: \n%+v\nfor msg:'%s'. Error:\n%v", s, msg, err)
+	}
+}
+
+// torsionSign returns a signature of msg whose R has a component of order 2.
+func torsionSign(t *testing.T, ed *EdDSA, msg []byte) []byte {
+	// (0, -1), the point of order 2 of Ed25519
+	buf := bytes.Repeat([]byte{0xff}, 32)
+	buf[0], buf[31] = 0xec, 0x7f
+	T := suite.Point()
+	assert.Nil(t, T.UnmarshalBinary(buf))
+
+	k := suite.Scalar().Pick(random.Stream)
+	R := suite.Point().Add(suite.Point().Mul(nil, k), T)
+	Rbuff, err := R.MarshalBinary()
+	assert.Nil(t, err)
+	Abuff, err := ed.Public.MarshalBinary()
+	assert.Nil(t, err)
+	hash := sha512.New()
+	hash.Write(Rbuff)
+	hash.Write(Abuff)
+	hash.Write(msg)
+	h := suite.Scalar().SetBytes(hash.Sum(nil))
+	s := suite.Scalar().Add(k, suite.Scalar().Mul(ed.Secret, h))
+	sBuff, err := s.MarshalBinary()
+	assert.Nil(t, err)
+	return append(Rbuff, sBuff...)
+}
+
+func TestEdDSAVerifyMode(t *testing.T) {
+	ed := NewEdDSA(nil)
+	msg := []byte("Hello EdDSA")
+	sig, err := ed.Sign(msg)
+	assert.Nil(t, err)
+	assert.Nil(t, VerifyWithMode(ed.Public, msg, sig, sign.Cofactorless))
+	assert.Nil(t, VerifyWithMode(ed.Public, msg, sig, sign.Cofactored))
+
+	// only the cofactored equation ignores small-order components
+	sig = torsionSign(t, ed, msg)
+	assert.Error(t, Verify(ed.Public, msg, sig))
+	assert.Error(t, VerifyWithMode(ed.Public, msg, sig, sign.Cofactorless))
+	assert.Nil(t, VerifyCofactored(ed.Public, msg, sig))
+	assert.Nil(t, VerifyWithMode(ed.Public, msg, sig, sign.Cofactored))
+}
+
+func TestEdDSABatchVerifier(t *testing.T) {
+	sigs := make([]sign.SchnorrItem, 10)
+	for i := range sigs {
+		ed := NewEdDSA(nil)
+		msg := []byte{byte(i)}
+		sig, err := ed.Sign(msg)
+		assert.Nil(t, err)
+		sigs[i] = sign.SchnorrItem{Public: ed.Public, Msg: msg, Sig: sig}
+	}
+	ed := NewEdDSA(nil)
+	msg := []byte("torsion")
+	sigs[4] = sign.SchnorrItem{Public: ed.Public, Msg: msg, Sig: torsionSign(t, ed, msg)}
+
+	// The batch agrees with the individual verifications in both modes
+	strict := NewBatchVerifier(nil, sign.Cofactorless)
+	permissive := NewBatchVerifier(nil, sign.Cofactored)
+	assert.Error(t, strict.Verify(sigs))
+	assert.Equal(t, []int{4}, strict.FindInvalid(sigs))
+	assert.Nil(t, permissive.Verify(sigs))
+	assert.Equal(t, 0, len(permissive.FindInvalid(sigs)))
+
+	sigs[7].Msg = []byte("other")
+	assert.Error(t, permissive.Verify(sigs))
+	assert.Equal(t, []int{7}, permissive.FindInvalid(sigs))
+	assert.Equal(t, []int{4, 7}, strict.FindInvalid(sigs))
+}
+
 type constantStream struct {
 	seed []byte
 }
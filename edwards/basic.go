@@ -71,6 +71,12 @@ func (P *basicPoint) HideDecode(rep []byte) {
 	P.c.hide.HideDecode(P, rep)
 }
 
+// Check whether the point is in the group in use, as the point was
+// only checked to lie on the curve when decoded.
+func (P *basicPoint) IsInCorrectSubgroup() bool {
+	return P.c.inGroup(P)
+}
+
 // Equality test for two Points on the same curve
 func (P *basicPoint) Equal(P2 abstract.Point) bool {
 	E2 := P2.(*basicPoint)
@@ -53,6 +53,27 @@ func (c *curve) PrimeOrder() bool {
 	return !c.full
 }
 
+// Returns the order of the prime-order subgroup of this curve.
+func (c *curve) Order() *big.Int {
+	return new(big.Int).Set(&c.Q)
+}
+
+// Returns the cofactor of the prime-order subgroup of this curve.
+func (c *curve) Cofactor() *big.Int {
+	return big.NewInt(int64(c.R))
+}
+
+// Test whether a point belongs to the group in use:
+// the full group if requested, or else the prime-order subgroup.
+func (c *curve) inGroup(P point) bool {
+	if c.full {
+		return true
+	}
+	var q nist.Int // see init for why q.M is left nil
+	q.V.Set(&c.Q)
+	return c.self.Point().Mul(P, &q).Equal(c.null)
+}
+
 // Returns the size in bytes of an encoded Scalar for this curve.
 func (c *curve) ScalarLen() int {
 	return (c.order.V.BitLen() + 7) / 8
@@ -74,6 +74,12 @@ func (P *extPoint) HideDecode(rep []byte) {
 	P.c.hide.HideDecode(P, rep)
 }
 
+// Check whether the point is in the group in use, as the point was
+// only checked to lie on the curve when decoded.
+func (P *extPoint) IsInCorrectSubgroup() bool {
+	return P.c.inGroup(P)
+}
+
 // Equality test for two Points on the same curve.
 // We can avoid inversions here because:
 //
@@ -100,13 +106,7 @@ func (P *extPoint) Set(CP2 abstract.Point) abstract.Point {
 }
 
 func (P *extPoint) Clone() abstract.Point {
-	return &extPoint{
-		c: P.c,
-		X: P.X,
-		Y: P.Y,
-		Z: P.Z,
-		T: P.T,
-	}
+	return new(extPoint).Set(P)
 }
 
 func (P *extPoint) Null() abstract.Point {
@@ -66,6 +66,12 @@ func (P *projPoint) HideDecode(rep []byte) {
 	P.c.hide.HideDecode(P, rep)
 }
 
+// Check whether the point is in the group in use, as the point was
+// only checked to lie on the curve when decoded.
+func (P *projPoint) IsInCorrectSubgroup() bool {
+	return P.c.inGroup(P)
+}
+
 // Equality test for two Points on the same curve.
 // We can avoid inversions here because:
 //
@@ -91,12 +97,7 @@ func (P *projPoint) Set(CP2 abstract.Point) abstract.Point {
 }
 
 func (P *projPoint) Clone() abstract.Point {
-	return &projPoint{
-		c: P.c,
-		X: P.X,
-		Y: P.Y,
-		Z: P.Z,
-	}
+	return new(projPoint).Set(P)
 }
 
 func (P *projPoint) Null() abstract.Point {
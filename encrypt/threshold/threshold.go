@@ -0,0 +1,101 @@
+// Package threshold implements threshold ElGamal decryption. A message point
+// is encrypted to the collective public key X = xG of a group of nodes, whose
+// private key x is Shamir-shared among them, e.g., by a distributed key
+// generation. Each node i holding the share x_i produces a decryption share
+// x_i*K of a ciphertext (K,C) = (kG, M+kX), together with a NIZK proof that
+// log_G(X_i) == log_K(x_i*K), where X_i = x_i*G is the public commitment to
+// its share. Any t valid decryption shares recover xK = kX by Lagrange
+// interpolation, hence the message M = C - xK, while the private key x is never
+// reconstructed. Decryption runs in three steps:
+//  1. Anyone encrypts a message to the collective key using Encrypt().
+//  2. Each node computes its decryption share using DecryptionShare().
+//  3. Once t decryption shares have been released, anyone can verify them
+//     against the public commitment polynomial of the key and recover the
+//     message using Recover().
+package threshold
+
+import (
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/proof"
+	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/share"
+)
+
+// Some error definitions.
+var errorTooFewShares = errors.New("not enough valid decryption shares")
+var errorShareVerification = errors.New("verification of decryption share failed")
+
+// Ciphertext is an ElGamal ciphertext (K,C) = (kG, M+kX) of a message point M
+// under the public key X.
+type Ciphertext struct {
+	K abstract.Point // Ephemeral key kG
+	C abstract.Point // Blinded message M+kX
+}
+
+// DecShare is a decryption share: a public share x_i*K of the ciphertext's
+// ephemeral key together with a proof of its correctness.
+type DecShare struct {
+	S share.PubShare  // Share
+	P proof.DLEQProof // Proof
+}
+
+// Encrypt encrypts the message point M under the public key X.
+func Encrypt(suite abstract.Suite, X abstract.Point, M abstract.Point) *Ciphertext {
+	k := suite.Scalar().Pick(random.Stream)
+	K := suite.Point().Mul(nil, k)
+	C := suite.Point().Mul(X, k)
+	C = suite.Point().Add(C, M)
+	return &Ciphertext{K, C}
+}
+
+// DecryptionShare computes the decryption share of a ciphertext for the given
+// private share of the collective key, and the proof that
+// log_G(X_i) == log_K(x_i*K).
+func DecryptionShare(suite abstract.Suite, priShare *share.PriShare, c *Ciphertext) (*DecShare, error) {
+	G := suite.Point().Base()
+	P, _, xK, err := proof.NewDLEQProof(suite, G, c.K, priShare.V)
+	if err != nil {
+		return nil, err
+	}
+	return &DecShare{share.PubShare{I: priShare.I, V: xK}, *P}, nil
+}
+
+// VerifyDecryptionShare checks a decryption share of a ciphertext against the
+// public commitment polynomial of the collective key, whose evaluation at the
+// share's index is the public commitment X_i to the private share.
+func VerifyDecryptionShare(suite abstract.Suite, pubPoly *share.PubPoly, c *Ciphertext, ds *DecShare) error {
+	G := suite.Point().Base()
+	Xi := pubPoly.Eval(ds.S.I).V
+	if err := ds.P.Verify(suite, G, c.K, Xi, ds.S.V); err != nil {
+		return errorShareVerification
+	}
+	return nil
+}
+
+// Recover verifies the given decryption shares of a ciphertext, ignores the
+// invalid ones, and recovers the message point from t of the valid ones.
+// The public commitment polynomial must be committed with respect to the
+// standard base point, as produced by PriPoly.Commit(nil).
+func Recover(suite abstract.Suite, pubPoly *share.PubPoly, c *Ciphertext, shares []*DecShare, t int, n int) (abstract.Point, error) {
+	var valid []*share.PubShare
+	seen := make(map[int]bool)
+	for _, ds := range shares {
+		if ds == nil || ds.S.I < 0 || ds.S.I >= n || seen[ds.S.I] {
+			continue
+		}
+		if VerifyDecryptionShare(suite, pubPoly, c, ds) == nil {
+			valid = append(valid, &ds.S)
+			seen[ds.S.I] = true
+		}
+	}
+	if len(valid) < t {
+		return nil, errorTooFewShares
+	}
+	xK, err := share.RecoverCommit(suite, valid, t, n)
+	if err != nil {
+		return nil, err
+	}
+	return suite.Point().Sub(c.C, xK), nil
+}
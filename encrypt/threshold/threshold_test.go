@@ -0,0 +1,48 @@
+package threshold
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/share"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThresholdDecryption(test *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	n := 10
+	t := 2*n/3 + 1
+
+	// Shared collective key, as produced by a DKG
+	priPoly := share.NewPriPoly(suite, t, nil, random.Stream)
+	pubPoly := priPoly.Commit(nil)
+	priShares := priPoly.Shares(n)
+	X := pubPoly.Commit()
+
+	M, _ := suite.Point().Pick([]byte("threshold"), random.Stream)
+	c := Encrypt(suite, X, M)
+
+	// Decryption shares, one of them corrupted
+	shares := make([]*DecShare, n)
+	for i := 0; i < n; i++ {
+		ds, err := DecryptionShare(suite, priShares[i], c)
+		require.Nil(test, err)
+		require.Nil(test, VerifyDecryptionShare(suite, pubPoly, c, ds))
+		shares[i] = ds
+	}
+	shares[0].S.V = suite.Point().Add(shares[0].S.V, suite.Point().Base())
+	require.NotNil(test, VerifyDecryptionShare(suite, pubPoly, c, shares[0]))
+
+	recovered, err := Recover(suite, pubPoly, c, shares, t, n)
+	require.Nil(test, err)
+	require.True(test, recovered.Equal(M))
+	data, err := recovered.Data()
+	require.Nil(test, err)
+	require.Equal(test, "threshold", string(data))
+
+	// t-1 valid shares and duplicates are not enough
+	few := append(shares[:t], shares[1])
+	_, err = Recover(suite, pubPoly, c, few, t, n)
+	require.NotNil(test, err)
+}
@@ -0,0 +1,254 @@
+// Package hdkey implements hierarchical deterministic key derivation in the
+// style of BIP32 over any abstract.Suite, so that many subkeys, e.g., one per
+// customer, can be derived from a single master key pair instead of being
+// generated and stored separately.
+//
+// An extended key is a private or public key together with a 32-byte chain
+// code. The child of index i of an extended key is derived from the key and
+// the chain code of its parent with HMAC-SHA512, as in BIP32:
+//
+//	I = HMAC-SHA512(chain code, data || i)
+//	child secret = parent secret + I[:32]
+//	child public = parent public + I[:32]*G
+//	child chain code = I[32:]
+//
+// where I[:32] is reduced modulo the order of the group. For non-hardened
+// indices, below HardenedOffset, the data is the public key of the parent, so
+// the public keys of the children can be derived from the extended public key
+// of the parent alone. For hardened indices the data is the secret key of the
+// parent, so that the leak of a hardened child secret and of the extended
+// public key of its parent does not reveal the secret of the parent.
+package hdkey
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+)
+
+// HardenedOffset is the first index of hardened children.
+const HardenedOffset uint32 = 1 << 31
+
+// ChainCodeSize is the size of chain codes in bytes.
+const ChainCodeSize = 32
+
+const masterKey = "hdkey master chain code"
+
+// Types of serialized extended keys
+const (
+	privateKey byte = iota
+	publicKey
+)
+
+var errorHardened = errors.New("hdkey: hardened derivation requires a private key")
+var errorPublic = errors.New("hdkey: not a private key")
+var errorDepth = errors.New("hdkey: maximum depth reached")
+var errorEncoding = errors.New("hdkey: malformed extended key")
+
+// ExtendedKey is a private or public key that can derive child keys.
+type ExtendedKey struct {
+	suite     abstract.Suite
+	secret    abstract.Scalar // nil for an extended public key
+	public    abstract.Point
+	chainCode [ChainCodeSize]byte
+	depth     uint8
+	index     uint32
+	parent    [4]byte // fingerprint of the parent public key
+}
+
+// NewMasterKey returns the master extended private key of a key pair. Its
+// chain code is derived from the secret key, so that the same key pair
+// always yields the same tree of keys.
+func NewMasterKey(kp *config.KeyPair) (*ExtendedKey, error) {
+	secret, err := kp.Secret.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha512.New, []byte(masterKey))
+	mac.Write(secret)
+	k := &ExtendedKey{
+		suite:  kp.Suite,
+		secret: kp.Suite.Scalar().Set(kp.Secret),
+		public: kp.Suite.Point().Mul(nil, kp.Secret),
+	}
+	copy(k.chainCode[:], mac.Sum(nil))
+	return k, nil
+}
+
+// IsPrivate returns whether the extended key holds a private key.
+func (k *ExtendedKey) IsPrivate() bool {
+	return k.secret != nil
+}
+
+// Public returns the public key.
+func (k *ExtendedKey) Public() abstract.Point {
+	return k.suite.Point().Set(k.public)
+}
+
+// KeyPair returns the key pair of an extended private key.
+func (k *ExtendedKey) KeyPair() (*config.KeyPair, error) {
+	if k.secret == nil {
+		return nil, errorPublic
+	}
+	return &config.KeyPair{
+		Suite:  k.suite,
+		Public: k.Public(),
+		Secret: k.suite.Scalar().Set(k.secret),
+	}, nil
+}
+
+// Depth returns the number of derivations from the master key.
+func (k *ExtendedKey) Depth() int {
+	return int(k.depth)
+}
+
+// Index returns the index of the key among the children of its parent.
+func (k *ExtendedKey) Index() uint32 {
+	return k.index
+}
+
+// Neuter returns the extended public key of an extended key, which can
+// derive the public keys of all non-hardened children.
+func (k *ExtendedKey) Neuter() *ExtendedKey {
+	n := *k
+	n.secret = nil
+	n.public = k.Public()
+	return &n
+}
+
+// Child derives the child of the given index. Indices from HardenedOffset on
+// are hardened, and can only be derived from an extended private key.
+func (k *ExtendedKey) Child(i uint32) (*ExtendedKey, error) {
+	if k.depth == 255 {
+		return nil, errorDepth
+	}
+	mac := hmac.New(sha512.New, k.chainCode[:])
+	if i >= HardenedOffset {
+		if k.secret == nil {
+			return nil, errorHardened
+		}
+		secret, err := k.secret.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		mac.Write([]byte{0})
+		mac.Write(secret)
+	} else if _, err := k.public.MarshalTo(mac); err != nil {
+		return nil, err
+	}
+	var index [4]byte
+	binary.BigEndian.PutUint32(index[:], i)
+	mac.Write(index[:])
+	sum := mac.Sum(nil)
+
+	fp, err := fingerprint(k.public)
+	if err != nil {
+		return nil, err
+	}
+	tweak := k.suite.Scalar().SetBytes(sum[:32])
+	c := &ExtendedKey{
+		suite:  k.suite,
+		depth:  k.depth + 1,
+		index:  i,
+		parent: fp,
+	}
+	copy(c.chainCode[:], sum[32:])
+	c.public = k.suite.Point().Mul(nil, tweak)
+	c.public.Add(c.public, k.public)
+	if k.secret != nil {
+		c.secret = k.suite.Scalar().Add(k.secret, tweak)
+	}
+	return c, nil
+}
+
+// Derive derives the descendant of the key along the given path of indices.
+func (k *ExtendedKey) Derive(path ...uint32) (*ExtendedKey, error) {
+	var err error
+	for _, i := range path {
+		if k, err = k.Child(i); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// fingerprint returns the first bytes of the SHA-256 hash of a public key.
+func fingerprint(public abstract.Point) ([4]byte, error) {
+	var fp [4]byte
+	h := sha256.New()
+	if _, err := public.MarshalTo(h); err != nil {
+		return fp, err
+	}
+	copy(fp[:], h.Sum(nil))
+	return fp, nil
+}
+
+// MarshalBinary encodes the extended key as its type, depth, parent
+// fingerprint, index, chain code and then private or public key.
+func (k *ExtendedKey) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 10, 10+ChainCodeSize)
+	buf[0] = publicKey
+	if k.secret != nil {
+		buf[0] = privateKey
+	}
+	buf[1] = k.depth
+	copy(buf[2:6], k.parent[:])
+	binary.BigEndian.PutUint32(buf[6:10], k.index)
+	buf = append(buf, k.chainCode[:]...)
+
+	var key []byte
+	var err error
+	if k.secret != nil {
+		key, err = k.secret.MarshalBinary()
+	} else {
+		key, err = k.public.MarshalBinary()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, key...), nil
+}
+
+// Unmarshal decodes an extended key of the given suite encoded by
+// MarshalBinary.
+func Unmarshal(suite abstract.Suite, buf []byte) (*ExtendedKey, error) {
+	const header = 10 + ChainCodeSize
+	if len(buf) < header {
+		return nil, errorEncoding
+	}
+	k := &ExtendedKey{
+		suite: suite,
+		depth: buf[1],
+		index: binary.BigEndian.Uint32(buf[6:10]),
+	}
+	copy(k.parent[:], buf[2:6])
+	copy(k.chainCode[:], buf[10:header])
+	key := buf[header:]
+	switch buf[0] {
+	case privateKey:
+		k.secret = suite.Scalar()
+		if len(key) != k.secret.MarshalSize() {
+			return nil, errorEncoding
+		}
+		if err := k.secret.UnmarshalBinary(key); err != nil {
+			return nil, err
+		}
+		k.public = suite.Point().Mul(nil, k.secret)
+	case publicKey:
+		k.public = suite.Point()
+		if len(key) != k.public.MarshalSize() {
+			return nil, errorEncoding
+		}
+		if err := k.public.UnmarshalBinary(key); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errorEncoding
+	}
+	return k, nil
+}
@@ -0,0 +1,88 @@
+package hdkey
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/nist"
+	"github.com/stretchr/testify/require"
+)
+
+var testSuites = []abstract.Suite{
+	edwards.NewAES128SHA256Ed25519(false),
+	nist.NewAES128SHA256P256(),
+}
+
+func TestDerive(t *testing.T) {
+	for _, suite := range testSuites {
+		kp := config.NewKeyPair(suite)
+		master, err := NewMasterKey(kp)
+		require.Nil(t, err)
+		again, err := NewMasterKey(kp)
+		require.Nil(t, err)
+
+		path := []uint32{HardenedOffset + 1, 7, 42}
+		k, err := master.Derive(path...)
+		require.Nil(t, err)
+		k2, err := again.Derive(path...)
+		require.Nil(t, err)
+		require.True(t, k.Public().Equal(k2.Public()))
+		require.Equal(t, 3, k.Depth())
+		require.Equal(t, uint32(42), k.Index())
+
+		child, err := k.KeyPair()
+		require.Nil(t, err)
+		require.True(t, suite.Point().Mul(nil, child.Secret).Equal(child.Public))
+		require.False(t, child.Public.Equal(kp.Public))
+
+		// Non-hardened public keys can be derived from the public parent
+		parent, err := master.Derive(path[0])
+		require.Nil(t, err)
+		pub, err := parent.Neuter().Derive(path[1:]...)
+		require.Nil(t, err)
+		require.False(t, pub.IsPrivate())
+		require.True(t, pub.Public().Equal(k.Public()))
+		_, err = pub.KeyPair()
+		require.Equal(t, errorPublic, err)
+
+		// but not hardened ones
+		_, err = master.Neuter().Child(HardenedOffset)
+		require.Equal(t, errorHardened, err)
+
+		// Siblings differ
+		sibling, err := parent.Child(8)
+		require.Nil(t, err)
+		require.False(t, sibling.Public().Equal(k.Public()))
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	for _, suite := range testSuites {
+		master, err := NewMasterKey(config.NewKeyPair(suite))
+		require.Nil(t, err)
+		k, err := master.Derive(HardenedOffset, 3)
+		require.Nil(t, err)
+
+		for _, key := range []*ExtendedKey{k, k.Neuter()} {
+			buf, err := key.MarshalBinary()
+			require.Nil(t, err)
+			dec, err := Unmarshal(suite, buf)
+			require.Nil(t, err)
+			require.Equal(t, key.IsPrivate(), dec.IsPrivate())
+			buf2, err := dec.MarshalBinary()
+			require.Nil(t, err)
+			require.Equal(t, buf, buf2)
+
+			c1, err := key.Child(5)
+			require.Nil(t, err)
+			c2, err := dec.Child(5)
+			require.Nil(t, err)
+			require.True(t, c1.Public().Equal(c2.Public()))
+
+			_, err = Unmarshal(suite, buf[:len(buf)-1])
+			require.Equal(t, errorEncoding, err)
+		}
+	}
+}
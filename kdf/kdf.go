@@ -0,0 +1,74 @@
+// Package kdf implements HKDF (RFC 5869) over the hash function of an
+// abstract.Suite, together with helpers to derive symmetric keys and scalars
+// from Diffie-Hellman outputs in a consistent way, and deterministic signature
+// nonces from private keys. All derivations take a label so that keys derived
+// for different purposes are independent.
+package kdf
+
+import (
+	"crypto/hmac"
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+var errorLength = errors.New("kdf: requested length too large")
+
+// Extract implements HKDF-Extract: it computes a pseudo-random key from the
+// input keying material secret and an optional salt.
+func Extract(suite abstract.Suite, salt, secret []byte) []byte {
+	if salt == nil {
+		salt = make([]byte, suite.Hash().Size())
+	}
+	mac := hmac.New(suite.Hash, salt)
+	mac.Write(secret)
+	return mac.Sum(nil)
+}
+
+// Expand implements HKDF-Expand: it expands the pseudo-random key prk into
+// length bytes of output keying material bound to info. The length can be
+// at most 255 times the output size of the suite's hash function.
+func Expand(suite abstract.Suite, prk, info []byte, length int) ([]byte, error) {
+	mac := hmac.New(suite.Hash, prk)
+	if length < 0 || length > 255*mac.Size() {
+		return nil, errorLength
+	}
+	out := make([]byte, 0, length+mac.Size())
+	var prev []byte
+	for ctr := byte(1); len(out) < length; ctr++ {
+		mac.Reset()
+		mac.Write(prev)
+		mac.Write(info)
+		mac.Write([]byte{ctr})
+		prev = mac.Sum(nil)
+		out = append(out, prev...)
+	}
+	return out[:length], nil
+}
+
+// Derive returns length bytes derived from secret for the given label, i.e.,
+// HKDF-Expand(HKDF-Extract(nil, secret), label, length).
+func Derive(suite abstract.Suite, secret []byte, label string, length int) ([]byte, error) {
+	return Expand(suite, Extract(suite, nil, secret), []byte(label), length)
+}
+
+// PointKey derives a symmetric key of length bytes for the given label from
+// a point, typically a Diffie-Hellman shared secret.
+func PointKey(suite abstract.Suite, p abstract.Point, label string, length int) ([]byte, error) {
+	buf, err := p.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return Derive(suite, buf, label, length)
+}
+
+// PointScalar derives a scalar for the given label from a point, typically
+// a Diffie-Hellman shared secret. The scalar is picked uniformly using the
+// suite's cipher keyed with a key derived by PointKey.
+func PointScalar(suite abstract.Suite, p abstract.Point, label string) (abstract.Scalar, error) {
+	key, err := PointKey(suite, p, label, suite.Hash().Size())
+	if err != nil {
+		return nil, err
+	}
+	return suite.Scalar().Pick(suite.Cipher(key)), nil
+}
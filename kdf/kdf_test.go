@@ -0,0 +1,88 @@
+package kdf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/dedis/crypto/nist"
+	"github.com/dedis/crypto/random"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 5869, test case 1 (SHA-256)
+func TestHKDF(t *testing.T) {
+	suite := nist.NewAES128SHA256P256()
+	ikm, _ := hex.DecodeString("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+	prk, _ := hex.DecodeString("077709362c2e32df0ddc3f0dc47bba6390b6c73bb50f9c3122ec844ad7c2b3e5")
+	okm, _ := hex.DecodeString("3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865")
+
+	require.Equal(t, prk, Extract(suite, salt, ikm))
+	out, err := Expand(suite, prk, info, len(okm))
+	require.Nil(t, err)
+	require.Equal(t, okm, out)
+
+	_, err = Expand(suite, prk, info, 255*32+1)
+	require.Equal(t, errorLength, err)
+}
+
+func TestPointDerivation(t *testing.T) {
+	suite := nist.NewAES128SHA256P256()
+	a := suite.Scalar().Pick(random.Stream)
+	b := suite.Scalar().Pick(random.Stream)
+	A := suite.Point().Mul(nil, a)
+	B := suite.Point().Mul(nil, b)
+
+	k1, err := PointKey(suite, suite.Point().Mul(B, a), "test", 16)
+	require.Nil(t, err)
+	k2, err := PointKey(suite, suite.Point().Mul(A, b), "test", 16)
+	require.Nil(t, err)
+	require.Equal(t, k1, k2)
+	require.Equal(t, 16, len(k1))
+
+	k3, err := PointKey(suite, suite.Point().Mul(A, b), "other", 16)
+	require.Nil(t, err)
+	require.False(t, bytes.Equal(k1, k3))
+
+	s1, err := PointScalar(suite, suite.Point().Mul(B, a), "test")
+	require.Nil(t, err)
+	s2, err := PointScalar(suite, suite.Point().Mul(A, b), "test")
+	require.Nil(t, err)
+	require.True(t, s1.Equal(s2))
+}
+
+func TestNonce(t *testing.T) {
+	suite := nist.NewAES128SHA256P256()
+	x := suite.Scalar().Pick(random.Stream)
+	msg := []byte("message")
+
+	k1, err := Nonce(suite, x, msg, 0, "test")
+	require.Nil(t, err)
+	k2, err := Nonce(suite, x, msg, 0, "test")
+	require.Nil(t, err)
+	require.True(t, k1.Equal(k2))
+
+	// Every input changes the nonce
+	others := []struct {
+		newKey  bool
+		msg     string
+		counter uint32
+		label   string
+	}{
+		{false, "message2", 0, "test"},
+		{false, "message", 1, "test"},
+		{false, "message", 0, "other"},
+		{true, "message", 0, "test"},
+	}
+	for _, o := range others {
+		y := x
+		if o.newKey {
+			y = suite.Scalar().Pick(random.Stream)
+		}
+		k, err := Nonce(suite, y, []byte(o.msg), o.counter, o.label)
+		require.Nil(t, err)
+		require.False(t, k1.Equal(k))
+	}
+}
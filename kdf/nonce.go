@@ -0,0 +1,45 @@
+package kdf
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+// Nonce derives the secret nonce of a Schnorr-type signature of msg by the
+// private key, in the style of RFC 6979: the nonce is a pseudo-random function
+// of the key and the message instead of being picked from random.Stream, so
+// that signing never reuses a nonce for two different messages, even when the
+// system's randomness repeats, e.g., after a VM snapshot is restored. The
+// personalization separates the nonces of different signing algorithms or
+// protocols, and the counter lets a signer derive several independent nonces
+// for the same message. The nonce is picked uniformly using the suite's cipher
+// keyed as NonceStream does.
+func Nonce(suite abstract.Suite, private abstract.Scalar, msg []byte, counter uint32, personalization string) (abstract.Scalar, error) {
+	rand, err := NonceStream(suite, private, msg, counter, personalization)
+	if err != nil {
+		return nil, err
+	}
+	return suite.Scalar().Pick(rand), nil
+}
+
+// NonceStream returns a deterministic stream to use in place of random.Stream
+// with signing algorithms that pick their nonces themselves, such as
+// anon.Sign. The stream is the suite's cipher keyed with
+// HKDF-Expand(HKDF-Extract(personalization, private), counter || msg).
+func NonceStream(suite abstract.Suite, private abstract.Scalar, msg []byte, counter uint32, personalization string) (cipher.Stream, error) {
+	secret, err := private.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	prk := Extract(suite, []byte(personalization), secret)
+	info := make([]byte, 4, 4+len(msg))
+	binary.BigEndian.PutUint32(info, counter)
+	info = append(info, msg...)
+	key, err := Expand(suite, prk, info, suite.Hash().Size())
+	if err != nil {
+		return nil, err
+	}
+	return suite.Cipher(key), nil
+}
@@ -0,0 +1,265 @@
+// Package negotiation lets peers supporting different ciphersuites agree on
+// the one to use for a protocol session.
+//
+// Each peer advertises the names of the suites it supports, as returned by
+// abstract.Suite.String. Agree deterministically picks the strongest suite
+// supported by all peers, so that every peer computes the same result from
+// the same advertisements. The resulting Agreement records the offers it was
+// derived from, and can be signed by the session leader and embedded into
+// the session IDs of protocols such as poly deals, so that a session cannot
+// be silently downgraded to a weaker suite.
+package negotiation
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/sign"
+)
+
+const signDomain = "negotiation.Agreement"
+
+var errorNoCommonSuite = errors.New("negotiation: no suite supported by all peers")
+var errorNoOffer = errors.New("negotiation: no offer")
+var errorMismatch = errors.New("negotiation: agreed suite differs from the offers")
+var errorEncoding = errors.New("negotiation: malformed agreement")
+
+// Security levels of the known suites, in bits.
+var strengths = struct {
+	sync.RWMutex
+	m map[string]int
+}{m: map[string]int{
+	"Ed25519":      128,
+	"Ed25519-SHA3": 128,
+	"P256":         128,
+	"QR512":        56,
+	"Ristretto255": 128,
+}}
+
+// RegisterStrength sets the security level, in bits, of the suite of the given
+// name. Suites of unknown strength are only agreed upon when no peer has a
+// suite of known strength in common.
+func RegisterStrength(name string, bits int) {
+	strengths.Lock()
+	defer strengths.Unlock()
+	strengths.m[name] = bits
+}
+
+// Strength returns the security level, in bits, of the suite of the given
+// name, or 0 if it is unknown.
+func Strength(name string) int {
+	strengths.RLock()
+	defer strengths.RUnlock()
+	return strengths.m[name]
+}
+
+// Agree returns the name of the strongest suite supported by all peers, given
+// the names of the suites each peer supports. Ties are broken by choosing the
+// name that comes first in lexicographic order, so that the result does not
+// depend on the order of the offers.
+func Agree(offers ...[]string) (string, error) {
+	if len(offers) == 0 {
+		return "", errorNoOffer
+	}
+	common := make(map[string]int)
+	for _, offer := range offers {
+		for _, name := range normalize(offer) {
+			common[name]++
+		}
+	}
+	best := ""
+	found := false
+	for name, count := range common {
+		if count != len(offers) {
+			continue
+		}
+		if !found || Strength(name) > Strength(best) ||
+			Strength(name) == Strength(best) && name < best {
+			best = name
+			found = true
+		}
+	}
+	if !found {
+		return "", errorNoCommonSuite
+	}
+	return best, nil
+}
+
+// normalize sorts and removes duplicates from a list of suite names.
+func normalize(offer []string) []string {
+	names := append([]string{}, offer...)
+	sort.Strings(names)
+	k := 0
+	for i, name := range names {
+		if i == 0 || name != names[k-1] {
+			names[k] = name
+			k++
+		}
+	}
+	return names[:k]
+}
+
+// An Agreement records the suite agreed upon by a set of peers, and the
+// offers of the peers, in order, from which it was derived.
+type Agreement struct {
+	Suite  string
+	Offers [][]string
+}
+
+// NewAgreement agrees on a suite from the offers of the peers, see Agree.
+func NewAgreement(offers ...[]string) (*Agreement, error) {
+	name, err := Agree(offers...)
+	if err != nil {
+		return nil, err
+	}
+	a := &Agreement{Suite: name, Offers: make([][]string, len(offers))}
+	for i, offer := range offers {
+		a.Offers[i] = normalize(offer)
+	}
+	return a, nil
+}
+
+// Verify checks that the agreed suite is the one Agree picks from the offers.
+func (a *Agreement) Verify() error {
+	name, err := Agree(a.Offers...)
+	if err != nil {
+		return err
+	}
+	if name != a.Suite {
+		return errorMismatch
+	}
+	return nil
+}
+
+// MarshalBinary returns the canonical encoding of the agreement: the agreed
+// suite, then every offer as a list of names, all of them length-prefixed.
+func (a *Agreement) MarshalBinary() ([]byte, error) {
+	var buf []byte
+	buf = appendString(buf, a.Suite)
+	buf = appendUvarint(buf, uint64(len(a.Offers)))
+	for _, offer := range a.Offers {
+		names := normalize(offer)
+		buf = appendUvarint(buf, uint64(len(names)))
+		for _, name := range names {
+			buf = appendString(buf, name)
+		}
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes an agreement encoded by MarshalBinary.
+func (a *Agreement) UnmarshalBinary(buf []byte) error {
+	d := decoder{buf: buf}
+	suite := d.string()
+	offers := make([][]string, d.count())
+	for i := range offers {
+		offers[i] = make([]string, d.count())
+		for j := range offers[i] {
+			offers[i][j] = d.string()
+		}
+	}
+	if d.err != nil || len(d.buf) != 0 {
+		return errorEncoding
+	}
+	a.Suite = suite
+	a.Offers = offers
+	return nil
+}
+
+// SessionID returns an identifier of the agreement, to be embedded into the
+// session IDs of the protocols run with the agreed suite.
+func (a *Agreement) SessionID() []byte {
+	buf, _ := a.MarshalBinary()
+	h := sha256.New()
+	h.Write([]byte(signDomain))
+	h.Write(buf)
+	return h.Sum(nil)
+}
+
+// Sign returns a signed agreement blob: the encoding of the agreement followed
+// by a Schnorr signature on it under the private key of the signer, drawn from
+// the given suite. The blob can be checked with Open.
+func (a *Agreement) Sign(suite abstract.Suite, private abstract.Scalar) ([]byte, error) {
+	buf, err := a.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := sign.SignWithDomain(suite, private, signDomain, buf)
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, sig...), nil
+}
+
+// Open checks the signature of a blob produced by Sign under the given public
+// key, and returns the agreement it carries once verified, see Verify.
+func Open(suite abstract.Suite, public abstract.Point, blob []byte) (*Agreement, error) {
+	sigSize := suite.Point().MarshalSize() + suite.Scalar().MarshalSize()
+	if len(blob) < sigSize {
+		return nil, errorEncoding
+	}
+	buf, sig := blob[:len(blob)-sigSize], blob[len(blob)-sigSize:]
+	if err := sign.VerifyWithDomain(suite, public, signDomain, buf, sig); err != nil {
+		return nil, err
+	}
+	a := new(Agreement)
+	if err := a.UnmarshalBinary(buf); err != nil {
+		return nil, err
+	}
+	if err := a.Verify(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutUvarint(b[:], v)]...)
+}
+
+func appendString(buf []byte, s string) []byte {
+	return append(appendUvarint(buf, uint64(len(s))), s...)
+}
+
+// A decoder for the encoding of MarshalBinary, recording the first error.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.err = errorEncoding
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+// count decodes a number of elements, each taking at least one byte.
+func (d *decoder) count() int {
+	v := d.uvarint()
+	if v > uint64(len(d.buf)) {
+		d.err = errorEncoding
+		return 0
+	}
+	return int(v)
+}
+
+func (d *decoder) string() string {
+	l := d.count()
+	if d.err != nil {
+		return ""
+	}
+	s := string(d.buf[:l])
+	d.buf = d.buf[l:]
+	return s
+}
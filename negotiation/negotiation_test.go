@@ -0,0 +1,64 @@
+package negotiation
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgree(t *testing.T) {
+	name, err := Agree([]string{"QR512", "P256", "Ed25519"},
+		[]string{"P256", "QR512"})
+	require.Nil(t, err)
+	require.Equal(t, "P256", name)
+
+	// Ties are broken by name, whatever the order of the offers
+	name, err = Agree([]string{"P256", "Ed25519"}, []string{"Ed25519", "P256"})
+	require.Nil(t, err)
+	require.Equal(t, "Ed25519", name)
+
+	// Unknown suites are only chosen as a last resort
+	name, err = Agree([]string{"X", "QR512"}, []string{"QR512", "X"})
+	require.Nil(t, err)
+	require.Equal(t, "QR512", name)
+
+	_, err = Agree([]string{"P256"}, []string{"Ed25519"})
+	require.Equal(t, errorNoCommonSuite, err)
+	_, err = Agree()
+	require.Equal(t, errorNoOffer, err)
+}
+
+func TestAgreementSign(t *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	private := suite.Scalar().Pick(random.Stream)
+	public := suite.Point().Mul(nil, private)
+
+	a, err := NewAgreement([]string{"P256", "Ed25519", "P256"},
+		[]string{"QR512", "Ed25519"})
+	require.Nil(t, err)
+	require.Equal(t, "Ed25519", a.Suite)
+
+	blob, err := a.Sign(suite, private)
+	require.Nil(t, err)
+	b, err := Open(suite, public, blob)
+	require.Nil(t, err)
+	require.Equal(t, a, b)
+	require.True(t, bytes.Equal(a.SessionID(), b.SessionID()))
+
+	// Tampered blob
+	blob[1] ^= 1
+	_, err = Open(suite, public, blob)
+	require.NotNil(t, err)
+
+	// Signed downgrade to a weaker suite
+	a.Suite = "QR512"
+	blob, err = a.Sign(suite, private)
+	require.Nil(t, err)
+	_, err = Open(suite, public, blob)
+	require.Equal(t, errorMismatch, err)
+
+	require.NotNil(t, new(Agreement).UnmarshalBinary([]byte{5, 'a'}))
+}
@@ -0,0 +1,247 @@
+package nist
+
+import (
+	"math/big"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+)
+
+// Constant-time arithmetic modulo an odd modulus, used by the Int operations
+// that handle secrets: Add, Sub, Neg, Mul, Div, Inv and the reductions of
+// SetBytes, InitBytes and HideDecode. The big.Int operations take time
+// depending on the values, e.g. ModInverse runs Euclid's algorithm, Mod
+// normalizes its result and Mul skips the zero words, which leaks bits of
+// long-term keys through signing. Here values are fixed-length slices of words
+// and the operations run the same instructions whatever the values, with
+// Montgomery multiplication and Fermat inversion, whose exponent is public.
+//
+// The values are still stored in big.Int, whose encoding only leaks the
+// number of words of a value, i.e., whether it is unusually small.
+
+// ctModulus holds an odd modulus and the constants of its Montgomery
+// arithmetic, for R = 2^(_W*len(m)).
+type ctModulus struct {
+	modulus *big.Int
+	m       []uint   // the modulus, little-endian words
+	minv    uint     // -m^-1 mod 2^_W
+	rr      []uint   // R^2 mod m
+	one     []uint   // 1, to leave the Montgomery form
+	e       *big.Int // m-2, the Fermat inversion exponent
+	prime   bool     // whether inverses may be computed as a^(m-2)
+}
+
+const _W = bits.UintSize
+
+// The temporaries of moduli up to stackWords words are allocated on the
+// stack, as the arithmetic is used by the curves.
+const stackWords = 16
+
+// The moduli are few and long-lived (see Int), so their constants are cached
+// by pointer, up to maxCtModuli moduli.
+const maxCtModuli = 1024
+
+var ctModuli sync.Map
+var numCtModuli int32
+
+// ctModulusOf returns the constants of the modulus m, or nil if it is not odd,
+// in which case the big.Int operations are used.
+func ctModulusOf(m *big.Int) *ctModulus {
+	if m == nil || m.Sign() <= 0 || m.Bit(0) == 0 {
+		return nil
+	}
+	if c, ok := ctModuli.Load(m); ok {
+		return c.(*ctModulus)
+	}
+	c := newCtModulus(m)
+	if atomic.AddInt32(&numCtModuli, 1) <= maxCtModuli {
+		ctModuli.Store(m, c)
+	}
+	return c
+}
+
+func newCtModulus(m *big.Int) *ctModulus {
+	n := (m.BitLen() + _W - 1) / _W
+	c := &ctModulus{modulus: new(big.Int).Set(m)}
+	c.m = c.words(m)
+
+	// Newton's iteration doubles the number of correct low bits of the
+	// inverse at each step, starting with 3 as m*m = 1 mod 8.
+	inv := c.m[0]
+	for k := 0; k < 6; k++ {
+		inv *= 2 - c.m[0]*inv
+	}
+	c.minv = -inv
+
+	rr := new(big.Int).Lsh(one, uint(2*_W*n))
+	c.rr = c.words(rr.Mod(rr, m))
+	c.one = c.words(one)
+	c.e = new(big.Int).Sub(m, two)
+	c.prime = m.ProbablyPrime(20)
+	return c
+}
+
+// words returns a value in [0,m) as len(m) little-endian words.
+func (c *ctModulus) words(v *big.Int) []uint {
+	z := make([]uint, (c.modulus.BitLen()+_W-1)/_W)
+	for k, w := range v.Bits() {
+		z[k] = uint(w)
+	}
+	return z
+}
+
+// limbs returns the value v of an Int as words, reducing it first if it is
+// not in [0,m), which only happens for values set directly.
+func (c *ctModulus) limbs(v *big.Int) []uint {
+	if v.Sign() < 0 || v.Cmp(c.modulus) >= 0 {
+		return c.words(new(big.Int).Mod(v, c.modulus))
+	}
+	return c.words(v)
+}
+
+// setBig sets v to the value of the words x.
+func (c *ctModulus) setBig(v *big.Int, x []uint) *big.Int {
+	w := make([]big.Word, len(x))
+	for k := range x {
+		w[k] = big.Word(x[k])
+	}
+	return v.SetBits(w)
+}
+
+// montMul sets z = x*y/R mod m, for x*y < m*R. The outputs may alias the
+// inputs.
+func (c *ctModulus) montMul(z, x, y []uint) {
+	m := c.m
+	n := len(m)
+	var buf [stackWords + 2]uint
+	t := buf[:]
+	if n > stackWords {
+		t = make([]uint, n+2)
+	}
+	for i := 0; i < n; i++ {
+		// t += x[i]*y
+		var carry, cc uint
+		for j := 0; j < n; j++ {
+			hi, lo := bits.Mul(x[i], y[j])
+			lo, cc = bits.Add(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add(lo, carry, 0)
+			hi += cc
+			t[j], carry = lo, hi
+		}
+		t[n], cc = bits.Add(t[n], carry, 0)
+		t[n+1] = cc
+
+		// t = (t + u*m)/2^_W, for the u that clears the low word
+		u := t[0] * c.minv
+		hi, lo := bits.Mul(u, m[0])
+		_, cc = bits.Add(lo, t[0], 0)
+		carry = hi + cc
+		for j := 1; j < n; j++ {
+			hi, lo = bits.Mul(u, m[j])
+			lo, cc = bits.Add(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add(lo, carry, 0)
+			hi += cc
+			t[j-1], carry = lo, hi
+		}
+		t[n-1], cc = bits.Add(t[n], carry, 0)
+		t[n] = t[n+1] + cc
+	}
+	// t < 2m, subtract m unless it borrows
+	c.reduceOnce(z, t[:n], t[n])
+}
+
+// reduceOnce sets z = x - m if the value hi*2^(_W*n) + x is at least m, or x
+// otherwise, for a value less than 2m.
+func (c *ctModulus) reduceOnce(z, x []uint, hi uint) {
+	n := len(c.m)
+	var buf [stackWords]uint
+	d := buf[:]
+	if n > stackWords {
+		d = make([]uint, n)
+	}
+	var borrow uint
+	for j := 0; j < n; j++ {
+		d[j], borrow = bits.Sub(x[j], c.m[j], borrow)
+	}
+	mask := -(hi | (borrow ^ 1))
+	for j := 0; j < n; j++ {
+		z[j] = d[j]&mask | x[j]&^mask
+	}
+}
+
+// add sets z = x + y mod m.
+func (c *ctModulus) add(z, x, y []uint) {
+	n := len(c.m)
+	var buf [stackWords]uint
+	s := buf[:]
+	if n > stackWords {
+		s = make([]uint, n)
+	}
+	var carry uint
+	for j := 0; j < n; j++ {
+		s[j], carry = bits.Add(x[j], y[j], carry)
+	}
+	c.reduceOnce(z, s[:n], carry)
+}
+
+// sub sets z = x - y mod m.
+func (c *ctModulus) sub(z, x, y []uint) {
+	n := len(c.m)
+	var borrow, carry uint
+	for j := 0; j < n; j++ {
+		z[j], borrow = bits.Sub(x[j], y[j], borrow)
+	}
+	mask := -borrow
+	for j := 0; j < n; j++ {
+		z[j], carry = bits.Add(z[j], c.m[j]&mask, carry)
+	}
+}
+
+// mul sets z = x*y mod m.
+func (c *ctModulus) mul(z, x, y []uint) {
+	c.montMul(z, x, y)
+	c.montMul(z, z, c.rr)
+}
+
+// inv sets z = x^(m-2) mod m, the inverse of x if m is prime, or 0 if x is 0.
+// The exponent is public, so it is scanned bit by bit.
+func (c *ctModulus) inv(z, x []uint) {
+	xr := make([]uint, len(c.m))
+	c.montMul(xr, x, c.rr)
+	acc := make([]uint, len(c.m))
+	c.montMul(acc, c.one, c.rr)
+	for k := c.e.BitLen() - 1; k >= 0; k-- {
+		c.montMul(acc, acc, acc)
+		if c.e.Bit(k) != 0 {
+			c.montMul(acc, acc, xr)
+		}
+	}
+	c.montMul(z, acc, c.one)
+}
+
+// reduce sets z = v mod m for any v >= 0, in a time that depends only on the
+// number of words of v. The words of v are taken by blocks of len(m), from the
+// most significant, in Horner's rule: z = z*R + block.
+func (c *ctModulus) reduce(z []uint, v *big.Int) {
+	n := len(c.m)
+	w := v.Bits()
+	for k := range z {
+		z[k] = 0
+	}
+	block := make([]uint, n)
+	for top := (len(w) + n - 1) / n * n; top > 0; top -= n {
+		for j := 0; j < n; j++ {
+			block[j] = 0
+			if top-n+j < len(w) {
+				block[j] = uint(w[top-n+j])
+			}
+		}
+		// block*R^2/R = block*R, then /R, for block < R
+		c.montMul(block, block, c.rr)
+		c.montMul(block, block, c.one)
+		c.montMul(z, z, c.rr)
+		c.add(z, z, block)
+	}
+}
@@ -25,15 +25,17 @@ func (p *curvePoint) String() string {
 func (p *curvePoint) Equal(p2 abstract.Point) bool {
 	cp2 := p2.(*curvePoint)
 
-	// Make sure both coordinates are normalized.
-	// Apparently Go's elliptic curve code doesn't always ensure this.
+	// Compare normalized coordinates, as apparently Go's elliptic curve
+	// code doesn't always ensure this. The points are not modified, so
+	// that they can be compared concurrently.
 	M := p.c.p.P
-	p.x.Mod(p.x, M)
-	p.y.Mod(p.y, M)
-	cp2.x.Mod(cp2.x, M)
-	cp2.y.Mod(cp2.y, M)
-
-	return p.x.Cmp(cp2.x) == 0 && p.y.Cmp(cp2.y) == 0
+	mod := func(v *big.Int) *big.Int {
+		if v.Sign() >= 0 && v.Cmp(M) < 0 {
+			return v
+		}
+		return new(big.Int).Mod(v, M)
+	}
+	return mod(p.x).Cmp(mod(cp2.x)) == 0 && mod(p.y).Cmp(mod(cp2.y)) == 0
 }
 
 func (p *curvePoint) Null() abstract.Point {
@@ -179,6 +181,9 @@ func (p *curvePoint) MarshalBinary() ([]byte, error) {
 }
 
 func (p *curvePoint) UnmarshalBinary(buf []byte) error {
+	if len(buf) != p.MarshalSize() {
+		return errors.New("invalid elliptic curve point length")
+	}
 	// Check whether all bytes after first one are 0, so we
 	// just return the initial point. Read everything to
 	// prevent timing-leakage.
@@ -225,6 +230,11 @@ func (g *curve) PrimeOrder() bool {
 	return true
 }
 
+// Returns 1: all the NIST curves we support are prime-order.
+func (c *curve) Cofactor() *big.Int {
+	return big.NewInt(1)
+}
+
 // Return the number of bytes in the encoding of a Scalar for this curve.
 func (c *curve) ScalarLen() int { return (c.p.N.BitLen() + 7) / 8 }
 
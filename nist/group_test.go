@@ -33,3 +33,16 @@ func BenchmarkPointBaseMul(b *testing.B) { benchP256.PointBaseMul(b.N) }
 func BenchmarkPointPick(b *testing.B)    { benchP256.PointPick(b.N) }
 func BenchmarkPointEncode(b *testing.B)  { benchP256.PointEncode(b.N) }
 func BenchmarkPointDecode(b *testing.B)  { benchP256.PointDecode(b.N) }
+
+// Encodings of the wrong length, including empty ones, are rejected
+func TestP256PointLength(t *testing.T) {
+	buf, _ := testP256.Point().Null().MarshalBinary()
+	for _, b := range [][]byte{nil, buf[:1], buf[1:], append(buf, 0)} {
+		if testP256.Point().UnmarshalBinary(b) == nil {
+			t.Errorf("Encoding of length %d should be rejected", len(b))
+		}
+	}
+	if err := testP256.Point().UnmarshalBinary(buf); err != nil {
+		t.Error(err)
+	}
+}
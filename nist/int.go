@@ -36,6 +36,9 @@ const (
 // but "carries around" a pointer to the relevant modulus
 // and automatically normalizes the value to that modulus
 // after all arithmetic operations, simplifying modular arithmetic.
+// For odd moduli, such as the orders of prime-order groups,
+// the arithmetic operations except Exp run in constant time,
+// so that they can be used on secret scalars (see ctint.go).
 // Binary operations assume that the source(s)
 // have the same modulus, but do not check this assumption.
 // Unary and binary arithmetic operations may be performed on uninitialized
@@ -92,7 +95,8 @@ func (i *Int) Init64(v int64, m *big.Int) *Int {
 func (i *Int) InitBytes(a []byte, m *big.Int) *Int {
 	i.M = m
 	i.BO = BigEndian
-	i.V.SetBytes(a).Mod(&i.V, i.M)
+	i.V.SetBytes(a)
+	i.reduce()
 	return i
 }
 
@@ -205,6 +209,12 @@ func (i *Int) Add(a, b abstract.Scalar) abstract.Scalar {
 	ai := a.(*Int)
 	bi := b.(*Int)
 	i.M = ai.M
+	if c := ctModulusOf(i.M); c != nil {
+		x := c.limbs(&ai.V)
+		c.add(x, x, c.limbs(&bi.V))
+		c.setBig(&i.V, x)
+		return i
+	}
 	i.V.Add(&ai.V, &bi.V).Mod(&i.V, i.M)
 	return i
 }
@@ -215,6 +225,12 @@ func (i *Int) Sub(a, b abstract.Scalar) abstract.Scalar {
 	ai := a.(*Int)
 	bi := b.(*Int)
 	i.M = ai.M
+	if c := ctModulusOf(i.M); c != nil {
+		x := c.limbs(&ai.V)
+		c.sub(x, x, c.limbs(&bi.V))
+		c.setBig(&i.V, x)
+		return i
+	}
 	i.V.Sub(&ai.V, &bi.V).Mod(&i.V, i.M)
 	return i
 }
@@ -223,6 +239,12 @@ func (i *Int) Sub(a, b abstract.Scalar) abstract.Scalar {
 func (i *Int) Neg(a abstract.Scalar) abstract.Scalar {
 	ai := a.(*Int)
 	i.M = ai.M
+	if c := ctModulusOf(i.M); c != nil {
+		x := c.limbs(&ai.V)
+		c.sub(x, make([]uint, len(x)), x)
+		c.setBig(&i.V, x)
+		return i
+	}
 	if ai.V.Sign() > 0 {
 		i.V.Sub(i.M, &ai.V)
 	} else {
@@ -237,31 +259,54 @@ func (i *Int) Mul(a, b abstract.Scalar) abstract.Scalar {
 	ai := a.(*Int)
 	bi := b.(*Int)
 	i.M = ai.M
+	if c := ctModulusOf(i.M); c != nil {
+		x := c.limbs(&ai.V)
+		c.mul(x, x, c.limbs(&bi.V))
+		c.setBig(&i.V, x)
+		return i
+	}
 	i.V.Mul(&ai.V, &bi.V).Mod(&i.V, i.M)
 	return i
 }
 
 // Set to a * b^-1 mod M, where b^-1 is the modular inverse of b.
+// Runs in constant time if M is an odd prime, see Inv.
 func (i *Int) Div(a, b abstract.Scalar) abstract.Scalar {
 	ai := a.(*Int)
 	bi := b.(*Int)
 	var t big.Int
 	i.M = ai.M
+	if c := ctModulusOf(i.M); c != nil && c.prime {
+		x, y := c.limbs(&ai.V), c.limbs(&bi.V)
+		c.inv(y, y)
+		c.mul(x, x, y)
+		c.setBig(&i.V, x)
+		return i
+	}
 	i.V.Mul(&ai.V, t.ModInverse(&bi.V, i.M))
 	i.V.Mod(&i.V, i.M)
 	return i
 }
 
 // Set to the modular inverse of a with respect to modulus M.
+// If M is an odd prime, the inverse is computed in constant time
+// as a^(M-2), so that the inverse of 0 is 0.
 func (i *Int) Inv(a abstract.Scalar) abstract.Scalar {
 	ai := a.(*Int)
 	i.M = ai.M
+	if c := ctModulusOf(i.M); c != nil && c.prime {
+		x := c.limbs(&ai.V)
+		c.inv(x, x)
+		c.setBig(&i.V, x)
+		return i
+	}
 	i.V.ModInverse(&a.(*Int).V, i.M)
 	return i
 }
 
 // Set to a^e mod M,
 // where e is an arbitrary big.Int exponent (not necessarily 0 <= e < M).
+// Unlike the other arithmetic operations, Exp is not constant time.
 func (i *Int) Exp(a abstract.Scalar, e *big.Int) abstract.Scalar {
 	ai := a.(*Int)
 	i.M = ai.M
@@ -385,7 +430,8 @@ func (i *Int) SetBytes(a []byte) abstract.Scalar {
 	if i.BO == LittleEndian {
 		buff = util.Reverse(nil, a)
 	}
-	i.V.SetBytes(buff).Mod(&i.V, i.M)
+	i.V.SetBytes(buff)
+	i.reduce()
 	return i
 }
 
@@ -472,5 +518,16 @@ func (i *Int) HideDecode(buf []byte) {
 		panic("Int.HideDecode: wrong size buffer")
 	}
 	i.V.SetBytes(buf)
+	i.reduce()
+}
+
+// Reduce the value modulo M, in constant time for an odd modulus.
+func (i *Int) reduce() {
+	if c := ctModulusOf(i.M); c != nil {
+		x := make([]uint, len(c.m))
+		c.reduce(x, &i.V)
+		c.setBig(&i.V, x)
+		return
+	}
 	i.V.Mod(&i.V, i.M)
 }
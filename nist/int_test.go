@@ -2,10 +2,13 @@ package nist
 
 import (
 	"bytes"
+	"crypto/elliptic"
 	"encoding/hex"
 	"math/big"
 	"testing"
+	"time"
 
+	"github.com/dedis/crypto/random"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -85,3 +88,121 @@ func TestIntClone(t *testing.T) {
 		t.Error("Should not be equal")
 	}
 }
+
+// The constant-time arithmetic must agree with big.Int, for prime and
+// composite odd moduli, and even ones which use big.Int.
+func TestIntArithmetic(t *testing.T) {
+	moduli := []*big.Int{
+		elliptic.P256().Params().N,
+		new(big.Int).Sub(new(big.Int).Lsh(one, 255), big.NewInt(19)),
+		new(big.Int).Sub(new(big.Int).Lsh(one, 521), one),
+		big.NewInt(65535),
+		big.NewInt(65536),
+		big.NewInt(3),
+	}
+	for _, m := range moduli {
+		values := []*big.Int{big.NewInt(0), big.NewInt(1),
+			new(big.Int).Sub(m, one)}
+		for k := 0; k < 10; k++ {
+			values = append(values, random.Int(m, random.Stream))
+		}
+		prime := m.ProbablyPrime(20)
+		for _, a := range values {
+			ai := NewInt(a, m)
+			for _, b := range values {
+				bi := NewInt(b, m)
+				exp := new(big.Int)
+				exp.Add(a, b).Mod(exp, m)
+				assert.Equal(t, 0, exp.Cmp(&new(Int).Add(ai, bi).(*Int).V))
+				exp.Sub(a, b).Mod(exp, m)
+				assert.Equal(t, 0, exp.Cmp(&new(Int).Sub(ai, bi).(*Int).V))
+				exp.Mul(a, b).Mod(exp, m)
+				assert.Equal(t, 0, exp.Cmp(&new(Int).Mul(ai, bi).(*Int).V))
+				if prime && b.Sign() != 0 {
+					exp.ModInverse(b, m).Mul(exp, a).Mod(exp, m)
+					assert.Equal(t, 0, exp.Cmp(&new(Int).Div(ai, bi).(*Int).V))
+				}
+			}
+			exp := new(big.Int).Neg(a)
+			assert.Equal(t, 0, exp.Mod(exp, m).Cmp(&new(Int).Neg(ai).(*Int).V))
+			if prime && a.Sign() != 0 {
+				exp.ModInverse(a, m)
+				assert.Equal(t, 0, exp.Cmp(&new(Int).Inv(ai).(*Int).V))
+			}
+
+			// reduction of values of any length
+			buf := random.Bytes(2*len(m.Bytes())+3, random.Stream)
+			exp.SetBytes(buf).Mod(exp, m)
+			assert.Equal(t, 0, exp.Cmp(&NewIntBytes(buf, m).V))
+		}
+
+		// values set directly may be out of range
+		over := &Int{M: m}
+		over.V.Add(m, two)
+		exp := new(big.Int).Mod(two, m)
+		exp.Mul(exp, exp).Mod(exp, m)
+		assert.Equal(t, 0, exp.Cmp(&new(Int).Mul(over, over).(*Int).V))
+	}
+}
+
+// minTimes returns the shortest times of a few runs of n calls to f and g,
+// interleaved so that both see the same load.
+func minTimes(n int, f, g func()) (time.Duration, time.Duration) {
+	var min [2]time.Duration
+	for run := 0; run < 40; run++ {
+		start := time.Now()
+		for k := 0; k < n; k++ {
+			if run%2 == 0 {
+				f()
+			} else {
+				g()
+			}
+		}
+		if d := time.Since(start); run < 2 || d < min[run%2] {
+			min[run%2] = d
+		}
+	}
+	return min[0], min[1]
+}
+
+// A coarse timing regression test: the operations on a secret must take about
+// as long for small values, on which big.Int is much faster, as for random
+// ones. The margin is large to absorb the noise of the test machines.
+func TestIntConstantTime(t *testing.T) {
+	if testing.Short() {
+		t.Skip("timing test skipped in short mode")
+	}
+	m := elliptic.P256().Params().N
+	small := NewInt64(1, m)
+	large := new(Int).Init64(0, m)
+	large.Pick(random.Stream)
+	buf := make([]byte, 64)
+	r := new(Int).Init64(0, m)
+	ops := []struct {
+		name string
+		n    int
+		op   func(a *Int, b []byte)
+	}{
+		{"Inv", 50, func(a *Int, b []byte) { r.Inv(a) }},
+		{"Div", 50, func(a *Int, b []byte) { r.Div(large, a) }},
+		{"Mul", 2000, func(a *Int, b []byte) { r.Mul(a, a) }},
+		{"Add", 5000, func(a *Int, b []byte) { r.Add(a, a) }},
+		{"Neg", 5000, func(a *Int, b []byte) { r.Neg(a) }},
+		{"SetBytes", 2000, func(a *Int, b []byte) {
+			copy(buf, b)
+			r.SetBytes(buf)
+		}},
+	}
+	zeros := make([]byte, 64)
+	zeros[0] = 1 // same length, small remainder
+	ones := random.Bytes(64, random.Stream)
+	for _, o := range ops {
+		fast, slow := minTimes(o.n, func() { o.op(small, zeros) },
+			func() { o.op(large, ones) })
+		ratio := float64(slow) / float64(fast)
+		if ratio > 2 || ratio < 1/2.0 {
+			t.Errorf("%s: small and random values take %v and %v", o.name,
+				fast, slow)
+		}
+	}
+}
@@ -44,12 +44,12 @@ func (p *residuePoint) Base() abstract.Point {
 
 func (p *residuePoint) Set(p2 abstract.Point) abstract.Point {
 	p.g = p2.(*residuePoint).g
-	p.Int = p2.(*residuePoint).Int
+	p.Int.Set(&p2.(*residuePoint).Int)
 	return p
 }
 
 func (p *residuePoint) Clone() abstract.Point {
-	return &residuePoint{g: p.g, Int: p.Int}
+	return new(residuePoint).Set(p)
 }
 
 func (p *residuePoint) Valid() bool {
@@ -57,6 +57,10 @@ func (p *residuePoint) Valid() bool {
 		new(big.Int).Exp(&p.Int, p.g.Q, p.g.P).Cmp(one) == 0
 }
 
+func (p *residuePoint) IsInCorrectSubgroup() bool {
+	return p.Valid()
+}
+
 func (p *residuePoint) PickLen() int {
 	// Reserve at least 8 most-significant bits for randomness,
 	// and the least-significant 16 bits for embedded data length.
@@ -195,6 +199,11 @@ func (g *ResidueGroup) PrimeOrder() bool {
 	return true
 }
 
+// Returns the cofactor R of the Residue group, with P=QR+1.
+func (g *ResidueGroup) Cofactor() *big.Int {
+	return new(big.Int).Set(g.R)
+}
+
 // Return the number of bytes in the encoding of a Scalar
 // for this Residue group.
 func (g *ResidueGroup) ScalarLen() int { return (g.Q.BitLen() + 7) / 8 }
@@ -0,0 +1,190 @@
+// Package audit implements periodic audits of the insurers of poly.Deals,
+// which give clients evidence that an insurer still holds its shares without
+// revealing them. The insurer of index i proves, with a Schnorr proof of
+// knowledge bound to a fresh challenge of the client, that it knows the
+// decrypted share s of each audited Deal such that s*G == pubPoly.Eval(i),
+// i.e., a share that passes pubPoly.Check(i, s). An audit goes as follows:
+//  1. The client picks a fresh challenge with NewChallenge() and sends it to
+//     the insurer, together with the list of Deals to audit.
+//  2. The insurer decrypts its shares and answers with Prove(), which returns
+//     one Proof per Deal under a single challenge.
+//  3. The client checks all the proofs at once with Verify(), and if the
+//     audit fails, finds the Deals whose share is missing with Failed().
+//
+// The public views of the Deals, as returned by Deal.Public, are enough for
+// the client. For a concrete example see audit_test.go.
+package audit
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/poly"
+	"github.com/dedis/crypto/random"
+)
+
+// ChallengeSize is the size in bytes of the challenges returned by
+// NewChallenge.
+const ChallengeSize = 32
+
+// Some error definitions.
+var errorChallenge = errors.New("audit challenge too short")
+var errorNoDeals = errors.New("no deals to audit")
+var errorIndex = errors.New("invalid insurer index")
+var errorShare = errors.New("share of the insurer cannot be revealed or is invalid")
+var errorDifferentLengths = errors.New("inputs of different lengths")
+var errorInvalidProof = errors.New("invalid audit proof")
+
+// Proof is the proof of knowledge of the share of an insurer in a Deal.
+type Proof struct {
+	V abstract.Point  // Commitment
+	R abstract.Scalar // Response
+}
+
+// NewChallenge returns a fresh random challenge for an audit.
+func NewChallenge() []byte {
+	return random.Bytes(ChallengeSize, random.Stream)
+}
+
+// Prove answers the challenge of the audit of the insurer of index i in the
+// given deals, whose long-term key pair is key. The function returns one proof
+// per deal, or an error if one of the shares cannot be revealed or does not
+// pass the check against the public polynomial of its deal.
+func Prove(suite abstract.Suite, deals []*poly.Deal, i int, key *config.KeyPair, challenge []byte) ([]*Proof, error) {
+	X, err := commits(deals, i, challenge)
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]abstract.Scalar, len(deals))
+	for k, d := range deals {
+		share, err := d.RevealShare(i, key)
+		if err != nil || d.VerifyRevealedShare(i, share) != nil {
+			return nil, errorShare
+		}
+		shares[k] = share
+	}
+	v := make([]abstract.Scalar, len(deals))
+	proofs := make([]*Proof, len(deals))
+	for k := range deals {
+		v[k] = suite.Scalar().Pick(random.Stream)
+		proofs[k] = &Proof{V: suite.Point().Mul(nil, v[k])}
+	}
+	c, err := auditChallenge(suite, i, challenge, X, proofs)
+	if err != nil {
+		return nil, err
+	}
+	for k, p := range proofs {
+		p.R = suite.Scalar().Mul(c, shares[k])
+		p.R.Add(p.R, v[k])
+	}
+	return proofs, nil
+}
+
+// Verify checks the proofs of the audit of the insurer of index i in the
+// given deals. All the proofs are checked at once with a random linear
+// combination, and the function returns nil iff they are all valid.
+func Verify(suite abstract.Suite, deals []*poly.Deal, i int, challenge []byte, proofs []*Proof) error {
+	X, c, err := verifyInit(suite, deals, i, challenge, proofs)
+	if err != nil {
+		return err
+	}
+
+	// sum(w_k*R_k)*G == sum(w_k*(V_k + c*X_k)) for random weights w_k
+	r := suite.Scalar().Zero()
+	right := suite.Point().Null()
+	w := suite.Scalar()
+	tmp := suite.Scalar()
+	P := suite.Point()
+	for k, p := range proofs {
+		w.Pick(random.Stream)
+		r.Add(r, tmp.Mul(w, p.R))
+		P.Mul(X[k], c)
+		P.Add(P, p.V)
+		right.Add(right, P.Mul(P, w))
+	}
+	if !suite.Point().Mul(nil, r).Equal(right) {
+		return errorInvalidProof
+	}
+	return nil
+}
+
+// Failed checks the proofs of the audit of the insurer of index i one by one,
+// and returns the positions in deals of those that are invalid, i.e., of the
+// deals whose share the insurer did not prove to hold.
+func Failed(suite abstract.Suite, deals []*poly.Deal, i int, challenge []byte, proofs []*Proof) ([]int, error) {
+	X, c, err := verifyInit(suite, deals, i, challenge, proofs)
+	if err != nil {
+		return nil, err
+	}
+	var failed []int
+	P := suite.Point()
+	for k, p := range proofs {
+		P.Mul(X[k], c)
+		P.Add(P, p.V)
+		if !suite.Point().Mul(nil, p.R).Equal(P) {
+			failed = append(failed, k)
+		}
+	}
+	return failed, nil
+}
+
+// Checks the shape of the proofs and returns the public shares of the insurer
+// together with the challenge of the proofs.
+func verifyInit(suite abstract.Suite, deals []*poly.Deal, i int, challenge []byte, proofs []*Proof) ([]abstract.Point, abstract.Scalar, error) {
+	X, err := commits(deals, i, challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(proofs) != len(deals) {
+		return nil, nil, errorDifferentLengths
+	}
+	for _, p := range proofs {
+		if p == nil || p.V == nil || p.R == nil {
+			return nil, nil, errorInvalidProof
+		}
+	}
+	c, err := auditChallenge(suite, i, challenge, X, proofs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return X, c, nil
+}
+
+// Returns the public shares of the insurer of index i in the deals.
+func commits(deals []*poly.Deal, i int, challenge []byte) ([]abstract.Point, error) {
+	if len(challenge) < ChallengeSize {
+		return nil, errorChallenge
+	}
+	if len(deals) == 0 {
+		return nil, errorNoDeals
+	}
+	X := make([]abstract.Point, len(deals))
+	for k, d := range deals {
+		if i < 0 || len(d.Insurers()) <= i {
+			return nil, errorIndex
+		}
+		X[k] = d.PubPoly().Eval(i)
+	}
+	return X, nil
+}
+
+// Computes the challenge of the proofs as the hash of the challenge of the
+// client, the index of the insurer, its public shares and the commitments.
+func auditChallenge(suite abstract.Suite, i int, challenge []byte, X []abstract.Point, proofs []*Proof) (abstract.Scalar, error) {
+	h := suite.Hash()
+	h.Write(challenge)
+	if err := binary.Write(h, binary.LittleEndian, uint32(i)); err != nil {
+		return nil, err
+	}
+	for k, x := range X {
+		if _, err := x.MarshalTo(h); err != nil {
+			return nil, err
+		}
+		if _, err := proofs[k].V.MarshalTo(h); err != nil {
+			return nil, err
+		}
+	}
+	return suite.Scalar().Pick(suite.Cipher(h.Sum(nil))), nil
+}
@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/poly"
+	"github.com/dedis/crypto/random"
+	"github.com/stretchr/testify/require"
+)
+
+func newKeyPair(suite abstract.Suite) *config.KeyPair {
+	key := new(config.KeyPair)
+	key.Gen(suite, random.Stream)
+	return key
+}
+
+func TestAudit(test *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	n := 5
+	t := 3
+
+	keys := make([]*config.KeyPair, n)
+	insurers := make([]abstract.Point, n)
+	for i := range keys {
+		keys[i] = newKeyPair(suite)
+		insurers[i] = keys[i].Public
+	}
+	dealer := newKeyPair(suite)
+	deals := make([]*poly.Deal, 3)
+	views := make([]*poly.Deal, len(deals))
+	for k := range deals {
+		deals[k] = new(poly.Deal).ConstructDeal(newKeyPair(suite), dealer, t, t, insurers)
+		view, err := deals[k].Public()
+		require.Nil(test, err)
+		views[k] = view
+	}
+
+	// (1) The client challenges insurer 1
+	i := 1
+	challenge := NewChallenge()
+
+	// (2) The insurer proves it holds its shares
+	proofs, err := Prove(suite, deals, i, keys[i], challenge)
+	require.Nil(test, err)
+
+	// (3) The client verifies the proofs on the public views
+	require.Nil(test, Verify(suite, views, i, challenge, proofs))
+	failed, err := Failed(suite, views, i, challenge, proofs)
+	require.Nil(test, err)
+	require.Equal(test, len(failed), 0)
+
+	// The proofs are bound to the challenge and to the insurer
+	require.Equal(test, Verify(suite, views, i, NewChallenge(), proofs), errorInvalidProof)
+	require.Equal(test, Verify(suite, views, 2, challenge, proofs), errorInvalidProof)
+
+	// A wrong proof is caught and located
+	proofs[1].R = suite.Scalar().Add(proofs[1].R, suite.Scalar().One())
+	require.Equal(test, Verify(suite, views, i, challenge, proofs), errorInvalidProof)
+	failed, err = Failed(suite, views, i, challenge, proofs)
+	require.Nil(test, err)
+	require.Equal(test, failed, []int{1})
+}
+
+func TestAuditInvalid(test *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	keys := []*config.KeyPair{newKeyPair(suite), newKeyPair(suite)}
+	insurers := []abstract.Point{keys[0].Public, keys[1].Public}
+	deal := new(poly.Deal).ConstructDeal(newKeyPair(suite), newKeyPair(suite), 2, 2, insurers)
+	deals := []*poly.Deal{deal}
+	challenge := NewChallenge()
+
+	_, err := Prove(suite, deals, 0, keys[0], challenge[:ChallengeSize-1])
+	require.Equal(test, err, errorChallenge)
+	_, err = Prove(suite, nil, 0, keys[0], challenge)
+	require.Equal(test, err, errorNoDeals)
+	_, err = Prove(suite, deals, 2, keys[0], challenge)
+	require.Equal(test, err, errorIndex)
+
+	// An insurer cannot prove the share of another one
+	_, err = Prove(suite, deals, 0, keys[1], challenge)
+	require.Equal(test, err, errorShare)
+
+	proofs, err := Prove(suite, deals, 0, keys[0], challenge)
+	require.Nil(test, err)
+	require.Equal(test, Verify(suite, []*poly.Deal{deal, deal}, 0, challenge, proofs), errorDifferentLengths)
+	require.Equal(test, Verify(suite, deals, 0, challenge, []*Proof{nil}), errorInvalidProof)
+}
@@ -0,0 +1,164 @@
+package poly
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/ed25519"
+	"github.com/dedis/crypto/nist"
+	"github.com/dedis/crypto/test"
+)
+
+var benchCSV = flag.String("benchcsv", "", "run the Deal benchmarks and write their results as CSV to this file")
+
+// The configurations of the Deal benchmarks, with r = t
+var benchParams = test.ThresholdConfigs([]abstract.Suite{
+	nist.NewAES128SHA256P256(),
+	ed25519.NewAES128SHA256Ed25519(false),
+}, [][2]int{{3, 5}, {10, 20}, {34, 100}})
+
+// The parties of a benchmarked Deal
+type benchParties struct {
+	secret, dealer *config.KeyPair
+	insurers       []*config.KeyPair
+	publics        []abstract.Point
+}
+
+func newBenchParties(p test.ThresholdParams) *benchParties {
+	bp := &benchParties{
+		secret:   config.NewKeyPair(p.Suite),
+		dealer:   config.NewKeyPair(p.Suite),
+		insurers: make([]*config.KeyPair, p.N),
+		publics:  make([]abstract.Point, p.N),
+	}
+	for i := range bp.insurers {
+		bp.insurers[i] = config.NewKeyPair(p.Suite)
+		bp.publics[i] = bp.insurers[i].Public
+	}
+	return bp
+}
+
+func (bp *benchParties) deal(p test.ThresholdParams) *Deal {
+	return new(Deal).ConstructDeal(bp.secret, bp.dealer, p.T, p.T, bp.publics)
+}
+
+// The signatures of all insurers on a Deal
+func (bp *benchParties) responses(deal *Deal) []*Response {
+	responses := make([]*Response, len(bp.insurers))
+	for i, key := range bp.insurers {
+		responses[i], _ = deal.ProduceResponse(i, key)
+	}
+	return responses
+}
+
+func benchConstructDeal(b *testing.B, p test.ThresholdParams) {
+	bp := newBenchParties(p)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bp.deal(p)
+	}
+}
+
+// Verification of one share by its insurer, producing a signature
+func benchDealVerify(b *testing.B, p test.ThresholdParams) {
+	bp := newBenchParties(p)
+	deal := bp.deal(p)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		j := i % p.N
+		if _, err := deal.ProduceResponse(j, bp.insurers[j]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Certification of a Deal from the signatures of all insurers
+func benchDealCertify(b *testing.B, p test.ThresholdParams) {
+	bp := newBenchParties(p)
+	deal := bp.deal(p)
+	responses := bp.responses(deal)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		state := new(State).Init(*deal)
+		for j, r := range responses {
+			if err := state.AddResponse(j, r); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := state.DealCertified(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Recovery of the secret from t revealed shares of a certified Deal
+func benchRecoverSecret(b *testing.B, p test.ThresholdParams) {
+	bp := newBenchParties(p)
+	deal := bp.deal(p)
+	state := new(State).Init(*deal)
+	for j, r := range bp.responses(deal) {
+		state.AddResponse(j, r)
+	}
+	shares := make([]abstract.Scalar, p.T)
+	for j := range shares {
+		shares[j], _ = state.RevealShare(j, bp.insurers[j])
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var ps PriShares
+		ps.Empty(p.Suite, p.T, p.N)
+		for j, s := range shares {
+			ps.SetShare(j, s)
+		}
+		ps.Secret()
+	}
+}
+
+func BenchmarkConstructDeal(b *testing.B) {
+	test.RunThresholdBench(b, benchParams, benchConstructDeal)
+}
+
+func BenchmarkDealVerify(b *testing.B) {
+	test.RunThresholdBench(b, benchParams, benchDealVerify)
+}
+
+func BenchmarkDealCertify(b *testing.B) {
+	test.RunThresholdBench(b, benchParams, benchDealCertify)
+}
+
+func BenchmarkDealRecoverSecret(b *testing.B) {
+	test.RunThresholdBench(b, benchParams, benchRecoverSecret)
+}
+
+// Runs all the Deal benchmarks and writes their results as CSV when the
+// -benchcsv flag is set, e.g., go test -run BenchmarkCSV -benchcsv deal.csv
+func TestBenchmarkCSV(t *testing.T) {
+	if *benchCSV == "" {
+		t.Skip("no -benchcsv file given")
+	}
+	f, err := os.Create(*benchCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	c, err := test.NewThresholdCSV(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bench := range []struct {
+		name string
+		f    test.ThresholdBench
+	}{
+		{"ConstructDeal", benchConstructDeal},
+		{"DealVerify", benchDealVerify},
+		{"DealCertify", benchDealCertify},
+		{"DealRecoverSecret", benchRecoverSecret},
+	} {
+		if err := c.Run(bench.name, benchParams, bench.f); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
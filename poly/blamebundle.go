@@ -0,0 +1,258 @@
+package poly
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/sign"
+)
+
+// Domain of the signatures by which Dealers issue their Deals, see
+// Deal.DealerSign
+const issueDomain = "poly.Deal.Issue"
+
+/* A BlameBundle is a self-contained proof that a Deal holds a corrupted
+ * share, for external systems such as a staking layer slashing malicious
+ * Dealers. It holds the Deal, the index of the bad share and the blameProof
+ * of its insurer, and is verified by VerifyBlameBundle without any State.
+ *
+ * The bundle carries the whole Deal rather than its public view (see
+ * MarshalPublic), as the public view only keeps a digest of the encrypted
+ * shares, from which the bad share cannot be decrypted. The shares are
+ * encrypted for their insurers, so the bundle reveals no more than the Deal
+ * itself. The blameProof is verified with the Diffie-Hellman wrapping of the
+ * shares, as for any Deal received over the network (see SetShareWrapper).
+ *
+ * The bundle also carries the signature by which the Dealer issued the Deal
+ * (see Deal.DealerSign), so that a valid bundle proves that the Dealer itself
+ * issued a Deal holding a bad share: an insurer cannot build a bad Deal naming
+ * another Dealer and blame it.
+ */
+type BlameBundle struct {
+
+	// The blamed Deal, with its encrypted shares
+	deal Deal
+
+	// The signature of the Dealer issuing the Deal
+	dealerSig signature
+
+	// The index of the bad share
+	index int
+
+	// The blameProof of the insurer of the bad share
+	blame blameProof
+}
+
+/* For Dealers, signs the Deal to certify that they issued it. The Dealer
+ * sends the signature along with the Deal, so that insurers can prove the
+ * Dealer malicious to third parties with a BlameBundle. The signature covers
+ * the content of the Deal (see Deal.contentDigest).
+ *
+ * Arguments
+ *    longPair = the long term keypair of the Dealer
+ *
+ * Returns
+ *   The signature, or an error if longPair is not the key of the Dealer or
+ *   if marshalling the Deal failed
+ */
+func (p *Deal) DealerSign(longPair *config.KeyPair) ([]byte, error) {
+	if !p.pubKey.Equal(longPair.Public) {
+		return nil, errors.New("Not the long term key of the Dealer")
+	}
+	digest, err := p.contentDigest()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := p.sign(0, longPair, issueDomain, digest)
+	if err != nil {
+		return nil, err
+	}
+	return sig.signature, nil
+}
+
+/* Verifies that the Dealer named by the Deal issued it, see DealerSign.
+ *
+ * Arguments
+ *    sig = the signature of the Dealer
+ *
+ * Returns
+ *   nil if the signature is valid, ErrInvalidDealerSignature otherwise.
+ */
+func (p *Deal) VerifyDealerSignature(sig []byte) error {
+	digest, err := p.contentDigest()
+	if err != nil {
+		return err
+	}
+	if sign.VerifyWithDomain(p.suite, p.pubKey, issueDomain, digest,
+		sig) != nil {
+		return ErrInvalidDealerSignature
+	}
+	return nil
+}
+
+/* Creates a BlameBundle from the blameProof Response of an insurer, once it
+ * is verified.
+ *
+ * Arguments
+ *    deal      = the blamed Deal, not its public view
+ *    dealerSig = the signature by which the Dealer issued the Deal, see
+ *                Deal.DealerSign
+ *    i         = the index of the insurer that produced the Response
+ *    response  = the blameProof Response of the insurer
+ *
+ * Returns
+ *   The BlameBundle, or an error if the Dealer did not sign the Deal or if
+ *   the Response is no justified blameProof
+ */
+func NewBlameBundle(deal *Deal, dealerSig []byte, i int,
+	response *Response) (*BlameBundle, error) {
+	if response.rtype != blameProofResponse || response.blameProof == nil {
+		return nil, ErrInvalidResponse
+	}
+	if err := deal.VerifyDealerSignature(dealerSig); err != nil {
+		return nil, err
+	}
+	// Verify the bundle as it will be once decoded
+	c := deal.clone()
+	c.wrapper = nil
+	if err := c.verifyBlame(i, response.blameProof); err != nil {
+		return nil, err
+	}
+	sig := append([]byte(nil), dealerSig...)
+	return &BlameBundle{c, *new(signature).init(deal.suite, sig), i,
+		*response.blameProof}, nil
+}
+
+/* Verifies a BlameBundle on its own, i.e., that its Deal is well formed and
+ * signed by its Dealer, and that the blameProof proves the share at its index
+ * to be bad.
+ *
+ * Arguments
+ *    suite  = the suite expected for the Deal
+ *    bundle = the BlameBundle
+ *
+ * Returns
+ *   nil if the bundle proves the share to be bad, an error otherwise.
+ */
+func VerifyBlameBundle(suite abstract.Suite, bundle *BlameBundle) error {
+	if bundle.deal.suite == nil || bundle.deal.suite.String() != suite.String() {
+		return errors.New("BlameBundle of a different suite")
+	}
+	if bundle.deal.isPublic() {
+		return ErrPublicDeal
+	}
+	if err := bundle.deal.verifyDeal(); err != nil {
+		return err
+	}
+	if err := bundle.deal.VerifyDealerSignature(
+		bundle.dealerSig.signature); err != nil {
+		return err
+	}
+	if err := checkSubgroup(bundle.blame.diffieKey); err != nil {
+		return err
+	}
+	return bundle.deal.verifyBlame(bundle.index, &bundle.blame)
+}
+
+// Returns the public view of the blamed Deal, see Deal.Public.
+func (b *BlameBundle) Deal() (*Deal, error) {
+	return b.deal.Public()
+}
+
+// Returns the index of the bad share.
+func (b *BlameBundle) Index() int {
+	return b.index
+}
+
+// Returns a copy of the long term public key of the blamed Dealer.
+func (b *BlameBundle) DealerKey() abstract.Point {
+	return b.deal.DealerKey()
+}
+
+/* For users of this code, initializes a BlameBundle for unmarshalling
+ *
+ * Arguments
+ *    suite = the suite of the Deal
+ *
+ * Returns
+ *   An initialized BlameBundle ready to unmarshal a buffer
+ */
+func (b *BlameBundle) UnmarshalInit(suite abstract.Suite) *BlameBundle {
+	b.deal.suite = suite
+	b.dealerSig.UnmarshalInit(suite)
+	b.blame.UnmarshalInit(suite)
+	return b
+}
+
+/* Marshals a BlameBundle into a byte array
+ *
+ * Returns
+ *   A buffer of the marshalled BlameBundle
+ *   The error status of the marshalling (nil if no error)
+ *
+ * Note
+ *   The buffer is formatted as follows:
+ *
+ *      ||deal||dealerSig||index||blameProof||
+ *
+ *   where the Deal is written by Deal.MarshalSuite, with its parameters, the
+ *   signature of the Dealer as a signature struct, and the index is a
+ *   big-endian uint32.
+ */
+func (b *BlameBundle) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := b.deal.MarshalSuite(&buf, b.deal.suite); err != nil {
+		return nil, err
+	}
+	if _, err := b.dealerSig.MarshalTo(&buf); err != nil {
+		return nil, err
+	}
+	binary.Write(&buf, binary.BigEndian, uint32(b.index))
+	if _, err := b.blame.MarshalTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+/* Unmarshals a BlameBundle from a byte buffer. The bundle still has to be
+ * verified with VerifyBlameBundle.
+ *
+ * Arguments
+ *    buf = the buffer containing the BlameBundle
+ *
+ * Returns
+ *   The error status of the unmarshalling (nil if no error)
+ */
+func (b *BlameBundle) UnmarshalBinary(buf []byte) error {
+	suite := b.deal.suite
+	if suite == nil {
+		return errors.New("BlameBundle not initialized, see UnmarshalInit")
+	}
+	r := bytes.NewReader(buf)
+	if err := b.deal.UnmarshalSuite(r, suite); err != nil {
+		return err
+	}
+	b.dealerSig.UnmarshalInit(suite)
+	if _, err := b.dealerSig.UnmarshalFrom(r); err != nil {
+		return err
+	}
+	var index uint32
+	if err := binary.Read(r, binary.BigEndian, &index); err != nil {
+		return err
+	}
+	if index >= uint32(b.deal.n) {
+		return ErrInvalidIndex
+	}
+	b.index = int(index)
+	b.blame.UnmarshalInit(suite)
+	if _, err := b.blame.UnmarshalFrom(r); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return errors.New("Buffer size too large")
+	}
+	return nil
+}
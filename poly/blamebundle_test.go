@@ -0,0 +1,102 @@
+package poly
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/sign"
+)
+
+func TestBlameBundle(t *testing.T) {
+	deal := new(Deal).ConstructDeal(secretKey, DealerKey, 3, 4, insurerList[:5])
+	deal.secrets[2] = suite.Scalar().Pick(random.Stream)
+	blame, err := deal.ProduceResponse(2, insurerKeys[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	approval, err := deal.ProduceResponse(1, insurerKeys[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := deal.DealerSign(insurerKeys[0]); err == nil {
+		t.Error("Only the Dealer should sign the Deal")
+	}
+	dealerSig, err := deal.DealerSign(DealerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewBlameBundle(deal, dealerSig, 1, approval); err != ErrInvalidResponse {
+		t.Error("A signature is no blame", err)
+	}
+	if _, err := NewBlameBundle(deal, dealerSig, 1, blame); err == nil {
+		t.Error("Blame of another insurer should be rejected")
+	}
+	bundle, err := NewBlameBundle(deal, dealerSig, 2, blame)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The bundle travels to the slashing system, which has no State
+	buf, err := bundle.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(BlameBundle).UnmarshalInit(suite)
+	if err := decoded.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyBlameBundle(suite, decoded); err != nil {
+		t.Error("BlameBundle should be valid", err)
+	}
+	if decoded.Index() != 2 || !decoded.DealerKey().Equal(DealerKey.Public) {
+		t.Error("Wrong blamed share or Dealer")
+	}
+	public, _ := deal.Public()
+	if view, err := decoded.Deal(); err != nil || !view.Equal(public) {
+		t.Error("Wrong blamed Deal", err)
+	}
+	if err := VerifyBlameBundle(altSuite, decoded); err == nil {
+		t.Error("BlameBundle of another suite should be rejected")
+	}
+	if err := decoded.UnmarshalBinary(buf[:len(buf)-1]); err == nil {
+		t.Error("Truncated BlameBundle should be rejected")
+	}
+	if err := new(BlameBundle).UnmarshalBinary(buf); err == nil {
+		t.Error("Uninitialized BlameBundle should be rejected")
+	}
+
+	// The signature of the Dealer is checked
+	decoded.UnmarshalBinary(buf)
+	decoded.dealerSig.signature[len(decoded.dealerSig.signature)-1] ^= 1
+	if err := VerifyBlameBundle(suite, decoded); err != ErrInvalidDealerSignature {
+		t.Error("BlameBundle with a bad Dealer signature should be rejected", err)
+	}
+
+	// The blame does not prove other shares bad
+	decoded.UnmarshalBinary(buf)
+	decoded.index = 1
+	if err := VerifyBlameBundle(suite, decoded); err == nil {
+		t.Error("Blame of a good share should be rejected")
+	}
+}
+
+// Verify that an insurer cannot frame another Dealer with a bad Deal
+func TestBlameBundleForgedDealer(t *testing.T) {
+	// The insurer deals a bad Deal naming the victim as its Dealer
+	forger := insurerKeys[2]
+	deal := new(Deal).ConstructDeal(secretKey, DealerKey, 3, 4, insurerList[:5])
+	deal.secrets[2] = suite.Scalar().Pick(random.Stream)
+	blame, _ := deal.ProduceResponse(2, forger)
+	forgedSig, _ := sign.SignWithDomain(suite, forger.Secret, issueDomain,
+		[]byte("Deal"))
+	if _, err := NewBlameBundle(deal, forgedSig, 2, blame); err != ErrInvalidDealerSignature {
+		t.Error("Deal not signed by its Dealer should be rejected", err)
+	}
+
+	// Nor reuse a signature of the Dealer on another Deal
+	other := new(Deal).ConstructDeal(secretKey, DealerKey, 3, 4, insurerList[:5])
+	otherSig, _ := other.DealerSign(DealerKey)
+	if _, err := NewBlameBundle(deal, otherSig, 2, blame); err != ErrInvalidDealerSignature {
+		t.Error("Signature of another Deal should be rejected", err)
+	}
+}
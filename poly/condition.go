@@ -0,0 +1,41 @@
+package poly
+
+/* A ReleaseCondition restricts when the insurers of a Deal reveal their
+ * shares, e.g., not before a deadline (see package release). Once set on a
+ * Deal with SetReleaseCondition, the condition is enforced by Deal.RevealShare
+ * and thus by all the reveal paths of a State.
+ *
+ * The digest of the condition is covered by the digests of the Deal (see
+ * Deal.sharesDigest), so that the signatures of the insurers approve the Deal
+ * together with its condition: a Deal is only certified if enough insurers
+ * agreed on the same condition, and the Dealer cannot substitute another one
+ * afterwards. As the condition is not marshalled with the Deal, the Dealer,
+ * the insurers and the clients must all set it on their Deal before producing
+ * or verifying signatures.
+ */
+type ReleaseCondition interface {
+
+	// Digest returns a digest of the condition, covered by the signatures
+	// of the insurers. It must not depend on the evidence that the
+	// condition holds, such as release tokens.
+	Digest() ([]byte, error)
+
+	// Check returns nil iff the shares of the Deal may be revealed now.
+	Check(deal *Deal) error
+}
+
+/* Sets the condition restricting the release of the shares of the Deal. The
+ * Dealer, the insurers and the clients must set the same condition before
+ * producing or verifying responses, see ReleaseCondition. The recertified
+ * versions of the Deal keep the condition.
+ *
+ * Arguments
+ *    c = the ReleaseCondition, or nil to release the shares unconditionally
+ *
+ * Returns
+ *   The Deal itself
+ */
+func (p *Deal) SetReleaseCondition(c ReleaseCondition) *Deal {
+	p.release = c
+	return p
+}
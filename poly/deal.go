@@ -120,34 +120,240 @@
 package poly
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"reflect"
+	"runtime"
 	"strconv"
+	"sync"
 
 	"github.com/dedis/crypto/abstract"
-	"github.com/dedis/crypto/anon"
 	"github.com/dedis/crypto/config"
-	"github.com/dedis/crypto/proof"
 	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/sign"
 )
 
 // Used mostly in marshalling code, this is the size of a uint32
 var uint32Size int = binary.Size(uint32(0))
 
+// Upper bounds enforced when unmarshalling, so that a malformed or malicious
+// message cannot make the decoders allocate unbounded amounts of memory.
+const (
+	// The maximum number of insurers of a Deal read from a message
+	MaxInsurers = 1 << 16
+
+	// The maximum length of a length-prefixed field, such as a signature, a
+	// blameProof or a field of a Deal
+	MaxFieldSize = 1 << 20
+)
+
+/* An internal helper checking the parameters of a Deal read from a message.
+ *
+ * Arguments
+ *    t, r, n = the parameters of the Deal, as in ConstructDeal
+ *
+ * Returns
+ *   ErrInvalidDeal unless 0 <= t <= r <= n <= MaxInsurers
+ */
+func checkParams(t, r, n int) error {
+	if t < 0 || t > r || r > n || n > MaxInsurers {
+		return ErrInvalidDeal
+	}
+	return nil
+}
+
+/* An internal helper checking the length of a length-prefixed field read
+ * from a message before allocating it.
+ */
+func checkFieldSize(length int) error {
+	if length < 0 || length > MaxFieldSize {
+		return errors.New("Field size too large")
+	}
+	return nil
+}
+
 // This is the protocol name used by crypto/proof verifiers and provers.
 var protocolName string = "Deal Protocol"
 
-// These are messages used for signatures
-var sigMsg []byte = []byte("Deal Signature")
-var sigBlameMsg []byte = []byte("Deal Blame Signature")
+// Domains of the signatures of insurers approving a Deal or blaming its
+// Dealer, see sign.SignWithDomain and Deal.indexedMsg
+const (
+	approveDomain = "poly.Deal.Approve"
+	blameDomain   = "poly.Deal.Blame"
+)
+
+// Prefix of the digests deriving the ids of recertified Deals, see
+// Deal.Recertify
+var recertifyMsg []byte = []byte("Deal Recertification")
+
+// Prefix of the digests of the content of Deals, see Deal.contentDigest
+var contentDigestMsg []byte = []byte("Deal Content")
+
+// Prefix of the digests of the shares of Deals, see Deal.sharesDigest
+var sharesDigestMsg []byte = []byte("Deal Shares")
+
+/* DealErrorCode identifies the reason a Deal, a share or a Response failed
+ * verification, so that callers can decide programmatically whether to blame
+ * the Dealer, retry, or ignore the message.
+ */
+type DealErrorCode int
+
+const (
+	CodeInvalidIndex DealErrorCode = iota + 1
+	CodeInvalidDeal
+	CodeWrongInsurerKey
+	CodeShareCheckFailed
+	CodeNilSignature
+	CodeUnjustifiedBlame
+	CodeResponseAdded
+	CodeInvalidResponse
+	CodeCorruptedShare
+	CodeBlamed
+	CodeNotCertified
+	CodeInvalidPoint
+	CodeUnsupportedVersion
+	CodeInvalidShareProof
+	CodePublicDeal
+	CodeRevoked
+	CodeInvalidRevocation
+	CodeUnauthorized
+	CodeNotInsurer
+	CodeDuplicateInsurer
+	CodeInvalidDealerSignature
+	CodeReplayedRequest
+)
+
+/* DealError is the error type returned by all verification failures of this
+ * file. Errors can either be matched against the exported Err* values with
+ * errors.Is, which compares their codes, or classified by their Code, see
+ * ErrorCode. Some errors carry details in their message, e.g. the number of
+ * signatures of a Deal that is not certified, so they are not always the
+ * Err* values themselves.
+ */
+type DealError struct {
+	Code DealErrorCode
+	msg  string
+}
+
+func (e *DealError) Error() string {
+	return e.msg
+}
+
+// Reports whether target is a DealError of the same code, for errors.Is.
+func (e *DealError) Is(target error) bool {
+	t, ok := target.(*DealError)
+	return ok && t.Code == e.Code
+}
+
+/* Returns the DealErrorCode of an error returned by this file, or 0 if the
+ * error is nil or wraps no DealError.
+ */
+func ErrorCode(err error) DealErrorCode {
+	var e *DealError
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return 0
+}
+
+var (
+	// The index of a share or insurer is out of range
+	ErrInvalidIndex = &DealError{CodeInvalidIndex, "Invalid index. Expected 0 <= i < n"}
+
+	// The Deal is syntactically invalid
+	ErrInvalidDeal = &DealError{CodeInvalidDeal, "Invalid Deal. Expected t <= r <= n and n insurers and shares"}
+
+	// The insurer key recorded in the Deal differs from the one expected
+	ErrWrongInsurerKey = &DealError{CodeWrongInsurerKey, "The long-term public key the Deal recorded as the insurer " +
+		"of this share differs from what is expected"}
+
+	// A share was maliciously constructed (fails the public polynomial
+	// check). Hence, the Dealer is malicious.
+	ErrShareCheckFailed = &DealError{CodeShareCheckFailed, "The share failed the public polynomial check."}
+
+	// A Response carries no signature
+	ErrNilSignature = &DealError{CodeNilSignature, "Nil signature"}
+
+	// A blameProof blames the Dealer for a share that is valid
+	ErrUnjustifiedBlame = &DealError{CodeUnjustifiedBlame, "Unjustified blame. The share checks out okay."}
+
+	// A Response was already added for this insurer
+	ErrResponseAdded = &DealError{CodeResponseAdded, "Response already added."}
+
+	// A Response is neither a signature nor a blameProof
+	ErrInvalidResponse = &DealError{CodeInvalidResponse, "Invalid response."}
 
-// This error denotes that a share was maliciously constructed (fails
-// the public polynomial check). Hence, the Dealer is malicious.
-var maliciousShare = errors.New("Share is malicious. PubPoly.Check failed.")
+	// A revealed share does not match the public polynomial
+	ErrCorruptedShare = &DealError{CodeCorruptedShare, "This share is corrupted."}
+
+	// A valid blameProof proves the Deal to be uncertified
+	ErrBlamed = &DealError{CodeBlamed, "A valid blameProof proves this Deal to be uncertified."}
+
+	// The Deal has not received enough signatures. The errors returned by
+	// State.DealCertified carry this code along with the signature counts.
+	ErrNotCertified = &DealError{CodeNotCertified, "Not enough signatures yet to be certified"}
+
+	// A received point lies outside of the prime-order subgroup of its curve,
+	// as used in small-subgroup attacks
+	ErrInvalidPoint = &DealError{CodeInvalidPoint, "Point outside of the prime-order subgroup"}
+
+	// A marshalled Deal has a version of the binary format that is neither
+	// the current one nor registered with RegisterDealUpgrade
+	ErrUnsupportedVersion = &DealError{CodeUnsupportedVersion, "Unsupported version of the Deal binary format"}
+
+	// The ShareProofs of a Deal are malformed or fail to verify
+	ErrInvalidShareProof = &DealError{CodeInvalidShareProof, "Invalid proof of correct share encryption"}
+
+	// The operation needs the shares of the Deal, which its public view
+	// (see MarshalPublic) does not carry
+	ErrPublicDeal = &DealError{CodePublicDeal, "The public view of a Deal carries no shares"}
+
+	// The Dealer revoked the Deal, see Revocation
+	ErrRevoked = &DealError{CodeRevoked, "The Deal was revoked by its Dealer"}
+
+	// A Revocation is not for this Deal or not signed by its Dealer
+	ErrInvalidRevocation = &DealError{CodeInvalidRevocation, "Invalid revocation of the Deal"}
+
+	// A share was requested without a ReconstructionRequest signed by enough
+	// clients, see State.SetClientQuorum
+	ErrUnauthorized = &DealError{CodeUnauthorized, "Reconstruction not authorized by enough clients"}
+
+	// A long-term public key is not among the insurers of the Deal
+	ErrNotInsurer = &DealError{CodeNotInsurer, "The long-term public key is not an insurer of the Deal"}
+
+	// A long-term public key appears twice in a Roster
+	ErrDuplicateInsurer = &DealError{CodeDuplicateInsurer, "The same long-term public key appears twice among the insurers"}
+
+	// A Deal is not signed by the Dealer it names, see Deal.DealerSign
+	ErrInvalidDealerSignature = &DealError{CodeInvalidDealerSignature, "The Deal is not signed by its Dealer"}
+
+	// A ReconstructionRequest was already served, see AuthorizedRevealShare
+	ErrReplayedRequest = &DealError{CodeReplayedRequest, "The reconstruction request was already served"}
+)
+
+/* Checks that points received from other parties lie in the prime-order
+ * subgroup of their curve, see abstract.SubgroupChecker.
+ *
+ * Arguments
+ *    points = the points to check
+ *
+ * Returns
+ *   ErrInvalidPoint if any point fails the check, nil otherwise
+ */
+func checkSubgroup(points ...abstract.Point) error {
+	for _, P := range points {
+		if !abstract.IsInCorrectSubgroup(P) {
+			return ErrInvalidPoint
+		}
+	}
+	return nil
+}
 
 /* Deal structs are mechanisms by which a server can deal other servers
  * that an abstract.Scalar will be availble even if the secret's owner goes
@@ -172,6 +378,7 @@ var maliciousShare = errors.New("Share is malicious. PubPoly.Check failed.")
  *   * State.RevealShare (public wrapper to Deal.RevealShare in State struct)
  *
  * - Clients
+ *   * UnmarshalPublic (to receive only the public view, see MarshalPublic)
  *   * VerifyRevealedShare
  *
  * - All
@@ -185,7 +392,8 @@ var maliciousShare = errors.New("Share is malicious. PubPoly.Check failed.")
 type Deal struct {
 
 	// The id of the deal used to differentiate it from others
-	// The id is the short term public key of the private key being deald
+	// The id is the short term public key of the private key being deald,
+	// or a point derived from it for recertified Deals (see Recertify)
 	id abstract.Point
 
 	// The cryptographic key suite used throughout the Deal.
@@ -213,8 +421,29 @@ type Deal struct {
 
 	// The list of shared secrets to be sent to the insurers. They are
 	// encrypted with Diffie-Hellman shared secrets between the insurer
-	// and the Dealer.
+	// and the Dealer, unless another ShareWrapper is used.
 	secrets []abstract.Scalar
+
+	// The digest of the secrets, only set for the public view of a Deal
+	// decoded by UnmarshalPublic, which has no secrets.
+	secretsDigest []byte
+
+	// The ShareWrapper used to encrypt and decrypt the secrets. If nil,
+	// the Diffie-Hellman wrapping is used. It is not marshalled.
+	wrapper ShareWrapper
+
+	// The number of goroutines ConstructDeal uses to encrypt the secrets.
+	// Values <= 1 mean sequential construction. It is not marshalled.
+	workers int
+
+	// Whether ConstructDeal produces ShareProofs, which is not marshalled,
+	// and the ShareProofs of the Deal, if any
+	verifiable  bool
+	shareProofs *ShareProofs
+
+	// The condition restricting the release of the shares, if any. It is
+	// not marshalled, but covered by the digests of the Deal.
+	release ReleaseCondition
 }
 
 /* Constructs a new Deal to guarentee a secret.
@@ -238,14 +467,13 @@ type Deal struct {
  */
 func (p *Deal) ConstructDeal(secretPair *config.KeyPair,
 	longPair *config.KeyPair, t, r int, insurers []abstract.Point) *Deal {
-	p.id = secretPair.Public
+	p.id = abstract.ClonePoint(secretPair.Public)
 	p.t = t
 	p.r = r
 	p.n = len(insurers)
 	p.suite = secretPair.Suite
-	p.pubKey = longPair.Public
-	p.insurers = make([]abstract.Point, p.n, p.n)
-	copy(p.insurers, insurers)
+	p.pubKey = abstract.ClonePoint(longPair.Public)
+	p.insurers = abstract.ClonePoints(insurers)
 	p.secrets = make([]abstract.Scalar, p.n, p.n)
 
 	// Verify that t <= r <= n
@@ -267,16 +495,120 @@ func (p *Deal) ConstructDeal(secretPair *config.KeyPair,
 
 	// Populate the secrets array with the shares encrypted by a Diffie-
 	// Hellman shared secret between the Dealer and appropriate insurer
-	for i := 0; i < p.n; i++ {
-		diffieBase := p.suite.Point().Mul(insurers[i], longPair.Secret)
-		diffieSecret := p.diffieHellmanSecret(diffieBase)
-		p.secrets[i] = p.suite.Scalar().Add(prishares.Share(i),
-			diffieSecret)
+	// (or by the ShareWrapper set beforehand), and commit to the keys
+	// wrapping them for a verifiable Deal.
+	wrapper := p.shareWrapper(longPair)
+	var verifier VerifiableShareWrapper
+	p.shareProofs = nil
+	if p.verifiable {
+		var ok bool
+		if verifier, ok = wrapper.(VerifiableShareWrapper); !ok {
+			panic("ShareProofs need a VerifiableShareWrapper")
+		}
+		p.shareProofs = &ShareProofs{p.suite, make([]abstract.Point, p.n)}
+	}
+	errs := make([]error, p.n)
+	wrap := func(i int) {
+		p.secrets[i], errs[i] = wrapper.Wrap(insurers[i], prishares.Share(i))
+		if errs[i] == nil && verifier != nil {
+			p.shareProofs.keys[i], errs[i] = verifier.WrapKey(insurers[i])
+		}
+	}
+	if p.workers <= 1 {
+		for i := 0; i < p.n; i++ {
+			wrap(i)
+		}
+	} else {
+		// Each worker writes its results at the index of the share, so
+		// the ordering of the secrets array is preserved.
+		jobs := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < p.workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					wrap(i)
+				}
+			}()
+		}
+		for i := 0; i < p.n; i++ {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+	}
+	for _, err := range errs {
+		if err != nil {
+			panic("Unable to wrap share: " + err.Error())
+		}
 	}
 
 	return p
 }
 
+/* Constructs a Deal for the insurers of a Roster, see ConstructDeal. The
+ * share of each insurer is at its index in the Roster.
+ */
+func (p *Deal) ConstructRosterDeal(secretPair *config.KeyPair,
+	longPair *config.KeyPair, t, r int, roster *Roster) *Deal {
+	return p.ConstructDeal(secretPair, longPair, t, r, roster.keys)
+}
+
+/* Sets the number of goroutines ConstructDeal uses to encrypt the shares of
+ * the insurers. Encrypting a share costs a Point.Mul, so spreading the work
+ * noticeably speeds up the construction of Deals with many insurers. The
+ * resulting Deal is the same as with sequential construction. Dealers must
+ * call it before ConstructDeal. If a ShareWrapper is set, it must be safe for
+ * concurrent use.
+ *
+ * Arguments
+ *    k = the number of goroutines, or a negative value to use GOMAXPROCS.
+ *        Values 0 and 1 mean sequential construction, which is the default.
+ *
+ * Returns
+ *   The Deal itself
+ */
+func (p *Deal) SetConcurrency(k int) *Deal {
+	if k < 0 {
+		k = runtime.GOMAXPROCS(0)
+	}
+	p.workers = k
+	return p
+}
+
+/* Sets the ShareWrapper used to encrypt and decrypt the shares of the Deal.
+ * Dealers must call it before ConstructDeal, insurers and verifiers after
+ * unmarshalling the Deal and before using it. All parties must use
+ * compatible wrappers.
+ *
+ * Arguments
+ *    w = the ShareWrapper to use, or nil for the Diffie-Hellman wrapping
+ *
+ * Returns
+ *   The Deal itself
+ */
+func (p *Deal) SetShareWrapper(w ShareWrapper) *Deal {
+	p.wrapper = w
+	return p
+}
+
+/* An internal helper returning the ShareWrapper to use on behalf of the
+ * party with the given long-term keypair.
+ *
+ * Arguments
+ *    key = the long-term keypair of the party, may be nil for verifiers
+ *
+ * Returns
+ *   The ShareWrapper set on the Deal, or the Diffie-Hellman one
+ */
+func (p *Deal) shareWrapper(key *config.KeyPair) ShareWrapper {
+	if p.wrapper != nil {
+		return p.wrapper
+	}
+	return &diffieHellmanWrapper{p.suite, key}
+}
+
 /* Initializes a Deal for unmarshalling
  *
  * Arguments
@@ -314,27 +646,54 @@ func (p *Deal) UnmarshalInit(t, r, n int, suite abstract.Suite) *Deal {
 func (p *Deal) verifyDeal() error {
 	// Verify t <= r <= n
 	if p.t > p.n || p.t > p.r || p.r > p.n {
-		return errors.New("Invalid t-of-n shares Deal. Expected: t <= r <= n")
+		return ErrInvalidDeal
 	}
-	// There should be a scalar and public key for each of the n insurers.
-	if len(p.insurers) != p.n || len(p.secrets) != p.n {
-		return errors.New("Insurers and scalars array should be of length deal.n")
+	// There should be a scalar and public key for each of the n insurers,
+	// or only the digest of the scalars for a public view.
+	if len(p.insurers) != p.n {
+		return ErrInvalidDeal
 	}
-	return nil
+	if len(p.secrets) != p.n && !(p.isPublic() && p.n > 0) {
+		return ErrInvalidDeal
+	}
+	// All the points must lie in the prime-order subgroup.
+	if err := checkSubgroup(p.id, p.pubKey); err != nil {
+		return err
+	}
+	if err := checkSubgroup(p.insurers...); err != nil {
+		return err
+	}
+	if err := checkSubgroup(p.pubPoly.p...); err != nil {
+		return err
+	}
+	return p.verifyShareProofs()
+}
+
+// Returns whether the Deal is a public view without secrets, see MarshalPublic.
+func (p *Deal) isPublic() bool {
+	return p.secrets == nil && len(p.secretsDigest) == sha256.Size
 }
 
+// Returns the public polynomial of the Deal. It is shared with the Deal and
+// must not be modified.
 func (p *Deal) PubPoly() *PubPoly {
 	return &p.pubPoly
 }
 
-// Returns the id of the Deal
-func (p *Deal) Id() string {
-	return p.id.String()
+// Returns the suite of the Deal
+func (p *Deal) Suite() abstract.Suite {
+	return p.suite
 }
 
-// Returns the id of the Dealer (aka its long term public key)
-func (p *Deal) DealerId() string {
-	return p.pubKey.String()
+// Returns the id of the Deal, i.e. the PointKey of its short term public key,
+// which can be used as a map key
+func (p *Deal) Id() [abstract.PointKeySize]byte {
+	return abstract.PointKey(p.id)
+}
+
+// Returns the id of the Dealer, i.e. the PointKey of its long term public key
+func (p *Deal) DealerId() [abstract.PointKeySize]byte {
+	return abstract.PointKey(p.pubKey)
 }
 
 // Returns a copy of the Dealer's long term public key
@@ -343,11 +702,43 @@ func (p *Deal) DealerKey() abstract.Point {
 }
 
 // Returns the list of insurers of the deal.
-// A copy of insurers is return to prevent tampering.
+// A deep copy of insurers is returned to prevent tampering.
 func (p *Deal) Insurers() []abstract.Point {
-	result := make([]abstract.Point, p.n, p.n)
-	copy(result, p.insurers)
-	return result
+	return abstract.ClonePoints(p.insurers)
+}
+
+/* Returns the Roster of the insurers of the Deal.
+ *
+ * Returns
+ *   The Roster, or ErrDuplicateInsurer if an insurer holds several shares
+ */
+func (p *Deal) Roster() (*Roster, error) {
+	return NewRoster(p.suite, p.insurers)
+}
+
+// Returns the index of the first share of the insurer with long term public
+// key pub, or -1 if it is no insurer of the Deal.
+func (p *Deal) InsurerIndex(pub abstract.Point) int {
+	for i, ins := range p.insurers {
+		if ins.Equal(pub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns a deep copy of the Deal, sharing no Points or Scalars with it.
+func (p *Deal) clone() Deal {
+	c := *p
+	c.id = abstract.ClonePoint(p.id)
+	c.pubKey = abstract.ClonePoint(p.pubKey)
+	c.pubPoly.b = abstract.ClonePoint(p.pubPoly.b)
+	c.pubPoly.p = abstract.ClonePoints(p.pubPoly.p)
+	c.insurers = abstract.ClonePoints(p.insurers)
+	c.secrets = abstract.CloneScalars(p.secrets)
+	c.secretsDigest = append([]byte(nil), p.secretsDigest...)
+	c.shareProofs = p.shareProofs.clone()
+	return c
 }
 
 /* Given a Diffie-Hellman shared public key, produces a scalar to encrypt
@@ -360,12 +751,32 @@ func (p *Deal) Insurers() []abstract.Point {
  *   the DH secret
  */
 func (p *Deal) diffieHellmanSecret(diffieBase abstract.Point) abstract.Scalar {
-	buff, err := diffieBase.MarshalBinary()
-	if err != nil {
-		panic("Bad shared secret for Diffie-Hellman given.")
+	return diffieHellmanSecret(p.suite, diffieBase)
+}
+
+/* An internal helper checking that a long term public key is the one of the
+ * insurer of share i, as Roster.Check.
+ *
+ * Arguments
+ *    i   = the index of the share
+ *    pub = the long term public key of the alleged insurer
+ *
+ * Return
+ *   nil if pub is the insurer of share i. Otherwise, ErrInvalidIndex,
+ *   ErrNotInsurer if pub is no insurer of the Deal, or ErrWrongInsurerKey if
+ *   pub is the insurer of other shares.
+ */
+func (p *Deal) checkInsurer(i int, pub abstract.Point) error {
+	if i < 0 || i >= p.n {
+		return ErrInvalidIndex
+	}
+	if !p.insurers[i].Equal(pub) {
+		if p.InsurerIndex(pub) < 0 {
+			return ErrNotInsurer
+		}
+		return ErrWrongInsurerKey
 	}
-	cipher := p.suite.Cipher(buff)
-	return p.suite.Scalar().Pick(cipher)
+	return nil
 }
 
 /* An internal helper function used by ProduceResponse, verifies that a share
@@ -379,19 +790,18 @@ func (p *Deal) diffieHellmanSecret(diffieBase abstract.Point) abstract.Scalar {
  *  an error if the share is malformed, nil otherwise.
  */
 func (p *Deal) verifyShare(i int, gKeyPair *config.KeyPair) error {
-	if i < 0 || i >= p.n {
-		return errors.New("Invalid index. Expected 0 <= i < n")
+	if err := p.checkInsurer(i, gKeyPair.Public); err != nil {
+		return err
 	}
-	msg := "The long-term public key the Deal recorded as the insurer" +
-		"of this shares differs from what is expected"
-	if !p.insurers[i].Equal(gKeyPair.Public) {
-		return errors.New(msg)
+	if p.isPublic() {
+		return ErrPublicDeal
+	}
+	share, err := p.shareWrapper(gKeyPair).Unwrap(p.pubKey, p.secrets[i])
+	if err != nil {
+		return err
 	}
-	diffieBase := p.suite.Point().Mul(p.pubKey, gKeyPair.Secret)
-	diffieSecret := p.diffieHellmanSecret(diffieBase)
-	share := p.suite.Scalar().Sub(p.secrets[i], diffieSecret)
 	if !p.pubPoly.Check(i, share) {
-		return maliciousShare
+		return ErrShareCheckFailed
 	}
 	return nil
 }
@@ -401,38 +811,61 @@ func (p *Deal) verifyShare(i int, gKeyPair *config.KeyPair) error {
  * Arguments
  *    i         = the index of the insurer's share
  *    gKeyPair  = the long term public/private keypair of the insurer.
+ *    domain    = the domain of the signature, e.g., approveDomain
+ *    msg       = the message to sign
+ *
+ * Return
+ *   A signature object with the signature, or an error if signing failed.
+ */
+func (p *Deal) sign(i int, gKeyPair *config.KeyPair, domain string,
+	msg []byte) (*signature, error) {
+	return signMsg(gKeyPair, domain, msg)
+}
+
+/* An internal helper function signing a message with a long term keypair,
+ * with sign.SignWithDomain. Every kind of message of the package has its own
+ * domain, so that a signature of one kind is never valid for another one.
+ * The nonce of the signature is derived from the private key and the message,
+ * so that signing does not depend on random.Stream.
+ *
+ * Arguments
+ *    gKeyPair  = the long term public/private keypair of the signer.
+ *    domain    = the domain of the signature
  *    msg       = the message to sign
  *
  * Return
- *   A signature object with the signature.
+ *   A signature object with the signature, or an error if signing failed.
  */
-func (p *Deal) sign(i int, gKeyPair *config.KeyPair, msg []byte) *signature {
-	set := anon.Set{gKeyPair.Public}
-	sig := anon.Sign(gKeyPair.Suite, random.Stream, msg, set, nil, 0,
-		gKeyPair.Secret)
-	return new(signature).init(gKeyPair.Suite, sig)
+func signMsg(gKeyPair *config.KeyPair, domain string, msg []byte) (*signature, error) {
+	sig, err := sign.SignWithDomain(gKeyPair.Suite, gKeyPair.Secret, domain,
+		msg)
+	if err != nil {
+		return nil, err
+	}
+	return new(signature).init(gKeyPair.Suite, sig), nil
 }
 
 /* An internal helper function, verifies a signature is from a given insurer.
  *
  * Arguments
- *    i   = the index of the insurer in the insurers list
- *    sig = the signature object containing the signature
- *    msg = the message that was signed
+ *    i      = the index of the insurer in the insurers list
+ *    sig    = the signature object containing the signature
+ *    domain = the domain of the signature
+ *    msg    = the message that was signed
  *
  * Return
  *   an error if the signature is malformed, nil otherwise.
  */
-func (p *Deal) verifySignature(i int, sig *signature, msg []byte) error {
+func (p *Deal) verifySignature(i int, sig *signature, domain string,
+	msg []byte) error {
 	if i < 0 || i >= p.n {
-		return errors.New("Invalid index. Expected 0 <= i < n")
+		return ErrInvalidIndex
 	}
 	if sig.signature == nil {
-		return errors.New("Nil signature")
+		return ErrNilSignature
 	}
-	set := anon.Set{p.insurers[i]}
-	_, err := anon.Verify(sig.suite, msg, set, nil, sig.signature)
-	return err
+	return sign.VerifyWithDomain(p.suite, p.insurers[i], domain, msg,
+		sig.signature)
 }
 
 /* Create a blameProof that the Dealer maliciously constructed a shared secret.
@@ -452,17 +885,34 @@ func (p *Deal) verifySignature(i int, sig *signature, msg []byte) error {
  *       the Dealer gives an invalid index.
  */
 func (p *Deal) blame(i int, gKeyPair *config.KeyPair) (*blameProof, error) {
-	diffieKey := p.suite.Point().Mul(p.pubKey, gKeyPair.Secret)
-	insurerSig := p.sign(i, gKeyPair, sigBlameMsg)
-
-	choice := make(map[proof.Predicate]int)
-	pred := proof.Rep("D", "x", "P")
-	choice[pred] = 1
-	rand := p.suite.Cipher(abstract.RandomKey)
-	sval := map[string]abstract.Scalar{"x": gKeyPair.Secret}
-	pval := map[string]abstract.Point{"D": diffieKey, "P": p.pubKey}
-	prover := pred.Prover(p.suite, sval, pval, choice)
-	proof, err := proof.HashProve(p.suite, protocolName, rand, prover)
+	msg, err := p.indexedMsg(i)
+	if err != nil {
+		return nil, err
+	}
+	return p.blameWith(i, gKeyPair, blameDomain, msg)
+}
+
+/* An internal helper creating a blameProof as blame, whose signature covers
+ * the given message in the given domain, e.g., one covering all the Deals of
+ * a MultiDeal.
+ *
+ * Arguments
+ *    i         = the index of the malicious shared secret
+ *    gKeyPair  = the long term key pair of the insurer of share i
+ *    domain    = the domain of the signature of the insurer
+ *    msg       = the message signed by the insurer
+ *
+ * Return
+ *   A blameProof that the Dealer is malicious or nil if an error occurs
+ *   An error object denoting the status of the blameProof construction
+ */
+func (p *Deal) blameWith(i int, gKeyPair *config.KeyPair, domain string,
+	msg []byte) (*blameProof, error) {
+	diffieKey, proof, err := p.shareWrapper(gKeyPair).Disclose(p.pubKey)
+	if err != nil {
+		return nil, err
+	}
+	insurerSig, err := p.sign(i, gKeyPair, domain, msg)
 	if err != nil {
 		return nil, err
 	}
@@ -479,29 +929,52 @@ func (p *Deal) blame(i int, gKeyPair *config.KeyPair) (*blameProof, error) {
  *   an error if the blame is unjustified or nil if the blame is justified.
  */
 func (p *Deal) verifyBlame(i int, bproof *blameProof) error {
+	if i < 0 || i >= p.n {
+		return ErrInvalidIndex
+	}
+	msg, err := p.indexedMsg(i)
+	if err != nil {
+		return err
+	}
+	return p.verifyBlameWith(i, bproof, blameDomain, msg)
+}
+
+/* An internal helper verifying a blameProof as verifyBlame, whose signature
+ * covers the given message in the given domain (see blameWith).
+ *
+ * Arguments
+ *    i      = the index of the share subject to blame
+ *    proof  = blameProof that alleges the Dealer to have constructed a bad share.
+ *    domain = the domain of the signature of the insurer
+ *    msg    = the message signed by the insurer
+ *
+ * Return
+ *   an error if the blame is unjustified or nil if the blame is justified.
+ */
+func (p *Deal) verifyBlameWith(i int, bproof *blameProof, domain string,
+	msg []byte) error {
 	// Basic sanity checks
 	if i < 0 || i >= p.n {
-		return errors.New("Invalid index. Expected 0 <= i < n")
+		return ErrInvalidIndex
 	}
-	if err := p.verifySignature(i, &bproof.signature, sigBlameMsg); err != nil {
+	if err := p.verifySignature(i, &bproof.signature, domain, msg); err != nil {
 		return err
 	}
+	if p.isPublic() {
+		return ErrPublicDeal
+	}
 
 	// Verify the Diffie-Hellman shared secret was constructed properly
-	pval := map[string]abstract.Point{"D": bproof.diffieKey, "P": p.pubKey}
-	pred := proof.Rep("D", "x", "P")
-	verifier := pred.Verifier(p.suite, pval)
-	err := proof.HashVerify(p.suite, protocolName, verifier,
-		bproof.proof)
+	// and use it to decrypt the share.
+	share, err := p.shareWrapper(nil).Open(p.pubKey, bproof.diffieKey,
+		bproof.proof, p.secrets[i])
 	if err != nil {
 		return err
 	}
 
 	// Verify the share is bad.
-	diffieSecret := p.diffieHellmanSecret(bproof.diffieKey)
-	share := p.suite.Scalar().Sub(p.secrets[i], diffieSecret)
 	if p.pubPoly.Check(i, share) {
-		return errors.New("Unjustified blame. The share checks out okay.")
+		return ErrUnjustifiedBlame
 	}
 	return nil
 }
@@ -524,7 +997,7 @@ func (p *Deal) ProduceResponse(i int, gKeyPair *config.KeyPair) (*Response, erro
 		// the insurer key is not the one expected. Do not produce a
 		// blameProof in these cases, simply ignore the Deal till
 		// the Dealer sends the valid index for this insurer.
-		if err != maliciousShare {
+		if err != ErrShareCheckFailed {
 			return nil, err
 		}
 
@@ -535,8 +1008,175 @@ func (p *Deal) ProduceResponse(i int, gKeyPair *config.KeyPair) (*Response, erro
 		return new(Response).constructBlameProofResponse(blameProof), nil
 	}
 
-	sig := p.sign(i, gKeyPair, sigMsg)
-	return new(Response).constructSignatureResponse(sig), nil
+	msg, err := p.indexedMsg(i)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := p.sign(i, gKeyPair, approveDomain, msg)
+	if err != nil {
+		return nil, err
+	}
+	return new(Response).constructIndexedSignatureResponse(i, sig), nil
+}
+
+/* An internal helper returning the digest of the secrets of the Deal and of
+ * their ShareProofs, if any, which is all the public view of the Deal knows
+ * about them.
+ *
+ * Returns
+ *   The digest, or an error if marshalling the secrets failed
+ */
+func (p *Deal) secretsHash() ([]byte, error) {
+	if p.isPublic() {
+		return p.secretsDigest, nil
+	}
+	h := sha256.New()
+	if err := p.suite.Write(h, p.secrets); err != nil {
+		return nil, err
+	}
+	if p.shareProofs != nil {
+		if err := p.suite.Write(h, p.shareProofs.keys); err != nil {
+			return nil, err
+		}
+	}
+	return h.Sum(nil), nil
+}
+
+/* An internal helper returning a digest of the shares dealt by the Deal: the
+ * parameters t and n, the key of the Dealer, the public polynomial, the
+ * insurers, the shares themselves and the release condition, if any. It covers
+ * the shares through their digest only, so that it can be computed from the public view of the Deal.
+ * It does not cover r nor the id, so that it is the same for a Deal and all
+ * its recertified versions (see Recertify): Revocations and
+ * ReconstructionRequests cover it, as they concern the shares whatever the
+ * quorum certifying them.
+ *
+ * Returns
+ *   The digest, or an error if marshalling the Deal failed
+ */
+func (p *Deal) sharesDigest() ([]byte, error) {
+	secrets, err := p.secretsHash()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	h.Write(sharesDigestMsg)
+	binary.Write(h, binary.BigEndian, uint32(p.t))
+	binary.Write(h, binary.BigEndian, uint32(p.n))
+	if err := p.suite.Write(h, p.pubKey, p.pubPoly.p,
+		p.insurers); err != nil {
+		return nil, err
+	}
+	h.Write(secrets)
+	if p.release != nil {
+		release, err := p.release.Digest()
+		if err != nil {
+			return nil, err
+		}
+		h.Write(release)
+	}
+	return h.Sum(nil), nil
+}
+
+/* An internal helper returning a digest of the content of the Deal approved
+ * by insurers: its shares (see sharesDigest), r and the id. As it covers r
+ * and the id, it differs between a Deal and its recertified versions (see
+ * Recertify).
+ *
+ * Returns
+ *   The digest, or an error if marshalling the Deal failed
+ */
+func (p *Deal) contentDigest() ([]byte, error) {
+	shares, err := p.sharesDigest()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	h.Write(contentDigestMsg)
+	h.Write(shares)
+	binary.Write(h, binary.BigEndian, uint32(p.r))
+	if err := p.suite.Write(h, p.id); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+/* An internal helper returning the message signed by insurer i, either
+ * approving the Deal (in approveDomain) or blaming the Dealer for share i (in
+ * blameDomain). It binds the signature to the content of the Deal (see
+ * contentDigest), including its id and the key of the Dealer, and to the
+ * index of the insurer, so that a signature cannot be presented for another
+ * Deal nor at another index, even if the same key insures several shares.
+ *
+ * Arguments
+ *    i = the index of the insurer
+ *
+ * Returns
+ *   The message, or an error if marshalling the Deal failed
+ */
+func (p *Deal) indexedMsg(i int) ([]byte, error) {
+	msg, err := p.contentDigest()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	h.Write(msg)
+	binary.Write(h, binary.BigEndian, uint32(i))
+	return h.Sum(nil), nil
+}
+
+/* Returns an identifier of the Deal and its certification parameters, which
+ * differs between a Deal and its recertified versions.
+ *
+ * Returns
+ *   The identifier, or an error if marshalling the Deal failed
+ */
+func (p *Deal) CertificationId() ([]byte, error) {
+	msg, err := p.contentDigest()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	h.Write(msg)
+	binary.Write(h, binary.BigEndian, uint32(p.r))
+	return h.Sum(nil), nil
+}
+
+/* Returns a copy of the Deal with a higher certification quorum r, with the
+ * same shares, under a new id. The id is derived from the Deal and newR, so
+ * that the Dealer, the insurers and the clients all compute the same
+ * recertified Deal. Signatures of insurers cover r and the id (see
+ * indexedMsg), so that the signatures of the Deal do not approve the
+ * recertified one: insurers approve it with ProduceResponse, and the Dealer
+ * cannot lower the quorum approved by insurers. Recertifying needs no
+ * authorization of the Dealer, as it only raises the quorum, and the
+ * Revocation of the Deal also revokes its recertified versions (see
+ * Revocation).
+ *
+ * Arguments
+ *    newR = the new minimum number of signatures, r <= newR <= n
+ *
+ * Returns
+ *   The recertified Deal, or ErrInvalidDeal if newR is out of range
+ */
+func (p *Deal) Recertify(newR int) (*Deal, error) {
+	if newR < p.r || newR > p.n {
+		return nil, ErrInvalidDeal
+	}
+	digest, err := p.contentDigest()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	h.Write(recertifyMsg)
+	h.Write(digest)
+	binary.Write(h, binary.BigEndian, uint32(newR))
+	s := p.suite.Scalar().Pick(p.suite.Cipher(h.Sum(nil)))
+
+	c := p.clone()
+	c.r = newR
+	c.id = p.suite.Point().Add(p.id, p.suite.Point().Mul(nil, s))
+	return &c, nil
 }
 
 /* An internal function, reveals the secret share that the insurer has been
@@ -547,13 +1187,28 @@ func (p *Deal) ProduceResponse(i int, gKeyPair *config.KeyPair) (*Response, erro
  *    gkeyPair = the long-term keypair of the insurer
  *
  * Return
- *   the revealed private share
+ *   the revealed private share, or nil and an error: ErrInvalidIndex if i is
+ *   out of range, ErrPublicDeal for the public view of a Deal, the error of
+ *   the release condition if it does not hold (see SetReleaseCondition), or
+ *   ErrCorruptedShare if the share cannot be unwrapped
  */
-func (p *Deal) RevealShare(i int, gKeyPair *config.KeyPair) abstract.Scalar {
-	diffieBase := p.suite.Point().Mul(p.pubKey, gKeyPair.Secret)
-	diffieSecret := p.diffieHellmanSecret(diffieBase)
-	share := p.suite.Scalar().Sub(p.secrets[i], diffieSecret)
-	return share
+func (p *Deal) RevealShare(i int, gKeyPair *config.KeyPair) (abstract.Scalar, error) {
+	if i < 0 || i >= p.n {
+		return nil, ErrInvalidIndex
+	}
+	if p.isPublic() {
+		return nil, ErrPublicDeal
+	}
+	if p.release != nil {
+		if err := p.release.Check(p); err != nil {
+			return nil, err
+		}
+	}
+	share, err := p.shareWrapper(gKeyPair).Unwrap(p.pubKey, p.secrets[i])
+	if err != nil {
+		return nil, ErrCorruptedShare
+	}
+	return share, nil
 }
 
 /* Verify that a revealed share is properly formed. This should be called by
@@ -568,10 +1223,10 @@ func (p *Deal) RevealShare(i int, gKeyPair *config.KeyPair) abstract.Scalar {
  */
 func (p *Deal) VerifyRevealedShare(i int, share abstract.Scalar) error {
 	if i < 0 || i >= p.n {
-		return errors.New("Invalid index. Expected 0 <= i < n")
+		return ErrInvalidIndex
 	}
 	if !p.pubPoly.Check(i, share) {
-		return errors.New("The share failed the public polynomial check.")
+		return ErrShareCheckFailed
 	}
 	return nil
 }
@@ -597,9 +1252,22 @@ func (p *Deal) Equal(p2 *Deal) bool {
 		return false
 	}
 
+	if p.isPublic() != p2.isPublic() || p.isPublic() &&
+		!bytes.Equal(p.secretsDigest, p2.secretsDigest) {
+		return false
+	}
 	for i := 0; i < p.n; i++ {
-		if !p.secrets[i].Equal(p2.secrets[i]) ||
-			!p.insurers[i].Equal(p2.insurers[i]) {
+		if !p.insurers[i].Equal(p2.insurers[i]) ||
+			!p.isPublic() && !p.secrets[i].Equal(p2.secrets[i]) {
+			return false
+		}
+	}
+	keys, keys2 := p.shareKeys(), p2.shareKeys()
+	if len(keys) != len(keys2) {
+		return false
+	}
+	for i := range keys {
+		if !keys[i].Equal(keys2[i]) {
 			return false
 		}
 	}
@@ -607,17 +1275,145 @@ func (p *Deal) Equal(p2 *Deal) bool {
 		p.pubKey.Equal(p2.pubKey) && p.pubPoly.Equal(&p2.pubPoly)
 }
 
+/* The version of the binary format of Deals produced by MarshalBinary. It
+ * must be increased whenever that format changes, and an upgrade decoding the
+ * previous format should then be registered with RegisterDealUpgrade.
+ *
+ * Version 1 laid the fields out at offsets computed from the PointLen and
+ * ScalarLen of the suite, and is decoded by an upgrade registered below.
+ * Version 2 prefixes every field with its length, so that suites whose
+ * Points or Scalars have variable-length encodings are supported.
+ * Version 3 appends the commitments of the ShareProofs, if any, and decodes
+ * version 2 as a Deal without ShareProofs.
+ */
+const DealVersion byte = 3
+
+/* A DealUpgrade decodes a Deal marshalled with an older version of the binary
+ * format into the current Deal struct. The Deal has been initialized with
+ * UnmarshalInit, and buf holds the marshalled Deal without its version byte.
+ * The upgrade need not verify the Deal: UnmarshalBinary does it afterwards.
+ */
+type DealUpgrade func(p *Deal, buf []byte) error
+
+var dealUpgrades = struct {
+	sync.RWMutex
+	m map[byte]DealUpgrade
+}{m: make(map[byte]DealUpgrade)}
+
+func init() {
+	RegisterDealUpgrade(1, (*Deal).unmarshalV1)
+	RegisterDealUpgrade(2, (*Deal).unmarshalV2)
+}
+
+/* Registers the upgrade to use for decoding Deals marshalled with an older
+ * version of the binary format. It is meant to be called from init functions.
+ *
+ * Arguments
+ *    version = the older version, which must be less than DealVersion
+ *    upgrade = the function decoding that version
+ */
+func RegisterDealUpgrade(version byte, upgrade DealUpgrade) {
+	if version >= DealVersion {
+		panic("Only older versions of the Deal format can be upgraded")
+	}
+	dealUpgrades.Lock()
+	defer dealUpgrades.Unlock()
+	dealUpgrades.m[version] = upgrade
+}
+
+/* An internal helper writing a length-prefixed field of the binary format,
+ * i.e. ||length||data|| where length is a big-endian uint32.
+ *
+ * Arguments
+ *    w = the writer to use for marshalling
+ *    m = the field to marshal
+ *
+ * Returns
+ *   The error status of the write (nil if no errors)
+ */
+func writeField(w io.Writer, m encoding.BinaryMarshaler) error {
+	buf, err := m.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(len(buf))); err != nil {
+		return err
+	}
+	_, err = w.Write(buf)
+	return err
+}
+
+/* An internal helper reading a length-prefixed field written by writeField.
+ * The field is read as it arrives, so that a forged length does not cause a
+ * large allocation.
+ *
+ * Arguments
+ *    r = the reader to use for unmarshalling
+ *    m = the field to unmarshal
+ *
+ * Returns
+ *   The number of bytes read
+ *   The error status of the read (nil if no errors)
+ */
+func readField(r io.Reader, m encoding.BinaryUnmarshaler) (int, error) {
+	var length uint32
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return 0, err
+	}
+	if err := checkFieldSize(int(length)); err != nil {
+		return uint32Size, err
+	}
+	var b bytes.Buffer
+	n, err := io.CopyN(&b, r, int64(length))
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return uint32Size + int(n), err
+	}
+	return uint32Size + int(n), m.UnmarshalBinary(b.Bytes())
+}
+
 /* Returns the number of bytes used by this struct when marshalled
  *
  * Returns
  *   The marshal size
  *
  * Note
- *   This function can be used after UnmarshalInit
+ *   This function can be used after UnmarshalInit, in which case it assumes
+ *   that the Points and Scalars of the suite are PointLen and ScalarLen bytes
+ *   long. It gives the size of the current version of the binary format.
  */
 func (p *Deal) MarshalSize() int {
-	return 2*p.suite.PointLen() + p.pubPoly.MarshalSize() +
-		p.n*p.suite.PointLen() + p.n*p.suite.ScalarLen()
+	size := 1 + 2*uint32Size + 2*p.suite.PointLen() +
+		uint32Size + p.pubPoly.MarshalSize()
+	for i := 0; i < p.n; i++ {
+		size += 2 * uint32Size
+		if p.insurers != nil {
+			size += p.insurers[i].MarshalSize()
+		} else {
+			size += p.suite.PointLen()
+		}
+		if p.secrets != nil {
+			size += p.secrets[i].MarshalSize()
+		} else {
+			size += p.suite.ScalarLen()
+		}
+	}
+	size += uint32Size
+	for _, K := range p.shareKeys() {
+		size += uint32Size + K.MarshalSize()
+	}
+	return size
+}
+
+// Returns the commitments of the ShareProofs of the Deal, or nil if it has
+// none.
+func (p *Deal) shareKeys() []abstract.Point {
+	if p.shareProofs == nil {
+		return nil
+	}
+	return p.shareProofs.keys
 }
 
 /* Marshals a Deal struct into a byte array
@@ -629,68 +1425,221 @@ func (p *Deal) MarshalSize() int {
  * Note
  *   The buffer is formatted as follows:
  *
- *      ||id||pubKey||pubPoly||==insurers_array==||==secrets==||
+ *      ||version||id||pubKey||pubPoly||==insurers_array==||==secrets==||
+ *        count||==share_keys==||
  *
+ *   where version is the single byte DealVersion, and every other field,
+ *   including each element of the arrays, is prefixed by its length as a
+ *   big-endian uint32 (see writeField). count is the number of commitments
+ *   of the ShareProofs as a big-endian uint32, either 0 or n.
  *   Remember: n == len(insurers) == len(secrets)
  */
 func (p *Deal) MarshalBinary() ([]byte, error) {
-	buf := make([]byte, p.MarshalSize())
-
-	pointLen := p.suite.PointLen()
-	polyLen := p.pubPoly.MarshalSize()
-	secretLen := p.suite.ScalarLen()
-
-	// Encode id, pubKey, and pubPoly
-	idBuf, err := p.id.MarshalBinary()
-	if err != nil {
-		return nil, err
+	if p.isPublic() {
+		return nil, ErrPublicDeal
 	}
-	copy(buf, idBuf)
-
-	pointBuf, err := p.pubKey.MarshalBinary()
-	if err != nil {
+	var b bytes.Buffer
+	b.WriteByte(DealVersion)
+	if err := p.marshalFields(&b, true); err != nil {
 		return nil, err
 	}
-	copy(buf[pointLen:], pointBuf)
+	return b.Bytes(), nil
+}
 
-	polyBuf, err := p.pubPoly.MarshalBinary()
-	if err != nil {
-		return nil, err
+/* An internal helper writing the length-prefixed fields of the Deal, shared
+ * with MarshalPublic.
+ *
+ * Arguments
+ *    w       = the writer to use for marshalling
+ *    secrets = whether to write the secrets, or only the fields before them
+ *
+ * Returns
+ *   The error status of the write (nil if no errors)
+ */
+func (p *Deal) marshalFields(w io.Writer, secrets bool) error {
+	if err := writeField(w, p.id); err != nil {
+		return err
+	}
+	if err := writeField(w, p.pubKey); err != nil {
+		return err
+	}
+	if err := writeField(w, &p.pubPoly); err != nil {
+		return err
 	}
-	copy(buf[2*pointLen:], polyBuf)
-
-	// Encode the insurers and secrets array (Based on poly/sharing.go code)
-	bufPos := 2*pointLen + polyLen
 	for i := range p.insurers {
-		pb, err := p.insurers[i].MarshalBinary()
-		if err != nil {
-			return nil, err
+		if err := writeField(w, p.insurers[i]); err != nil {
+			return err
 		}
-		copy(buf[bufPos+i*pointLen:], pb)
 	}
-	bufPos += p.n * pointLen
-
+	if !secrets {
+		return nil
+	}
 	for i := range p.secrets {
-		pb, err := p.secrets[i].MarshalBinary()
-		if err != nil {
-			return nil, err
+		if err := writeField(w, p.secrets[i]); err != nil {
+			return err
 		}
-		copy(buf[bufPos+i*secretLen:], pb)
 	}
-	return buf, nil
+	keys := p.shareKeys()
+	if err := binary.Write(w, binary.BigEndian, uint32(len(keys))); err != nil {
+		return err
+	}
+	for i := range keys {
+		if err := writeField(w, keys[i]); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-/* Unmarshals a Deal from a byte buffer
+/* Unmarshals a Deal from a byte buffer. Deals marshalled with an older
+ * version of the binary format are decoded with the DealUpgrade registered
+ * for that version.
  *
  * Arguments
  *    buf = the buffer containing the Deal
  *
  * Returns
- *   The error status of the unmarshalling (nil if no error)
+ *   The error status of the unmarshalling (nil if no error), which is
+ *   ErrUnsupportedVersion if the version of the format is unknown
  */
 func (p *Deal) UnmarshalBinary(buf []byte) error {
+	if len(buf) == 0 {
+		return errors.New("Buffer size too small")
+	}
+	if buf[0] != DealVersion {
+		dealUpgrades.RLock()
+		upgrade := dealUpgrades.m[buf[0]]
+		dealUpgrades.RUnlock()
+		if upgrade == nil {
+			return ErrUnsupportedVersion
+		}
+		if err := upgrade(p, buf[1:]); err != nil {
+			return err
+		}
+		return p.verifyDeal()
+	}
+	return p.unmarshalCurrent(buf[1:])
+}
+
+// Decodes the current version of the binary format, without the version byte.
+func (p *Deal) unmarshalCurrent(buf []byte) error {
+	r := bytes.NewReader(buf)
+	if _, err := p.unmarshalFields(r, true); err != nil {
+		return err
+	}
+	if _, err := p.unmarshalShareKeys(r); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return errors.New("Buffer size too large")
+	}
+	// Make sure the Deal is valid.
+	return p.verifyDeal()
+}
+
+/* Decodes version 2 of the binary format, without the version byte, which
+ * lacks the ShareProofs:
+ *
+ *      ||id||pubKey||pubPoly||==insurers_array==||==secrets==||
+ */
+func (p *Deal) unmarshalV2(buf []byte) error {
+	r := bytes.NewReader(buf)
+	if _, err := p.unmarshalFields(r, true); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return errors.New("Buffer size too large")
+	}
+	return nil
+}
+
+/* An internal helper reading the commitments of the ShareProofs written
+ * after the secrets by marshalFields.
+ *
+ * Arguments
+ *    r = the reader to use for unmarshalling
+ *
+ * Returns
+ *   The number of bytes read
+ *   The error status of the read (nil if no errors)
+ */
+func (p *Deal) unmarshalShareKeys(r io.Reader) (int, error) {
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return 0, err
+	}
+	total := uint32Size
+	if count == 0 {
+		return total, nil
+	}
+	if int(count) != p.n {
+		return total, ErrInvalidShareProof
+	}
+	sp := &ShareProofs{p.suite, make([]abstract.Point, p.n)}
+	for i := range sp.keys {
+		sp.keys[i] = p.suite.Point()
+		n, err := readField(r, sp.keys[i])
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	p.shareProofs = sp
+	return total, nil
+}
+
+/* An internal helper reading the length-prefixed fields written by
+ * marshalFields.
+ *
+ * Arguments
+ *    r       = the reader to use for unmarshalling
+ *    secrets = whether to read the secrets, or only the fields before them
+ *
+ * Returns
+ *   The number of bytes read
+ *   The error status of the read (nil if no errors)
+ */
+func (p *Deal) unmarshalFields(r io.Reader, secrets bool) (int, error) {
+	p.shareProofs = nil
+	p.id = p.suite.Point()
+	p.pubKey = p.suite.Point()
+	p.insurers = make([]abstract.Point, p.n)
+	fields := []encoding.BinaryUnmarshaler{p.id, p.pubKey, &p.pubPoly}
+	for i := range p.insurers {
+		p.insurers[i] = p.suite.Point()
+		fields = append(fields, p.insurers[i])
+	}
+	if secrets {
+		p.secrets = make([]abstract.Scalar, p.n)
+		for i := range p.secrets {
+			p.secrets[i] = p.suite.Scalar()
+			fields = append(fields, p.secrets[i])
+		}
+	}
+	total := 0
+	for _, f := range fields {
+		n, err := readField(r, f)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+/* Decodes version 1 of the binary format, without the version byte, whose
+ * fields are laid out at fixed offsets:
+ *
+ *      ||id||pubKey||pubPoly||==insurers_array==||==secrets==||
+ */
+func (p *Deal) unmarshalV1(buf []byte) error {
 	pointLen := p.suite.PointLen()
 	secretLen := p.suite.ScalarLen()
+	polyLen := p.pubPoly.MarshalSize()
+	if len(buf) != 2*pointLen+polyLen+p.n*pointLen+p.n*secretLen {
+		return errors.New("Buffer size does not match the Deal parameters")
+	}
+	p.shareProofs = nil
 
 	bufPos := 0
 
@@ -707,7 +1656,6 @@ func (p *Deal) UnmarshalBinary(buf []byte) error {
 	}
 	bufPos += pointLen
 
-	polyLen := p.pubPoly.MarshalSize()
 	if err := p.pubPoly.UnmarshalBinary(buf[bufPos : bufPos+polyLen]); err != nil {
 		return err
 	}
@@ -723,53 +1671,110 @@ func (p *Deal) UnmarshalBinary(buf []byte) error {
 			return err
 		}
 	}
-	bufPos += p.n * pointLen
-	p.secrets = make([]abstract.Scalar, p.n, p.n)
-	for i := 0; i < p.n; i++ {
-		start := bufPos + i*secretLen
-		end := start + secretLen
-		p.secrets[i] = p.suite.Scalar()
-		if err := p.secrets[i].UnmarshalBinary(buf[start:end]); err != nil {
-			return err
-		}
+	bufPos += p.n * pointLen
+	p.secrets = make([]abstract.Scalar, p.n, p.n)
+	for i := 0; i < p.n; i++ {
+		start := bufPos + i*secretLen
+		end := start + secretLen
+		p.secrets[i] = p.suite.Scalar()
+		if err := p.secrets[i].UnmarshalBinary(buf[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/* Marshals a Deal struct using an io.Writer
+ *
+ * Arguments
+ *    w = the writer to use for marshalling
+ *
+ * Returns
+ *   The number of bytes written
+ *   The error status of the write (nil if no errors)
+ */
+func (p *Deal) MarshalTo(w io.Writer) (int, error) {
+	buf, err := p.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	return w.Write(buf)
+}
+
+/* Unmarshals a Deal struct using an io.Reader. The fields are read one by
+ * one, so only the current version of the binary format can be read this
+ * way: Deals of older versions must be decoded with UnmarshalBinary.
+ *
+ * Arguments
+ *    r = the reader to use for unmarshalling
+ *
+ * Returns
+ *   The number of bytes read
+ *   The error status of the read (nil if no errors)
+ */
+func (p *Deal) UnmarshalFrom(r io.Reader) (int, error) {
+	version := make([]byte, 1)
+	if n, err := io.ReadFull(r, version); err != nil {
+		return n, err
+	}
+	if version[0] != DealVersion {
+		return 1, ErrUnsupportedVersion
 	}
-	// Make sure the Deal is valid.
-	return p.verifyDeal()
+	n, err := p.unmarshalFields(r, true)
+	if err != nil {
+		return 1 + n, err
+	}
+	m, err := p.unmarshalShareKeys(r)
+	if err != nil {
+		return 1 + n + m, err
+	}
+	return 1 + n + m, p.verifyDeal()
 }
 
-/* Marshals a Deal struct using an io.Writer
+/* Marshals a Deal together with its t, r and n parameters, so that it can be
+ * decoded by UnmarshalSuite without a prior call to UnmarshalInit.
+ * Implements abstract.SuiteMarshaler.
  *
  * Arguments
  *    w = the writer to use for marshalling
+ *    s = the suite of the Deal
  *
  * Returns
- *   The number of bytes written
  *   The error status of the write (nil if no errors)
  */
-func (p *Deal) MarshalTo(w io.Writer) (int, error) {
-	buf, err := p.MarshalBinary()
-	if err != nil {
-		return 0, err
+func (p *Deal) MarshalSuite(w io.Writer, s abstract.Suite) error {
+	if s.String() != p.suite.String() {
+		return errors.New("Deal marshalled with a different suite")
 	}
-	return w.Write(buf)
+	if err := abstract.WriteInts(w, p.t, p.r, p.n); err != nil {
+		return err
+	}
+	_, err := p.MarshalTo(w)
+	return err
 }
 
-/* Unmarshals a Deal struct using an io.Reader
+/* Unmarshals a Deal written by MarshalSuite.
+ * Implements abstract.SuiteMarshaler.
  *
  * Arguments
  *    r = the reader to use for unmarshalling
+ *    s = the suite of the Deal
  *
  * Returns
- *   The number of bytes read
  *   The error status of the read (nil if no errors)
  */
-func (p *Deal) UnmarshalFrom(r io.Reader) (int, error) {
-	buf := make([]byte, p.MarshalSize())
-	n, err := io.ReadFull(r, buf)
+func (p *Deal) UnmarshalSuite(r io.Reader, s abstract.Suite) error {
+	params, err := abstract.ReadInts(r, 3)
 	if err != nil {
-		return n, err
+		return err
+	}
+	t, rr, n := params[0], params[1], params[2]
+	if err := checkParams(t, rr, n); err != nil {
+		return err
 	}
-	return n, p.UnmarshalBinary(buf)
+	p.UnmarshalInit(t, rr, n, s)
+	_, err = p.UnmarshalFrom(r)
+	return err
 }
 
 /* Returns a string representation of the Deal for easy debugging
@@ -789,7 +1794,9 @@ func (p *Deal) String() string {
 	secrets := ""
 	for i := 0; i < p.n; i++ {
 		insurers += p.insurers[i].String() + ",\n"
-		secrets += p.secrets[i].String() + ",\n"
+		if !p.isPublic() {
+			secrets += p.secrets[i].String() + ",\n"
+		}
 	}
 	s += "Insurers =>\n[" + insurers + "],\n"
 	s += "Secrets =>\n[" + secrets + "]\n"
@@ -814,10 +1821,12 @@ func (p *Deal) String() string {
  *
  *    To reconstruct the secret, do:
  *
- *       p.PriShares.Secret()
+ *       secret, err := p.PriShares.SecretOrError()
  *
- *    Be warned that Secret will panic unless there are enough shares to
- *    reconstruct the secret. (See poly/sharing.go for more info)
+ *    which fails with ErrNotEnoughShares unless there are enough shares to
+ *    reconstruct the secret. p.PriShares.CanRecover() tells beforehand
+ *    whether there are. Be warned that p.PriShares.Secret() panics instead.
+ *    (See poly/sharing.go for more info)
  *
  * TODO Consider if it is worth adding a String function
  */
@@ -834,9 +1843,57 @@ type State struct {
 	// A list of responses (either approving signatures or blameProofs)
 	// that have been received so far.
 	responses []*Response
+
+	// The result of verifying the deal, computed once by Init, and the
+	// number of signatures and blameProofs in responses. Responses are
+	// verified once by AddResponse, so that checking whether the deal is
+	// certified needs not verify anything again.
+	dealErr    error
+	signatures int
+	blames     int
+
+	// The observer notified of the certification progress, if any, and
+	// whether it was notified of the certification of the Deal.
+	observer  StateObserver
+	certified bool
+
+	// The Revocation of the Deal by its Dealer, if any
+	revocation *Revocation
+
+	// The roster of the clients allowed to request the reconstruction of
+	// the Deal and the number of them needed, if set with SetClientQuorum
+	clients      []abstract.Point
+	clientQuorum int
+
+	// The nonces of the ReconstructionRequests served so far, shared with
+	// the recertified versions of the State, see AuthorizedRevealShare
+	served map[string]bool
+
+	// The States of the recertified versions of the Deal to which responses
+	// were added so far, by quorum r, see AddRecertifiedResponse
+	recertified map[int]*State
+}
+
+/* A StateObserver is notified by a State of the progress of the certification
+ * of its Deal, e.g., to expose it to a monitoring system. The methods are
+ * called synchronously by AddResponse, after the response is added, and should
+ * return quickly.
+ */
+type StateObserver interface {
+
+	// Called when a valid signature from insurer i is added, with the
+	// number of signatures added so far.
+	OnSignatureAdded(i int, signatures int)
+
+	// Called when a valid blameProof from insurer i is added.
+	OnBlameVerified(i int)
+
+	// Called once, when the Deal becomes certified (see DealCertified).
+	OnCertified()
 }
 
-/* Initializes a new State
+/* Initializes a new State. The deal is verified once here, so it should not
+ * be modified afterwards.
  *
  * Arguments
  *    deal = the deal to keep track of
@@ -845,13 +1902,24 @@ type State struct {
  *   An initialized State
  */
 func (ps *State) Init(deal Deal) *State {
-	ps.Deal = deal
+	// Keep a deep copy, as the caller may still use the Deal concurrently.
+	ps.Deal = deal.clone()
 
 	// Initialize a new PriShares based on information from the deal.
 	ps.PriShares = PriShares{}
 	ps.PriShares.Empty(deal.suite, deal.t, deal.n)
 	// There will be at most n responses, one per insurer
 	ps.responses = make([]*Response, deal.n, deal.n)
+	ps.dealErr = ps.Deal.verifyDeal()
+	ps.signatures = 0
+	ps.blames = 0
+	ps.observer = nil
+	ps.certified = false
+	ps.revocation = nil
+	ps.clients = nil
+	ps.clientQuorum = 0
+	ps.served = make(map[string]bool)
+	ps.recertified = nil
 	return ps
 }
 
@@ -867,27 +1935,240 @@ func (ps *State) Init(deal Deal) *State {
  */
 func (ps *State) AddResponse(i int, response *Response) error {
 	if ps.responses[i] != nil {
-		return errors.New("Response already added.")
+		return ErrResponseAdded
 	}
 
 	var err error
 	switch response.rtype {
 	case signatureResponse:
-		err = ps.Deal.verifySignature(i, response.signature, sigMsg)
+		if err = ps.verifySignature(i, response); err == nil {
+			ps.signatures++
+		}
 
 	case blameProofResponse:
-		err = ps.Deal.verifyBlame(i, response.blameProof)
+		if err = ps.Deal.verifyBlame(i, response.blameProof); err == nil {
+			ps.blames++
+		}
 
 	default:
-		err = errors.New("Invalid response.")
+		err = ErrInvalidResponse
+	}
+	if err != nil {
+		return err
+	}
+	ps.responses[i] = response
+	if ps.observer != nil {
+		ps.notify(i, response)
+	}
+	return nil
+}
+
+/* An internal helper verifying the signature of a Response added by insurer
+ * i. Only signatures of the
+ * message of insurer i (see Deal.indexedMsg) are accepted: signatures of
+ * earlier versions, on a constant message or on a message that is not bound
+ * to the index of the insurer, could be transplanted to other Deals or
+ * indices and are rejected.
+ *
+ * Arguments
+ *    i        = the index of the insurer
+ *    response = the signature Response
+ *
+ * Returns
+ *   nil if the signature is valid, an error otherwise.
+ */
+func (ps *State) verifySignature(i int, response *Response) error {
+	if !response.indexed || response.index != i {
+		return ErrInvalidIndex
 	}
+	msg, err := ps.Deal.indexedMsg(i)
 	if err != nil {
 		return err
 	}
+	return ps.Deal.verifySignature(i, response.signature, approveDomain, msg)
+}
+
+/* An internal helper adding a signature Response of insurer i that the caller
+ * already verified, such as a signature approving a whole MultiDeal.
+ *
+ * Arguments
+ *    i        = the index of the insurer
+ *    response = the signature Response
+ */
+func (ps *State) addSignature(i int, response *Response) {
+	ps.signatures++
+	ps.responses[i] = response
+	if ps.observer != nil {
+		ps.notify(i, response)
+	}
+}
+
+/* An internal helper adding a blameProof Response of insurer i that the
+ * caller already verified, such as a blameProof against a whole MultiDeal.
+ *
+ * Arguments
+ *    i        = the index of the insurer
+ *    response = the blameProof Response
+ */
+func (ps *State) addBlame(i int, response *Response) {
+	ps.blames++
 	ps.responses[i] = response
+	if ps.observer != nil {
+		ps.notify(i, response)
+	}
+}
+
+/* Marks the Deal as revoked by its Dealer. Afterwards, the Deal is no longer
+ * certified and RevealShare refuses to reveal shares, whatever the responses
+ * added. A revoked Deal cannot be reinstated.
+ *
+ * Arguments
+ *    rev = the Revocation of the Deal
+ *
+ * Returns
+ *   nil if the Revocation is valid, an error otherwise.
+ */
+func (ps *State) AddRevocation(rev *Revocation) error {
+	if err := ps.Deal.VerifyRevocation(rev); err != nil {
+		return err
+	}
+	ps.revocation = rev
 	return nil
 }
 
+// Returns whether the Deal was revoked by its Dealer, see AddRevocation.
+func (ps *State) Revoked() bool {
+	return ps.revocation != nil
+}
+
+/* Sets the observer notified of the certification progress of the Deal. It
+ * must be called after Init.
+ *
+ * Arguments
+ *    o = the observer, or nil to remove it
+ *
+ * Returns
+ *   The State itself
+ */
+func (ps *State) SetObserver(o StateObserver) *State {
+	ps.observer = o
+	return ps
+}
+
+// Notifies the observer of the response added for insurer i.
+func (ps *State) notify(i int, response *Response) {
+	switch response.rtype {
+	case signatureResponse:
+		ps.observer.OnSignatureAdded(i, ps.signatures)
+	case blameProofResponse:
+		ps.observer.OnBlameVerified(i)
+	}
+	if !ps.certified && ps.Certified() {
+		ps.certified = true
+		ps.observer.OnCertified()
+	}
+}
+
+/* An internal helper returning the State tracking the responses to the Deal
+ * recertified with quorum newR, created on first use.
+ *
+ * Arguments
+ *    newR = the quorum of the recertified Deal, r <= newR <= n
+ *
+ * Returns
+ *   The State, or an error if newR is out of range
+ */
+func (ps *State) recertifiedState(newR int) (*State, error) {
+	if ns, ok := ps.recertified[newR]; ok {
+		return ns, nil
+	}
+	deal, err := ps.Deal.Recertify(newR)
+	if err != nil {
+		return nil, err
+	}
+	if ps.recertified == nil {
+		ps.recertified = make(map[int]*State)
+	}
+	ns := new(State).Init(*deal)
+	ps.recertified[newR] = ns
+	return ns, nil
+}
+
+/* Adds a response of insurer i to the Deal recertified with quorum newR (see
+ * Deal.Recertify), as produced by the ProduceResponse of the recertified Deal,
+ * e.g., when insurers approve the new quorum before the State is recertified.
+ * The response must cover the recertified Deal: responses to the Deal itself
+ * are added with AddResponse. State.Recertify migrates the responses added
+ * here.
+ *
+ * Arguments
+ *    newR     = the quorum of the recertified Deal, r <= newR <= n
+ *    i        = the index of the insurer
+ *    response = the response to the recertified Deal
+ *
+ * Returns
+ *   nil if the response was added succesfully, an error otherwise, e.g.,
+ *   ErrRevoked if the Deal was revoked
+ */
+func (ps *State) AddRecertifiedResponse(newR, i int, response *Response) error {
+	if ps.revocation != nil {
+		return ErrRevoked
+	}
+	ns, err := ps.recertifiedState(newR)
+	if err != nil {
+		return err
+	}
+	return ns.AddResponse(i, response)
+}
+
+/* Creates the State of the Deal recertified with a higher quorum r (see
+ * Deal.Recertify). The responses that explicitly cover the recertified Deal,
+ * added with AddRecertifiedResponse, are migrated, and so is the client
+ * quorum. The responses to the Deal itself are not: their signatures cover
+ * the r and the id of the Deal, so that neither approvals nor blameProofs are
+ * valid for the recertified Deal, which insurers must approve or blame again.
+ *
+ * A revoked Deal cannot be recertified, and the Revocation of the Deal also
+ * revokes its recertified versions (see Revocation).
+ *
+ * Arguments
+ *    newR = the new minimum number of signatures, r <= newR <= n
+ *
+ * Returns
+ *   The new State, or an error if newR is out of range or ErrRevoked if the
+ *   Deal was revoked
+ */
+func (ps *State) Recertify(newR int) (*State, error) {
+	if ps.revocation != nil {
+		return nil, ErrRevoked
+	}
+	ns, err := ps.recertifiedState(newR)
+	if err != nil {
+		return nil, err
+	}
+	delete(ps.recertified, newR)
+	ns.clients = ps.clients
+	ns.clientQuorum = ps.clientQuorum
+	ns.served = ps.served
+	return ns, nil
+}
+
+// Returns the number of valid signatures added so far.
+func (ps *State) Signatures() int {
+	return ps.signatures
+}
+
+// Returns the indices of the insurers from which no response was added yet.
+func (ps *State) MissingInsurers() []int {
+	var missing []int
+	for i, r := range ps.responses {
+		if r == nil {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
 /* A public wrapper for Deal.RevealShare, ensures that a share is only
  * revealed for a Deal that has received a sufficient number of signatures.
  * An insurer should call this function on behalf of a client after verifying
@@ -900,7 +2181,10 @@ func (ps *State) AddResponse(i int, response *Response) error {
  * Return
  *   (share, error)
  *      share = the revealed private share, or nil if the deal share is corrupted
- *      error = nil if successful, error if the deal share is corrupted
+ *      error = nil if successful, error if the deal share is corrupted, or
+ *              ErrRevoked if the Dealer revoked the deal (see AddRevocation),
+ *              or ErrUnauthorized if a client quorum is set, in which case
+ *              AuthorizedRevealShare must be used (see SetClientQuorum)
  *
  *   This error checking insures that a good insurer who has produced a valid blameproof does
  *   not reveal an incorrect share.
@@ -935,12 +2219,29 @@ func (ps *State) AddResponse(i int, response *Response) error {
  *   considered certified otherwise. This is further incentive to create valid deals.
  */
 func (ps *State) RevealShare(i int, gKeyPair *config.KeyPair) (abstract.Scalar, error) {
+	if ps.clients != nil {
+		return nil, ErrUnauthorized
+	}
+	return ps.revealShare(i, gKeyPair)
+}
+
+// Reveals the share of insurer i, see RevealShare and AuthorizedRevealShare.
+func (ps *State) revealShare(i int, gKeyPair *config.KeyPair) (abstract.Scalar, error) {
+	if ps.revocation != nil {
+		return nil, ErrRevoked
+	}
 	if ps.SufficientSignatures() != nil {
 		panic("RevealShare should only be called with deals with enough signatures.")
 	}
-	share := ps.Deal.RevealShare(i, gKeyPair)
+	if err := ps.Deal.checkInsurer(i, gKeyPair.Public); err != nil {
+		return nil, err
+	}
+	share, err := ps.Deal.RevealShare(i, gKeyPair)
+	if err != nil {
+		return nil, err
+	}
 	if !ps.Deal.pubPoly.Check(i, share) {
-		return nil, errors.New("This share is corrupted.")
+		return nil, ErrCorruptedShare
 	}
 	return share, nil
 }
@@ -962,37 +2263,27 @@ func (ps *State) RevealShare(i int, gKeyPair *config.KeyPair) (abstract.Scalar,
  *   the error was caused by a valid blameProof. A single valid blameProof will
  *   permanently make a Deal uncertified.
  *
- * Technical Notes: The function goes through the list of responses and checks
- *                  for signatures that are properly signed. If at least r of
- *                  these are signed and r is greater than t (the minimum number
- *                  of shares needed to reconstruct the secret), the deal is
- *                  considered certified. If any valid blameProofs are found, an
- *                  error is immediately produced if blameProofFail is true.
- *                  Otherwise, it ignores blameProofs.
- *
- *                  AddResponse handles deal validation. Hence, it is assumed
- *                  any deals included within the response array are valid.
+ * Technical Notes: The function relies on the counts of signatures and
+ *                  blameProofs kept by AddResponse, which verifies every
+ *                  response once when it is added, and on the verification
+ *                  of the deal done by Init. If at least r signatures have
+ *                  been added, the deal is considered certified. If any valid
+ *                  blameProof has been added, an error is produced if
+ *                  blameProofFail is true. Otherwise, blameProofs are ignored.
  */
 func (ps *State) dealCertified(blameProofFail bool) error {
-	if err := ps.Deal.verifyDeal(); err != nil {
-		return err
+	if ps.dealErr != nil {
+		return ps.dealErr
 	}
-	validSigs := 0
-	for i := 0; i < ps.Deal.n; i++ {
-		if ps.responses[i] == nil {
-			continue
-		}
-
-		if ps.responses[i].rtype == signatureResponse {
-			validSigs += 1
-		}
-
-		if blameProofFail && ps.responses[i].rtype == blameProofResponse {
-			return errors.New("A valid blameProof proves this Deal to be uncertified.")
-		}
+	if ps.revocation != nil {
+		return ErrRevoked
 	}
-	if validSigs < ps.Deal.r {
-		return errors.New(fmt.Sprintf("Not enough signatures yet to be certified %d vs %d", validSigs, ps.Deal.r))
+	if blameProofFail && ps.blames > 0 {
+		return ErrBlamed
+	}
+	if ps.signatures < ps.Deal.r {
+		return &DealError{CodeNotCertified, fmt.Sprintf("%s %d vs %d",
+			ErrNotCertified.msg, ps.signatures, ps.Deal.r)}
 	}
 	return nil
 }
@@ -1003,6 +2294,7 @@ func (ps *State) dealCertified(blameProofFail bool) error {
  *   1) The deal must be syntatically valid.
  *   2) It must have >= r valid signatures
  *   3) It must not have any valid blameProofs
+ *   4) It must not be revoked by its Dealer (see AddRevocation)
  *
  *
  * Use this function when determining whether a deal is safe to be accepted.
@@ -1024,6 +2316,19 @@ func (ps *State) SufficientSignatures() error {
 	return ps.dealCertified(false)
 }
 
+/* Returns whether the Deal is certified, see DealCertified.
+ */
+func (ps *State) Certified() bool {
+	return ps.dealCertified(true) == nil
+}
+
+/* Returns whether the State has received enough signatures for the Deal to
+ * be considered certified, ignoring blameProofs, see SufficientSignatures.
+ */
+func (ps *State) EnoughSignatures() bool {
+	return ps.dealCertified(false) == nil
+}
+
 /* The signature struct is used by insurers to express their approval
  * or disapproval of a given deal. After receiving a deal and verifying
  * that their shares are good, insurers can produce a signature to send back
@@ -1177,6 +2482,9 @@ func (p *signature) UnmarshalFrom(r io.Reader) (int, error) {
 	}
 
 	sigLen := int(binary.LittleEndian.Uint32(buf))
+	if err := checkFieldSize(sigLen); err != nil {
+		return n, err
+	}
 
 	// Calculate the length of the entire message and create the new buffer.
 	finalBuf := make([]byte, uint32Size+sigLen)
@@ -1192,6 +2500,34 @@ func (p *signature) UnmarshalFrom(r io.Reader) (int, error) {
 	return n + m, p.UnmarshalBinary(finalBuf)
 }
 
+/* Marshals a signature, implements abstract.SuiteMarshaler.
+ *
+ * Arguments
+ *    w = the writer to use for marshalling
+ *    s = the suite of the signature
+ *
+ * Returns
+ *   The error status of the write (nil if no errors)
+ */
+func (p *signature) MarshalSuite(w io.Writer, s abstract.Suite) error {
+	_, err := p.MarshalTo(w)
+	return err
+}
+
+/* Unmarshals a signature, implements abstract.SuiteMarshaler.
+ *
+ * Arguments
+ *    r = the reader to use for unmarshalling
+ *    s = the suite of the signature
+ *
+ * Returns
+ *   The error status of the read (nil if no errors)
+ */
+func (p *signature) UnmarshalSuite(r io.Reader, s abstract.Suite) error {
+	_, err := p.UnmarshalInit(s).UnmarshalFrom(r)
+	return err
+}
+
 /* Returns a string representation of the signature for easy debugging
  *
  * Returns
@@ -1372,6 +2708,9 @@ func (bp *blameProof) UnmarshalBinary(buf []byte) error {
 	if err := bp.diffieKey.UnmarshalBinary(buf[bufPos : bufPos+pointLen]); err != nil {
 		return err
 	}
+	if err := checkSubgroup(bp.diffieKey); err != nil {
+		return err
+	}
 	bufPos += pointLen
 
 	if len(buf) < 2*uint32Size+pointLen+proofLen+sigLen {
@@ -1425,6 +2764,9 @@ func (bp *blameProof) UnmarshalFrom(r io.Reader) (int, error) {
 	pointLen := bp.suite.PointLen()
 	proofLen := int(binary.LittleEndian.Uint32(buf))
 	sigLen := int(binary.LittleEndian.Uint32(buf[uint32Size:]))
+	if err := checkFieldSize(proofLen + sigLen); err != nil {
+		return n, err
+	}
 
 	// Calculate the final buffer, copy the old data to it, and fill it
 	// for unmarshalling
@@ -1438,6 +2780,34 @@ func (bp *blameProof) UnmarshalFrom(r io.Reader) (int, error) {
 	return n + m, bp.UnmarshalBinary(finalBuf)
 }
 
+/* Marshals a blameProof, implements abstract.SuiteMarshaler.
+ *
+ * Arguments
+ *    w = the writer to use for marshalling
+ *    s = the suite of the blameProof
+ *
+ * Returns
+ *   The error status of the write (nil if no errors)
+ */
+func (bp *blameProof) MarshalSuite(w io.Writer, s abstract.Suite) error {
+	_, err := bp.MarshalTo(w)
+	return err
+}
+
+/* Unmarshals a blameProof, implements abstract.SuiteMarshaler.
+ *
+ * Arguments
+ *    r = the reader to use for unmarshalling
+ *    s = the suite of the blameProof
+ *
+ * Returns
+ *   The error status of the read (nil if no errors)
+ */
+func (bp *blameProof) UnmarshalSuite(r io.Reader, s abstract.Suite) error {
+	_, err := bp.UnmarshalInit(s).UnmarshalFrom(r)
+	return err
+}
+
 /* Returns a string representation of the blameProof for easy debugging
  *
  * Returns
@@ -1467,6 +2837,12 @@ const (
 
 	// Denotes that the Response contains a blameProof
 	blameProofResponse
+
+	// Only used in the binary encoding, denotes that the Response contains
+	// a signature bound to the index of its insurer, which follows the
+	// type. In memory, such a Response is a signatureResponse with indexed
+	// set.
+	indexedSignatureResponse
 )
 
 /* The Response struct is a union of the signature and blameProof types.
@@ -1487,6 +2863,12 @@ type Response struct {
 	// A signature proving that the insurer approves of a Deal
 	signature *signature
 
+	// Whether the signature is bound to the index of the insurer, and
+	// the index (see Deal.indexedMsg). Signatures of earlier versions are
+	// not, and State rejects them.
+	indexed bool
+	index   int
+
 	// blameProof showing that the Deal has been badly constructed.
 	blameProof *blameProof
 }
@@ -1505,6 +2887,23 @@ func (r *Response) constructSignatureResponse(sig *signature) *Response {
 	return r
 }
 
+/* An internal function, constructs a new Response with a signature bound
+ * to the index of its insurer
+ *
+ * Arguments
+ *    i   = the index of the insurer
+ *    sig = the signature to send.
+ *
+ * Returns
+ *   An initialized Response
+ */
+func (r *Response) constructIndexedSignatureResponse(i int, sig *signature) *Response {
+	r.constructSignatureResponse(sig)
+	r.indexed = true
+	r.index = i
+	return r
+}
+
 /* An internal function, constructs a new Response with a blameProof
  *
  * Arguments
@@ -1549,7 +2948,8 @@ func (r *Response) Equal(r2 *Response) bool {
 		return false
 	}
 	if r.rtype == signatureResponse {
-		return r.signature.Equal(r2.signature)
+		return r.indexed == r2.indexed && r.index == r2.index &&
+			r.signature.Equal(r2.signature)
 	}
 	// r.rtype == blameProofSignature
 	return r.blameProof.Equal(r2.blameProof)
@@ -1570,6 +2970,9 @@ func (r *Response) MarshalSize() int {
 		panic("Response not initialized")
 	}
 
+	if r.rtype == signatureResponse && r.indexed {
+		return 3*uint32Size + r.signature.MarshalSize()
+	}
 	if r.rtype == signatureResponse {
 		return 2*uint32Size + r.signature.MarshalSize()
 	}
@@ -1587,6 +2990,10 @@ func (r *Response) MarshalSize() int {
  *   The buffer is formatted as follows:
  *
  *   ||signature_Or_blameProof_Length||Type||signature_or_blameProof||
+ *
+ *   except for signatures bound to the index of their insurer:
+ *
+ *   ||Index_And_signature_Length||Type||Index||signature||
  */
 func (r *Response) MarshalBinary() ([]byte, error) {
 	if r.rtype == errorResponse {
@@ -1595,15 +3002,23 @@ func (r *Response) MarshalBinary() ([]byte, error) {
 
 	var msgLen int
 	buf := make([]byte, r.MarshalSize())
+	rtype := r.rtype
+	bufPos := 2 * uint32Size
 
 	if r.rtype == signatureResponse {
 		msgLen = r.signature.MarshalSize()
 	} else { //r.rtype == blameProofResponse
 		msgLen = r.blameProof.MarshalSize()
 	}
+	if r.rtype == signatureResponse && r.indexed {
+		rtype = indexedSignatureResponse
+		msgLen += uint32Size
+		binary.LittleEndian.PutUint32(buf[bufPos:], uint32(r.index))
+		bufPos += uint32Size
+	}
 
 	binary.LittleEndian.PutUint32(buf, uint32(msgLen))
-	binary.LittleEndian.PutUint32(buf[uint32Size:], uint32(r.rtype))
+	binary.LittleEndian.PutUint32(buf[uint32Size:], uint32(rtype))
 
 	var msgBuf []byte
 	var err error
@@ -1617,7 +3032,7 @@ func (r *Response) MarshalBinary() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	copy(buf[2*uint32Size:], msgBuf)
+	copy(buf[bufPos:], msgBuf)
 	return buf, nil
 }
 
@@ -1648,6 +3063,23 @@ func (r *Response) UnmarshalBinary(buf []byte) error {
 	if r.rtype == errorResponse {
 		return errors.New("Uninitialized reponse sent")
 	}
+	if r.rtype != signatureResponse && r.rtype != indexedSignatureResponse &&
+		r.rtype != blameProofResponse {
+		return ErrInvalidResponse
+	}
+
+	r.indexed = false
+	r.index = 0
+	if r.rtype == indexedSignatureResponse {
+		if msgLen < uint32Size {
+			return errors.New("Buffer size too small")
+		}
+		r.rtype = signatureResponse
+		r.indexed = true
+		r.index = int(binary.LittleEndian.Uint32(buf[bufPos:]))
+		bufPos += uint32Size
+		msgLen -= uint32Size
+	}
 
 	if r.rtype == signatureResponse {
 		r.signature = new(signature).UnmarshalInit(r.suite)
@@ -1697,6 +3129,9 @@ func (rp *Response) UnmarshalFrom(r io.Reader) (int, error) {
 		return n, err
 	}
 	msgLen := int(binary.LittleEndian.Uint32(buf))
+	if err := checkFieldSize(msgLen); err != nil {
+		return n, err
+	}
 
 	// Calculate the final buffer, copy the old data to it, and fill it
 	// for unmarshalling
@@ -1710,6 +3145,37 @@ func (rp *Response) UnmarshalFrom(r io.Reader) (int, error) {
 	return n + m, rp.UnmarshalBinary(finalBuf)
 }
 
+/* Marshals a Response, implements abstract.SuiteMarshaler.
+ *
+ * Arguments
+ *    w = the writer to use for marshalling
+ *    s = the suite of the Response
+ *
+ * Returns
+ *   The error status of the write (nil if no errors)
+ */
+func (r *Response) MarshalSuite(w io.Writer, s abstract.Suite) error {
+	if r.rtype == errorResponse {
+		return ErrInvalidResponse
+	}
+	_, err := r.MarshalTo(w)
+	return err
+}
+
+/* Unmarshals a Response, implements abstract.SuiteMarshaler.
+ *
+ * Arguments
+ *    rd = the reader to use for unmarshalling
+ *    s  = the suite of the Response
+ *
+ * Returns
+ *   The error status of the read (nil if no errors)
+ */
+func (r *Response) UnmarshalSuite(rd io.Reader, s abstract.Suite) error {
+	_, err := r.UnmarshalInit(s).UnmarshalFrom(rd)
+	return err
+}
+
 /* Returns a string representation of the Response for easy debugging
  *
  * Returns
@@ -1719,6 +3185,9 @@ func (r Response) String() string {
 	s := "{Response:\n"
 	s += "ResponseType => " + strconv.Itoa(int(r.rtype)) + ",\n"
 
+	if r.rtype == signatureResponse && r.indexed {
+		s += "index => " + strconv.Itoa(r.index) + ",\n"
+	}
 	if r.rtype == signatureResponse {
 		s += "signature => " + r.signature.String() + ",\n"
 	}
@@ -2,15 +2,18 @@ package poly
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 
 	"github.com/dedis/crypto/abstract"
-	"github.com/dedis/crypto/anon"
 	"github.com/dedis/crypto/config"
 	"github.com/dedis/crypto/edwards"
 	"github.com/dedis/crypto/nist"
 	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/sign"
+	"github.com/dedis/crypto/suites"
 )
 
 var suite = nist.NewAES128SHA256P256()
@@ -29,6 +32,9 @@ var insurerList = produceinsurerList()
 var basicDeal = new(Deal).ConstructDeal(secretKey, DealerKey, pt, r, insurerList)
 var basicState = new(State).Init(*basicDeal)
 
+// An arbitrary message to test signatures
+var testMsg = []byte("Deal Signature")
+
 func produceKeyPair() *config.KeyPair {
 	keyPair := new(config.KeyPair)
 	keyPair.Gen(suite, random.Stream)
@@ -87,7 +93,7 @@ func TestDealSignatureUnMarshalInit(t *testing.T) {
 // Verifies that signature's marshalling code works
 func TestDealSignatureBinaryMarshalling(t *testing.T) {
 	// Tests BinaryMarshal, BinaryUnmarshal, and MarshalSize
-	sig := basicDeal.sign(numInsurers-1, insurerKeys[numInsurers-1], sigMsg)
+	sig, _ := basicDeal.sign(numInsurers-1, insurerKeys[numInsurers-1], approveDomain, testMsg)
 	encodedSig, err := sig.MarshalBinary()
 	if err != nil || len(encodedSig) != sig.MarshalSize() {
 		t.Fatal("Marshalling failed: ", err,
@@ -102,12 +108,12 @@ func TestDealSignatureBinaryMarshalling(t *testing.T) {
 	if !sig.Equal(decodedSig) {
 		t.Error("Decoded signature not equal to original")
 	}
-	if basicDeal.verifySignature(numInsurers-1, decodedSig, sigMsg) != nil {
+	if basicDeal.verifySignature(numInsurers-1, decodedSig, approveDomain, testMsg) != nil {
 		t.Error("Decoded signature failed to be verified.")
 	}
 
 	// Tests MarshlTo and UnmarshalFrom
-	sig2 := basicDeal.sign(1, insurerKeys[1], sigMsg)
+	sig2, _ := basicDeal.sign(1, insurerKeys[1], approveDomain, testMsg)
 	bufWriter := new(bytes.Buffer)
 	bytesWritter, errs := sig2.MarshalTo(bufWriter)
 	if bytesWritter != sig2.MarshalSize() || errs != nil {
@@ -127,7 +133,7 @@ func TestDealSignatureBinaryMarshalling(t *testing.T) {
 	if !sig2.Equal(decodedSig2) {
 		t.Error("signature read does not equal original")
 	}
-	if basicDeal.verifySignature(1, decodedSig2, sigMsg) != nil {
+	if basicDeal.verifySignature(1, decodedSig2, approveDomain, testMsg) != nil {
 		t.Error("Read signature failed to be verified.")
 	}
 
@@ -242,7 +248,7 @@ func TestBlameProofBinaryMarshalling(t *testing.T) {
 	}
 
 	// Tests MarshlTo and UnmarshalFrom
-	bp2, _ := basicDeal.blame(0, insurerKeys[0])
+	bp2, _ := deal.blame(0, insurerKeys[0])
 	bufWriter := new(bytes.Buffer)
 	bytesWritter, errs := bp2.MarshalTo(bufWriter)
 	if bytesWritter != bp2.MarshalSize() || errs != nil {
@@ -268,9 +274,17 @@ func TestBlameProofBinaryMarshalling(t *testing.T) {
 
 }
 
+// Returns a Response of insurer i approving the Deal, as ProduceResponse but
+// without verifying the share.
+func approve(deal *Deal, i int) *Response {
+	msg, _ := deal.indexedMsg(i)
+	sig, _ := deal.sign(i, insurerKeys[i], approveDomain, msg)
+	return new(Response).constructIndexedSignatureResponse(i, sig)
+}
+
 // Verifies that constructSignatureResponse properly initalizes a new Response
 func TestResponseConstructSignatureResponse(t *testing.T) {
-	sig := basicDeal.sign(0, insurerKeys[0], sigMsg)
+	sig, _ := basicDeal.sign(0, insurerKeys[0], approveDomain, testMsg)
 
 	response := new(Response).constructSignatureResponse(sig)
 	if response.rtype != signatureResponse {
@@ -304,7 +318,7 @@ func TestResponseUnMarshalInit(t *testing.T) {
 
 // Verifies that Equal properly works for Response objects
 func TestResponseEqual(t *testing.T) {
-	sig := basicDeal.sign(0, insurerKeys[0], sigMsg)
+	sig, _ := basicDeal.sign(0, insurerKeys[0], approveDomain, testMsg)
 	proof, _ := basicDeal.blame(0, insurerKeys[0])
 
 	response := new(Response).constructBlameProofResponse(proof)
@@ -324,7 +338,8 @@ func TestResponseEqual(t *testing.T) {
 	}
 	response = new(Response).constructSignatureResponse(sig)
 	response2 = new(Response).constructSignatureResponse(sig)
-	response2.signature = basicDeal.sign(1, insurerKeys[1], sigMsg)
+	response2.signature, _ = basicDeal.sign(1, insurerKeys[1], approveDomain,
+		testMsg)
 	if response.Equal(response2) {
 		t.Error("Response differ in Signatures.")
 	}
@@ -384,10 +399,14 @@ func responseMarshallingHelper(t *testing.T, response *Response) {
 func TestResponseBinaryMarshalling(t *testing.T) {
 
 	// Verify a signature response can be encoded properly
-	sig := basicDeal.sign(0, insurerKeys[0], sigMsg)
+	sig, _ := basicDeal.sign(0, insurerKeys[0], approveDomain, testMsg)
 	response := new(Response).constructSignatureResponse(sig)
 	responseMarshallingHelper(t, response)
 
+	// Verify a signature response bound to its index can be encoded properly
+	response, _ = basicDeal.ProduceResponse(numInsurers-1, insurerKeys[numInsurers-1])
+	responseMarshallingHelper(t, response)
+
 	// Verify a proof response can be encoded properly
 	proof, _ := basicDeal.blame(0, insurerKeys[0])
 	response = new(Response).constructBlameProofResponse(proof)
@@ -535,18 +554,41 @@ func TestDealverifyDeal(t *testing.T) {
 	if deal.verifyDeal() == nil {
 		t.Error("dealis invalid: secrets list is the wrong length")
 	}
+
+	// A commitment with a small-order component, i.e., the point (0,-1)
+	insurers := []abstract.Point{produceAltKeyPair().Public,
+		produceAltKeyPair().Public, produceAltKeyPair().Public}
+	deal = new(Deal).ConstructDeal(produceAltKeyPair(), produceAltKeyPair(),
+		2, 2, insurers)
+	if deal.verifyDeal() != nil {
+		t.Error("dealis valid")
+	}
+	low := make([]byte, altSuite.PointLen())
+	low[0] = 0xec
+	for i := 1; i < 31; i++ {
+		low[i] = 0xff
+	}
+	low[31] = 0x7f
+	L := altSuite.Point()
+	if err := L.UnmarshalBinary(low); err != nil {
+		t.Fatal(err)
+	}
+	deal.pubPoly.p[1] = altSuite.Point().Add(deal.pubPoly.p[1], L)
+	if deal.verifyDeal() != ErrInvalidPoint {
+		t.Error("dealis invalid: commitment outside of the subgroup")
+	}
 }
 
 // Verifies that Id returns the id expected
 func TestDealId(t *testing.T) {
-	if basicDeal.Id() != secretKey.Public.String() {
+	if basicDeal.Id() != abstract.PointKey(secretKey.Public) {
 		t.Error("Wrong id returned.")
 	}
 }
 
 // Verifies that DealerId returns the id expected
 func TestDealDealerId(t *testing.T) {
-	if basicDeal.DealerId() != DealerKey.Public.String() {
+	if basicDeal.DealerId() != abstract.PointKey(DealerKey.Public) {
 		t.Error("Wrong id returned.")
 	}
 }
@@ -569,7 +611,7 @@ func TestDealerKey(t *testing.T) {
 func TestDealInsurers(t *testing.T) {
 	result := basicDeal.Insurers()
 	for i := 0; i < basicDeal.n; i++ {
-		if result[i] != basicDeal.insurers[i] {
+		if !result[i].Equal(basicDeal.insurers[i]) {
 			t.Fatal("Wrong insurers list returned.")
 		}
 	}
@@ -580,6 +622,25 @@ func TestDealInsurers(t *testing.T) {
 		t.Error("Changing the return result shouldn't change the original array")
 	}
 
+	result = basicDeal.Insurers()
+	result[1].Null()
+	if basicDeal.insurers[1].Equal(result[1]) {
+		t.Error("Changing a returned insurer shouldn't change the original key")
+	}
+}
+
+// Verifies that a State does not share Points and Scalars with its Deal
+func TestStateInitDeepCopy(t *testing.T) {
+	deal := new(Deal).ConstructDeal(secretKey, DealerKey, pt, r, insurerList)
+	state := new(State).Init(*deal)
+	deal.insurers[0].Null()
+	deal.secrets[0].Zero()
+	deal.pubPoly.p[0].Null()
+	if state.Deal.insurers[0].Equal(deal.insurers[0]) ||
+		state.Deal.secrets[0].Equal(deal.secrets[0]) ||
+		state.Deal.pubPoly.p[0].Equal(deal.pubPoly.p[0]) {
+		t.Error("State shares mutable values with its Deal")
+	}
 }
 
 // Tests that encrypting a secret with a diffie-hellman shared secret and then
@@ -613,69 +674,77 @@ func TestDealVerifyShare(t *testing.T) {
 	}
 
 	// Error handling
-	if basicDeal.verifyShare(-1, insurerKeys[0]) == nil {
+	if basicDeal.verifyShare(-1, insurerKeys[0]) != ErrInvalidIndex {
 		t.Error("The share should not have been valid. Index is negative.")
 	}
-	if basicDeal.verifyShare(basicDeal.n, insurerKeys[0]) == nil {
+	if basicDeal.verifyShare(basicDeal.n, insurerKeys[0]) != ErrInvalidIndex {
 		t.Error("The share should not have been valid. Index >= n")
 	}
-	if basicDeal.verifyShare(numInsurers-1, insurerKeys[0]) == nil {
+	if basicDeal.verifyShare(numInsurers-1, insurerKeys[0]) != ErrWrongInsurerKey {
 		t.Error("Share should be invalid. Index and Public Key did not match.")
 	}
+	if basicDeal.verifyShare(0, produceKeyPair()) != ErrNotInsurer {
+		t.Error("Share should be invalid. Public Key is not an insurer.")
+	}
 }
 
 // Verify that the dealcan produce a valid signature and then verify it.
 // In short, all signatures produced by the sign method should be accepted.
 func TestDealSignAndVerify(t *testing.T) {
-	sig := basicDeal.sign(0, insurerKeys[0], sigMsg)
-	if basicDeal.verifySignature(0, sig, sigMsg) != nil {
+	sig, _ := basicDeal.sign(0, insurerKeys[0], approveDomain, testMsg)
+	if basicDeal.verifySignature(0, sig, approveDomain, testMsg) != nil {
 		t.Error("Signature failed to be validated")
 	}
 }
 
 // Produces a bad signature that has a malformed approve message
 func produceSigWithBadMessage() *signature {
-	set := anon.Set{insurerKeys[0].Public}
 	approveMsg := "Bad message"
-	digSig := anon.Sign(insurerKeys[0].Suite, random.Stream, []byte(approveMsg),
-		set, nil, 0, insurerKeys[0].Secret)
+	digSig, _ := sign.SignWithDomain(insurerKeys[0].Suite,
+		insurerKeys[0].Secret, approveDomain, []byte(approveMsg))
 	return new(signature).init(insurerKeys[0].Suite, digSig)
 }
 
 // Verify that mallformed signatures are not accepted.
 func TestDealVerifySignature(t *testing.T) {
 	// Fail if the signature is not the specially formatted approve message.
-	if basicDeal.verifySignature(0, produceSigWithBadMessage(), sigMsg) == nil {
+	if basicDeal.verifySignature(0, produceSigWithBadMessage(), approveDomain, testMsg) == nil {
 		t.Error("Signature has a bad message and should be rejected.")
 	}
 
 	//Error Handling
 	// Fail if a valid signature is applied to the wrong share.
-	sig := basicDeal.sign(0, insurerKeys[0], sigMsg)
-	if basicDeal.verifySignature(numInsurers-1, sig, sigMsg) == nil {
+	sig, _ := basicDeal.sign(0, insurerKeys[0], approveDomain, testMsg)
+	if basicDeal.verifySignature(numInsurers-1, sig, approveDomain, testMsg) == nil {
 		t.Error("Signature is for the wrong share.")
 	}
 	// Fail if index is negative
-	if basicDeal.verifySignature(-1, sig, sigMsg) == nil {
+	if basicDeal.verifySignature(-1, sig, approveDomain, testMsg) == nil {
 		t.Error("Error: Index < 0")
 	}
 	// Fail if index >= n
-	if basicDeal.verifySignature(basicDeal.n, sig, sigMsg) == nil {
+	if basicDeal.verifySignature(basicDeal.n, sig, approveDomain, testMsg) == nil {
 		t.Error("Error: Index >= n")
 	}
 	// Should return false if passed nil
 	sig.signature = nil
-	if basicDeal.verifySignature(0, sig, sigMsg) == nil {
+	if basicDeal.verifySignature(0, sig, approveDomain, testMsg) == nil {
 		t.Error("Error: Signature is nil")
 	}
 }
 
 // Verify that insurer secret shares can be revealed properly and verified.
 func TestDealerevealShareAndShareVerify(t *testing.T) {
-	DealShare := basicDeal.RevealShare(0, insurerKeys[0])
-	if basicDeal.VerifyRevealedShare(0, DealShare) != nil {
+	DealShare, err := basicDeal.RevealShare(0, insurerKeys[0])
+	if err != nil || basicDeal.VerifyRevealedShare(0, DealShare) != nil {
 		t.Error("The share should have been marked as valid")
 	}
+	if _, err := basicDeal.RevealShare(-1, insurerKeys[0]); err != ErrInvalidIndex {
+		t.Error("RevealShare should reject an index too low", err)
+	}
+	if _, err := basicDeal.RevealShare(numInsurers, insurerKeys[0]); err != ErrInvalidIndex {
+		t.Error("RevealShare should reject an index too high", err)
+	}
 
 	// Error Handling
 	if basicDeal.VerifyRevealedShare(-1, DealShare) == nil {
@@ -686,7 +755,7 @@ func TestDealerevealShareAndShareVerify(t *testing.T) {
 	}
 	// Ensures the public polynomial fails when the share provided doesn't
 	// match the index.
-	if basicDeal.VerifyRevealedShare(2, DealShare) == nil {
+	if basicDeal.VerifyRevealedShare(2, DealShare) != ErrShareCheckFailed {
 		t.Error("The share provided is not for the index.")
 	}
 }
@@ -731,12 +800,45 @@ func TestDealBlameAndVerify(t *testing.T) {
 		t.Error("Invalid blame. Bad Diffie-Hellman key proof.")
 	}
 	badSignature, _ := basicDeal.blame(0, insurerKeys[0])
-	badSignature.signature = *deal.sign(1, insurerKeys[1], sigMsg)
+	sig, _ := deal.sign(1, insurerKeys[1], approveDomain, testMsg)
+	badSignature.signature = *sig
 	if basicDeal.verifyBlame(0, badSignature) == nil {
 		t.Error("Invalid blame. The signature is bad.")
 	}
 }
 
+// Verify that blameProofs cannot be presented for another share or Deal
+func TestDealVerifyBlameBinding(t *testing.T) {
+	deal := new(Deal).ConstructDeal(secretKey, DealerKey, pt, r, insurerList)
+	deal.secrets[0] = deal.suite.Scalar()
+	deal.secrets[1] = deal.suite.Scalar()
+	bproof, _ := deal.blame(0, insurerKeys[0])
+	if err := deal.verifyBlame(0, bproof); err != nil {
+		t.Fatal("The blame is justified", err)
+	}
+
+	// The blame of share 1 signed with the message of share 0
+	msg, _ := deal.indexedMsg(0)
+	forged, _ := deal.blameWith(1, insurerKeys[1], blameDomain, msg)
+	if deal.verifyBlame(1, forged) == nil {
+		t.Error("Blame signed for another share should be rejected")
+	}
+
+	// A blame whose signature is in another domain, e.g., an approval
+	msg, _ = deal.indexedMsg(0)
+	forged, _ = deal.blameWith(0, insurerKeys[0], approveDomain, msg)
+	if deal.verifyBlame(0, forged) == nil {
+		t.Error("Blame signed as an approval should be rejected")
+	}
+
+	// The same bad share in another Deal
+	other := deal.clone()
+	other.id = produceKeyPair().Public
+	if other.verifyBlame(0, bproof) == nil {
+		t.Error("Blame signed for another Deal should be rejected")
+	}
+}
+
 // Verify that insurers can properly produce responses
 func TestDealProduceResponse(t *testing.T) {
 
@@ -748,9 +850,16 @@ func TestDealProduceResponse(t *testing.T) {
 	if response.rtype != signatureResponse {
 		t.Fatal("Response should be a blameProof")
 	}
-	if basicDeal.verifySignature(0, response.signature, sigMsg) != nil {
+	msg, _ := basicDeal.indexedMsg(0)
+	if !response.indexed || response.index != 0 ||
+		basicDeal.verifySignature(0, response.signature, approveDomain,
+			msg) != nil {
 		t.Error("The proof is valid and should be accepted.")
 	}
+	if basicDeal.verifySignature(0, response.signature, blameDomain,
+		msg) == nil {
+		t.Error("An approval should not verify as a blame.")
+	}
 
 	// Verify a proper blameProofResponse can be created
 	deal := new(Deal).ConstructDeal(secretKey, DealerKey, pt,
@@ -928,6 +1037,128 @@ func TestDealBinaryMarshalling(t *testing.T) {
 	}
 }
 
+// Verifies that the version of the binary format is checked and that older
+// versions are decoded with the registered upgrade
+func TestDealBinaryVersion(t *testing.T) {
+	encodedP, err := basicDeal.MarshalBinary()
+	if err != nil || encodedP[0] != DealVersion {
+		t.Fatal("Marshalling failed: ", err)
+	}
+
+	// Pretend the current format was version 0
+	encodedP[0] = 0
+	decodedP := new(Deal).UnmarshalInit(pt, r, numInsurers, suite)
+	if err := decodedP.UnmarshalBinary(encodedP); err != ErrUnsupportedVersion {
+		t.Fatal("Unknown version should be rejected: ", err)
+	}
+	RegisterDealUpgrade(0, func(p *Deal, buf []byte) error {
+		return p.unmarshalCurrent(buf)
+	})
+	defer delete(dealUpgrades.m, 0)
+	if err := decodedP.UnmarshalBinary(encodedP); err != nil {
+		t.Fatal("Upgrade failed: ", err)
+	}
+	if !basicDeal.Equal(decodedP) {
+		t.Error("Upgraded Deal differs from the original")
+	}
+
+	encodedP[0] = DealVersion + 1
+	if err := decodedP.UnmarshalBinary(encodedP); err != ErrUnsupportedVersion {
+		t.Fatal("Newer version should be rejected: ", err)
+	}
+}
+
+// Verifies that Deals marshalled with version 1 of the binary format, whose
+// fields have fixed offsets, are still decoded
+func TestDealBinaryV1(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteByte(1)
+	polyBuf, _ := basicDeal.pubPoly.MarshalBinary()
+	if err := suite.Write(&b, basicDeal.id, basicDeal.pubKey); err != nil {
+		t.Fatal(err)
+	}
+	b.Write(polyBuf)
+	if err := suite.Write(&b, basicDeal.insurers, basicDeal.secrets); err != nil {
+		t.Fatal(err)
+	}
+	decodedP := new(Deal).UnmarshalInit(pt, r, numInsurers, suite)
+	if err := decodedP.UnmarshalBinary(b.Bytes()); err != nil {
+		t.Fatal("Version 1 should be decoded: ", err)
+	}
+	if !basicDeal.Equal(decodedP) {
+		t.Error("Decoded Deal differs from the original")
+	}
+	if err := decodedP.UnmarshalBinary(b.Bytes()[:b.Len()-1]); err == nil {
+		t.Error("Truncated version 1 should be rejected")
+	}
+}
+
+// Verifies that Deals marshalled with version 2 of the binary format, which
+// lacks the ShareProofs, are still decoded
+func TestDealBinaryV2(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteByte(2)
+	if err := basicDeal.marshalFields(&b, true); err != nil {
+		t.Fatal(err)
+	}
+	v2 := b.Bytes()[:b.Len()-uint32Size]
+	decodedP := new(Deal).UnmarshalInit(pt, r, numInsurers, suite)
+	if err := decodedP.UnmarshalBinary(v2); err != nil {
+		t.Fatal("Version 2 should be decoded: ", err)
+	}
+	if !basicDeal.Equal(decodedP) {
+		t.Error("Decoded Deal differs from the original")
+	}
+	if err := decodedP.UnmarshalBinary(b.Bytes()); err == nil {
+		t.Error("Trailing data should be rejected in version 2")
+	}
+	public, _ := basicDeal.MarshalPublic()
+	public[0] = 2
+	if err := decodedP.UnmarshalPublic(public); err != nil {
+		t.Error("Public view of version 2 should be decoded", err)
+	}
+}
+
+// Verifies that truncated, oversized and corrupted buffers are rejected
+// without panicking
+func TestDealBinaryMalformed(t *testing.T) {
+	encodedP, err := basicDeal.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decode := func(buf []byte) (err error) {
+		defer func() {
+			if e := recover(); e != nil {
+				t.Fatal("Unmarshalling panicked: ", e)
+			}
+		}()
+		decodedP := new(Deal).UnmarshalInit(pt, r, numInsurers, suite)
+		if err := decodedP.UnmarshalBinary(buf); err != nil {
+			return err
+		}
+		_, err = new(Deal).UnmarshalInit(pt, r, numInsurers, suite).
+			UnmarshalFrom(bytes.NewReader(buf))
+		return err
+	}
+	for i := 0; i < len(encodedP); i++ {
+		if decode(encodedP[:i]) == nil {
+			t.Fatal("Truncated buffer should be rejected: ", i)
+		}
+	}
+	if decode(append(append([]byte(nil), encodedP...), 0)) == nil {
+		t.Error("Oversized buffer should be rejected")
+	}
+
+	// Forged lengths and random corruptions
+	for i := 1; i < len(encodedP); i += 7 {
+		buf := append([]byte(nil), encodedP...)
+		buf[i] = 0xff
+		decode(buf)
+		buf[i] ^= byte(random.Uint32(random.Stream))
+		decode(buf)
+	}
+}
+
 // Verifies that Init properly initalizes a new State object
 func TestStateInit(t *testing.T) {
 	DealState := new(State).Init(*basicDeal)
@@ -944,10 +1175,9 @@ func TestStateAddSignature(t *testing.T) {
 	DealState := new(State).Init(*basicDeal)
 	for i := 0; i < numInsurers; i++ {
 		// Verify valid signatures are added.
-		sig := DealState.Deal.sign(i, insurerKeys[i], sigMsg)
-		response := new(Response).constructSignatureResponse(sig)
+		response := approve(&DealState.Deal, i)
 		err := DealState.AddResponse(i, response)
-		if err != nil || !sig.Equal(DealState.responses[i].signature) {
+		if err != nil || !response.Equal(DealState.responses[i]) {
 			t.Error("Signature failed to be added", err)
 		}
 
@@ -961,12 +1191,16 @@ func TestStateAddSignature(t *testing.T) {
 	DealState = new(State).Init(*deal)
 
 	// Verify invalid signatures are not added.
-	sig := DealState.Deal.sign(i, insurerKeys[i], sigMsg)
-	response := new(Response).constructSignatureResponse(sig)
+	response := approve(&DealState.Deal, i)
 	err := DealState.AddResponse(i+1, response)
 	if err == nil || DealState.responses[i] != nil {
 		t.Error("Signature is invalid and should not be added.", err)
 	}
+	sig, _ := DealState.Deal.sign(i, insurerKeys[i], approveDomain, testMsg)
+	err = DealState.AddResponse(i, new(Response).constructSignatureResponse(sig))
+	if err != ErrInvalidIndex || DealState.responses[i] != nil {
+		t.Error("Signature not bound to its index should not be added.", err)
+	}
 
 	// Change the response to an error and verify it is not added.
 	response.rtype = errorResponse
@@ -1009,9 +1243,7 @@ func TestStateDealCertified(t *testing.T) {
 	// Once enough signatures have been added, the dealshould remain
 	// certified.
 	for i := 1; i < numInsurers; i++ {
-		sig := DealState.Deal.sign(i, insurerKeys[i], sigMsg)
-		response := new(Response).constructSignatureResponse(sig)
-		DealState.AddResponse(i, response)
+		DealState.AddResponse(i, approve(&DealState.Deal, i))
 
 		err := DealState.DealCertified()
 		if i < r && err == nil {
@@ -1024,10 +1256,17 @@ func TestStateDealCertified(t *testing.T) {
 
 	// Error handling
 
+	if !DealState.Certified() || !DealState.EnoughSignatures() {
+		t.Error("The deal should be certified")
+	}
+
 	// If the dealfails verifyDeal, it should be uncertified even if
 	// everything else is okay.
-	DealState.Deal.n = 0
-	if err := DealState.DealCertified(); err == nil {
+	badDeal := *deal
+	badDeal.n = 0
+	badState := new(State).Init(badDeal)
+	badState.signatures = DealState.signatures
+	if err := badState.DealCertified(); err == nil || badState.Certified() {
 		t.Error("The dealis malformed and should be uncertified")
 	}
 
@@ -1041,13 +1280,217 @@ func TestStateDealCertified(t *testing.T) {
 	DealState.AddResponse(0, response)
 
 	for i := 1; i < numInsurers; i++ {
-		sig := DealState.Deal.sign(i, insurerKeys[i], sigMsg)
-		response := new(Response).constructSignatureResponse(sig)
-		DealState.AddResponse(i, response)
-		if DealState.DealCertified() == nil {
+		DealState.AddResponse(i, approve(&DealState.Deal, i))
+		if DealState.DealCertified() == nil || DealState.Certified() {
 			t.Error("A valid blameProof makes this uncertified")
 		}
 	}
+	if !DealState.EnoughSignatures() {
+		t.Error("BlameProofs should not prevent having enough signatures")
+	}
+}
+
+// Verify that certification failures can be classified by their error code.
+func TestStateDealCertifiedErrorCodes(t *testing.T) {
+	deal := new(Deal).ConstructDeal(secretKey, DealerKey, pt, r, insurerList)
+	DealState := new(State).Init(*deal)
+	if err := DealState.DealCertified(); ErrorCode(err) != CodeNotCertified {
+		t.Error("Expected a not certified error, got", err)
+	}
+	err := DealState.DealCertified()
+	if !errors.Is(err, ErrNotCertified) || errors.Is(err, ErrBlamed) {
+		t.Error("Not certified error should match ErrNotCertified only, got", err)
+	}
+	wrapped := fmt.Errorf("checking deal: %w", err)
+	if !errors.Is(wrapped, ErrNotCertified) ||
+		ErrorCode(wrapped) != CodeNotCertified {
+		t.Error("Wrapped error should keep its code, got", wrapped)
+	}
+	if err := DealState.AddResponse(0, &Response{}); err != ErrInvalidResponse {
+		t.Error("Expected an invalid response error, got", err)
+	}
+
+	DealState.Deal.secrets[0] = deal.suite.Scalar()
+	bproof, _ := DealState.Deal.blame(0, insurerKeys[0])
+	response := new(Response).constructBlameProofResponse(bproof)
+	if err := DealState.AddResponse(0, response); err != nil {
+		t.Fatal(err)
+	}
+	if err := DealState.AddResponse(0, response); err != ErrResponseAdded {
+		t.Error("Expected a response already added error, got", err)
+	}
+	if err := DealState.DealCertified(); err != ErrBlamed {
+		t.Error("Expected a blamed error, got", err)
+	}
+
+	// Share 1 is valid, so blaming the Dealer for it is unjustified.
+	bproof, _ = DealState.Deal.blame(1, insurerKeys[1])
+	response = new(Response).constructBlameProofResponse(bproof)
+	if err := DealState.AddResponse(1, response); err != ErrUnjustifiedBlame {
+		t.Error("Expected an unjustified blame error, got", err)
+	}
+	if ErrorCode(nil) != 0 || ErrorCode(errors.New("other")) != 0 {
+		t.Error("Only DealErrors have an error code")
+	}
+}
+
+// A StateObserver recording the events it is notified of
+type testObserver struct {
+	signatures []int
+	blames     []int
+	certified  int
+}
+
+func (o *testObserver) OnSignatureAdded(i int, signatures int) {
+	o.signatures = append(o.signatures, signatures)
+}
+
+func (o *testObserver) OnBlameVerified(i int) {
+	o.blames = append(o.blames, i)
+}
+
+func (o *testObserver) OnCertified() {
+	o.certified++
+}
+
+// Verify that the StateObserver is notified of the certification progress
+func TestStateObserver(t *testing.T) {
+	deal := new(Deal).ConstructDeal(secretKey, DealerKey, pt, r, insurerList)
+	o := &testObserver{}
+	DealState := new(State).Init(*deal).SetObserver(o)
+
+	// An unjustified blame is not reported
+	bproof, _ := DealState.Deal.blame(0, insurerKeys[0])
+	DealState.AddResponse(0, new(Response).constructBlameProofResponse(bproof))
+	if len(o.blames) != 0 {
+		t.Error("Unjustified blame reported")
+	}
+
+	for i := 1; i <= r+1; i++ {
+		DealState.AddResponse(i, approve(&DealState.Deal, i))
+		if o.signatures[i-1] != i || DealState.Signatures() != i {
+			t.Error("Wrong signature count", o.signatures, DealState.Signatures())
+		}
+		if (i >= r) != (o.certified == 1) {
+			t.Error("Certification reported at the wrong time", i, o.certified)
+		}
+	}
+	missing := DealState.MissingInsurers()
+	if len(missing) != numInsurers-r-1 || missing[0] != 0 ||
+		missing[1] != r+2 {
+		t.Error("Wrong missing insurers", missing)
+	}
+
+	// Justified blames are reported
+	DealState = new(State).Init(*deal).SetObserver(o)
+	DealState.Deal.secrets[0] = deal.suite.Scalar()
+	bproof, _ = DealState.Deal.blame(0, insurerKeys[0])
+	DealState.AddResponse(0, new(Response).constructBlameProofResponse(bproof))
+	if len(o.blames) != 1 || o.blames[0] != 0 {
+		t.Error("Blame not reported", o.blames)
+	}
+}
+
+// Verify the recertification of a Deal and the migration of its responses
+func TestStateRecertify(t *testing.T) {
+	deal := new(Deal).ConstructDeal(secretKey, DealerKey, pt, r, insurerList)
+	DealState := new(State).Init(*deal)
+
+	// A justified blame
+	DealState.Deal.secrets[1] = deal.suite.Scalar()
+	bproof, _ := DealState.Deal.blame(1, insurerKeys[1])
+	DealState.AddResponse(1, new(Response).constructBlameProofResponse(bproof))
+	for i := 2; i < r+2; i++ {
+		response, err := DealState.Deal.ProduceResponse(i, insurerKeys[i])
+		if err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+		DealState.AddResponse(i, response)
+	}
+	if DealState.Signatures() != r || !DealState.EnoughSignatures() {
+		t.Fatal("Deal should be certified", DealState.Signatures())
+	}
+
+	// The Dealer cannot lower the quorum approved by the insurers
+	if _, err := DealState.Recertify(r - 1); err != ErrInvalidDeal {
+		t.Error("Lower quorum should be rejected", err)
+	}
+	if _, err := deal.Recertify(pt); err != ErrInvalidDeal {
+		t.Error("Lower quorum should be rejected", err)
+	}
+	if _, err := DealState.Recertify(numInsurers + 1); err != ErrInvalidDeal {
+		t.Error("Quorum above the number of insurers should be rejected", err)
+	}
+
+	// Insurers may approve the new quorum before the State is recertified,
+	// but only with responses covering the recertified Deal
+	newDeal, _ := DealState.Deal.Recertify(r + 1)
+	for i := 2; i < 4; i++ {
+		response, _ := newDeal.ProduceResponse(i, insurerKeys[i])
+		if err := DealState.AddRecertifiedResponse(r+1, i,
+			response); err != nil {
+			t.Fatal("Response to the recertified Deal should be accepted", err)
+		}
+	}
+	if err := DealState.AddRecertifiedResponse(r+1, 4,
+		DealState.responses[4]); err == nil {
+		t.Error("Response to the old Deal should be rejected")
+	}
+	if err := DealState.AddRecertifiedResponse(r-1, 4,
+		DealState.responses[4]); err != ErrInvalidDeal {
+		t.Error("Lower quorum should be rejected", err)
+	}
+
+	newState, err := DealState.Recertify(r + 1)
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if newState.Deal.r != r+1 || !newState.Deal.pubPoly.Equal(&deal.pubPoly) {
+		t.Error("Recertified Deal differs from the original one")
+	}
+	if newState.Deal.Id() == deal.Id() {
+		t.Error("Recertified Deal should have a new id")
+	}
+	again, _ := DealState.Deal.Recertify(r + 1)
+	if !again.Equal(&newState.Deal) {
+		t.Error("Recertification should be deterministic")
+	}
+	id, _ := DealState.Deal.CertificationId()
+	newId, _ := newState.Deal.CertificationId()
+	if bytes.Equal(id, newId) {
+		t.Error("Recertified Deal should have a new certification id")
+	}
+
+	// Only the responses covering the recertified Deal are migrated: the
+	// signatures and blames of the old Deal are not
+	if newState.Signatures() != 2 || newState.responses[2] == nil ||
+		newState.responses[4] != nil || newState.responses[1] != nil ||
+		newState.EnoughSignatures() {
+		t.Error("Wrong migration of the responses", newState.Signatures())
+	}
+	if err := newState.AddResponse(4, DealState.responses[4]); err == nil {
+		t.Error("Signature of the old quorum should be rejected")
+	}
+	if err := newState.AddResponse(1, DealState.responses[1]); err == nil {
+		t.Error("Blame of the old Deal should be rejected")
+	}
+	for i := 4; i < r+3; i++ {
+		response, _ := newState.Deal.ProduceResponse(i, insurerKeys[i])
+		if err := newState.AddResponse(i, response); err != nil {
+			t.Fatal("Signature should be accepted", err)
+		}
+	}
+	if !newState.EnoughSignatures() || !newState.Certified() {
+		t.Error("Recertified Deal should be certified")
+	}
+	if err := DealState.AddResponse(r+2, newState.responses[r+2]); err == nil {
+		t.Error("Signature of the new quorum should not approve the old one")
+	}
+	sec, err := newState.RevealShare(2, insurerKeys[2])
+	share, _ := deal.RevealShare(2, insurerKeys[2])
+	if err != nil || !sec.Equal(share) {
+		t.Error("Shares should be preserved", err)
+	}
 }
 
 // Verify State's SufficientSignatures function
@@ -1066,9 +1509,7 @@ func TestStateSufficientSignatures(t *testing.T) {
 	// Once enough signatures have been added, the dealshould remain
 	// certified.
 	for i := 1; i < numInsurers; i++ {
-		sig := DealState.Deal.sign(i, insurerKeys[i], sigMsg)
-		response := new(Response).constructSignatureResponse(sig)
-		DealState.AddResponse(i, response)
+		DealState.AddResponse(i, approve(&DealState.Deal, i))
 
 		err := DealState.SufficientSignatures()
 		if i < r && err == nil {
@@ -1101,9 +1542,7 @@ func TestStateRevealShare(t *testing.T) {
 
 	// Add enough signatures for the dealto be certified otherwise.
 	for i := 1; i < r+1; i++ {
-		sig := DealState.Deal.sign(i, insurerKeys[i], sigMsg)
-		response := new(Response).constructSignatureResponse(sig)
-		DealState.AddResponse(i, response)
+		DealState.AddResponse(i, approve(&DealState.Deal, i))
 	}
 
 	// Verify that attempting to reveal a bad share results in an error.
@@ -1124,7 +1563,7 @@ func TestStateRevealShare(t *testing.T) {
 
 // Tests all the string functions. Simply calls them to make sure they return.
 func TestString(t *testing.T) {
-	sig := basicDeal.sign(0, insurerKeys[0], sigMsg)
+	sig, _ := basicDeal.sign(0, insurerKeys[0], approveDomain, testMsg)
 	sig.String()
 
 	bp, _ := basicDeal.blame(0, insurerKeys[0])
@@ -1157,3 +1596,146 @@ func TestDealAbstractEncoding(t *testing.T) {
 		t.Error("dealshould be equals")
 	}
 }
+
+// Verify that Deals and Responses can be decoded without UnmarshalInit
+// through the abstract.SuiteMarshaler interface.
+func TestDealSuiteMarshaler(t *testing.T) {
+	response, _ := basicDeal.ProduceResponse(0, insurerKeys[0])
+	buf, err := abstract.MarshalSuite(suite, basicDeal, response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deal := new(Deal)
+	decoded := new(Response)
+	if err := abstract.UnmarshalSuite(suite, buf, deal, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !basicDeal.Equal(deal) || !response.Equal(decoded) {
+		t.Error("Objects differ after encoding")
+	}
+	if abstract.UnmarshalSuite(suite, append(buf, 0), new(Deal),
+		new(Response)) == nil {
+		t.Error("Trailing data should be rejected")
+	}
+	if _, err := abstract.MarshalSuite(altSuite, basicDeal); err == nil {
+		t.Error("Deal should not be marshalled with another suite")
+	}
+}
+
+// Verify that a Deal constructed concurrently is valid and in order.
+func TestDealConstructDealConcurrent(t *testing.T) {
+	deal := new(Deal).SetConcurrency(-1).ConstructDeal(secretKey, DealerKey,
+		pt, r, insurerList)
+	for i := 0; i < numInsurers; i++ {
+		if err := deal.verifyShare(i, insurerKeys[i]); err != nil {
+			t.Error("Share", i, "failed to verify:", err)
+		}
+	}
+	deal = new(Deal).SetConcurrency(3).ConstructDeal(secretKey, DealerKey,
+		pt, r, insurerList)
+	for i := 0; i < numInsurers; i++ {
+		if err := deal.verifyShare(i, insurerKeys[i]); err != nil {
+			t.Error("Share", i, "failed to verify:", err)
+		}
+	}
+}
+
+// Runs a whole Deal, from construction to secret recovery, with every suite
+func TestDealSuites(t *testing.T) {
+	for name, s := range suites.All() {
+		gen := func() *config.KeyPair {
+			kp := new(config.KeyPair)
+			kp.Gen(s, random.Stream)
+			return kp
+		}
+		secret, dealer := gen(), gen()
+		keys := make([]*config.KeyPair, 5)
+		insurers := make([]abstract.Point, len(keys))
+		for i := range keys {
+			keys[i] = gen()
+			insurers[i] = keys[i].Public
+		}
+		deal := new(Deal).ConstructDeal(secret, dealer, 3, 4, insurers)
+
+		buf, err := deal.MarshalBinary()
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		received := new(Deal).UnmarshalInit(3, 4, len(keys), s)
+		if err := received.UnmarshalBinary(buf); err != nil {
+			t.Fatal(name, err)
+		}
+
+		state := new(State).Init(*received)
+		for i := range keys {
+			response, err := received.ProduceResponse(i, keys[i])
+			if err != nil {
+				t.Fatal(name, err)
+			}
+			if err := state.AddResponse(i, response); err != nil {
+				t.Fatal(name, err)
+			}
+		}
+		if !state.Certified() {
+			t.Fatal(name, "deal not certified")
+		}
+		for i := 0; i < 3; i++ {
+			share, err := state.RevealShare(i, keys[i])
+			if err != nil {
+				t.Fatal(name, err)
+			}
+			state.PriShares.SetShare(i, share)
+		}
+		if !state.PriShares.Secret().Equal(secret.Secret) {
+			t.Error(name, "recovered the wrong secret")
+		}
+	}
+}
+
+// Verifies that signatures cannot be presented at another index
+func TestStateAddIndexedSignature(t *testing.T) {
+	deal := new(Deal).ConstructDeal(secretKey, DealerKey, pt, r, insurerList)
+	DealState := new(State).Init(*deal)
+
+	response, _ := DealState.Deal.ProduceResponse(0, insurerKeys[0])
+	if err := DealState.AddResponse(1, response); err != ErrInvalidIndex {
+		t.Error("Signature presented at another index should be rejected", err)
+	}
+
+	// Even if the same key insures another share
+	insurers := append([]abstract.Point{}, insurerList...)
+	insurers[1] = insurers[0]
+	dupDeal := new(Deal).ConstructDeal(secretKey, DealerKey, pt, r, insurers)
+	dupState := new(State).Init(*dupDeal)
+	dupResponse, _ := dupDeal.ProduceResponse(0, insurerKeys[0])
+	dupResponse.index = 1
+	if dupState.AddResponse(1, dupResponse) == nil {
+		t.Error("Signature presented at another index should be rejected")
+	}
+
+	// Nor presented as a signature of an earlier version
+	legacy := new(Response).constructSignatureResponse(response.signature)
+	if DealState.AddResponse(0, legacy) != ErrInvalidIndex {
+		t.Error("Indexed signature should not verify as a legacy one")
+	}
+
+	// Nor presented with the message of another index
+	msg, _ := deal.indexedMsg(1)
+	sig, _ := deal.sign(0, insurerKeys[0], approveDomain, msg)
+	forged := new(Response).constructIndexedSignatureResponse(0, sig)
+	if DealState.AddResponse(0, forged) == nil {
+		t.Error("Signature of another index should be rejected")
+	}
+
+	// Nor for another Deal of the same Dealer and insurers
+	other := new(Deal).ConstructDeal(secretKey, DealerKey, pt, r, insurerList)
+	otherResponse, _ := other.ProduceResponse(0, insurerKeys[0])
+	if DealState.AddResponse(0, otherResponse) == nil {
+		t.Error("Signature of another Deal should be rejected")
+	}
+
+	response.index = 0
+	if err := DealState.AddResponse(0, response); err != nil {
+		t.Error("Signature should be accepted", err)
+	}
+}
@@ -0,0 +1,140 @@
+package poly
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+/* This file implements the gob encoding of the messages of deal.go, so that
+ * they can be used with encoding/gob and net/rpc. Gob cannot encode the
+ * unexported fields of these messages, and the receiver of a message cannot
+ * know its suite in advance, unlike the caller of UnmarshalBinary who must
+ * call UnmarshalInit first. Each gob encoding therefore starts with the name
+ * of the suite of the message, followed by its MarshalSuite encoding. When
+ * decoding, the suite set with UnmarshalInit is used if any, and must match
+ * the recorded one; otherwise the suite is looked up among suites.All().
+ */
+
+var errorGob = errors.New("Malformed gob encoding")
+
+// The messages implement the standard binary and gob interfaces.
+var (
+	_ encoding.BinaryMarshaler   = (*Deal)(nil)
+	_ encoding.BinaryUnmarshaler = (*Deal)(nil)
+	_ gob.GobEncoder             = (*Deal)(nil)
+	_ gob.GobDecoder             = (*Deal)(nil)
+
+	_ encoding.BinaryMarshaler   = (*signature)(nil)
+	_ encoding.BinaryUnmarshaler = (*signature)(nil)
+	_ gob.GobEncoder             = (*signature)(nil)
+	_ gob.GobDecoder             = (*signature)(nil)
+
+	_ encoding.BinaryMarshaler   = (*blameProof)(nil)
+	_ encoding.BinaryUnmarshaler = (*blameProof)(nil)
+	_ gob.GobEncoder             = (*blameProof)(nil)
+	_ gob.GobDecoder             = (*blameProof)(nil)
+
+	_ encoding.BinaryMarshaler   = (*Response)(nil)
+	_ encoding.BinaryUnmarshaler = (*Response)(nil)
+	_ gob.GobEncoder             = (*Response)(nil)
+	_ gob.GobDecoder             = (*Response)(nil)
+)
+
+// Encodes a message prefixed with the name of its suite.
+func gobEncode(suite abstract.Suite, m abstract.SuiteMarshaler) ([]byte, error) {
+	var b bytes.Buffer
+	var l [binary.MaxVarintLen64]byte
+	name := suite.String()
+	b.Write(l[:binary.PutUvarint(l[:], uint64(len(name)))])
+	b.WriteString(name)
+	if err := m.MarshalSuite(&b, suite); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Decodes a message encoded by gobEncode. The suite is the one set with
+// UnmarshalInit, if any.
+func gobDecode(suite abstract.Suite, buf []byte, m abstract.SuiteMarshaler) error {
+	l, n := binary.Uvarint(buf)
+	if n <= 0 || l > uint64(len(buf)-n) {
+		return errorGob
+	}
+	suite, err := jsonSuite(suite, string(buf[n:n+int(l)]))
+	if err != nil {
+		return err
+	}
+	return abstract.UnmarshalSuite(suite, buf[n+int(l):], m)
+}
+
+// Encodes a Deal with its suite and parameters, implements gob.GobEncoder.
+func (p *Deal) GobEncode() ([]byte, error) {
+	return gobEncode(p.suite, p)
+}
+
+/* Decodes a Deal encoded by GobEncode, implements gob.GobDecoder. The Deal
+ * needs not be initialized with UnmarshalInit.
+ *
+ * Arguments
+ *    buf = the gob encoding of the Deal
+ *
+ * Returns
+ *   The error status of the decoding (nil if no error)
+ */
+func (p *Deal) GobDecode(buf []byte) error {
+	return gobDecode(p.suite, buf, p)
+}
+
+// Encodes a signature with its suite, implements gob.GobEncoder.
+func (p *signature) GobEncode() ([]byte, error) {
+	return gobEncode(p.suite, p)
+}
+
+// Decodes a signature encoded by GobEncode, implements gob.GobDecoder.
+func (p *signature) GobDecode(buf []byte) error {
+	return gobDecode(p.suite, buf, p)
+}
+
+// Encodes a blameProof with its suite, implements gob.GobEncoder.
+func (bp *blameProof) GobEncode() ([]byte, error) {
+	return gobEncode(bp.suite, bp)
+}
+
+// Decodes a blameProof encoded by GobEncode, implements gob.GobDecoder.
+func (bp *blameProof) GobDecode(buf []byte) error {
+	return gobDecode(bp.suite, buf, bp)
+}
+
+// Encodes a Response with its suite, implements gob.GobEncoder.
+func (r *Response) GobEncode() ([]byte, error) {
+	// Responses constructed locally only record the suite of their content
+	suite := r.suite
+	switch {
+	case suite != nil:
+	case r.rtype == signatureResponse:
+		suite = r.signature.suite
+	case r.rtype == blameProofResponse:
+		suite = r.blameProof.suite
+	default:
+		return nil, ErrInvalidResponse
+	}
+	return gobEncode(suite, r)
+}
+
+/* Decodes a Response encoded by GobEncode, implements gob.GobDecoder. The
+ * Response needs not be initialized with UnmarshalInit.
+ *
+ * Arguments
+ *    buf = the gob encoding of the Response
+ *
+ * Returns
+ *   The error status of the decoding (nil if no error)
+ */
+func (r *Response) GobDecode(buf []byte) error {
+	return gobDecode(r.suite, buf, r)
+}
@@ -0,0 +1,58 @@
+package poly
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+)
+
+// A message as sent over net/rpc, carrying Deal messages.
+type gobMessage struct {
+	Deal      *Deal
+	Responses []*Response
+}
+
+func TestDealGob(t *testing.T) {
+	sigResponse, _ := basicDeal.ProduceResponse(0, insurerKeys[0])
+	bproof, _ := basicDeal.blame(0, insurerKeys[0])
+	blameResponse := new(Response).constructBlameProofResponse(bproof)
+	msg := gobMessage{basicDeal, []*Response{sigResponse, blameResponse}}
+
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(&msg); err != nil {
+		t.Fatal(err)
+	}
+	var decoded gobMessage
+	if err := gob.NewDecoder(&b).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !basicDeal.Equal(decoded.Deal) {
+		t.Error("Deal differs after gob round trip")
+	}
+	// Response.Equal requires the very same suite instance, which a decoder
+	// looking up the suite by name cannot provide: compare encodings instead.
+	for i, response := range msg.Responses {
+		b1, _ := response.MarshalBinary()
+		b2, err := decoded.Responses[i].MarshalBinary()
+		if err != nil || !bytes.Equal(b1, b2) ||
+			decoded.Responses[i].suite.String() != suite.String() {
+			t.Error("Response differs after gob round trip", i)
+		}
+	}
+
+	// Error handling
+	buf, err := basicDeal.GobEncode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := new(Deal).UnmarshalInit(pt, r, numInsurers,
+		altSuite).GobDecode(buf); err != errorJSONSuite {
+		t.Error("Deal of another suite should be rejected")
+	}
+	if new(Deal).GobDecode(buf[:len(buf)-1]) == nil {
+		t.Error("Truncated Deal should be rejected")
+	}
+	if new(Deal).GobDecode([]byte{0xff}) != errorGob {
+		t.Error("Malformed encoding should be rejected")
+	}
+}
@@ -0,0 +1,332 @@
+package poly
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/suites"
+)
+
+/* This file implements the JSON encoding of the messages of deal.go, mostly
+ * meant for dashboards and debugging tools. Points and scalars are encoded
+ * with MarshalBinary and then base64, as for any []byte in encoding/json.
+ * Each message records the name of its suite. When decoding, the suite set
+ * with UnmarshalInit is used if any, and must match the recorded one;
+ * otherwise the suite is looked up among suites.All().
+ *
+ * Decoding is strict: unknown fields, missing fields and inconsistent lengths
+ * are rejected.
+ */
+
+var errorJSONSuite = errors.New("Ciphersuite of JSON message differs from the expected one")
+var errorJSONMissing = errors.New("Missing field in JSON message")
+
+// JSON representation of a Deal
+type dealJSON struct {
+	Suite    string   `json:"suite"`
+	T        int      `json:"t"`
+	R        int      `json:"r"`
+	N        int      `json:"n"`
+	Id       []byte   `json:"id"`
+	PubKey   []byte   `json:"pubKey"`
+	Commits  [][]byte `json:"commits"`
+	Insurers [][]byte `json:"insurers"`
+	Secrets  [][]byte `json:"secrets"`
+
+	// The commitments of the ShareProofs, omitted if the Deal has none
+	ShareKeys [][]byte `json:"shareKeys,omitempty"`
+}
+
+// JSON representation of a signature
+type signatureJSON struct {
+	Suite     string `json:"suite"`
+	Signature []byte `json:"signature"`
+}
+
+// JSON representation of a blameProof
+type blameProofJSON struct {
+	Suite     string     `json:"suite"`
+	DiffieKey []byte     `json:"diffieKey"`
+	Proof     []byte     `json:"proof"`
+	Signature *signature `json:"signature"`
+}
+
+// JSON representation of a Response, only one of the signature and blameProof
+// fields is set. The index is set for signatures bound to the index of their
+// insurer.
+type responseJSON struct {
+	Signature  json.RawMessage `json:"signature,omitempty"`
+	Index      *int            `json:"index,omitempty"`
+	BlameProof json.RawMessage `json:"blameProof,omitempty"`
+}
+
+// Decodes a JSON message into v, rejecting unknown fields and trailing data.
+func decodeJSON(buf []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("Trailing data after JSON message")
+	}
+	return nil
+}
+
+// Returns the suite to use for decoding a message recording the given name.
+func jsonSuite(suite abstract.Suite, name string) (abstract.Suite, error) {
+	if suite == nil {
+		return suites.StringToSuite(name)
+	}
+	if suite.String() != name {
+		return nil, errorJSONSuite
+	}
+	return suite, nil
+}
+
+func jsonPoints(points []abstract.Point) ([][]byte, error) {
+	bufs := make([][]byte, len(points))
+	for i, p := range points {
+		b, err := p.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		bufs[i] = b
+	}
+	return bufs, nil
+}
+
+func jsonPoint(suite abstract.Suite, b []byte) (abstract.Point, error) {
+	if b == nil {
+		return nil, errorJSONMissing
+	}
+	return protoPoint(suite, b)
+}
+
+/* Marshals a Deal into JSON.
+ *
+ * Returns
+ *   The JSON encoding of the Deal
+ *   The error status of the marshalling (nil if no error)
+ */
+func (p *Deal) MarshalJSON() ([]byte, error) {
+	if p.isPublic() {
+		return nil, ErrPublicDeal
+	}
+	id, err := p.id.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := p.pubKey.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	commits, err := jsonPoints(p.pubPoly.p)
+	if err != nil {
+		return nil, err
+	}
+	insurers, err := jsonPoints(p.insurers)
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([][]byte, len(p.secrets))
+	for i, s := range p.secrets {
+		if secrets[i], err = s.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
+	shareKeys, err := jsonPoints(p.shareKeys())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&dealJSON{p.suite.String(), p.t, p.r, p.n, id,
+		pubKey, commits, insurers, secrets, shareKeys})
+}
+
+/* Unmarshals a Deal from JSON. The Deal may be initialized with UnmarshalInit
+ * beforehand to enforce its suite, but its t, r and n parameters are always
+ * read from the message.
+ *
+ * Arguments
+ *    buf = the JSON encoding of the Deal
+ *
+ * Returns
+ *   The error status of the unmarshalling (nil if no error)
+ */
+func (p *Deal) UnmarshalJSON(buf []byte) error {
+	var d dealJSON
+	if err := decodeJSON(buf, &d); err != nil {
+		return err
+	}
+	suite, err := jsonSuite(p.suite, d.Suite)
+	if err != nil {
+		return err
+	}
+	if d.T != len(d.Commits) || d.N != len(d.Insurers) ||
+		d.N != len(d.Secrets) || checkParams(d.T, d.R, d.N) != nil {
+		return ErrInvalidDeal
+	}
+	if len(d.ShareKeys) != 0 && len(d.ShareKeys) != d.N {
+		return ErrInvalidShareProof
+	}
+	p.UnmarshalInit(d.T, d.R, d.N, suite)
+
+	if p.id, err = jsonPoint(suite, d.Id); err != nil {
+		return err
+	}
+	if p.pubKey, err = jsonPoint(suite, d.PubKey); err != nil {
+		return err
+	}
+	for i, b := range d.Commits {
+		if p.pubPoly.p[i], err = jsonPoint(suite, b); err != nil {
+			return err
+		}
+	}
+	p.insurers = make([]abstract.Point, p.n)
+	for i, b := range d.Insurers {
+		if p.insurers[i], err = jsonPoint(suite, b); err != nil {
+			return err
+		}
+	}
+	p.secrets = make([]abstract.Scalar, p.n)
+	for i, b := range d.Secrets {
+		if p.secrets[i], err = protoScalar(suite, b); err != nil {
+			return err
+		}
+	}
+	p.shareProofs = nil
+	if len(d.ShareKeys) != 0 {
+		p.shareProofs = &ShareProofs{suite, make([]abstract.Point, p.n)}
+		for i, b := range d.ShareKeys {
+			if p.shareProofs.keys[i], err = jsonPoint(suite, b); err != nil {
+				return err
+			}
+		}
+	}
+	return p.verifyDeal()
+}
+
+// Marshals a signature into JSON.
+func (p *signature) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&signatureJSON{p.suite.String(), p.signature})
+}
+
+// Unmarshals a signature from JSON.
+func (p *signature) UnmarshalJSON(buf []byte) error {
+	var s signatureJSON
+	if err := decodeJSON(buf, &s); err != nil {
+		return err
+	}
+	suite, err := jsonSuite(p.suite, s.Suite)
+	if err != nil {
+		return err
+	}
+	if s.Signature == nil {
+		return errorJSONMissing
+	}
+	p.suite = suite
+	p.signature = s.Signature
+	return nil
+}
+
+// Marshals a blameProof into JSON.
+func (bp *blameProof) MarshalJSON() ([]byte, error) {
+	key, err := bp.diffieKey.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&blameProofJSON{bp.suite.String(), key, bp.proof,
+		&bp.signature})
+}
+
+// Unmarshals a blameProof from JSON.
+func (bp *blameProof) UnmarshalJSON(buf []byte) error {
+	b := blameProofJSON{Signature: new(signature).UnmarshalInit(bp.suite)}
+	if err := decodeJSON(buf, &b); err != nil {
+		return err
+	}
+	suite, err := jsonSuite(bp.suite, b.Suite)
+	if err != nil {
+		return err
+	}
+	if b.Proof == nil || b.Signature.signature == nil {
+		return errorJSONMissing
+	}
+	if bp.diffieKey, err = jsonPoint(suite, b.DiffieKey); err != nil {
+		return err
+	}
+	bp.suite = suite
+	bp.proof = b.Proof
+	bp.signature = *b.Signature
+	return nil
+}
+
+/* Marshals a Response into JSON.
+ *
+ * Returns
+ *   The JSON encoding of the Response
+ *   The error status of the marshalling (nil if no error)
+ */
+func (r *Response) MarshalJSON() ([]byte, error) {
+	var rj responseJSON
+	var err error
+	switch r.rtype {
+	case signatureResponse:
+		rj.Signature, err = r.signature.MarshalJSON()
+		if r.indexed {
+			rj.Index = &r.index
+		}
+	case blameProofResponse:
+		rj.BlameProof, err = r.blameProof.MarshalJSON()
+	default:
+		err = ErrInvalidResponse
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&rj)
+}
+
+/* Unmarshals a Response from JSON. The Response may be initialized with
+ * UnmarshalInit beforehand to enforce its suite.
+ *
+ * Arguments
+ *    buf = the JSON encoding of the Response
+ *
+ * Returns
+ *   The error status of the unmarshalling (nil if no error)
+ */
+func (r *Response) UnmarshalJSON(buf []byte) error {
+	var rj responseJSON
+	if err := decodeJSON(buf, &rj); err != nil {
+		return err
+	}
+	switch {
+	case rj.Signature != nil && rj.BlameProof == nil:
+		sig := new(signature).UnmarshalInit(r.suite)
+		if err := sig.UnmarshalJSON(rj.Signature); err != nil {
+			return err
+		}
+		r.rtype = signatureResponse
+		r.suite = sig.suite
+		r.signature = sig
+		r.indexed = rj.Index != nil
+		r.index = 0
+		if r.indexed {
+			r.index = *rj.Index
+		}
+	case rj.BlameProof != nil && rj.Signature == nil && rj.Index == nil:
+		bp := new(blameProof).UnmarshalInit(r.suite)
+		if err := bp.UnmarshalJSON(rj.BlameProof); err != nil {
+			return err
+		}
+		r.rtype = blameProofResponse
+		r.suite = bp.suite
+		r.blameProof = bp
+	default:
+		return ErrInvalidResponse
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package poly
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDealJSON(t *testing.T) {
+	buf, err := json.Marshal(basicDeal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deal := new(Deal)
+	if err := json.Unmarshal(buf, deal); err != nil {
+		t.Fatal(err)
+	}
+	if !basicDeal.Equal(deal) {
+		t.Error("Deal differs after JSON round trip")
+	}
+
+	// Error handling
+	if err := json.Unmarshal(buf, new(Deal).UnmarshalInit(pt, r, numInsurers,
+		altSuite)); err != errorJSONSuite {
+		t.Error("Deal of another suite should be rejected")
+	}
+	bad := strings.Replace(string(buf), `"t":`, `"x":1,"t":`, 1)
+	if json.Unmarshal([]byte(bad), new(Deal)) == nil {
+		t.Error("Unknown field should be rejected")
+	}
+	bad = strings.Replace(string(buf), `"t":10`, `"t":9`, 1)
+	if json.Unmarshal([]byte(bad), new(Deal)) == nil {
+		t.Error("Inconsistent threshold should be rejected")
+	}
+}
+
+func TestResponseJSON(t *testing.T) {
+	sigResponse, _ := basicDeal.ProduceResponse(0, insurerKeys[0])
+	bproof, _ := basicDeal.blame(0, insurerKeys[0])
+	blameResponse := new(Response).constructBlameProofResponse(bproof)
+
+	for _, response := range []*Response{sigResponse, blameResponse} {
+		buf, err := json.Marshal(response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := new(Response).UnmarshalInit(suite)
+		if err := json.Unmarshal(buf, decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !response.Equal(decoded) {
+			t.Error("Response differs after JSON round trip")
+		}
+	}
+
+	if json.Unmarshal([]byte(`{}`), new(Response)) != ErrInvalidResponse {
+		t.Error("Empty Response should be rejected")
+	}
+}
@@ -0,0 +1,470 @@
+package poly
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+/* This file converts the messages of deal.go to and from the protocol buffer
+ * messages defined in deal.proto, so that Deals and Responses can be carried
+ * by gRPC services and evolve compatibly. The protobuf wire format is encoded
+ * by hand to avoid depending on a protobuf runtime: every field is either a
+ * varint or a length-delimited byte string.
+ *
+ * Points and scalars are encoded with MarshalBinary. Each message records the
+ * name of its suite so that the receiver can look it up when decoding.
+ */
+
+var errorProto = errors.New("Malformed protobuf message")
+var errorProtoSuite = errors.New("Unsupported ciphersuite in protobuf message")
+
+// Protobuf wire types used by deal.proto
+const (
+	protoVarint = 0
+	protoBytes  = 2
+)
+
+// Field numbers of the messages in deal.proto
+const (
+	protoDealSuite     = 1
+	protoDealT         = 2
+	protoDealR         = 3
+	protoDealN         = 4
+	protoDealId        = 5
+	protoDealPubKey    = 6
+	protoDealCommits   = 7
+	protoDealInsurers  = 8
+	protoDealSecrets   = 9
+	protoDealShareKeys = 10
+
+	protoSigSuite     = 1
+	protoSigSignature = 2
+
+	protoBlameSuite     = 1
+	protoBlameDiffieKey = 2
+	protoBlameProof     = 3
+	protoBlameSignature = 4
+
+	protoResponseType       = 1
+	protoResponseSignature  = 2
+	protoResponseBlameProof = 3
+	protoResponseIndex      = 4
+)
+
+// An encoder for protobuf messages.
+type protoEncoder struct {
+	buf []byte
+}
+
+func (e *protoEncoder) key(field, wire int) {
+	e.uvarint(uint64(field)<<3 | uint64(wire))
+}
+
+func (e *protoEncoder) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf = append(e.buf, b[:binary.PutUvarint(b[:], v)]...)
+}
+
+// Encodes a varint field, omitted if zero as in proto3.
+func (e *protoEncoder) varint(field int, v uint64) {
+	if v != 0 {
+		e.key(field, protoVarint)
+		e.uvarint(v)
+	}
+}
+
+// Encodes a length-delimited field. Elements of repeated fields must always
+// be encoded, even if empty.
+func (e *protoEncoder) bytes(field int, b []byte) {
+	e.key(field, protoBytes)
+	e.uvarint(uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *protoEncoder) point(field int, p abstract.Point) error {
+	b, err := p.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	e.bytes(field, b)
+	return nil
+}
+
+func (e *protoEncoder) scalar(field int, s abstract.Scalar) error {
+	b, err := s.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	e.bytes(field, b)
+	return nil
+}
+
+// A decoded protobuf field.
+type protoField struct {
+	num  int    // Field number
+	wire int    // Wire type
+	v    uint64 // Value of a varint field
+	b    []byte // Value of a length-delimited field
+}
+
+/* Splits a protobuf message into its fields. Fields of a wire type unused by
+ * deal.proto are skipped so that newer message versions can be decoded.
+ */
+func protoFields(buf []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(buf) > 0 {
+		key, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, errorProto
+		}
+		buf = buf[n:]
+		f := protoField{num: int(key >> 3), wire: int(key & 7)}
+		switch f.wire {
+		case protoVarint:
+			if f.v, n = binary.Uvarint(buf); n <= 0 {
+				return nil, errorProto
+			}
+			buf = buf[n:]
+		case protoBytes:
+			l, n := binary.Uvarint(buf)
+			if n <= 0 || l > uint64(len(buf)-n) {
+				return nil, errorProto
+			}
+			f.b = buf[n : n+int(l)]
+			buf = buf[n+int(l):]
+		case 1: // 64-bit
+			if len(buf) < 8 {
+				return nil, errorProto
+			}
+			buf = buf[8:]
+			continue
+		case 5: // 32-bit
+			if len(buf) < 4 {
+				return nil, errorProto
+			}
+			buf = buf[4:]
+			continue
+		default:
+			return nil, errorProto
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func protoSuite(suites map[string]abstract.Suite, name []byte) (abstract.Suite, error) {
+	suite := suites[string(name)]
+	if suite == nil {
+		return nil, errorProtoSuite
+	}
+	return suite, nil
+}
+
+func protoPoint(suite abstract.Suite, b []byte) (abstract.Point, error) {
+	p := suite.Point()
+	if err := p.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	if err := checkSubgroup(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func protoScalar(suite abstract.Suite, b []byte) (abstract.Scalar, error) {
+	s := suite.Scalar()
+	if err := s.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+/* Marshals a Deal into a protobuf Deal message.
+ *
+ * Returns
+ *   The encoded message
+ *   The error status of the marshalling (nil if no error)
+ */
+func (p *Deal) MarshalProto() ([]byte, error) {
+	if p.isPublic() {
+		return nil, ErrPublicDeal
+	}
+	e := &protoEncoder{}
+	e.bytes(protoDealSuite, []byte(p.suite.String()))
+	e.varint(protoDealT, uint64(p.t))
+	e.varint(protoDealR, uint64(p.r))
+	e.varint(protoDealN, uint64(p.n))
+	if err := e.point(protoDealId, p.id); err != nil {
+		return nil, err
+	}
+	if err := e.point(protoDealPubKey, p.pubKey); err != nil {
+		return nil, err
+	}
+	for _, c := range p.pubPoly.p {
+		if err := e.point(protoDealCommits, c); err != nil {
+			return nil, err
+		}
+	}
+	for _, ins := range p.insurers {
+		if err := e.point(protoDealInsurers, ins); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range p.secrets {
+		if err := e.scalar(protoDealSecrets, s); err != nil {
+			return nil, err
+		}
+	}
+	for _, K := range p.shareKeys() {
+		if err := e.point(protoDealShareKeys, K); err != nil {
+			return nil, err
+		}
+	}
+	return e.buf, nil
+}
+
+/* Unmarshals a Deal from a protobuf Deal message. Unlike UnmarshalBinary,
+ * the Deal needs not be initialized with UnmarshalInit: its parameters and
+ * suite are read from the message.
+ *
+ * Arguments
+ *    suites = the supported suites, indexed by name
+ *    buf    = the encoded message
+ *
+ * Returns
+ *   The error status of the unmarshalling (nil if no error)
+ */
+func (p *Deal) UnmarshalProto(suites map[string]abstract.Suite, buf []byte) error {
+	fields, err := protoFields(buf)
+	if err != nil {
+		return err
+	}
+	var suiteName []byte
+	var t, r, n uint64
+	var id, pubKey []byte
+	var commits, insurers, secrets, shareKeys [][]byte
+	for _, f := range fields {
+		switch f.num {
+		case protoDealSuite:
+			suiteName = f.b
+		case protoDealT:
+			t = f.v
+		case protoDealR:
+			r = f.v
+		case protoDealN:
+			n = f.v
+		case protoDealId:
+			id = f.b
+		case protoDealPubKey:
+			pubKey = f.b
+		case protoDealCommits:
+			commits = append(commits, f.b)
+		case protoDealInsurers:
+			insurers = append(insurers, f.b)
+		case protoDealSecrets:
+			secrets = append(secrets, f.b)
+		case protoDealShareKeys:
+			shareKeys = append(shareKeys, f.b)
+		}
+	}
+
+	suite, err := protoSuite(suites, suiteName)
+	if err != nil {
+		return err
+	}
+	if uint64(len(commits)) != t || uint64(len(insurers)) != n ||
+		uint64(len(secrets)) != n || r > n || n > MaxInsurers ||
+		checkParams(int(t), int(r), int(n)) != nil {
+		return ErrInvalidDeal
+	}
+	if len(shareKeys) != 0 && uint64(len(shareKeys)) != n {
+		return ErrInvalidShareProof
+	}
+	p.UnmarshalInit(int(t), int(r), int(n), suite)
+
+	if p.id, err = protoPoint(suite, id); err != nil {
+		return err
+	}
+	if p.pubKey, err = protoPoint(suite, pubKey); err != nil {
+		return err
+	}
+	for i, b := range commits {
+		if p.pubPoly.p[i], err = protoPoint(suite, b); err != nil {
+			return err
+		}
+	}
+	p.insurers = make([]abstract.Point, p.n)
+	for i, b := range insurers {
+		if p.insurers[i], err = protoPoint(suite, b); err != nil {
+			return err
+		}
+	}
+	p.secrets = make([]abstract.Scalar, p.n)
+	for i, b := range secrets {
+		if p.secrets[i], err = protoScalar(suite, b); err != nil {
+			return err
+		}
+	}
+	p.shareProofs = nil
+	if len(shareKeys) != 0 {
+		p.shareProofs = &ShareProofs{suite, make([]abstract.Point, p.n)}
+		for i, b := range shareKeys {
+			if p.shareProofs.keys[i], err = protoPoint(suite, b); err != nil {
+				return err
+			}
+		}
+	}
+	return p.verifyDeal()
+}
+
+// Marshals a signature into a protobuf Signature message.
+func (p *signature) marshalProto() []byte {
+	e := &protoEncoder{}
+	e.bytes(protoSigSuite, []byte(p.suite.String()))
+	e.bytes(protoSigSignature, p.signature)
+	return e.buf
+}
+
+// Unmarshals a signature from a protobuf Signature message.
+func (p *signature) unmarshalProto(suites map[string]abstract.Suite, buf []byte) error {
+	fields, err := protoFields(buf)
+	if err != nil {
+		return err
+	}
+	var suiteName []byte
+	p.signature = nil
+	for _, f := range fields {
+		switch f.num {
+		case protoSigSuite:
+			suiteName = f.b
+		case protoSigSignature:
+			p.signature = append([]byte{}, f.b...)
+		}
+	}
+	p.suite, err = protoSuite(suites, suiteName)
+	return err
+}
+
+// Marshals a blameProof into a protobuf BlameProof message.
+func (bp *blameProof) marshalProto() ([]byte, error) {
+	e := &protoEncoder{}
+	e.bytes(protoBlameSuite, []byte(bp.suite.String()))
+	if err := e.point(protoBlameDiffieKey, bp.diffieKey); err != nil {
+		return nil, err
+	}
+	e.bytes(protoBlameProof, bp.proof)
+	e.bytes(protoBlameSignature, bp.signature.marshalProto())
+	return e.buf, nil
+}
+
+// Unmarshals a blameProof from a protobuf BlameProof message.
+func (bp *blameProof) unmarshalProto(suites map[string]abstract.Suite, buf []byte) error {
+	fields, err := protoFields(buf)
+	if err != nil {
+		return err
+	}
+	var suiteName, key, sig []byte
+	bp.proof = nil
+	for _, f := range fields {
+		switch f.num {
+		case protoBlameSuite:
+			suiteName = f.b
+		case protoBlameDiffieKey:
+			key = f.b
+		case protoBlameProof:
+			bp.proof = append([]byte{}, f.b...)
+		case protoBlameSignature:
+			sig = f.b
+		}
+	}
+	if bp.suite, err = protoSuite(suites, suiteName); err != nil {
+		return err
+	}
+	if bp.diffieKey, err = protoPoint(bp.suite, key); err != nil {
+		return err
+	}
+	return bp.signature.unmarshalProto(suites, sig)
+}
+
+/* Marshals a Response into a protobuf Response message.
+ *
+ * Returns
+ *   The encoded message
+ *   The error status of the marshalling (nil if no error)
+ */
+func (r *Response) MarshalProto() ([]byte, error) {
+	e := &protoEncoder{}
+	if r.rtype == signatureResponse && r.indexed {
+		e.varint(protoResponseType, uint64(indexedSignatureResponse))
+	} else {
+		e.varint(protoResponseType, uint64(r.rtype))
+	}
+	switch r.rtype {
+	case signatureResponse:
+		e.bytes(protoResponseSignature, r.signature.marshalProto())
+		if r.indexed {
+			e.varint(protoResponseIndex, uint64(r.index))
+		}
+	case blameProofResponse:
+		b, err := r.blameProof.marshalProto()
+		if err != nil {
+			return nil, err
+		}
+		e.bytes(protoResponseBlameProof, b)
+	default:
+		return nil, ErrInvalidResponse
+	}
+	return e.buf, nil
+}
+
+/* Unmarshals a Response from a protobuf Response message. The Response needs
+ * not be initialized with UnmarshalInit.
+ *
+ * Arguments
+ *    suites = the supported suites, indexed by name
+ *    buf    = the encoded message
+ *
+ * Returns
+ *   The error status of the unmarshalling (nil if no error)
+ */
+func (r *Response) UnmarshalProto(suites map[string]abstract.Suite, buf []byte) error {
+	fields, err := protoFields(buf)
+	if err != nil {
+		return err
+	}
+	var sig, blame []byte
+	r.rtype = errorResponse
+	r.indexed = false
+	r.index = 0
+	for _, f := range fields {
+		switch f.num {
+		case protoResponseType:
+			r.rtype = responseType(f.v)
+		case protoResponseSignature:
+			sig = f.b
+		case protoResponseBlameProof:
+			blame = f.b
+		case protoResponseIndex:
+			r.index = int(uint32(f.v))
+		}
+	}
+	if r.rtype == indexedSignatureResponse {
+		r.rtype = signatureResponse
+		r.indexed = true
+	}
+	switch r.rtype {
+	case signatureResponse:
+		r.signature = new(signature)
+		err = r.signature.unmarshalProto(suites, sig)
+		r.suite = r.signature.suite
+	case blameProofResponse:
+		r.blameProof = new(blameProof)
+		err = r.blameProof.unmarshalProto(suites, blame)
+		r.suite = r.blameProof.suite
+	default:
+		err = ErrInvalidResponse
+	}
+	return err
+}
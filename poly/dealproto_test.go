@@ -0,0 +1,61 @@
+package poly
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+var protoSuites = map[string]abstract.Suite{suite.String(): suite}
+
+func TestDealProto(t *testing.T) {
+	buf, err := basicDeal.MarshalProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deal := new(Deal)
+	if err := deal.UnmarshalProto(protoSuites, buf); err != nil {
+		t.Fatal(err)
+	}
+	if !basicDeal.Equal(deal) {
+		t.Error("Deal differs after protobuf round trip")
+	}
+	if deal.verifyShare(0, insurerKeys[0]) != nil {
+		t.Error("Share of decoded Deal should verify")
+	}
+
+	// Error handling
+	if new(Deal).UnmarshalProto(map[string]abstract.Suite{}, buf) != errorProtoSuite {
+		t.Error("Unknown suite should be rejected")
+	}
+	if new(Deal).UnmarshalProto(protoSuites, buf[:len(buf)-1]) == nil {
+		t.Error("Truncated message should be rejected")
+	}
+}
+
+func TestResponseProto(t *testing.T) {
+	sigResponse, _ := basicDeal.ProduceResponse(0, insurerKeys[0])
+	bproof, _ := basicDeal.blame(0, insurerKeys[0])
+	blameResponse := new(Response).constructBlameProofResponse(bproof)
+
+	for _, response := range []*Response{sigResponse, blameResponse} {
+		buf, err := response.MarshalProto()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := new(Response)
+		if err := decoded.UnmarshalProto(protoSuites, buf); err != nil {
+			t.Fatal(err)
+		}
+		if !response.Equal(decoded) {
+			t.Error("Response differs after protobuf round trip")
+		}
+	}
+
+	if _, err := new(Response).MarshalProto(); err != ErrInvalidResponse {
+		t.Error("Uninitialized Response should not be marshalled")
+	}
+	if new(Response).UnmarshalProto(protoSuites, nil) != ErrInvalidResponse {
+		t.Error("Empty message should be rejected")
+	}
+}
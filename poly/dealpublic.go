@@ -0,0 +1,120 @@
+package poly
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+)
+
+/* This file implements the public view of a Deal, for clients. Clients only
+ * need the parameters, the keys, the public polynomial and the insurers of a
+ * Deal to verify the signatures of the insurers and the shares they reveal,
+ * but not the n encrypted secrets, which make up most of the Deal. The public
+ * view replaces the secrets, and the ShareProofs if any, with their digest,
+ * which the signatures of the insurers cover (see Deal.contentDigest).
+ *
+ * A State can be initialized with the public view of a Deal: it accepts the
+ * signatures produced by ProduceResponse and tells whether the Deal is
+ * certified, but it cannot verify blameProofs nor signatures of earlier
+ * versions, which cover the secrets themselves, and fails with ErrPublicDeal.
+ */
+
+/* Returns the number of bytes used by the public view of the Deal when
+ * marshalled with MarshalPublic
+ *
+ * Returns
+ *   The marshal size
+ *
+ * Note
+ *   This function can be used after UnmarshalInit, with the same assumption
+ *   as MarshalSize.
+ */
+func (p *Deal) PublicMarshalSize() int {
+	size := 1 + 2*uint32Size + 2*p.suite.PointLen() +
+		uint32Size + p.pubPoly.MarshalSize() + sha256.Size
+	for i := 0; i < p.n; i++ {
+		size += uint32Size
+		if p.insurers != nil {
+			size += p.insurers[i].MarshalSize()
+		} else {
+			size += p.suite.PointLen()
+		}
+	}
+	return size
+}
+
+/* Marshals the public view of a Deal into a byte array
+ *
+ * Returns
+ *   A buffer of the marshalled public view
+ *   The error status of the marshalling (nil if no error)
+ *
+ * Note
+ *   The buffer is formatted as follows:
+ *
+ *      ||version||id||pubKey||pubPoly||==insurers_array==||secrets_digest||
+ *
+ *   where version is the single byte DealVersion, the fields up to the
+ *   insurers are length-prefixed as in MarshalBinary, and secrets_digest is
+ *   the SHA-256 digest of the secrets.
+ */
+func (p *Deal) MarshalPublic() ([]byte, error) {
+	digest, err := p.secretsHash()
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.WriteByte(DealVersion)
+	if err := p.marshalFields(&b, false); err != nil {
+		return nil, err
+	}
+	b.Write(digest)
+	return b.Bytes(), nil
+}
+
+/* Unmarshals the public view of a Deal from a byte buffer. The Deal must be
+ * initialized with UnmarshalInit beforehand, as for UnmarshalBinary.
+ *
+ * Arguments
+ *    buf = the buffer containing the public view
+ *
+ * Returns
+ *   The error status of the unmarshalling (nil if no error)
+ */
+func (p *Deal) UnmarshalPublic(buf []byte) error {
+	if len(buf) == 0 {
+		return errors.New("Buffer size too small")
+	}
+	// The public view is laid out the same in version 2 of the format
+	if buf[0] != DealVersion && buf[0] != 2 {
+		return ErrUnsupportedVersion
+	}
+	r := bytes.NewReader(buf[1:])
+	if _, err := p.unmarshalFields(r, false); err != nil {
+		return err
+	}
+	if r.Len() != sha256.Size {
+		return errors.New("Buffer size does not match the Deal parameters")
+	}
+	p.secrets = nil
+	p.secretsDigest = make([]byte, sha256.Size)
+	r.Read(p.secretsDigest)
+	return p.verifyDeal()
+}
+
+/* Returns the public view of the Deal, as decoded from MarshalPublic.
+ *
+ * Returns
+ *   The public view, or an error if computing the digest of the secrets failed
+ */
+func (p *Deal) Public() (*Deal, error) {
+	digest, err := p.secretsHash()
+	if err != nil {
+		return nil, err
+	}
+	c := p.clone()
+	c.secrets = nil
+	c.secretsDigest = digest
+	c.shareProofs = nil
+	return &c, nil
+}
@@ -0,0 +1,86 @@
+package poly
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDealPublic(t *testing.T) {
+	deal := new(Deal).ConstructDeal(secretKey, DealerKey, pt, r, insurerList)
+	buf, err := deal.MarshalPublic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, _ := deal.MarshalBinary()
+	if len(buf) != deal.PublicMarshalSize() || len(buf) >= len(full) {
+		t.Error("Wrong size of the public view", len(buf), len(full))
+	}
+
+	public := new(Deal).UnmarshalInit(pt, r, numInsurers, suite)
+	if err := public.UnmarshalPublic(buf); err != nil {
+		t.Fatal(err)
+	}
+	view, _ := deal.Public()
+	if !public.Equal(view) || public.Equal(deal) {
+		t.Error("Public view differs after round trip")
+	}
+	id, _ := deal.CertificationId()
+	publicId, _ := public.CertificationId()
+	if !bytes.Equal(id, publicId) {
+		t.Error("Public view should have the same certification id")
+	}
+	for _, b := range [][]byte{buf[:len(buf)-1], append(buf, 0)} {
+		if err := new(Deal).UnmarshalInit(pt, r, numInsurers, suite).
+			UnmarshalPublic(b); err == nil {
+			t.Error("Public view of the wrong size should be rejected")
+		}
+	}
+	if _, err := public.MarshalBinary(); err != ErrPublicDeal {
+		t.Error("Public view should not be marshalled as a Deal", err)
+	}
+	if _, err := public.RevealShare(0, insurerKeys[0]); err != ErrPublicDeal {
+		t.Error("Public view should not reveal shares", err)
+	}
+
+	// Clients can verify the signatures of the insurers
+	state := new(State).Init(*public)
+	for i := 0; i < r; i++ {
+		response, err := deal.ProduceResponse(i, insurerKeys[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := state.AddResponse(i, response); err != nil {
+			t.Fatal("Signature should be accepted", err)
+		}
+	}
+	if err := state.DealCertified(); err != nil {
+		t.Error("Deal should be certified", err)
+	}
+	share, _ := deal.RevealShare(0, insurerKeys[0])
+	if err := public.VerifyRevealedShare(0, share); err != nil {
+		t.Error("Revealed share should be valid", err)
+	}
+
+	// But not blames, nor signatures of earlier versions
+	bproof, _ := deal.blame(r, insurerKeys[r])
+	err = state.AddResponse(r, new(Response).constructBlameProofResponse(bproof))
+	if err != ErrPublicDeal {
+		t.Error("Blames cannot be verified on the public view", err)
+	}
+	sig, _ := deal.sign(r, insurerKeys[r], approveDomain, testMsg)
+	err = state.AddResponse(r, new(Response).constructSignatureResponse(sig))
+	if err != ErrInvalidIndex {
+		t.Error("Legacy signatures should be rejected", err)
+	}
+
+	// The public view covers the secrets
+	buf[len(buf)-1] ^= 1
+	other := new(Deal).UnmarshalInit(pt, r, numInsurers, suite)
+	if err := other.UnmarshalPublic(buf); err != nil {
+		t.Fatal(err)
+	}
+	response, _ := deal.ProduceResponse(0, insurerKeys[0])
+	if new(State).Init(*other).AddResponse(0, response) == nil {
+		t.Error("Signature should not verify for other secrets")
+	}
+}
@@ -0,0 +1,194 @@
+package poly
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/dedis/crypto/random"
+)
+
+/* Fuzz targets for the decoders of this package. Every decoder must reject
+ * malformed input with an error, never with a panic, and without allocating
+ * memory out of proportion with its input. Run them with e.g.
+ *
+ *    go test -fuzz=FuzzDeal -run=^$ github.com/dedis/crypto/poly
+ *
+ * Plain go test only runs the seeds, which are valid encodings.
+ */
+
+func FuzzDeal(f *testing.F) {
+	seeds := [][]byte{}
+	add := func(buf []byte, err error) {
+		if err != nil {
+			f.Fatal(err)
+		}
+		seeds = append(seeds, buf)
+	}
+	add(basicDeal.MarshalBinary())
+	add(basicDeal.MarshalPublic())
+	add(basicDeal.MarshalJSON())
+	add(basicDeal.MarshalProto())
+	add(basicDeal.GobEncode())
+	verifiable := new(Deal).SetVerifiable().ConstructDeal(secretKey,
+		DealerKey, pt, r, insurerList)
+	add(verifiable.MarshalBinary())
+	add(verifiable.MarshalJSON())
+	add(verifiable.MarshalProto())
+	var b bytes.Buffer
+	if err := basicDeal.MarshalSuite(&b, suite); err != nil {
+		f.Fatal(err)
+	}
+	seeds = append(seeds, b.Bytes())
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		newDeal := func() *Deal {
+			return new(Deal).UnmarshalInit(pt, r, numInsurers, suite)
+		}
+		newDeal().UnmarshalBinary(buf)
+		newDeal().UnmarshalFrom(bytes.NewReader(buf))
+		newDeal().UnmarshalPublic(buf)
+		newDeal().UnmarshalJSON(buf)
+		new(Deal).UnmarshalProto(protoSuites, buf)
+		new(Deal).UnmarshalSuite(bytes.NewReader(buf), suite)
+		new(Deal).GobDecode(buf)
+	})
+}
+
+func FuzzResponse(f *testing.F) {
+	response, err := basicDeal.ProduceResponse(0, insurerKeys[0])
+	if err != nil {
+		f.Fatal(err)
+	}
+	bproof, err := basicDeal.blame(0, insurerKeys[0])
+	if err != nil {
+		f.Fatal(err)
+	}
+	blame := new(Response).constructBlameProofResponse(bproof)
+	for _, res := range []*Response{response, blame} {
+		for _, enc := range []func() ([]byte, error){res.MarshalBinary,
+			res.MarshalJSON, res.MarshalProto, res.GobEncode} {
+			buf, err := enc()
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(buf)
+		}
+	}
+	s, _ := basicDeal.sign(0, insurerKeys[0], approveDomain, testMsg)
+	sig, _ := s.MarshalBinary()
+	f.Add(sig)
+	bp, _ := bproof.MarshalBinary()
+	f.Add(bp)
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		new(Response).UnmarshalInit(suite).UnmarshalBinary(buf)
+		new(Response).UnmarshalInit(suite).UnmarshalFrom(bytes.NewReader(buf))
+		new(Response).UnmarshalInit(suite).UnmarshalJSON(buf)
+		new(Response).UnmarshalProto(protoSuites, buf)
+		new(Response).GobDecode(buf)
+		new(signature).UnmarshalInit(suite).UnmarshalBinary(buf)
+		new(signature).UnmarshalInit(suite).UnmarshalFrom(bytes.NewReader(buf))
+		new(signature).UnmarshalInit(suite).UnmarshalJSON(buf)
+		new(blameProof).UnmarshalInit(suite).UnmarshalBinary(buf)
+		new(blameProof).UnmarshalInit(suite).UnmarshalFrom(bytes.NewReader(buf))
+		new(blameProof).UnmarshalInit(suite).UnmarshalJSON(buf)
+	})
+}
+
+func FuzzRevocation(f *testing.F) {
+	rev, err := basicDeal.Revoke(DealerKey)
+	if err != nil {
+		f.Fatal(err)
+	}
+	buf, _ := rev.MarshalBinary()
+	f.Add(buf)
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		new(Revocation).UnmarshalInit(suite).UnmarshalBinary(buf)
+	})
+}
+
+func FuzzReconstructionRequest(f *testing.F) {
+	req, err := NewReconstructionRequest(basicDeal, DealerKey.Public)
+	if err != nil {
+		f.Fatal(err)
+	}
+	req.Sign(0, insurerKeys[0])
+	req.Sign(1, insurerKeys[1])
+	buf, _ := req.MarshalBinary()
+	f.Add(buf)
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		new(ReconstructionRequest).UnmarshalInit(suite).UnmarshalBinary(buf)
+	})
+}
+
+func FuzzBlameBundle(f *testing.F) {
+	deal := new(Deal).ConstructDeal(secretKey, DealerKey, 3, 4, insurerList[:5])
+	deal.secrets[0] = suite.Scalar().Pick(random.Stream)
+	response, err := deal.ProduceResponse(0, insurerKeys[0])
+	if err != nil {
+		f.Fatal(err)
+	}
+	dealerSig, _ := deal.DealerSign(DealerKey)
+	bundle, err := NewBlameBundle(deal, dealerSig, 0, response)
+	if err != nil {
+		f.Fatal(err)
+	}
+	buf, _ := bundle.MarshalBinary()
+	f.Add(buf)
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		b := new(BlameBundle).UnmarshalInit(suite)
+		if b.UnmarshalBinary(buf) == nil {
+			VerifyBlameBundle(suite, b)
+		}
+	})
+}
+
+func FuzzPubPoly(f *testing.F) {
+	buf, _ := basicDeal.pubPoly.MarshalBinary()
+	f.Add(buf)
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		new(PubPoly).Init(suite, pt, nil).UnmarshalBinary(buf)
+		new(PubPoly).Init(suite, pt, nil).UnmarshalFrom(bytes.NewReader(buf))
+	})
+}
+
+// Verifies that forged lengths and parameters are rejected before allocating
+func TestUnmarshalLimits(t *testing.T) {
+	huge := make([]byte, 4)
+	binary.LittleEndian.PutUint32(huge, 0xffffffff)
+	if _, err := new(signature).UnmarshalInit(suite).
+		UnmarshalFrom(bytes.NewReader(huge)); err == nil {
+		t.Error("Forged signature length should be rejected")
+	}
+	if _, err := new(Response).UnmarshalInit(suite).
+		UnmarshalFrom(bytes.NewReader(huge)); err == nil {
+		t.Error("Forged response length should be rejected")
+	}
+	if _, err := new(blameProof).UnmarshalInit(suite).
+		UnmarshalFrom(bytes.NewReader(append(huge, huge...))); err == nil {
+		t.Error("Forged blameProof length should be rejected")
+	}
+
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, []uint32{1, 2, MaxInsurers + 1})
+	if err := new(Deal).UnmarshalSuite(&b, suite); err != ErrInvalidDeal {
+		t.Error("Too many insurers should be rejected", err)
+	}
+
+	// A forged field length in a Deal
+	buf := []byte{DealVersion, 0xff, 0xff, 0xff, 0xff}
+	if err := new(Deal).UnmarshalInit(pt, r, numInsurers, suite).
+		UnmarshalBinary(buf); err == nil {
+		t.Error("Forged field length should be rejected")
+	}
+
+	// An unknown response type
+	res, _ := basicDeal.ProduceResponse(0, insurerKeys[0])
+	buf, _ = res.MarshalBinary()
+	binary.LittleEndian.PutUint32(buf[uint32Size:], 42)
+	if err := new(Response).UnmarshalInit(suite).UnmarshalBinary(buf); err != ErrInvalidResponse {
+		t.Error("Unknown response type should be rejected", err)
+	}
+}
@@ -0,0 +1,150 @@
+package poly
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+)
+
+/* An InsurerSession lets an insurer process a batch of Deals in one call.
+ * It takes the long-term keypair of the insurer once and, for every share
+ * of every Deal that the insurer owns, verifies the share and produces either
+ * a signature approving the Deal or a blameProof blaming the Dealer, exactly
+ * as Deal.ProduceResponse does. Deals are processed concurrently.
+ */
+type InsurerSession struct {
+
+	// The long-term keypair of the insurer
+	key *config.KeyPair
+
+	// The number of goroutines used to process Deals
+	workers int
+}
+
+/* The result of processing one share of a Deal in an InsurerSession.
+ * Exactly one of Response and Err is non-nil.
+ */
+type InsurerResponse struct {
+
+	// The index of the Deal in the batch given to InsurerSession.Respond
+	Deal int
+
+	// The index of the insurer's share in the Deal
+	Index int
+
+	// The signature or blameProof Response, as from Deal.ProduceResponse
+	Response *Response
+
+	// The error preventing a Response to be produced
+	Err error
+}
+
+/* Creates a new InsurerSession.
+ *
+ * Arguments
+ *    key = the long-term keypair of the insurer
+ *
+ * Returns
+ *   An InsurerSession using GOMAXPROCS goroutines
+ */
+func NewInsurerSession(key *config.KeyPair) *InsurerSession {
+	return &InsurerSession{key, runtime.GOMAXPROCS(0)}
+}
+
+/* Sets the number of goroutines used to process Deals.
+ *
+ * Arguments
+ *    k = the number of goroutines, values <= 1 mean sequential processing
+ *
+ * Returns
+ *   The InsurerSession itself
+ */
+func (s *InsurerSession) SetConcurrency(k int) *InsurerSession {
+	s.workers = k
+	return s
+}
+
+/* Returns the indices of the shares of a Deal owned by the insurer, i.e.,
+ * the indices at which the Deal lists the insurer's public key. The keys are
+ * compared by abstract.PointKey, as Point.Equal may normalize the points it
+ * compares.
+ *
+ * Arguments
+ *    deal = the Deal
+ *    key  = the PointKey of the long-term public key of the insurer
+ */
+func indices(deal *Deal, key [abstract.PointKeySize]byte) []int {
+	var idx []int
+	for i, ins := range deal.insurers {
+		if abstract.PointKey(ins) == key {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+/* Verifies and responds to all the shares owned by the insurer in a batch
+ * of Deals. A Deal that does not list the insurer yields a single result
+ * with Index -1 and the error ErrNotInsurer.
+ *
+ * Arguments
+ *    deals = the Deals to respond to
+ *
+ * Returns
+ *   The results, ordered by Deal and then by share index
+ */
+func (s *InsurerSession) Respond(deals []*Deal) []InsurerResponse {
+	// The indices are computed before starting the workers, which share
+	// the keypair of the insurer.
+	key := abstract.PointKey(s.key.Public)
+	owned := make([][]int, len(deals))
+	for d, deal := range deals {
+		owned[d] = indices(deal, key)
+	}
+
+	results := make([][]InsurerResponse, len(deals))
+	respond := func(d int) {
+		deal := deals[d]
+		idx := owned[d]
+		if len(idx) == 0 {
+			results[d] = []InsurerResponse{{d, -1, nil, ErrNotInsurer}}
+			return
+		}
+		results[d] = make([]InsurerResponse, len(idx))
+		for j, i := range idx {
+			response, err := deal.ProduceResponse(i, s.key)
+			results[d][j] = InsurerResponse{d, i, response, err}
+		}
+	}
+
+	if s.workers <= 1 {
+		for d := range deals {
+			respond(d)
+		}
+	} else {
+		jobs := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < s.workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for d := range jobs {
+					respond(d)
+				}
+			}()
+		}
+		for d := range deals {
+			jobs <- d
+		}
+		close(jobs)
+		wg.Wait()
+	}
+
+	var all []InsurerResponse
+	for _, r := range results {
+		all = append(all, r...)
+	}
+	return all
+}
@@ -0,0 +1,56 @@
+package poly
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+// Verify that an InsurerSession responds to every share it owns, blames
+// bad shares and reports Deals it does not insure.
+func TestInsurerSession(t *testing.T) {
+	key := insurerKeys[0]
+
+	// A Deal where the insurer owns shares 0 and 3
+	list := make([]abstract.Point, numInsurers)
+	copy(list, insurerList)
+	list[3] = key.Public
+	twice := new(Deal).ConstructDeal(secretKey, DealerKey, pt, r, list)
+
+	// A Deal with a bad share 0
+	bad := new(Deal).ConstructDeal(secretKey, DealerKey, pt, r, insurerList)
+	bad.secrets[0] = suite.Scalar().Zero()
+
+	// A Deal the insurer is not part of
+	other := new(Deal).ConstructDeal(secretKey, DealerKey, pt, r,
+		insurerList[1:])
+
+	deals := []*Deal{basicDeal, twice, bad, other}
+	results := NewInsurerSession(key).SetConcurrency(3).Respond(deals)
+	if len(results) != 5 {
+		t.Fatal("Expected 5 results, got", len(results))
+	}
+	expected := []struct {
+		deal, index int
+		rtype       responseType
+	}{
+		{0, 0, signatureResponse},
+		{1, 0, signatureResponse},
+		{1, 3, signatureResponse},
+		{2, 0, blameProofResponse},
+	}
+	for k, e := range expected {
+		res := results[k]
+		if res.Deal != e.deal || res.Index != e.index || res.Err != nil ||
+			res.Response.rtype != e.rtype {
+			t.Error("Unexpected result", k, res)
+		}
+		state := new(State).Init(*deals[res.Deal])
+		if err := state.AddResponse(res.Index, res.Response); err != nil {
+			t.Error("Response", k, "should be accepted:", err)
+		}
+	}
+	if results[4].Deal != 3 || results[4].Err != ErrNotInsurer {
+		t.Error("Deal without the insurer should be reported")
+	}
+}
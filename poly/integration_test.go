@@ -0,0 +1,295 @@
+package poly
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/random"
+)
+
+/* An end-to-end run of a Deal between a Dealer, insurers and clients running
+ * in their own goroutines and exchanging marshalled messages over channels,
+ * as they would over the network:
+ *
+ *   1) The Dealer sends the Deal to the insurers and the clients. The shares
+ *      are encrypted for the insurers, and clients need them to verify the
+ *      blameProofs.
+ *   2) Every insurer verifies its share and responds with a signature, or
+ *      with a blameProof if its share is corrupted.
+ *   3) The responses are broadcast to all parties, which add them to their
+ *      States until the Deal has enough signatures.
+ *   4) The clients sign a ReconstructionRequest and send it to the insurers.
+ *   5) The insurers reveal their shares, and the clients verify them and
+ *      recover the secret.
+ *
+ * Run it with -race to check that the parties share no state.
+ */
+
+const (
+	integrationT       = 3
+	integrationR       = 4
+	integrationN       = 6
+	integrationClients = 2
+)
+
+// A marshalled message and the index of its sender
+type wireMsg struct {
+	from int
+	buf  []byte
+}
+
+// The channels connecting the parties of an integration run
+type network struct {
+	deals     []chan []byte  // Dealer to each insurer, then each client
+	responses chan wireMsg   // insurers to the broadcaster
+	inboxes   []chan wireMsg // broadcaster to each insurer, then each client
+	requests  []chan []byte  // last client to each insurer
+	signing   chan []byte    // clients signing the request in turn
+	shares    chan wireMsg   // insurers to the last client
+	errors    chan error     // any party to the test
+	certified chan error     // clients to the test, see State.DealCertified
+	secret    chan abstract.Scalar
+}
+
+func newNetwork() *network {
+	net := &network{
+		deals:     make([]chan []byte, integrationN+integrationClients),
+		responses: make(chan wireMsg),
+		inboxes:   make([]chan wireMsg, integrationN+integrationClients),
+		requests:  make([]chan []byte, integrationN),
+		signing:   make(chan []byte),
+		shares:    make(chan wireMsg),
+		errors:    make(chan error, integrationN+integrationClients+1),
+		certified: make(chan error, integrationClients),
+		secret:    make(chan abstract.Scalar, 1),
+	}
+	for k := range net.deals {
+		net.deals[k] = make(chan []byte, 1)
+		net.inboxes[k] = make(chan wireMsg, integrationN)
+	}
+	for i := range net.requests {
+		net.requests[i] = make(chan []byte, 1)
+	}
+	return net
+}
+
+// Constructs the Deal, corrupts the shares of the given insurers, and sends it
+func runDealer(net *network, secretPair, longPair *config.KeyPair,
+	insurers []abstract.Point, corrupted []int) {
+	deal := new(Deal).ConstructDeal(secretPair, longPair, integrationT,
+		integrationR, insurers)
+	for _, i := range corrupted {
+		deal.secrets[i] = deal.suite.Scalar().Pick(random.Stream)
+	}
+	buf, err := deal.MarshalBinary()
+	if err != nil {
+		net.errors <- err
+		return
+	}
+	for _, c := range net.deals {
+		c <- buf
+	}
+}
+
+// Forwards every response to every insurer and client
+func runBroadcaster(net *network) {
+	for k := 0; k < integrationN; k++ {
+		msg := <-net.responses
+		for _, c := range net.inboxes {
+			c <- msg
+		}
+	}
+}
+
+/* Collects the responses of all the insurers into a new State of the Deal.
+ *
+ * Arguments
+ *    deal  = the Deal
+ *    inbox = the channel of the broadcast responses
+ *
+ * Returns
+ *   The State, or an error if a response is invalid
+ */
+func collectResponses(deal *Deal, inbox chan wireMsg) (*State, error) {
+	state := new(State).Init(*deal)
+	for k := 0; k < integrationN; k++ {
+		msg := <-inbox
+		response := new(Response).UnmarshalInit(deal.suite)
+		if err := response.UnmarshalBinary(msg.buf); err != nil {
+			return nil, err
+		}
+		if err := state.AddResponse(msg.from, response); err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
+
+// Runs insurer i, which reveals its share on request of the clients
+func runInsurer(net *network, i int, key *config.KeyPair, clients []abstract.Point) {
+	deal := new(Deal).UnmarshalInit(integrationT, integrationR, integrationN, key.Suite)
+	if err := deal.UnmarshalBinary(<-net.deals[i]); err != nil {
+		net.errors <- err
+		return
+	}
+	response, err := deal.ProduceResponse(i, key)
+	if err != nil {
+		net.errors <- err
+		return
+	}
+	buf, err := response.MarshalBinary()
+	if err != nil {
+		net.errors <- err
+		return
+	}
+	net.responses <- wireMsg{i, buf}
+
+	state, err := collectResponses(deal, net.inboxes[i])
+	if err != nil {
+		net.errors <- err
+		return
+	}
+	state.SetClientQuorum(clients, integrationClients)
+	req := new(ReconstructionRequest).UnmarshalInit(key.Suite)
+	if err := req.UnmarshalBinary(<-net.requests[i]); err != nil {
+		net.errors <- err
+		return
+	}
+
+	// An insurer with a corrupted share refuses to reveal it, and answers
+	// with an empty message.
+	share, err := state.AuthorizedRevealShare(i, key, req)
+	buf = nil
+	if err == nil {
+		buf, err = share.MarshalBinary()
+	} else if err == ErrCorruptedShare {
+		err = nil
+	}
+	net.shares <- wireMsg{i, buf}
+	net.errors <- err
+}
+
+/* Runs client j. Clients sign the ReconstructionRequest in turn, and the last
+ * one, the requester, sends it to the insurers and recovers the secret from
+ * their shares.
+ */
+func runClient(net *network, j int, key *config.KeyPair, requester abstract.Point) {
+	deal := new(Deal).UnmarshalInit(integrationT, integrationR, integrationN, key.Suite)
+	if err := deal.UnmarshalBinary(<-net.deals[integrationN+j]); err != nil {
+		net.errors <- err
+		return
+	}
+	state, err := collectResponses(deal, net.inboxes[integrationN+j])
+	if err != nil {
+		net.errors <- err
+		return
+	}
+	if err := state.SufficientSignatures(); err != nil {
+		net.errors <- err
+		return
+	}
+	net.certified <- state.DealCertified()
+
+	req := new(ReconstructionRequest).UnmarshalInit(key.Suite)
+	if j == 0 {
+		req, err = NewReconstructionRequest(deal, requester)
+	} else {
+		err = req.UnmarshalBinary(<-net.signing)
+	}
+	if err != nil {
+		net.errors <- err
+		return
+	}
+	req.Sign(j, key)
+	buf, err := req.MarshalBinary()
+	if err != nil {
+		net.errors <- err
+		return
+	}
+	if j < integrationClients-1 {
+		net.signing <- buf
+		net.errors <- nil
+		return
+	}
+	for _, c := range net.requests {
+		c <- buf
+	}
+
+	// Only valid shares are used, whatever the insurers send
+	shares := new(PriShares)
+	shares.Empty(key.Suite, integrationT, integrationN)
+	for k := 0; k < integrationN; k++ {
+		msg := <-net.shares
+		wrapped := key.Suite.Scalar()
+		if wrapped.UnmarshalBinary(msg.buf) != nil {
+			continue
+		}
+		share, err := deal.UnwrapRevealedShare(msg.from, key, wrapped)
+		if err != nil || deal.VerifyRevealedShare(msg.from, share) != nil {
+			continue
+		}
+		shares.SetShare(msg.from, share)
+	}
+	secret, err := shares.SecretOrError()
+	net.secret <- secret
+	net.errors <- err
+}
+
+/* Runs a Deal over goroutines and channels, and checks that the clients
+ * recover the dealt secret.
+ *
+ * Arguments
+ *    corrupted = the indices of the insurers receiving a corrupted share
+ *
+ * Returns
+ *   The certification status of the Deal for the clients
+ */
+func runIntegration(t *testing.T, corrupted []int) []error {
+	secretPair := produceKeyPair()
+	clientKeys := make([]*config.KeyPair, integrationClients)
+	clients := make([]abstract.Point, integrationClients)
+	for j := range clientKeys {
+		clientKeys[j] = produceKeyPair()
+		clients[j] = clientKeys[j].Public
+	}
+
+	net := newNetwork()
+	go runDealer(net, secretPair, DealerKey, insurerList[:integrationN], corrupted)
+	go runBroadcaster(net)
+	for i := 0; i < integrationN; i++ {
+		go runInsurer(net, i, insurerKeys[i], clients)
+	}
+	for j := 0; j < integrationClients; j++ {
+		go runClient(net, j, clientKeys[j], clients[integrationClients-1])
+	}
+	for k := 0; k < integrationN+integrationClients; k++ {
+		if err := <-net.errors; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if secret := <-net.secret; !secret.Equal(secretPair.Secret) {
+		t.Error("Recovered secret differs from the dealt secret")
+	}
+	certified := make([]error, integrationClients)
+	for j := range certified {
+		certified[j] = <-net.certified
+	}
+	return certified
+}
+
+func TestIntegration(t *testing.T) {
+	for _, err := range runIntegration(t, nil) {
+		if err != nil {
+			t.Error("Deal should be certified", err)
+		}
+	}
+
+	// The blamed Deal has enough signatures to be reconstructed from the
+	// valid shares, but is not certified.
+	for _, err := range runIntegration(t, []int{1, 4}) {
+		if err != ErrBlamed {
+			t.Error("Deal should be blamed", err)
+		}
+	}
+}
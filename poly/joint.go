@@ -125,7 +125,10 @@ func (r *Receiver) ProduceSharedSecret() (*SharedSecret, error) {
 	for index := range r.deals {
 		// Compute secret shares of the shared secret = sum of the respectives shares of peer i
 		// For peer i , s = SUM fj(i)
-		s := r.deals[index].RevealShare(r.index, r.key)
+		s, err := r.deals[index].RevealShare(r.index, r.key)
+		if err != nil {
+			return nil, err
+		}
 		//s, e := r.Dealers[index].State.RevealShare(r.index, r.Key)
 		share.Add(share, s)
 
@@ -0,0 +1,336 @@
+package poly
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+)
+
+// Domains of the signatures of insurers approving all the Deals of a MultiDeal
+// at once or blaming their Dealer, see MultiDeal.multiMsg
+const (
+	multiApproveDomain = "poly.MultiDeal.Approve"
+	multiBlameDomain   = "poly.MultiDeal.Blame"
+)
+
+/* A MultiDeal lets a Dealer deal several secrets to the same insurers under a
+ * single certification round. Each secret is dealt in its own Deal, with the
+ * same parameters, Dealer key and insurers, but insurers verify all their
+ * shares at once and sign a single message covering all the Deals (see
+ * multiMsg). A MultiState tracks the certification of all the Deals from these
+ * signatures, and exposes one State per Deal to reveal shares secret by secret.
+ *
+ * Dealers construct a MultiDeal with ConstructMultiDeal and send its Deals as
+ * usual. Insurers and clients rebuild it from the received Deals with
+ * NewMultiDeal.
+ *
+ * If the share of an insurer is invalid in some of the Deals, the insurer
+ * sends a blameProof instead. The blameProof discloses the secret shared by
+ * the insurer and the Dealer, which is the same for all the Deals, so
+ * MultiState.AddResponse verifies it against every Deal and blames exactly
+ * those with an invalid share. The insurer can still approve the other Deals
+ * one by one with Deal.ProduceResponse, see MultiState.AddDealResponse.
+ */
+type MultiDeal struct {
+
+	// The Deals, all of the same parameters, Dealer and insurers
+	deals []*Deal
+}
+
+/* Constructs a new MultiDeal to guarantee several secrets.
+ *
+ * Arguments
+ *    secretPairs = the keypairs of the secrets to be dealt, one per Deal
+ *    longPair    = the long term keypair of the Dealer
+ *    t           = minimum number of shares needed to reconstruct a secret.
+ *    r           = minimum signatures needed to certify the MultiDeal
+ *    insurers    = a list of the long-term public keys of the insurers.
+ *
+ * Returns
+ *   A newly constructed MultiDeal
+ *
+ * Note
+ *   As ConstructDeal, it panics if the parameters are invalid. To construct
+ *   the Deals with non-default options (see Deal.SetShareWrapper), construct
+ *   them one by one and use NewMultiDeal.
+ */
+func ConstructMultiDeal(secretPairs []*config.KeyPair, longPair *config.KeyPair,
+	t, r int, insurers []abstract.Point) *MultiDeal {
+	deals := make([]*Deal, len(secretPairs))
+	for k, secretPair := range secretPairs {
+		deals[k] = new(Deal).ConstructDeal(secretPair, longPair, t, r,
+			insurers)
+	}
+	return &MultiDeal{deals}
+}
+
+/* Groups Deals received from a Dealer into a MultiDeal.
+ *
+ * Arguments
+ *    deals = the Deals, in the order the Dealer constructed them
+ *
+ * Returns
+ *   The MultiDeal, or ErrInvalidDeal if there are no Deals or if they differ
+ *   in their suite, parameters, Dealer or insurers
+ */
+func NewMultiDeal(deals []*Deal) (*MultiDeal, error) {
+	if len(deals) == 0 {
+		return nil, ErrInvalidDeal
+	}
+	d := deals[0]
+	if d.suite == nil {
+		return nil, ErrInvalidDeal
+	}
+	for _, o := range deals[1:] {
+		// Decoded Deals each carry their own instance of the suite
+		if o.suite == nil || o.suite.String() != d.suite.String() ||
+			o.t != d.t || o.r != d.r || o.n != d.n ||
+			!o.pubKey.Equal(d.pubKey) ||
+			len(o.insurers) != len(d.insurers) {
+			return nil, ErrInvalidDeal
+		}
+		for i := range d.insurers {
+			if !o.insurers[i].Equal(d.insurers[i]) {
+				return nil, ErrInvalidDeal
+			}
+		}
+	}
+	return &MultiDeal{append([]*Deal(nil), deals...)}, nil
+}
+
+// Returns the number of Deals of the MultiDeal
+func (md *MultiDeal) Len() int {
+	return len(md.deals)
+}
+
+// Returns the k-th Deal of the MultiDeal. It is shared with the MultiDeal and
+// must not be modified.
+func (md *MultiDeal) Deal(k int) *Deal {
+	return md.deals[k]
+}
+
+/* An internal helper returning the message signed by insurer i, either
+ * approving all the Deals (in multiApproveDomain) or blaming their Dealer (in
+ * multiBlameDomain). It binds the signature to the content of every Deal (see
+ * Deal.contentDigest), to their order and to the index of the insurer, so
+ * that MultiState can verify a blameProof against each of the Deals.
+ *
+ * Arguments
+ *    i = the index of the insurer
+ *
+ * Returns
+ *   The message, or an error if marshalling a Deal failed
+ */
+func (md *MultiDeal) multiMsg(i int) ([]byte, error) {
+	h := sha256.New()
+	binary.Write(h, binary.BigEndian, uint32(len(md.deals)))
+	for _, d := range md.deals {
+		msg, err := d.contentDigest()
+		if err != nil {
+			return nil, err
+		}
+		h.Write(msg)
+	}
+	binary.Write(h, binary.BigEndian, uint32(i))
+	return h.Sum(nil), nil
+}
+
+/* For insurers, produces a single response to all the Deals. If the insurer's
+ * shares are all valid, the function returns a Response approving all the
+ * Deals. Otherwise, a Response with a blameProof blaming the Dealer is made,
+ * which MultiState verifies against every Deal.
+ *
+ * Arguments
+ *    i        = the index of the insurer in the insurers list
+ *    gKeyPair = the long term public/private keypair of the insurer.
+ *
+ * Return
+ *   the Response, or nil if there is an error.
+ *   an error, nil otherwise.
+ */
+func (md *MultiDeal) ProduceResponse(i int, gKeyPair *config.KeyPair) (*Response, error) {
+	for _, d := range md.deals {
+		if err := d.verifyShare(i, gKeyPair); err != nil {
+			// As in Deal.ProduceResponse, only blame for bad shares
+			if err != ErrShareCheckFailed {
+				return nil, err
+			}
+			msg, err := md.multiMsg(i)
+			if err != nil {
+				return nil, err
+			}
+			blameProof, err := d.blameWith(i, gKeyPair,
+				multiBlameDomain, msg)
+			if err != nil {
+				return nil, err
+			}
+			return new(Response).constructBlameProofResponse(blameProof), nil
+		}
+	}
+
+	msg, err := md.multiMsg(i)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := md.deals[0].sign(i, gKeyPair, multiApproveDomain, msg)
+	if err != nil {
+		return nil, err
+	}
+	return new(Response).constructIndexedSignatureResponse(i, sig), nil
+}
+
+/* The MultiState struct keeps state about a MultiDeal: one State per Deal,
+ * updated by the responses of the insurers to the whole MultiDeal.
+ *
+ * Note to users of this code:
+ *
+ *    The States of the Deals are available with State(k), e.g. to reveal
+ *    shares or reconstruct one of the secrets.
+ */
+type MultiState struct {
+
+	// The MultiDeal
+	multiDeal *MultiDeal
+
+	// The States of the Deals, in the order of the MultiDeal
+	states []*State
+}
+
+/* Initializes a new MultiState.
+ *
+ * Arguments
+ *    md = the MultiDeal to keep track of
+ *
+ * Returns
+ *   An initialized MultiState
+ */
+func (ms *MultiState) Init(md *MultiDeal) *MultiState {
+	ms.multiDeal = md
+	ms.states = make([]*State, len(md.deals))
+	for k, d := range md.deals {
+		ms.states[k] = new(State).Init(*d)
+	}
+	return ms
+}
+
+// Returns the State of the k-th Deal of the MultiDeal.
+func (ms *MultiState) State(k int) *State {
+	return ms.states[k]
+}
+
+/* Adds a response of insurer i to the whole MultiDeal, as produced by
+ * MultiDeal.ProduceResponse. A signature is added to the State of every Deal.
+ * A blameProof is added to the States of the Deals whose share it proves
+ * invalid, and only to those.
+ *
+ * Arguments
+ *    i        = the index of the insurer
+ *    response = the response to add
+ *
+ * Returns
+ *   nil if the response was added succesfully, an error otherwise.
+ */
+func (ms *MultiState) AddResponse(i int, response *Response) error {
+	if i < 0 || i >= ms.multiDeal.deals[0].n {
+		return ErrInvalidIndex
+	}
+	for _, s := range ms.states {
+		if s.responses[i] != nil {
+			return ErrResponseAdded
+		}
+	}
+
+	switch response.rtype {
+	case signatureResponse:
+		if !response.indexed || response.index != i {
+			return ErrInvalidIndex
+		}
+		msg, err := ms.multiDeal.multiMsg(i)
+		if err != nil {
+			return err
+		}
+		err = ms.multiDeal.deals[0].verifySignature(i,
+			response.signature, multiApproveDomain, msg)
+		if err != nil {
+			return err
+		}
+		for _, s := range ms.states {
+			s.addSignature(i, response)
+		}
+		return nil
+
+	case blameProofResponse:
+		// The blame is justified if it is for at least one of the Deals
+		msg, err := ms.multiDeal.multiMsg(i)
+		if err != nil {
+			return err
+		}
+		var blamed []int
+		for k, d := range ms.multiDeal.deals {
+			if e := d.verifyBlameWith(i, response.blameProof,
+				multiBlameDomain, msg); e == nil {
+				blamed = append(blamed, k)
+			} else if err == nil || err == ErrUnjustifiedBlame {
+				err = e
+			}
+		}
+		if len(blamed) == 0 {
+			return err
+		}
+		for _, k := range blamed {
+			ms.states[k].addBlame(i, response)
+		}
+		return nil
+	}
+	return ErrInvalidResponse
+}
+
+/* Adds a response of insurer i to the k-th Deal only, as produced by
+ * Deal.ProduceResponse, e.g. by an insurer approving the Deals other than
+ * those it blamed.
+ *
+ * Arguments
+ *    k        = the index of the Deal in the MultiDeal
+ *    i        = the index of the insurer
+ *    response = the response to add
+ *
+ * Returns
+ *   nil if the response was added succesfully, an error otherwise.
+ */
+func (ms *MultiState) AddDealResponse(k, i int, response *Response) error {
+	if k < 0 || k >= len(ms.states) {
+		return ErrInvalidIndex
+	}
+	return ms.states[k].AddResponse(i, response)
+}
+
+/* Checks whether all the Deals of the MultiDeal are certified, see
+ * State.DealCertified.
+ *
+ * Return
+ *   nil if all the Deals are certified, the error of the first Deal that is
+ *   not otherwise.
+ */
+func (ms *MultiState) DealCertified() error {
+	for _, s := range ms.states {
+		if err := s.DealCertified(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/* Returns the indices in the MultiDeal of the Deals that are blamed by a
+ * valid blameProof.
+ */
+func (ms *MultiState) Blamed() []int {
+	var blamed []int
+	for k, s := range ms.states {
+		if s.blames > 0 {
+			blamed = append(blamed, k)
+		}
+	}
+	return blamed
+}
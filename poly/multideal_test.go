@@ -0,0 +1,182 @@
+package poly
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/config"
+)
+
+func produceMultiDeal(n int) *MultiDeal {
+	secrets := make([]*config.KeyPair, n)
+	for k := range secrets {
+		secrets[k] = produceKeyPair()
+	}
+	return ConstructMultiDeal(secrets, DealerKey, 3, 4, insurerList[:5])
+}
+
+// Verify that a single signature per insurer certifies all the Deals.
+func TestMultiDealCertification(t *testing.T) {
+	md := produceMultiDeal(3)
+
+	// Insurers and clients rebuild the MultiDeal from its Deals
+	deals := make([]*Deal, md.Len())
+	for k := range deals {
+		buf, err := md.Deal(k).MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		deals[k] = new(Deal).UnmarshalInit(3, 4, 5, suite)
+		if err := deals[k].UnmarshalBinary(buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	received, err := NewMultiDeal(deals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := new(MultiState).Init(md)
+	for i := 0; i < 4; i++ {
+		if state.DealCertified() == nil {
+			t.Error("MultiDeal should not be certified yet")
+		}
+		response, err := received.ProduceResponse(i, insurerKeys[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := state.AddResponse(i, response); err != nil {
+			t.Fatal("Signature should be accepted", err)
+		}
+	}
+	if err := state.DealCertified(); err != nil {
+		t.Error("MultiDeal should be certified", err)
+	}
+
+	// Shares are revealed secret by secret
+	share, err := state.State(1).RevealShare(0, insurerKeys[0])
+	if err != nil || md.Deal(1).VerifyRevealedShare(0, share) != nil {
+		t.Error("Share should be revealed", err)
+	}
+
+	// A signature is neither accepted twice, nor for another index, nor for
+	// a single Deal
+	response, _ := md.ProduceResponse(0, insurerKeys[0])
+	if err := state.AddResponse(0, response); err != ErrResponseAdded {
+		t.Error("Response should have been added already", err)
+	}
+	if err := state.AddResponse(4, response); err != ErrInvalidIndex {
+		t.Error("Signature should be bound to its index", err)
+	}
+	if err := new(State).Init(*md.Deal(0)).AddResponse(0, response); err == nil {
+		t.Error("Signature should not approve a single Deal")
+	}
+	other := produceMultiDeal(3)
+	if err := new(MultiState).Init(other).AddResponse(0, response); err == nil {
+		t.Error("Signature should not approve another MultiDeal")
+	}
+}
+
+// Verify that a blameProof only blames the Deals with an invalid share, and
+// that the insurer can still approve the other Deals one by one.
+func TestMultiDealBlame(t *testing.T) {
+	md := produceMultiDeal(3)
+	md.Deal(1).secrets[0] = suite.Scalar().Zero()
+
+	state := new(MultiState).Init(md)
+	response, err := md.ProduceResponse(0, insurerKeys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.rtype != blameProofResponse {
+		t.Fatal("Insurer should blame the Dealer")
+	}
+	if err := state.AddResponse(0, response); err != nil {
+		t.Fatal("Blame should be accepted", err)
+	}
+	if blamed := state.Blamed(); len(blamed) != 1 || blamed[0] != 1 {
+		t.Error("Only Deal 1 should be blamed", blamed)
+	}
+	for _, k := range []int{0, 2} {
+		response, err := md.Deal(k).ProduceResponse(0, insurerKeys[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := state.AddDealResponse(k, 0, response); err != nil {
+			t.Error("Signature should be accepted", err)
+		}
+	}
+	for i := 1; i < 4; i++ {
+		response, err := md.ProduceResponse(i, insurerKeys[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := state.AddResponse(i, response); err != nil {
+			t.Fatal("Signature should be accepted", err)
+		}
+	}
+	if state.State(0).DealCertified() != nil ||
+		state.State(2).DealCertified() != nil {
+		t.Error("Deals 0 and 2 should be certified")
+	}
+	if state.State(1).DealCertified() != ErrBlamed ||
+		state.DealCertified() != ErrBlamed {
+		t.Error("Deal 1 should be blamed")
+	}
+
+	// An unjustified blame is rejected
+	good := produceMultiDeal(2)
+	msg, _ := good.multiMsg(0)
+	bproof, _ := good.Deal(0).blameWith(0, insurerKeys[0], multiBlameDomain,
+		msg)
+	err = new(MultiState).Init(good).AddResponse(0,
+		new(Response).constructBlameProofResponse(bproof))
+	if err != ErrUnjustifiedBlame {
+		t.Error("Blame should be unjustified", err)
+	}
+
+	// A blame of a single Deal does not blame the MultiDeal
+	md = produceMultiDeal(2)
+	md.Deal(0).secrets[0] = suite.Scalar().Zero()
+	bproof, _ = md.Deal(0).blame(0, insurerKeys[0])
+	err = new(MultiState).Init(md).AddResponse(0,
+		new(Response).constructBlameProofResponse(bproof))
+	if err == nil {
+		t.Error("Blame of a single Deal should be rejected")
+	}
+}
+
+func TestNewMultiDeal(t *testing.T) {
+	md := produceMultiDeal(2)
+	if _, err := NewMultiDeal(nil); err != ErrInvalidDeal {
+		t.Error("Empty MultiDeal should be invalid", err)
+	}
+	other := new(Deal).ConstructDeal(secretKey, DealerKey, 3, 4, insurerList[1:6])
+	if _, err := NewMultiDeal([]*Deal{md.Deal(0), other}); err != ErrInvalidDeal {
+		t.Error("Deals of different insurers should be rejected", err)
+	}
+	other = new(Deal).ConstructDeal(secretKey, produceKeyPair(), 3, 4, insurerList[:5])
+	if _, err := NewMultiDeal([]*Deal{md.Deal(0), other}); err != ErrInvalidDeal {
+		t.Error("Deals of different Dealers should be rejected", err)
+	}
+
+	// Deals decoded separately each get their own instance of the suite
+	decoded := make([]*Deal, md.Len())
+	for k := range decoded {
+		buf, err := md.Deal(k).GobEncode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded[k] = new(Deal)
+		if err := decoded[k].GobDecode(buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := NewMultiDeal(decoded); err != nil {
+		t.Error("Decoded Deals of the same suite should be grouped", err)
+	}
+	other = new(Deal).ConstructDeal(secretKey, DealerKey, 3, 4, insurerList[:5])
+	other.suite = altSuite
+	if _, err := NewMultiDeal([]*Deal{md.Deal(0), other}); err != ErrInvalidDeal {
+		t.Error("Deals of different suites should be rejected", err)
+	}
+}
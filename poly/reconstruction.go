@@ -0,0 +1,352 @@
+package poly
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/sign"
+)
+
+// Domain of the signatures of clients requesting the reconstruction of a
+// Deal, see sign.SignWithDomain and reconstructionMsg
+const reconstructDomain = "poly.Deal.Reconstruct"
+
+// Size in bytes of the nonces of ReconstructionRequests
+const reconstructionNonceSize = 32
+
+/* A ReconstructionRequest authorizes insurers to reveal their shares of a
+ * Deal to a requester. It is signed by clients of a roster configured on the
+ * States of the insurers (see State.SetClientQuorum), and insurers only reveal
+ * their shares (see State.AuthorizedRevealShare) once at least k distinct
+ * clients of the roster signed the request, instead of trusting any single
+ * client claiming that the Dealer is down.
+ *
+ * Clients create the request with NewReconstructionRequest, and each client
+ * adds its signature with Sign before passing the request on. A request
+ * covers the shares of the Deal (see Deal.sharesDigest), so that it is valid
+ * for the Deal and all its recertified versions alike.
+ *
+ * The signatures also cover the long-term public key of the requester and a
+ * fresh nonce. Insurers wrap the revealed shares for the requester, so that
+ * anyone replaying the request learns nothing, and serve each nonce once.
+ */
+type ReconstructionRequest struct {
+
+	// The suite of the signatures
+	suite abstract.Suite
+
+	// The digest of the shares of the Deal to reconstruct
+	digest []byte
+
+	// The long-term public key of the party receiving the shares
+	requester abstract.Point
+
+	// The nonce identifying the request
+	nonce []byte
+
+	// The indices in the client roster of the signers, and their signatures
+	signers    []int
+	signatures []*signature
+}
+
+/* An internal helper returning the message signed by the client of index j
+ * of the roster to request the reconstruction of the Deal.
+ *
+ * Arguments
+ *    digest    = the shares digest of the Deal
+ *    requester = the long-term public key of the requester
+ *    nonce     = the nonce of the request
+ *    j         = the index of the client in the roster
+ *
+ * Returns
+ *   The message, or an error if marshalling the requester failed
+ */
+func reconstructionMsg(digest []byte, requester abstract.Point, nonce []byte,
+	j int) ([]byte, error) {
+	h := sha256.New()
+	h.Write(digest)
+	if _, err := requester.MarshalTo(h); err != nil {
+		return nil, err
+	}
+	h.Write(nonce)
+	binary.Write(h, binary.BigEndian, uint32(j))
+	return h.Sum(nil), nil
+}
+
+/* Creates a new ReconstructionRequest for a Deal with a fresh nonce, without
+ * signatures.
+ *
+ * Arguments
+ *    deal      = the Deal to reconstruct, or its public view
+ *    requester = the long-term public key of the party receiving the shares
+ *
+ * Returns
+ *   The request, or an error if marshalling the Deal failed
+ */
+func NewReconstructionRequest(deal *Deal, requester abstract.Point) (*ReconstructionRequest, error) {
+	digest, err := deal.sharesDigest()
+	if err != nil {
+		return nil, err
+	}
+	return &ReconstructionRequest{
+		suite:     deal.suite,
+		digest:    digest,
+		requester: deal.suite.Point().Set(requester),
+		nonce:     random.Bytes(reconstructionNonceSize, random.Stream),
+	}, nil
+}
+
+// Returns the long-term public key of the party receiving the shares.
+func (req *ReconstructionRequest) Requester() abstract.Point {
+	return req.requester
+}
+
+/* Adds the signature of a client to the request.
+ *
+ * Arguments
+ *    j         = the index of the client in the client roster
+ *    clientKey = the long-term keypair of the client
+ *
+ * Returns
+ *   nil if the signature was added, an error if signing failed
+ */
+func (req *ReconstructionRequest) Sign(j int, clientKey *config.KeyPair) error {
+	msg, err := reconstructionMsg(req.digest, req.requester, req.nonce, j)
+	if err != nil {
+		return err
+	}
+	sig, err := signMsg(clientKey, reconstructDomain, msg)
+	if err != nil {
+		return err
+	}
+	req.signers = append(req.signers, j)
+	req.signatures = append(req.signatures, sig)
+	return nil
+}
+
+/* Configures the roster of the clients allowed to request the reconstruction
+ * of the Deal. Afterwards, RevealShare refuses to reveal shares, and
+ * AuthorizedRevealShare requires a ReconstructionRequest signed by at least k
+ * distinct clients of the roster.
+ *
+ * Arguments
+ *    clients = the long-term public keys of the clients
+ *    k       = the minimum number of signatures of a request, 0 < k <= len(clients)
+ *
+ * Returns
+ *   The State itself
+ */
+func (ps *State) SetClientQuorum(clients []abstract.Point, k int) *State {
+	if k <= 0 || k > len(clients) {
+		panic("Invalid client quorum. Expected 0 < k <= len(clients)")
+	}
+	ps.clients = abstract.ClonePoints(clients)
+	ps.clientQuorum = k
+	return ps
+}
+
+/* Verifies that a ReconstructionRequest is for the Deal and signed by enough
+ * distinct clients of the roster. Invalid signatures are ignored, and clients
+ * appearing several times in the roster count once.
+ *
+ * Arguments
+ *    req = the request
+ *
+ * Returns
+ *   nil if the request authorizes the reconstruction, an error otherwise.
+ */
+func (ps *State) VerifyReconstructionRequest(req *ReconstructionRequest) error {
+	if ps.clients == nil {
+		return ErrUnauthorized
+	}
+	digest, err := ps.Deal.sharesDigest()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(digest, req.digest) {
+		return ErrUnauthorized
+	}
+	if req.requester == nil || len(req.nonce) != reconstructionNonceSize {
+		return ErrUnauthorized
+	}
+	seen := make(map[[abstract.PointKeySize]byte]bool)
+	for k, j := range req.signers {
+		if j < 0 || j >= len(ps.clients) {
+			continue
+		}
+		key := abstract.PointKey(ps.clients[j])
+		if seen[key] {
+			continue
+		}
+		msg, err := reconstructionMsg(digest, req.requester, req.nonce, j)
+		if err != nil {
+			return err
+		}
+		if err := sign.VerifyWithDomain(ps.Deal.suite, ps.clients[j],
+			reconstructDomain, msg, req.signatures[k].signature); err == nil {
+			seen[key] = true
+		}
+	}
+	if len(seen) < ps.clientQuorum {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+/* Reveals the share of the insurer as RevealShare does, if the request is
+ * signed by enough clients of the roster configured with SetClientQuorum. The
+ * share is wrapped for the requester with the default ShareWrapper keyed by
+ * the insurer (see NewDiffieHellmanWrapper), so that only the requester can
+ * unwrap it with Deal.UnwrapRevealedShare. Each request is served once.
+ *
+ * Arguments
+ *    i        = the index of the insurer
+ *    gKeyPair = the long-term keypair of the insurer
+ *    req      = the ReconstructionRequest of the clients
+ *
+ * Return
+ *   (share, error)
+ *      share = the revealed private share wrapped for the requester, or nil
+ *              if an error occurred
+ *      error = nil if successful, ErrUnauthorized if the request does not
+ *              authorize the reconstruction, ErrReplayedRequest if the
+ *              request was already served, or any error of RevealShare
+ *
+ * Postcondition
+ *   panics if an insufficient number of signatures have been received, as
+ *   RevealShare
+ */
+func (ps *State) AuthorizedRevealShare(i int, gKeyPair *config.KeyPair,
+	req *ReconstructionRequest) (abstract.Scalar, error) {
+	if err := ps.VerifyReconstructionRequest(req); err != nil {
+		return nil, err
+	}
+	if ps.served[string(req.nonce)] {
+		return nil, ErrReplayedRequest
+	}
+	share, err := ps.revealShare(i, gKeyPair)
+	if err != nil {
+		return nil, err
+	}
+	if ps.served == nil {
+		ps.served = make(map[string]bool)
+	}
+	ps.served[string(req.nonce)] = true
+	return NewDiffieHellmanWrapper(gKeyPair).Wrap(req.requester, share)
+}
+
+/* Unwraps a share revealed by insurer i with AuthorizedRevealShare. The share
+ * should then be checked with VerifyRevealedShare.
+ *
+ * Arguments
+ *    i            = the index of the insurer
+ *    requesterKey = the long-term keypair of the requester
+ *    wrapped      = the wrapped share
+ *
+ * Returns
+ *   The revealed share, or an error if i is out of range
+ */
+func (p *Deal) UnwrapRevealedShare(i int, requesterKey *config.KeyPair,
+	wrapped abstract.Scalar) (abstract.Scalar, error) {
+	if i < 0 || i >= p.n {
+		return nil, ErrInvalidDeal
+	}
+	return NewDiffieHellmanWrapper(requesterKey).Unwrap(p.insurers[i], wrapped)
+}
+
+/* For users of this code, initializes a ReconstructionRequest for
+ * unmarshalling
+ *
+ * Arguments
+ *    suite = the suite of the Deal
+ *
+ * Returns
+ *   An initialized request ready to unmarshal a buffer
+ */
+func (req *ReconstructionRequest) UnmarshalInit(suite abstract.Suite) *ReconstructionRequest {
+	req.suite = suite
+	return req
+}
+
+/* Marshals a ReconstructionRequest into a byte array
+ *
+ * Returns
+ *   A buffer of the marshalled request
+ *   The error status of the marshalling (nil if no error)
+ *
+ * Note
+ *   The buffer is formatted as follows:
+ *
+ *      ||deal_digest||requester||nonce||count||==(signer_index||signature)_array==||
+ *
+ *   where count and the indices are big-endian uint32.
+ */
+func (req *ReconstructionRequest) MarshalBinary() ([]byte, error) {
+	var b bytes.Buffer
+	b.Write(req.digest)
+	if _, err := req.requester.MarshalTo(&b); err != nil {
+		return nil, err
+	}
+	b.Write(req.nonce)
+	binary.Write(&b, binary.BigEndian, uint32(len(req.signers)))
+	for k, j := range req.signers {
+		binary.Write(&b, binary.BigEndian, uint32(j))
+		if _, err := req.signatures[k].MarshalTo(&b); err != nil {
+			return nil, err
+		}
+	}
+	return b.Bytes(), nil
+}
+
+/* Unmarshals a ReconstructionRequest from a byte buffer
+ *
+ * Arguments
+ *    buf = the buffer containing the request
+ *
+ * Returns
+ *   The error status of the unmarshalling (nil if no error)
+ */
+func (req *ReconstructionRequest) UnmarshalBinary(buf []byte) error {
+	pointLen := req.suite.PointLen()
+	headerLen := sha256.Size + pointLen + reconstructionNonceSize
+	if len(buf) < headerLen+uint32Size {
+		return errors.New("Buffer size too small")
+	}
+	req.digest = append([]byte(nil), buf[:sha256.Size]...)
+	req.requester = req.suite.Point()
+	if err := req.requester.UnmarshalBinary(buf[sha256.Size : sha256.Size+pointLen]); err != nil {
+		return err
+	}
+	req.nonce = append([]byte(nil), buf[sha256.Size+pointLen:headerLen]...)
+	r := bytes.NewReader(buf[headerLen:])
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return err
+	}
+	if count > MaxInsurers {
+		return errors.New("Too many signers")
+	}
+	req.signers = nil
+	req.signatures = nil
+	for k := uint32(0); k < count; k++ {
+		var j uint32
+		if err := binary.Read(r, binary.BigEndian, &j); err != nil {
+			return err
+		}
+		sig := new(signature).UnmarshalInit(req.suite)
+		if _, err := sig.UnmarshalFrom(r); err != nil {
+			return err
+		}
+		req.signers = append(req.signers, int(j))
+		req.signatures = append(req.signatures, sig)
+	}
+	if r.Len() != 0 {
+		return errors.New("Buffer size too large")
+	}
+	return nil
+}
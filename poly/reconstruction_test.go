@@ -0,0 +1,142 @@
+package poly
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+)
+
+func TestReconstructionRequest(t *testing.T) {
+	deal := new(Deal).ConstructDeal(secretKey, DealerKey, 3, 4, insurerList[:5])
+	state := new(State).Init(*deal)
+	for i := 0; i < 4; i++ {
+		response, err := deal.ProduceResponse(i, insurerKeys[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := state.AddResponse(i, response); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clientKeys := make([]*config.KeyPair, 3)
+	clients := make([]abstract.Point, len(clientKeys))
+	for j := range clientKeys {
+		clientKeys[j] = produceKeyPair()
+		clients[j] = clientKeys[j].Public
+	}
+	state.SetClientQuorum(clients, 2)
+	if _, err := state.RevealShare(0, insurerKeys[0]); err != ErrUnauthorized {
+		t.Error("Shares should only be revealed on request of the clients", err)
+	}
+
+	// Clients sign the request for the public view of the Deal
+	requester := produceKeyPair()
+	public, _ := deal.Public()
+	req, err := NewReconstructionRequest(public, requester.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Sign(0, clientKeys[0])
+	req.Sign(0, clientKeys[0])
+	if _, err := state.AuthorizedRevealShare(0, insurerKeys[0], req); err != ErrUnauthorized {
+		t.Error("Request of a single client should be refused", err)
+	}
+	req.Sign(2, clientKeys[1])
+	if _, err := state.AuthorizedRevealShare(0, insurerKeys[0], req); err != ErrUnauthorized {
+		t.Error("Signature at the wrong index should be ignored", err)
+	}
+	req.Sign(2, clientKeys[2])
+
+	// The request travels over the network
+	buf, err := req.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(ReconstructionRequest).UnmarshalInit(suite)
+	if err := decoded.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.UnmarshalBinary(buf[:len(buf)-1]); err == nil {
+		t.Error("Truncated request should be rejected")
+	}
+	huge := append([]byte(nil), buf...)
+	copy(huge[len(req.digest)+suite.PointLen()+reconstructionNonceSize:],
+		[]byte{0xff, 0xff, 0xff, 0xff})
+	if err := decoded.UnmarshalBinary(huge); err == nil {
+		t.Error("Request with too many signers should be rejected")
+	}
+	decoded.UnmarshalBinary(buf)
+	if !decoded.Requester().Equal(requester.Public) {
+		t.Error("Requester should survive the encoding")
+	}
+	wrapped, err := state.AuthorizedRevealShare(0, insurerKeys[0], decoded)
+	if err != nil {
+		t.Fatal("Share should be revealed", err)
+	}
+	if deal.VerifyRevealedShare(0, wrapped) == nil {
+		t.Error("Revealed share should be wrapped for the requester")
+	}
+	share, err := deal.UnwrapRevealedShare(0, requester, wrapped)
+	if err != nil || deal.VerifyRevealedShare(0, share) != nil {
+		t.Error("Requester should unwrap the share", err)
+	}
+	if share, _ := deal.UnwrapRevealedShare(0, produceKeyPair(), wrapped); deal.VerifyRevealedShare(0, share) == nil {
+		t.Error("Only the requester should unwrap the share")
+	}
+
+	// A request is served once, by the State and its recertified versions
+	if _, err := state.AuthorizedRevealShare(0, insurerKeys[0], decoded); err != ErrReplayedRequest {
+		t.Error("Replayed request should be refused", err)
+	}
+	recertified, err := state.Recertify(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := recertified.VerifyReconstructionRequest(req); err != nil {
+		t.Error("Request should cover the recertified Deal", err)
+	}
+	if _, err := recertified.AuthorizedRevealShare(0, insurerKeys[0], req); err != ErrReplayedRequest {
+		t.Error("Replayed request should be refused after Recertify", err)
+	}
+
+	// The signatures are bound to the requester and the nonce
+	forged, _ := NewReconstructionRequest(public, produceKeyPair().Public)
+	forged.signers, forged.signatures = req.signers, req.signatures
+	if err := state.VerifyReconstructionRequest(forged); err != ErrUnauthorized {
+		t.Error("Signatures should not authorize another requester", err)
+	}
+	forged, _ = NewReconstructionRequest(public, requester.Public)
+	forged.signers, forged.signatures = req.signers, req.signatures
+	if err := state.VerifyReconstructionRequest(forged); err != ErrUnauthorized {
+		t.Error("Signatures should not authorize another nonce", err)
+	}
+
+	// A request for another Deal is refused
+	other := new(Deal).ConstructDeal(produceKeyPair(), DealerKey, 3, 4, insurerList[:5])
+	otherReq, _ := NewReconstructionRequest(other, requester.Public)
+	otherReq.Sign(0, clientKeys[0])
+	otherReq.Sign(1, clientKeys[1])
+	if err := state.VerifyReconstructionRequest(otherReq); err != ErrUnauthorized {
+		t.Error("Request for another Deal should be refused", err)
+	}
+
+	// A client appearing twice in the roster counts once
+	dup := new(State).Init(*deal)
+	dup.SetClientQuorum([]abstract.Point{clients[0], clients[0], clients[1]}, 2)
+	dupReq, _ := NewReconstructionRequest(deal, requester.Public)
+	dupReq.Sign(0, clientKeys[0])
+	dupReq.Sign(1, clientKeys[0])
+	if err := dup.VerifyReconstructionRequest(dupReq); err != ErrUnauthorized {
+		t.Error("Duplicate roster keys should count once", err)
+	}
+	dupReq.Sign(2, clientKeys[1])
+	if err := dup.VerifyReconstructionRequest(dupReq); err != nil {
+		t.Error("Distinct clients should authorize the request", err)
+	}
+
+	// Without a client quorum, requests are not verified
+	if err := new(State).Init(*deal).VerifyReconstructionRequest(req); err != ErrUnauthorized {
+		t.Error("Request should be refused without client roster", err)
+	}
+}
@@ -0,0 +1,158 @@
+package poly
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/sign"
+)
+
+// Domain of the signatures of Revocations, see sign.SignWithDomain
+const revokeDomain = "poly.Deal.Revoke"
+
+/* A Revocation is a public message by which a Dealer cancels one of its Deals,
+ * e.g. when the dealt short-term key is retired. It is signed by the long-term
+ * key of the Dealer over the shares of the Deal (see Deal.sharesDigest), so
+ * that insurers and clients can verify it against the Deal or its public view.
+ * As the shares of a Deal and of its recertified versions are the same (see
+ * Deal.Recertify), it revokes all of them at once: a recertified Deal cannot
+ * escape the Revocation of the Deal it comes from.
+ *
+ * Once a State accepts a Revocation (see State.AddRevocation), the Deal is no
+ * longer certified, and insurers refuse to reveal their shares. Insurers may
+ * then forget their shares.
+ */
+type Revocation struct {
+
+	// The suite of the signature
+	suite abstract.Suite
+
+	// The digest of the shares of the revoked Deal
+	digest []byte
+
+	// The signature of the Dealer
+	signature signature
+}
+
+/* For Dealers, produces the Revocation of the Deal.
+ *
+ * Arguments
+ *    longPair = the long term keypair of the Dealer
+ *
+ * Returns
+ *   The Revocation, or an error if longPair is not the key of the Dealer or
+ *   if marshalling the Deal failed
+ */
+func (p *Deal) Revoke(longPair *config.KeyPair) (*Revocation, error) {
+	if !p.pubKey.Equal(longPair.Public) {
+		return nil, errors.New("Not the long term key of the Dealer")
+	}
+	digest, err := p.sharesDigest()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := p.sign(0, longPair, revokeDomain, digest)
+	if err != nil {
+		return nil, err
+	}
+	return &Revocation{p.suite, digest, *sig}, nil
+}
+
+/* Verifies that a Revocation cancels the Deal and is signed by its Dealer.
+ *
+ * Arguments
+ *    rev = the Revocation
+ *
+ * Returns
+ *   nil if the Revocation is valid, an error otherwise.
+ */
+func (p *Deal) VerifyRevocation(rev *Revocation) error {
+	digest, err := p.sharesDigest()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(digest, rev.digest) {
+		return ErrInvalidRevocation
+	}
+	if rev.signature.signature == nil {
+		return ErrNilSignature
+	}
+	if err := sign.VerifyWithDomain(p.suite, p.pubKey, revokeDomain, digest,
+		rev.signature.signature); err != nil {
+		return ErrInvalidRevocation
+	}
+	return nil
+}
+
+/* For users of this code, initializes a Revocation for unmarshalling
+ *
+ * Arguments
+ *    suite = the suite of the Deal
+ *
+ * Returns
+ *   An initialized Revocation ready to unmarshal a buffer
+ */
+func (rev *Revocation) UnmarshalInit(suite abstract.Suite) *Revocation {
+	rev.suite = suite
+	rev.signature.UnmarshalInit(suite)
+	return rev
+}
+
+/* Returns the digest of the shares of the revoked Deal (see
+ * Deal.sharesDigest), which insurers can use to look the Deal up.
+ */
+func (rev *Revocation) DealDigest() []byte {
+	return append([]byte(nil), rev.digest...)
+}
+
+/* Tests whether two Revocations are equal
+ *
+ * Arguments
+ *    rev2 = a pointer to the Revocation to test for equality
+ *
+ * Returns
+ *   true if equal, false otherwise
+ */
+func (rev *Revocation) Equal(rev2 *Revocation) bool {
+	return rev.suite == rev2.suite && bytes.Equal(rev.digest, rev2.digest) &&
+		bytes.Equal(rev.signature.signature, rev2.signature.signature)
+}
+
+/* Marshals a Revocation into a byte array
+ *
+ * Returns
+ *   A buffer of the marshalled Revocation
+ *   The error status of the marshalling (nil if no error)
+ *
+ * Note
+ *   The buffer is formatted as follows:
+ *
+ *      ||deal_digest||signature||
+ */
+func (rev *Revocation) MarshalBinary() ([]byte, error) {
+	sig, err := rev.signature.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(nil), rev.digest...), sig...), nil
+}
+
+/* Unmarshals a Revocation from a byte buffer
+ *
+ * Arguments
+ *    buf = the buffer containing the Revocation
+ *
+ * Returns
+ *   The error status of the unmarshalling (nil if no error)
+ */
+func (rev *Revocation) UnmarshalBinary(buf []byte) error {
+	if len(buf) < sha256.Size {
+		return errors.New("Buffer size too small")
+	}
+	rev.digest = append([]byte(nil), buf[:sha256.Size]...)
+	rev.signature.UnmarshalInit(rev.suite)
+	return rev.signature.UnmarshalBinary(buf[sha256.Size:])
+}
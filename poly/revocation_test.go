@@ -0,0 +1,89 @@
+package poly
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRevocation(t *testing.T) {
+	deal := new(Deal).ConstructDeal(secretKey, DealerKey, 3, 4, insurerList[:5])
+	state := new(State).Init(*deal)
+	for i := 0; i < 4; i++ {
+		response, err := deal.ProduceResponse(i, insurerKeys[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := state.AddResponse(i, response); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := deal.Revoke(insurerKeys[0]); err == nil {
+		t.Error("Only the Dealer should revoke the Deal")
+	}
+	rev, err := deal.Revoke(DealerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The Revocation travels over the network and verifies on the public
+	// view of the Deal
+	buf, err := rev.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(Revocation).UnmarshalInit(suite)
+	if err := decoded.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Equal(rev) {
+		t.Error("Revocation differs after decoding")
+	}
+	digest, _ := deal.sharesDigest()
+	if !bytes.Equal(decoded.DealDigest(), digest) {
+		t.Error("Wrong digest of the revoked Deal")
+	}
+	public, _ := deal.Public()
+	if err := public.VerifyRevocation(decoded); err != nil {
+		t.Error("Revocation should be valid", err)
+	}
+
+	// The revoked Deal is dead
+	if err := state.AddRevocation(decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !state.Revoked() || state.DealCertified() != ErrRevoked {
+		t.Error("Deal should be revoked")
+	}
+	if _, err := state.RevealShare(0, insurerKeys[0]); err != ErrRevoked {
+		t.Error("Shares of a revoked Deal should not be revealed", err)
+	}
+	if _, err := state.Recertify(5); err != ErrRevoked {
+		t.Error("A revoked Deal should not be recertified", err)
+	}
+
+	// Nor can a recertified Deal escape the Revocation, even if insurers
+	// approve it
+	recertified, _ := deal.Recertify(5)
+	recertifiedState := new(State).Init(*recertified)
+	for i := 0; i < 5; i++ {
+		response, _ := recertified.ProduceResponse(i, insurerKeys[i])
+		recertifiedState.AddResponse(i, response)
+	}
+	if err := recertifiedState.AddRevocation(decoded); err != nil {
+		t.Fatal("Revocation should cancel the recertified Deal", err)
+	}
+	if _, err := recertifiedState.RevealShare(0, insurerKeys[0]); err != ErrRevoked {
+		t.Error("Shares of a revoked Deal should not be revealed", err)
+	}
+
+	// A Revocation only cancels its own Deal
+	other := new(Deal).ConstructDeal(produceKeyPair(), DealerKey, 3, 4, insurerList[:5])
+	if err := other.VerifyRevocation(rev); err != ErrInvalidRevocation {
+		t.Error("Revocation should not cancel another Deal", err)
+	}
+	forged, _ := other.Revoke(DealerKey)
+	forged.digest = rev.digest
+	if err := new(State).Init(*deal).AddRevocation(forged); err != ErrInvalidRevocation {
+		t.Error("Forged revocation should be rejected", err)
+	}
+}
@@ -0,0 +1,169 @@
+package poly
+
+import (
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+)
+
+/* A Roster is the ordered list of the long term public keys of the insurers
+ * of a Deal. The position of a key is the index of its insurer, i.e., of its
+ * share, so that a Roster maps keys to indices and back, in place of the
+ * bookkeeping of raw []abstract.Point by callers. The keys of a Roster are
+ * distinct, so that every insurer has a single, stable index.
+ *
+ * A Roster is the decoded form of a config.Roster, such as a roster file
+ * listing the insurers: see NewRosterFromConfig and Roster.Config.
+ */
+type Roster struct {
+
+	// The roster the Roster was built from
+	config *config.Roster
+
+	// The long term public keys of the insurers, in order
+	keys []abstract.Point
+
+	// The index of each key, by abstract.PointKey
+	index map[[abstract.PointKeySize]byte]int
+}
+
+/* Creates a Roster of the given keys, copied so that the Roster cannot be
+ * tampered with. The addresses of the insurers in its config.Roster are
+ * empty.
+ *
+ * Arguments
+ *    suite = the suite of the keys
+ *    keys  = the long term public keys of the insurers, in order
+ *
+ * Returns
+ *   The Roster, or ErrDuplicateInsurer if a key appears twice
+ */
+func NewRoster(suite abstract.Suite, keys []abstract.Point) (*Roster, error) {
+	c, err := config.NewRoster(suite, keys, make([]string, len(keys)))
+	if err != nil {
+		return nil, err
+	}
+	return newRoster(c, keys)
+}
+
+/* Creates a Roster of the insurers listed in a config.Roster, e.g., read from
+ * a roster file with config.LoadRoster.
+ *
+ * Arguments
+ *    c      = the config.Roster listing the insurers, in order
+ *    suites = the suites the config.Roster may use, by name
+ *
+ * Returns
+ *   The Roster, an error if its suite is unknown or a key cannot be decoded,
+ *   or ErrDuplicateInsurer if a key appears twice
+ */
+func NewRosterFromConfig(c *config.Roster,
+	suites map[string]abstract.Suite) (*Roster, error) {
+	_, keys, err := c.Publics(suites)
+	if err != nil {
+		return nil, err
+	}
+	cc := *c
+	cc.Members = append([]config.RosterMember(nil), c.Members...)
+	return newRoster(&cc, keys)
+}
+
+// Creates a Roster of the keys listed in c, which the Roster takes over.
+func newRoster(c *config.Roster, keys []abstract.Point) (*Roster, error) {
+	index := abstract.PointKeys(keys)
+	if len(index) != len(keys) {
+		return nil, ErrDuplicateInsurer
+	}
+	return &Roster{c, abstract.ClonePoints(keys), index}, nil
+}
+
+/* Returns a copy of the config.Roster of the Roster, e.g., to save it in a
+ * roster file. It keeps the addresses of the config.Roster the Roster was
+ * created from, if any.
+ */
+func (r *Roster) Config() *config.Roster {
+	c := *r.config
+	c.Members = append([]config.RosterMember(nil), r.config.Members...)
+	return &c
+}
+
+// Returns the number of insurers of the Roster.
+func (r *Roster) Len() int {
+	return len(r.keys)
+}
+
+// Returns a copy of the key of insurer i, or nil if i is out of range.
+func (r *Roster) Key(i int) abstract.Point {
+	if i < 0 || i >= len(r.keys) {
+		return nil
+	}
+	return abstract.ClonePoint(r.keys[i])
+}
+
+// Returns a deep copy of the keys of the Roster, in order.
+func (r *Roster) Keys() []abstract.Point {
+	return abstract.ClonePoints(r.keys)
+}
+
+// Returns the index of the insurer with key pub, or -1 if it is no insurer.
+func (r *Roster) Index(pub abstract.Point) int {
+	if i, ok := r.index[abstract.PointKey(pub)]; ok {
+		return i
+	}
+	return -1
+}
+
+// Returns whether pub is the key of an insurer of the Roster.
+func (r *Roster) Contains(pub abstract.Point) bool {
+	return r.Index(pub) >= 0
+}
+
+/* Checks that pub is the key of insurer i, e.g., before accepting a message
+ * signed with pub for share i.
+ *
+ * Arguments
+ *    i   = the index claimed for the key
+ *    pub = the long term public key
+ *
+ * Returns
+ *   nil if pub is the key of insurer i. Otherwise, ErrInvalidIndex if i is
+ *   out of range, ErrNotInsurer if pub is no insurer, and ErrWrongInsurerKey
+ *   if pub is the key of another insurer.
+ */
+func (r *Roster) Check(i int, pub abstract.Point) error {
+	if i < 0 || i >= len(r.keys) {
+		return ErrInvalidIndex
+	}
+	switch r.Index(pub) {
+	case i:
+		return nil
+	case -1:
+		return ErrNotInsurer
+	default:
+		return ErrWrongInsurerKey
+	}
+}
+
+// Checks whether two Rosters have the same keys in the same order.
+func (r *Roster) Equal(o *Roster) bool {
+	if len(r.keys) != len(o.keys) {
+		return false
+	}
+	for i := range r.keys {
+		if !r.keys[i].Equal(o.keys[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+/* Returns a digest identifying the Roster, e.g., to agree on the insurers
+ * before running Deals. It is the config.Roster.Hash of its config.Roster, so
+ * that insurers agree on the digest whether they built the Roster from keys or
+ * read it from a roster file.
+ *
+ * Returns
+ *   The digest of the suite and the keys, in order, or an error
+ */
+func (r *Roster) Hash() ([]byte, error) {
+	return r.config.Hash()
+}
@@ -0,0 +1,152 @@
+package poly
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+)
+
+func TestRoster(t *testing.T) {
+	roster, err := NewRoster(suite, insurerList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if roster.Len() != numInsurers {
+		t.Error("Roster should hold all the insurers")
+	}
+	for i, key := range insurerList {
+		if roster.Index(key) != i || !roster.Contains(key) ||
+			!roster.Key(i).Equal(key) || roster.Check(i, key) != nil {
+			t.Error("Insurer", i, "should be at its index")
+		}
+	}
+	outsider := produceKeyPair().Public
+	if roster.Index(outsider) != -1 || roster.Contains(outsider) ||
+		roster.Key(-1) != nil || roster.Key(numInsurers) != nil {
+		t.Error("Roster should only hold the insurers")
+	}
+	if roster.Check(0, outsider) != ErrNotInsurer ||
+		roster.Check(0, insurerList[1]) != ErrWrongInsurerKey ||
+		roster.Check(numInsurers, insurerList[0]) != ErrInvalidIndex {
+		t.Error("Index and key mismatches should be reported")
+	}
+
+	// The Roster is a copy of the keys
+	keys := abstract.ClonePoints(insurerList)
+	copied, _ := NewRoster(suite, keys)
+	keys[0].Set(outsider)
+	if !copied.Equal(roster) || copied.Contains(outsider) {
+		t.Error("Roster should not change with the keys it was created from")
+	}
+
+	// The hash depends on the keys and their order
+	h1, err := roster.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2, _ := copied.Hash()
+	swapped := roster.Keys()
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	other, _ := NewRoster(suite, swapped)
+	h3, _ := other.Hash()
+	if !bytes.Equal(h1, h2) || bytes.Equal(h1, h3) || other.Equal(roster) {
+		t.Error("Roster hash should identify the keys in order")
+	}
+
+	if _, err := NewRoster(suite, append(insurerList[:2:2], insurerList[0])); err != ErrDuplicateInsurer {
+		t.Error("Roster keys should be distinct", err)
+	}
+}
+
+func TestRosterConfig(t *testing.T) {
+	roster, _ := NewRoster(suite, insurerList)
+	addrs := make([]string, numInsurers)
+	for i := range addrs {
+		addrs[i] = fmt.Sprintf("127.0.0.1:%d", 2000+i)
+	}
+	c, err := config.NewRoster(suite, insurerList, addrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	suites := map[string]abstract.Suite{suite.String(): suite}
+	fromConfig, err := NewRosterFromConfig(c, suites)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fromConfig.Equal(roster) {
+		t.Error("Roster should have the keys of its config.Roster")
+	}
+
+	// The Roster has the hash of its config.Roster, whatever the addresses
+	h, _ := roster.Hash()
+	hc, _ := c.Hash()
+	hf, _ := fromConfig.Hash()
+	if !bytes.Equal(h, hc) || !bytes.Equal(h, hf) {
+		t.Error("Roster should have the hash of its config.Roster")
+	}
+
+	// and keeps the addresses
+	c.Members[0].Address = "changed"
+	if !reflect.DeepEqual(fromConfig.Config().Addresses(), addrs) {
+		t.Error("Roster should keep the addresses of its config.Roster")
+	}
+
+	if _, err := NewRosterFromConfig(c, nil); err == nil {
+		t.Error("Roster of an unknown suite should fail")
+	}
+	c.Members[1].Public = c.Members[0].Public
+	if _, err := NewRosterFromConfig(c, suites); err != ErrDuplicateInsurer {
+		t.Error("Roster keys should be distinct", err)
+	}
+}
+
+func TestRosterDeal(t *testing.T) {
+	roster, _ := NewRoster(suite, insurerList[:5])
+	deal := new(Deal).ConstructRosterDeal(secretKey, DealerKey, 3, 4, roster)
+	dealRoster, err := deal.Roster()
+	if err != nil || !dealRoster.Equal(roster) {
+		t.Fatal("Deal should have the insurers of the Roster", err)
+	}
+	for i := 0; i < roster.Len(); i++ {
+		if _, err := deal.ProduceResponse(i, insurerKeys[i]); err != nil {
+			t.Error("Insurer", i, "should respond at its Roster index", err)
+		}
+	}
+	if deal.InsurerIndex(insurerKeys[3].Public) != 3 ||
+		deal.InsurerIndex(insurerKeys[5].Public) != -1 {
+		t.Error("Deal should find the index of its insurers")
+	}
+	if _, err := deal.ProduceResponse(0, insurerKeys[5]); err != ErrNotInsurer {
+		t.Error("Outsiders should not respond", err)
+	}
+	if _, err := deal.ProduceResponse(0, insurerKeys[1]); err != ErrWrongInsurerKey {
+		t.Error("Insurers should only respond for their share", err)
+	}
+
+	// Shares are only revealed to their insurer
+	state := new(State).Init(*deal)
+	for i := 0; i < 4; i++ {
+		response, _ := deal.ProduceResponse(i, insurerKeys[i])
+		state.AddResponse(i, response)
+	}
+	if _, err := state.RevealShare(0, insurerKeys[5]); err != ErrNotInsurer {
+		t.Error("Outsiders should not reveal shares", err)
+	}
+	if _, err := state.RevealShare(0, insurerKeys[1]); err != ErrWrongInsurerKey {
+		t.Error("Insurers should only reveal their share", err)
+	}
+	if _, err := state.RevealShare(0, insurerKeys[0]); err != nil {
+		t.Error("Insurer should reveal its share", err)
+	}
+
+	// Deals may list an insurer several times, but then have no Roster
+	twice := new(Deal).ConstructDeal(secretKey, DealerKey, 1, 2,
+		[]abstract.Point{insurerList[0], insurerList[0]})
+	if _, err := twice.Roster(); err != ErrDuplicateInsurer {
+		t.Error("Deal with a repeated insurer should have no Roster", err)
+	}
+}
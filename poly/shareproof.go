@@ -0,0 +1,114 @@
+package poly
+
+import (
+	"github.com/dedis/crypto/abstract"
+)
+
+/* ShareProofs make the wrapped shares of a Deal publicly verifiable, in the
+ * style of Schoenmakers' PVSS ("A Simple Publicly Verifiable Secret Sharing
+ * Scheme and its Application to Electronic Voting", CRYPTO 1999), where the
+ * encrypted shares are checked against the commitments of the Dealer. A
+ * VerifiableShareWrapper wraps the share of insurer i as
+ *
+ *     secrets[i] = share_i + k_i
+ *
+ * and the Dealer publishes the commitment K_i = k_i * G to the key of every
+ * insurer. Anyone, in particular a client receiving a certified Deal, can
+ * thus check that
+ *
+ *     secrets[i] * G == C_i + K_i
+ *
+ * where C_i = share_i * G is the commitment to the share given by the public
+ * polynomial, i.e., that every wrapped share of the Deal hides a share
+ * consistent with the public polynomial under the committed key. Insurers
+ * still check that the key they derive matches K_i when verifying their share,
+ * and blame the Dealer otherwise.
+ *
+ * ShareProofs are produced by ConstructDeal for a Deal on which SetVerifiable
+ * was called. They are part of the Deal: they are marshalled with it in all
+ * its encodings, covered by the signatures of the insurers through the digest
+ * of the secrets, and checked whenever the Deal is verified.
+ */
+type ShareProofs struct {
+
+	// The suite of the Deal
+	suite abstract.Suite
+
+	// The commitments to the keys wrapping the shares of the insurers
+	keys []abstract.Point
+}
+
+/* Makes ConstructDeal produce ShareProofs, see ShareProofs. It must be called
+ * before ConstructDeal, and the ShareWrapper of the Deal, if any, must be a
+ * VerifiableShareWrapper.
+ *
+ * Returns
+ *   The Deal itself
+ */
+func (p *Deal) SetVerifiable() *Deal {
+	p.verifiable = true
+	return p
+}
+
+/* Returns the ShareProofs of the Deal, or nil if it was constructed without
+ * SetVerifiable.
+ */
+func (p *Deal) ShareProofs() *ShareProofs {
+	return p.shareProofs
+}
+
+/* Returns the commitment K_i to the key wrapping the share of insurer i.
+ */
+func (sp *ShareProofs) WrapKey(i int) abstract.Point {
+	return abstract.ClonePoint(sp.keys[i])
+}
+
+// Returns a deep copy of the ShareProofs, or nil for nil ShareProofs.
+func (sp *ShareProofs) clone() *ShareProofs {
+	if sp == nil {
+		return nil
+	}
+	return &ShareProofs{sp.suite, abstract.ClonePoints(sp.keys)}
+}
+
+/* Verifies the ShareProofs of the Deal, checking that the wrapped share of
+ * every insurer matches the public polynomial.
+ *
+ * Returns
+ *   nil if the Deal carries valid ShareProofs, ErrInvalidShareProof
+ *   otherwise
+ */
+func (p *Deal) VerifyShareProofs() error {
+	if p.shareProofs == nil || p.isPublic() {
+		return ErrInvalidShareProof
+	}
+	return p.verifyShareProofs()
+}
+
+/* An internal helper verifying the ShareProofs of the Deal, if any. The public
+ * view of a Deal has no secrets to check them against: its ShareProofs are
+ * only covered by the digest of the secrets.
+ *
+ * Returns
+ *   nil if the Deal has no ShareProofs or valid ones, an error otherwise
+ */
+func (p *Deal) verifyShareProofs() error {
+	sp := p.shareProofs
+	if sp == nil || p.isPublic() {
+		return nil
+	}
+	if len(sp.keys) != p.n || len(p.secrets) != p.n {
+		return ErrInvalidShareProof
+	}
+	if err := checkSubgroup(sp.keys...); err != nil {
+		return err
+	}
+	for i := 0; i < p.n; i++ {
+		wrapped := p.suite.Point().Mul(nil, p.secrets[i])
+		expected := p.suite.Point().Add(p.pubPoly.Eval(i), sp.keys[i])
+		if !wrapped.Equal(expected) {
+			return ErrInvalidShareProof
+		}
+	}
+	return nil
+}
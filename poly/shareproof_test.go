@@ -0,0 +1,114 @@
+package poly
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dedis/crypto/random"
+)
+
+func TestShareProofs(t *testing.T) {
+	deal := new(Deal).SetVerifiable().ConstructDeal(secretKey, DealerKey,
+		pt, r, insurerList)
+	if err := deal.VerifyShareProofs(); err != nil {
+		t.Fatal(err)
+	}
+	if basicDeal.ShareProofs() != nil ||
+		basicDeal.VerifyShareProofs() != ErrInvalidShareProof {
+		t.Error("Deals are not verifiable by default")
+	}
+
+	// The commitments match the keys derived by the insurers
+	i := 3
+	share, err := deal.RevealShare(i, insurerKeys[i])
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := deal.suite.Scalar().Sub(deal.secrets[i], share)
+	if !deal.ShareProofs().WrapKey(i).Equal(deal.suite.Point().Mul(nil, k)) {
+		t.Error("Commitment does not match the wrapping key")
+	}
+
+	// The proofs are part of every encoding of the Deal
+	decoded := []*Deal{}
+	buf, err := deal.MarshalBinary()
+	if err != nil || len(buf) != deal.MarshalSize() {
+		t.Fatal("Marshalling failed: ", err)
+	}
+	d := new(Deal).UnmarshalInit(pt, r, numInsurers, suite)
+	if err := d.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded = append(decoded, d)
+	d = new(Deal).UnmarshalInit(pt, r, numInsurers, suite)
+	if _, err := d.UnmarshalFrom(bytes.NewReader(buf)); err != nil {
+		t.Fatal(err)
+	}
+	decoded = append(decoded, d)
+	buf, _ = deal.MarshalJSON()
+	d = new(Deal)
+	if err := d.UnmarshalJSON(buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded = append(decoded, d)
+	buf, _ = deal.MarshalProto()
+	d = new(Deal)
+	if err := d.UnmarshalProto(protoSuites, buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded = append(decoded, d)
+	buf, _ = deal.GobEncode()
+	d = new(Deal)
+	if err := d.GobDecode(buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded = append(decoded, d)
+	for k, d := range decoded {
+		if !deal.Equal(d) || d.VerifyShareProofs() != nil {
+			t.Error("ShareProofs lost by the encoding", k)
+		}
+	}
+
+	// The signatures of the insurers cover the proofs
+	response, err := deal.ProduceResponse(0, insurerKeys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	stripped := deal.clone()
+	stripped.shareProofs = nil
+	if new(State).Init(stripped).AddResponse(0, response) == nil {
+		t.Error("Signature should not cover a Deal without its ShareProofs")
+	}
+	public, _ := deal.Public()
+	if err := new(State).Init(*public).AddResponse(0, response); err != nil {
+		t.Error("Public view should be approved with the proofs", err)
+	}
+
+	// A wrapped share that does not match the public polynomial is rejected
+	// by anyone, without the help of the insurer
+	tampered := deal.clone()
+	tampered.secrets[i] = deal.suite.Scalar().Pick(random.Stream)
+	if tampered.VerifyShareProofs() != ErrInvalidShareProof ||
+		tampered.verifyDeal() != ErrInvalidShareProof {
+		t.Error("Tampered share verified")
+	}
+	buf, _ = tampered.MarshalBinary()
+	if new(Deal).UnmarshalInit(pt, r, numInsurers, suite).
+		UnmarshalBinary(buf) != ErrInvalidShareProof {
+		t.Error("Tampered Deal decoded")
+	}
+	tampered = deal.clone()
+	tampered.shareProofs.keys = tampered.shareProofs.keys[1:]
+	if tampered.VerifyShareProofs() != ErrInvalidShareProof {
+		t.Error("Truncated share proofs verified")
+	}
+
+	// Custom wrappers must support verification
+	defer func() {
+		if recover() == nil {
+			t.Error("Verifiable Deal constructed without a VerifiableShareWrapper")
+		}
+	}()
+	new(Deal).SetVerifiable().SetShareWrapper(newEncKeyWrapper(DealerKey, nil)).
+		ConstructDeal(secretKey, DealerKey, pt, r, insurerList)
+}
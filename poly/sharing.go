@@ -173,9 +173,34 @@ func (ps *PriShares) xCoords() []abstract.Scalar {
 	return x
 }
 
+// Error returned by SecretOrError when not enough shares are present.
+var ErrNotEnoughShares = errors.New("Not enough shares to reconstruct secret")
+
+// Return whether at least a threshold k of shares are populated (non-nil),
+// i.e., whether Secret() can reconstruct the secret.
+func (ps *PriShares) CanRecover() bool {
+	c := 0
+	for i := range ps.s {
+		if ps.s[i] != nil {
+			c++
+		}
+	}
+	return c >= ps.k
+}
+
+// Same as Secret(), but returns ErrNotEnoughShares instead of panicking
+// if less than a threshold k of shares are populated.
+func (ps *PriShares) SecretOrError() (abstract.Scalar, error) {
+	if !ps.CanRecover() {
+		return nil, ErrNotEnoughShares
+	}
+	return ps.Secret(), nil
+}
+
 // Use Lagrange interpolation to reconstruct a secret,
 // from a private share array of which
 // at least a threshold k of shares are populated (non-nil).
+// Panics if there are not enough shares, see SecretOrError.
 func (ps *PriShares) Secret() abstract.Scalar {
 
 	// compute Lagrange interpolation for point x=0 (the shared secret)
@@ -267,6 +267,26 @@ func TestPriSharesSecret(t *testing.T) {
 	test(testShares)
 }
 
+// Ensures that SecretOrError reports missing shares instead of panicking.
+func TestPriSharesSecretOrError(t *testing.T) {
+	testShares := producePriShares(group, k, k, secret)
+	if !testShares.CanRecover() {
+		t.Error("The shares should be enough to reconstruct the secret.")
+	}
+	result, err := testShares.SecretOrError()
+	if err != nil || !secret.Equal(result) {
+		t.Error("The secret failed to be reconstructed.", err)
+	}
+
+	testShares.s[0] = nil
+	if testShares.CanRecover() {
+		t.Error("The shares should not be enough to reconstruct the secret.")
+	}
+	if _, err := testShares.SecretOrError(); err != ErrNotEnoughShares {
+		t.Error("SecretOrError should have failed.", err)
+	}
+}
+
 // Tests the string function by simply verifying that it runs to completion.
 func TestPriSharesString(t *testing.T) {
 	_ = testPriPolyGl.String()
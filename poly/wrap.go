@@ -0,0 +1,159 @@
+package poly
+
+import (
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/proof"
+)
+
+/* A ShareWrapper is responsible for encrypting the shares of a Deal for the
+ * insurers. By default, a Deal wraps each share by adding a secret derived
+ * from the Diffie-Hellman shared key between the Dealer and the insurer (see
+ * NewDiffieHellmanWrapper). Users of this code can plug their own wrapping
+ * with Deal.SetShareWrapper, for example to keep the long-term secrets in an
+ * HSM or to encrypt the shares with keys that differ from the signing keys.
+ *
+ * A ShareWrapper is always used on behalf of a single party: the Dealer uses
+ * Wrap while constructing the Deal, whereas an insurer uses Unwrap to verify
+ * or reveal its share and Disclose to produce a blameProof. Open is used by
+ * anyone to check a blameProof and must not depend on any secret.
+ *
+ * Since the wrapped shares are marshalled as part of the Deal, a wrapped share
+ * must remain an abstract.Scalar of the Deal's suite.
+ */
+type ShareWrapper interface {
+
+	// Wrap encrypts a share for the insurer with the given long-term public
+	// key. Called by the Dealer.
+	Wrap(insurer abstract.Point, share abstract.Scalar) (abstract.Scalar, error)
+
+	// Unwrap decrypts a share wrapped by the Dealer with the given
+	// long-term public key. Called by the insurer.
+	Unwrap(dealer abstract.Point, wrapped abstract.Scalar) (abstract.Scalar, error)
+
+	// Disclose returns a key and a proof of its correctness allowing anyone
+	// to unwrap the shares sent to this insurer by the given Dealer. Called
+	// by the insurer when blaming the Dealer.
+	Disclose(dealer abstract.Point) (abstract.Point, []byte, error)
+
+	// Open verifies a key produced by Disclose and uses it to decrypt a
+	// wrapped share.
+	Open(dealer, key abstract.Point, prf []byte, wrapped abstract.Scalar) (abstract.Scalar, error)
+}
+
+/* A VerifiableShareWrapper is a ShareWrapper whose wrapped shares can be
+ * checked publicly against the public polynomial of the Deal, see ShareProofs.
+ * Wrap must add to the share a key k that depends only on the insurer, i.e.,
+ * return share + k.
+ */
+type VerifiableShareWrapper interface {
+	ShareWrapper
+
+	// WrapKey returns the commitment k * G to the key k added by Wrap to
+	// the share of the insurer with the given long-term public key.
+	// Called by the Dealer.
+	WrapKey(insurer abstract.Point) (abstract.Point, error)
+}
+
+// The default ShareWrapper based on Diffie-Hellman shared secrets. It is a
+// VerifiableShareWrapper.
+type diffieHellmanWrapper struct {
+
+	// The suite used for the Diffie-Hellman exchange
+	suite abstract.Suite
+
+	// The long-term keypair of the party using the wrapper. It may be nil
+	// if the wrapper is only used to Open shares.
+	key *config.KeyPair
+}
+
+/* Returns the default ShareWrapper, which encrypts a share by adding to it a
+ * scalar derived from the Diffie-Hellman shared key between the Dealer and
+ * the insurer.
+ *
+ * Arguments
+ *    key = the long-term keypair of the Dealer or insurer using the wrapper
+ *
+ * Returns
+ *   The Diffie-Hellman ShareWrapper
+ */
+func NewDiffieHellmanWrapper(key *config.KeyPair) ShareWrapper {
+	return &diffieHellmanWrapper{key.Suite, key}
+}
+
+func (w *diffieHellmanWrapper) Wrap(insurer abstract.Point,
+	share abstract.Scalar) (abstract.Scalar, error) {
+	diffieBase := w.suite.Point().Mul(insurer, w.key.Secret)
+	diffieSecret := diffieHellmanSecret(w.suite, diffieBase)
+	return w.suite.Scalar().Add(share, diffieSecret), nil
+}
+
+func (w *diffieHellmanWrapper) WrapKey(insurer abstract.Point) (abstract.Point,
+	error) {
+	diffieBase := w.suite.Point().Mul(insurer, w.key.Secret)
+	diffieSecret := diffieHellmanSecret(w.suite, diffieBase)
+	return w.suite.Point().Mul(nil, diffieSecret), nil
+}
+
+func (w *diffieHellmanWrapper) Unwrap(dealer abstract.Point,
+	wrapped abstract.Scalar) (abstract.Scalar, error) {
+	diffieBase := w.suite.Point().Mul(dealer, w.key.Secret)
+	diffieSecret := diffieHellmanSecret(w.suite, diffieBase)
+	return w.suite.Scalar().Sub(wrapped, diffieSecret), nil
+}
+
+func (w *diffieHellmanWrapper) Disclose(dealer abstract.Point) (abstract.Point,
+	[]byte, error) {
+	diffieKey := w.suite.Point().Mul(dealer, w.key.Secret)
+
+	choice := make(map[proof.Predicate]int)
+	pred := proof.Rep("D", "x", "P")
+	choice[pred] = 1
+	rand := w.suite.Cipher(abstract.RandomKey)
+	sval := map[string]abstract.Scalar{"x": w.key.Secret}
+	pval := map[string]abstract.Point{"D": diffieKey, "P": dealer}
+	prover := pred.Prover(w.suite, sval, pval, choice)
+	prf, err := proof.HashProve(w.suite, protocolName, rand, prover)
+	if err != nil {
+		return nil, nil, err
+	}
+	return diffieKey, prf, nil
+}
+
+func (w *diffieHellmanWrapper) Open(dealer, key abstract.Point, prf []byte,
+	wrapped abstract.Scalar) (abstract.Scalar, error) {
+	if key == nil {
+		return nil, errors.New("Nil Diffie-Hellman key")
+	}
+
+	// Verify the Diffie-Hellman shared secret was constructed properly
+	pval := map[string]abstract.Point{"D": key, "P": dealer}
+	pred := proof.Rep("D", "x", "P")
+	verifier := pred.Verifier(w.suite, pval)
+	if err := proof.HashVerify(w.suite, protocolName, verifier, prf); err != nil {
+		return nil, err
+	}
+	diffieSecret := diffieHellmanSecret(w.suite, key)
+	return w.suite.Scalar().Sub(wrapped, diffieSecret), nil
+}
+
+/* Given a Diffie-Hellman shared public key, produces a scalar to encrypt
+ * another scalar
+ *
+ * Arguments
+ *    suite       = the suite of the Diffie-Hellman key
+ *    diffieBase  = the DH shared public key
+ *
+ * Return
+ *   the DH secret
+ */
+func diffieHellmanSecret(suite abstract.Suite, diffieBase abstract.Point) abstract.Scalar {
+	buff, err := diffieBase.MarshalBinary()
+	if err != nil {
+		panic("Bad shared secret for Diffie-Hellman given.")
+	}
+	cipher := suite.Cipher(buff)
+	return suite.Scalar().Pick(cipher)
+}
@@ -0,0 +1,90 @@
+package poly
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+)
+
+// A ShareWrapper encrypting shares with dedicated encryption keys instead of
+// the long-term signing keys of the Dealer and insurers.
+type encKeyWrapper struct {
+	dh      ShareWrapper
+	encKeys map[string]abstract.Point // long-term key => encryption key
+}
+
+func newEncKeyWrapper(own *config.KeyPair, encKeys map[string]abstract.Point) *encKeyWrapper {
+	return &encKeyWrapper{NewDiffieHellmanWrapper(own), encKeys}
+}
+
+func (w *encKeyWrapper) Wrap(insurer abstract.Point, share abstract.Scalar) (abstract.Scalar, error) {
+	return w.dh.Wrap(w.encKeys[insurer.String()], share)
+}
+
+func (w *encKeyWrapper) Unwrap(dealer abstract.Point, wrapped abstract.Scalar) (abstract.Scalar, error) {
+	return w.dh.Unwrap(w.encKeys[dealer.String()], wrapped)
+}
+
+func (w *encKeyWrapper) Disclose(dealer abstract.Point) (abstract.Point, []byte, error) {
+	return w.dh.Disclose(w.encKeys[dealer.String()])
+}
+
+func (w *encKeyWrapper) Open(dealer, key abstract.Point, prf []byte, wrapped abstract.Scalar) (abstract.Scalar, error) {
+	return w.dh.Open(w.encKeys[dealer.String()], key, prf, wrapped)
+}
+
+func TestDealShareWrapper(t *testing.T) {
+	encKeys := make(map[string]abstract.Point)
+	dealerEnc := produceKeyPair()
+	encKeys[DealerKey.Public.String()] = dealerEnc.Public
+	insurerEnc := make([]*config.KeyPair, numInsurers)
+	for i := range insurerEnc {
+		insurerEnc[i] = produceKeyPair()
+		encKeys[insurerList[i].String()] = insurerEnc[i].Public
+	}
+
+	deal := new(Deal).SetShareWrapper(newEncKeyWrapper(dealerEnc, encKeys))
+	deal.ConstructDeal(secretKey, DealerKey, pt, r, insurerList)
+
+	// The default wrapping cannot decrypt the shares.
+	deal.SetShareWrapper(nil)
+	if deal.verifyShare(0, insurerKeys[0]) != ErrShareCheckFailed {
+		t.Error("Share should not be decrypted with the default wrapping")
+	}
+
+	state := new(State).Init(*deal)
+	for i := 0; i < numInsurers; i++ {
+		deal.SetShareWrapper(newEncKeyWrapper(insurerEnc[i], encKeys))
+		response, err := deal.ProduceResponse(i, insurerKeys[i])
+		if err != nil {
+			t.Fatal("ProduceResponse failed: ", err)
+		}
+		if response.rtype != signatureResponse {
+			t.Fatal("Share should be valid with the right wrapper")
+		}
+		if err := state.AddResponse(i, response); err != nil {
+			t.Fatal("AddResponse failed: ", err)
+		}
+	}
+	if state.DealCertified() != nil {
+		t.Fatal("Deal should be certified")
+	}
+
+	deal.SetShareWrapper(newEncKeyWrapper(insurerEnc[0], encKeys))
+	share, err := deal.RevealShare(0, insurerKeys[0])
+	if err != nil || deal.VerifyRevealedShare(0, share) != nil {
+		t.Error("Revealed share should be valid")
+	}
+
+	// Blame a bad share using the custom wrapping.
+	deal.secrets[0] = deal.suite.Scalar().Add(deal.secrets[0], deal.suite.Scalar().One())
+	response, err := deal.ProduceResponse(0, insurerKeys[0])
+	if err != nil || response.rtype != blameProofResponse {
+		t.Fatal("Insurer should have blamed the Dealer")
+	}
+	deal.SetShareWrapper(newEncKeyWrapper(produceKeyPair(), encKeys))
+	if err := deal.verifyBlame(0, response.blameProof); err != nil {
+		t.Error("Blame should be valid: ", err)
+	}
+}
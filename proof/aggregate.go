@@ -0,0 +1,128 @@
+package proof
+
+import (
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/hash"
+	"github.com/dedis/crypto/random"
+)
+
+// Length in bytes of the random weights of AggregateVerify. An invalid
+// instance passes the verification with probability at most 2^-128.
+const aggregateWeightLen = 16
+
+var errorEmptyProof = errors.New("no instances to prove or verify")
+
+// AggregateDLEQProof is a NIZK dlog-equality proof for a vector of instances
+// (G_i, H_i, xG_i, xH_i), e.g., all the encrypted shares of a PVSS run. The
+// instances share a single challenge c = H(xG,xH,vG,vH) computed over all of
+// them, as in NewDLEQProofBatch, which is stored only once.
+type AggregateDLEQProof struct {
+	C  abstract.Scalar   // common challenge
+	R  []abstract.Scalar // responses
+	VG []abstract.Point  // public commitments with respect to the base points G
+	VH []abstract.Point  // public commitments with respect to the base points H
+}
+
+// AggregateProve computes an aggregate NIZK dlog-equality proof for the
+// secrets with respect to the base points G and H. Besides the proof, this
+// function also returns the encrypted base points xG and xH.
+func AggregateProve(suite abstract.Suite, G []abstract.Point, H []abstract.Point, secrets []abstract.Scalar) (proof *AggregateDLEQProof, xG []abstract.Point, xH []abstract.Point, err error) {
+	if len(secrets) == 0 {
+		return nil, nil, nil, errorEmptyProof
+	}
+	proofs, xG, xH, err := NewDLEQProofBatch(suite, G, H, secrets)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	n := len(proofs)
+	proof = &AggregateDLEQProof{
+		C:  proofs[0].C,
+		R:  make([]abstract.Scalar, n),
+		VG: make([]abstract.Point, n),
+		VH: make([]abstract.Point, n),
+	}
+	for i, p := range proofs {
+		proof.R[i] = p.R
+		proof.VG[i] = p.VG
+		proof.VH[i] = p.VH
+	}
+	return proof, xG, xH, nil
+}
+
+// AggregateVerify examines the validity of an aggregate NIZK dlog-equality
+// proof. It recomputes the common challenge, then checks all the instances at
+// once with a random linear combination of the verification equations of
+// DLEQProof.Verify: for random weights w_i,
+//   sum(w_i*vG_i) == sum(w_i*r_i*G_i) + c*sum(w_i*xG_i)
+//   sum(w_i*vH_i) == sum(w_i*r_i*H_i) + c*sum(w_i*xH_i)
+// The weights are only 128 bits long, which roughly halves the cost of the
+// multiplications by the weights, and consecutive instances sharing a base
+// point, as in PVSS, share its multiplication. The function returns nil iff
+// all the instances are valid, except with negligible probability. To find
+// the invalid instances of a rejected proof, verify them one by one with
+// Proof(i).Verify.
+func AggregateVerify(suite abstract.Suite, G []abstract.Point, H []abstract.Point, xG []abstract.Point, xH []abstract.Point, proof *AggregateDLEQProof) error {
+	n := len(G)
+	if n == 0 {
+		return errorEmptyProof
+	}
+	if len(H) != n || len(xG) != n || len(xH) != n || len(proof.R) != n ||
+		len(proof.VG) != n || len(proof.VH) != n {
+		return errorDifferentLengths
+	}
+
+	// Common challenge
+	cb, err := hash.Structures(suite.Hash(), xG, xH, proof.VG, proof.VH)
+	if err != nil {
+		return err
+	}
+	c := suite.Scalar().Pick(suite.Cipher(cb))
+	if proof.C == nil || !c.Equal(proof.C) {
+		return errorInvalidProof
+	}
+
+	w := make([]abstract.Scalar, n)
+	for i := range w {
+		w[i] = suite.Scalar().SetBytes(random.Bytes(aggregateWeightLen, random.Stream))
+	}
+	if !aggregateCheck(suite, w, c, proof.R, G, xG, proof.VG) ||
+		!aggregateCheck(suite, w, c, proof.R, H, xH, proof.VH) {
+		return errorInvalidProof
+	}
+	return nil
+}
+
+// Proof returns the individual proof of the instance i, to be checked with
+// DLEQProof.Verify.
+func (p *AggregateDLEQProof) Proof(i int) *DLEQProof {
+	return &DLEQProof{p.C, p.R[i], p.VG[i], p.VH[i]}
+}
+
+// Checks sum(w_i*V_i) == sum(w_i*r_i*B_i) + c*sum(w_i*X_i) for the base points
+// B, the encrypted base points X and the commitments V.
+func aggregateCheck(suite abstract.Suite, w []abstract.Scalar, c abstract.Scalar, r []abstract.Scalar, B []abstract.Point, X []abstract.Point, V []abstract.Point) bool {
+	left := suite.Point().Null()
+	sumX := suite.Point().Null()
+	sumRB := suite.Point().Null()
+	rb := suite.Scalar().Zero() // sum of w_i*r_i for the current base point
+	P := suite.Point()
+	s := suite.Scalar()
+	for i := range w {
+		if V[i] == nil || X[i] == nil || r[i] == nil {
+			return false
+		}
+		if i > 0 && B[i] != B[i-1] && !B[i].Equal(B[i-1]) {
+			sumRB.Add(sumRB, P.Mul(B[i-1], rb))
+			rb.Zero()
+		}
+		rb.Add(rb, s.Mul(w[i], r[i]))
+		left.Add(left, P.Mul(V[i], w[i]))
+		sumX.Add(sumX, P.Mul(X[i], w[i]))
+	}
+	sumRB.Add(sumRB, P.Mul(B[len(B)-1], rb))
+	right := suite.Point().Mul(sumX, c)
+	right.Add(right, sumRB)
+	return left.Equal(right)
+}
@@ -0,0 +1,96 @@
+package proof
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateProof(t *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	n := 10
+	x := make([]abstract.Scalar, n)
+	g := make([]abstract.Point, n)
+	h := make([]abstract.Point, n)
+	for i := range x {
+		x[i] = suite.Scalar().Pick(random.Stream)
+		g[i], _ = suite.Point().Pick([]byte(fmt.Sprintf("G%d", i)), random.Stream)
+		h[i], _ = suite.Point().Pick([]byte(fmt.Sprintf("H%d", i)), random.Stream)
+	}
+	proof, xG, xH, err := AggregateProve(suite, g, h, x)
+	require.Nil(t, err)
+	require.Nil(t, AggregateVerify(suite, g, h, xG, xH, proof))
+	for i := range x {
+		require.Nil(t, proof.Proof(i).Verify(suite, g[i], h[i], xG[i], xH[i]))
+	}
+
+	// A single invalid instance makes the whole proof fail
+	proof.R[3] = suite.Scalar().Pick(random.Stream)
+	require.Equal(t, AggregateVerify(suite, g, h, xG, xH, proof), errorInvalidProof)
+	require.Equal(t, proof.Proof(3).Verify(suite, g[3], h[3], xG[3], xH[3]), errorInvalidProof)
+
+	// Instances cannot be swapped
+	proof, xG, xH, _ = AggregateProve(suite, g, h, x)
+	xG[0], xG[1] = xG[1], xG[0]
+	require.Equal(t, AggregateVerify(suite, g, h, xG, xH, proof), errorInvalidProof)
+	require.Equal(t, AggregateVerify(suite, g, h, xG[1:], xH, proof), errorDifferentLengths)
+
+	_, _, _, err = AggregateProve(suite, nil, nil, nil)
+	require.Equal(t, err, errorEmptyProof)
+}
+
+// In PVSS, all the instances share the base point G.
+func TestAggregateProofCommonBase(t *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	n := 10
+	x := make([]abstract.Scalar, n)
+	g := make([]abstract.Point, n)
+	h := make([]abstract.Point, n)
+	G, _ := suite.Point().Pick([]byte("G"), random.Stream)
+	for i := range x {
+		x[i] = suite.Scalar().Pick(random.Stream)
+		g[i] = G
+		h[i], _ = suite.Point().Pick([]byte(fmt.Sprintf("H%d", i)), random.Stream)
+	}
+	proof, xG, xH, err := AggregateProve(suite, g, h, x)
+	require.Nil(t, err)
+	require.Nil(t, AggregateVerify(suite, g, h, xG, xH, proof))
+	proof.VG[n-1], _ = suite.Point().Pick(nil, random.Stream)
+	require.Equal(t, AggregateVerify(suite, g, h, xG, xH, proof), errorInvalidProof)
+}
+
+func benchmarkDLEQVerify(b *testing.B, aggregate bool) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	n := 100
+	x := make([]abstract.Scalar, n)
+	g := make([]abstract.Point, n)
+	h := make([]abstract.Point, n)
+	for i := range x {
+		x[i] = suite.Scalar().Pick(random.Stream)
+		g[i] = suite.Point().Base()
+		h[i], _ = suite.Point().Pick([]byte(fmt.Sprintf("H%d", i)), random.Stream)
+	}
+	proof, xG, xH, _ := AggregateProve(suite, g, h, x)
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		if aggregate {
+			AggregateVerify(suite, g, h, xG, xH, proof)
+			continue
+		}
+		for i := range x {
+			proof.Proof(i).Verify(suite, g[i], h[i], xG[i], xH[i])
+		}
+	}
+}
+
+func BenchmarkDLEQVerify(b *testing.B) {
+	benchmarkDLEQVerify(b, false)
+}
+
+func BenchmarkAggregateVerify(b *testing.B) {
+	benchmarkDLEQVerify(b, true)
+}
@@ -0,0 +1,156 @@
+package proof
+
+import (
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+// A CompiledPredicate is a Predicate prepared once for a given suite,
+// for proving or verifying many instances of the same statement
+// with different values, such as thousands of identical proofs
+// about different ciphertexts.
+//
+// Compiling enumerates the variables of the predicate,
+// checks that its Or operators are all above its And operators,
+// and records the values of the generators common to all instances,
+// so that none of this work is repeated for each proof.
+// A CompiledPredicate is immutable once created,
+// and is safe for concurrent use by multiple goroutines.
+type CompiledPredicate struct {
+	pred Predicate
+	prf  proof                     // template with enumerated variables
+	gens map[string]abstract.Point // values of the common generators
+}
+
+// Compile prepares a predicate for repeated proofs under a given suite.
+// The generators map gives the values of the public Point variables
+// that are the same in all instances of the statement, typically bases;
+// it may be nil, and is copied so that the caller may reuse it.
+// The values of the remaining Point variables are provided for each proof.
+func Compile(suite abstract.Suite, pred Predicate,
+	generators map[string]abstract.Point) (*CompiledPredicate, error) {
+
+	if err := checkNesting(pred, false); err != nil {
+		return nil, err
+	}
+	cp := &CompiledPredicate{pred: pred}
+	cp.prf = *proof{}.init(suite, pred)
+	cp.gens = make(map[string]abstract.Point, len(generators))
+	for name, G := range generators {
+		if cp.prf.pidx[name] == 0 {
+			return nil, errors.New("generator " + name +
+				" is not used in predicate " + pred.String())
+		}
+		cp.gens[name] = G
+	}
+	return cp, nil
+}
+
+// checkNesting ensures that no Or predicate appears below an And predicate,
+// which the proof engine would otherwise only detect when proving.
+func checkNesting(pred Predicate, inAnd bool) error {
+	switch p := pred.(type) {
+	case *orPred:
+		if inAnd {
+			return errors.New("Or predicate within And predicate: " +
+				p.String())
+		}
+		for _, sub := range *p {
+			if err := checkNesting(sub, false); err != nil {
+				return err
+			}
+		}
+	case *andPred:
+		for _, sub := range *p {
+			if err := checkNesting(sub, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Predicate returns the predicate this CompiledPredicate was compiled from,
+// which may serve as key in the choice map of Or branches.
+func (cp *CompiledPredicate) Predicate() Predicate {
+	return cp.pred
+}
+
+// points merges the common generators with the per-instance points.
+// Per-instance values may not override common generators.
+func (cp *CompiledPredicate) points(points map[string]abstract.Point) (
+	map[string]abstract.Point, error) {
+
+	pval := make(map[string]abstract.Point, cp.prf.npvars)
+	for name, G := range cp.gens {
+		pval[name] = G
+	}
+	for name, P := range points {
+		if _, ok := cp.gens[name]; ok {
+			return nil, errors.New("point " + name +
+				" redefines a compiled generator")
+		}
+		pval[name] = P
+	}
+	for _, name := range cp.prf.pvar[1:] {
+		if pval[name] == nil {
+			return nil, errors.New("missing value for point " + name)
+		}
+	}
+	return pval, nil
+}
+
+// Prover creates a Sigma-protocol prover for one instance of the statement,
+// given the secrets, the points specific to this instance,
+// and the Or branch choices as for Predicate.Prover.
+func (cp *CompiledPredicate) Prover(secrets map[string]abstract.Scalar,
+	points map[string]abstract.Point,
+	choice map[Predicate]int) (Prover, error) {
+
+	pval, err := cp.points(points)
+	if err != nil {
+		return nil, err
+	}
+	prf := cp.prf // fresh per-proof state sharing the enumerated variables
+	return prf.prover(cp.pred, secrets, pval, choice), nil
+}
+
+// Verifier creates a Sigma-protocol verifier for one instance of the
+// statement, given the points specific to this instance.
+func (cp *CompiledPredicate) Verifier(points map[string]abstract.Point) (
+	Verifier, error) {
+
+	pval, err := cp.points(points)
+	if err != nil {
+		return nil, err
+	}
+	prf := cp.prf
+	return prf.verifier(cp.pred, pval), nil
+}
+
+// HashProve produces a non-interactive proof of one instance of the
+// statement, as HashProve does for the Prover of the predicate.
+func (cp *CompiledPredicate) HashProve(protocolName string,
+	random abstract.Cipher, secrets map[string]abstract.Scalar,
+	points map[string]abstract.Point,
+	choice map[Predicate]int) ([]byte, error) {
+
+	prover, err := cp.Prover(secrets, points, choice)
+	if err != nil {
+		return nil, err
+	}
+	return HashProve(cp.prf.s, protocolName, random, prover)
+}
+
+// HashVerify checks a proof produced by HashProve for one instance of the
+// statement, given the points specific to this instance.
+func (cp *CompiledPredicate) HashVerify(protocolName string,
+	points map[string]abstract.Point, proof []byte) error {
+
+	verifier, err := cp.Verifier(points)
+	if err != nil {
+		return err
+	}
+	return HashVerify(cp.prf.s, protocolName, verifier, proof)
+}
@@ -0,0 +1,189 @@
+package proof
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/nist"
+)
+
+func TestCompiledPredicate(t *testing.T) {
+	suite := nist.NewAES128SHA256P256()
+	rand := suite.Cipher(abstract.RandomKey)
+	B := suite.Point().Base()
+	H, _ := suite.Point().Pick(nil, suite.Cipher([]byte("H")))
+
+	// Prove knowledge of the discrete log of X, or of Y
+	pred := Or(Rep("X", "x", "B"), Rep("Y", "y", "H"))
+	choice := map[Predicate]int{pred: 0}
+	gens := map[string]abstract.Point{"B": B, "H": H}
+	cp, err := Compile(suite, pred, gens)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Prove and verify many instances concurrently
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rand := suite.Cipher(abstract.RandomKey)
+			x := suite.Scalar().Pick(rand)
+			pval := map[string]abstract.Point{
+				"X": suite.Point().Mul(nil, x),
+				"Y": suite.Point().Mul(H, suite.Scalar().Pick(rand)),
+			}
+			sval := map[string]abstract.Scalar{"x": x}
+			prf, err := cp.HashProve("TEST", rand, sval, pval, choice)
+			if err == nil {
+				err = cp.HashVerify("TEST", pval, prf)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A proof must not verify for another instance
+	x := suite.Scalar().Pick(rand)
+	pval := map[string]abstract.Point{
+		"X": suite.Point().Mul(nil, x),
+		"Y": suite.Point().Mul(H, suite.Scalar().Pick(rand)),
+	}
+	sval := map[string]abstract.Scalar{"x": x}
+	prf, err := cp.HashProve("TEST", rand, sval, pval, choice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pval["X"] = suite.Point().Mul(H, x)
+	if cp.HashVerify("TEST", pval, prf) == nil {
+		t.Fatal("Proof verified for the wrong statement")
+	}
+
+	// Missing and redefined points are rejected
+	if _, err := cp.Verifier(map[string]abstract.Point{"X": B}); err == nil {
+		t.Fatal("Missing point accepted")
+	}
+	pval["B"] = H
+	if _, err := cp.Verifier(pval); err == nil {
+		t.Fatal("Redefined generator accepted")
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	suite := nist.NewAES128SHA256P256()
+	B := suite.Point().Base()
+	rep := Rep("X", "x", "B")
+	if _, err := Compile(suite, And(rep, Or(rep, rep)), nil); err == nil {
+		t.Fatal("Or within And accepted")
+	}
+	gens := map[string]abstract.Point{"G": B}
+	if _, err := Compile(suite, rep, gens); err == nil {
+		t.Fatal("Unused generator accepted")
+	}
+}
+
+func TestHashVerifier(t *testing.T) {
+	suite := nist.NewAES128SHA256P256()
+	rand := suite.Cipher(abstract.RandomKey)
+	B := suite.Point().Base()
+	H, _ := suite.Point().Pick(nil, suite.Cipher([]byte("H")))
+
+	// Or of And, to exercise the reuse of every kind of sub-predicate
+	pred := Or(And(Rep("X", "x", "B"), Rep("Y", "x", "H")), Rep("Z", "z", "B"))
+	choice := map[Predicate]int{pred: 0}
+	cp, err := Compile(suite, pred, map[string]abstract.Point{"B": B, "H": H})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := sync.Pool{New: func() interface{} {
+		return cp.NewHashVerifier("TEST", NoReflection)
+	}}
+	plain := cp.NewHashVerifier("TEST")
+
+	for i := 0; i < 10; i++ {
+		x := suite.Scalar().Pick(rand)
+		pval := map[string]abstract.Point{
+			"X": suite.Point().Mul(nil, x),
+			"Y": suite.Point().Mul(H, x),
+			"Z": suite.Point().Mul(nil, suite.Scalar().Pick(rand)),
+		}
+		sval := map[string]abstract.Scalar{"x": x}
+		prf, err := cp.HashProve("TEST", rand, sval, pval, choice)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hv := pool.Get().(*HashVerifier)
+		if err := hv.Verify(pval, prf); err != nil {
+			t.Fatal(err)
+		}
+		if err := plain.Verify(pval, prf); err != nil {
+			t.Fatal(err)
+		}
+
+		// Invalid proofs are still rejected after successful ones
+		pval["Y"] = suite.Point().Mul(nil, x)
+		if hv.Verify(pval, prf) == nil || plain.Verify(pval, prf) == nil {
+			t.Fatal("Proof verified for the wrong statement")
+		}
+		if hv.Verify(pval, prf[1:]) == nil {
+			t.Fatal("Truncated proof verified")
+		}
+		pool.Put(hv)
+	}
+
+	if plain.Verify(map[string]abstract.Point{"X": B}, nil) == nil {
+		t.Fatal("Missing point accepted")
+	}
+	if plain.Verify(map[string]abstract.Point{"B": B}, nil) == nil {
+		t.Fatal("Redefined generator accepted")
+	}
+}
+
+func benchmarkHashVerify(b *testing.B, reuse bool) {
+	suite := nist.NewAES128SHA256P256()
+	rand := suite.Cipher(abstract.RandomKey)
+	pred := Rep("X", "x", "B")
+	cp, err := Compile(suite, pred,
+		map[string]abstract.Point{"B": suite.Point().Base()})
+	if err != nil {
+		b.Fatal(err)
+	}
+	x := suite.Scalar().Pick(rand)
+	pval := map[string]abstract.Point{"X": suite.Point().Mul(nil, x)}
+	prf, err := cp.HashProve("BENCH", rand,
+		map[string]abstract.Scalar{"x": x}, pval, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	hv := cp.NewHashVerifier("BENCH", NoReflection)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if reuse {
+			err = hv.Verify(pval, prf)
+		} else {
+			err = cp.HashVerify("BENCH", pval, prf)
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHashVerify(b *testing.B) {
+	benchmarkHashVerify(b, false)
+}
+
+func BenchmarkHashVerifierReuse(b *testing.B) {
+	benchmarkHashVerify(b, true)
+}
@@ -0,0 +1,412 @@
+package proof
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Predicates may be sent over the wire along with their proofs, in the text
+// form produced by String and read by Parse, or in the binary form of
+// MarshalPredicate and UnmarshalPredicate. Both forms are canonical:
+// they represent the exact tree of Rep, And and Or predicates,
+// since nesting an Or predicate changes the proof.
+// The text grammar, with optional spaces between tokens, is:
+//
+//	or   = and { "||" and }
+//	and  = atom { "&&" atom }
+//	atom = "(" or ")" | rep
+//	rep  = name "=" name "*" name { "+" name "*" name }
+//	name = one or more printable ASCII characters except ()=*+&| and spaces
+//
+// so that names such as "X[0]" may be used.
+//
+// Security note: a proof only shows that the prover knows secrets satisfying
+// the statement it was made for, and the challenges of HashProve depend on
+// the commitments only, not on the statement or on the values of the points.
+// A verifier receiving a statement from the prover must therefore check that
+// it is the statement it expects, e.g., with EqualPredicates against its own,
+// and that the generators are its own, and not only that the proof verifies:
+// otherwise, the prover could send an easier statement, such as an Or with a
+// branch it can prove, or a Rep whose base point it chose. To bind a proof to
+// its statement, include the text of the statement (and the values of its
+// points) in the protocolName of HashProve and HashVerify.
+
+// The limits enforced when reading predicates, so that a malicious statement
+// cannot exhaust the stack or memory of the verifier.
+const (
+	maxPredicateDepth = 32      // nesting of And and Or predicates
+	maxPredicateSize  = 1 << 16 // bytes of a text or binary predicate
+)
+
+// The tags of the predicates in the binary form
+const (
+	tagRep byte = iota + 1
+	tagAnd
+	tagOr
+)
+
+// Validate checks that a predicate can be proven and serialized:
+// it must consist of Rep, And and Or predicates only,
+// every And and Or predicate must have at least two sub-predicates,
+// no Or predicate may appear within an And predicate (see Predicate),
+// every Rep predicate must have at least one term,
+// and every variable must have a valid name,
+// used either for Scalar or for Point variables, but not both.
+func Validate(pred Predicate) error {
+	scalars := make(map[string]bool)
+	points := make(map[string]bool)
+	if err := validate(pred, false, 0, scalars, points); err != nil {
+		return err
+	}
+	for name := range scalars {
+		if points[name] {
+			return errors.New("variable " + name +
+				" used both as Scalar and as Point")
+		}
+	}
+	return nil
+}
+
+func validate(pred Predicate, inAnd bool, depth int,
+	scalars, points map[string]bool) error {
+	if depth > maxPredicateDepth {
+		return errors.New("predicate nested too deeply")
+	}
+	switch p := pred.(type) {
+	case *repPred:
+		if len(p.T) == 0 {
+			return errors.New("Rep predicate without terms")
+		}
+		names := []string{p.P}
+		points[p.P] = true
+		for _, t := range p.T {
+			scalars[t.S] = true
+			points[t.B] = true
+			names = append(names, t.S, t.B)
+		}
+		for _, name := range names {
+			if !validName(name) {
+				return errors.New("invalid variable name: \"" +
+					name + "\"")
+			}
+		}
+	case *andPred:
+		if len(*p) < 2 {
+			return errors.New("And predicate with less than 2 terms")
+		}
+		for _, sub := range *p {
+			if err := validate(sub, true, depth+1, scalars,
+				points); err != nil {
+				return err
+			}
+		}
+	case *orPred:
+		if inAnd {
+			return errors.New("Or predicate within And predicate")
+		}
+		if len(*p) < 2 {
+			return errors.New("Or predicate with less than 2 terms")
+		}
+		for _, sub := range *p {
+			if err := validate(sub, false, depth+1, scalars,
+				points); err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.New("unknown predicate type")
+	}
+	return nil
+}
+
+// isNameChar checks whether c may appear in a variable name.
+func isNameChar(c byte) bool {
+	return c > ' ' && c <= '~' && strings.IndexByte("()=*+&|", c) < 0
+}
+
+// validName checks that a variable name can be read back from the text
+// form, and is short enough for the binary form.
+func validName(name string) bool {
+	if len(name) == 0 || len(name) > 255 {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isNameChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// EqualPredicates checks whether two predicates are the same statement,
+// i.e., have the same tree with the same variable names.
+func EqualPredicates(p1, p2 Predicate) bool {
+	b1, err1 := MarshalPredicate(p1)
+	b2, err2 := MarshalPredicate(p2)
+	return err1 == nil && err2 == nil && bytes.Equal(b1, b2)
+}
+
+////////// Text form //////////
+
+// Parse reads a predicate in the text form produced by its String method,
+// and validates it (see Validate).
+func Parse(s string) (Predicate, error) {
+	if len(s) > maxPredicateSize {
+		return nil, errors.New("predicate too long")
+	}
+	p := &parser{s: s}
+	pred, err := p.or(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.skip(); p.pos != len(s) {
+		return nil, p.errorf("unexpected input")
+	}
+	if err := Validate(pred); err != nil {
+		return nil, err
+	}
+	return pred, nil
+}
+
+// parser is a recursive-descent parser of the text form.
+type parser struct {
+	s   string
+	pos int
+}
+
+func (p *parser) errorf(msg string) error {
+	return errors.New("predicate: " + msg + " at offset " +
+		strconv.Itoa(p.pos))
+}
+
+// skip advances past spaces.
+func (p *parser) skip() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\n\r", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// accept consumes the token tok if it comes next.
+func (p *parser) accept(tok string) bool {
+	p.skip()
+	if strings.HasPrefix(p.s[p.pos:], tok) {
+		p.pos += len(tok)
+		return true
+	}
+	return false
+}
+
+func (p *parser) name() (string, error) {
+	p.skip()
+	start := p.pos
+	for p.pos < len(p.s) && isNameChar(p.s[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected variable name")
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *parser) or(depth int) (Predicate, error) {
+	return p.list(depth, "||", Or, p.and)
+}
+
+func (p *parser) and(depth int) (Predicate, error) {
+	return p.list(depth, "&&", And, p.atom)
+}
+
+// list parses one or more operands separated by op, combined with the
+// constructor of op if there are several.
+func (p *parser) list(depth int, op string, combine func(...Predicate) Predicate,
+	operand func(int) (Predicate, error)) (Predicate, error) {
+	var sub []Predicate
+	for {
+		pred, err := operand(depth)
+		if err != nil {
+			return nil, err
+		}
+		sub = append(sub, pred)
+		if !p.accept(op) {
+			break
+		}
+	}
+	if len(sub) == 1 {
+		return sub[0], nil
+	}
+	return combine(sub...), nil
+}
+
+func (p *parser) atom(depth int) (Predicate, error) {
+	if p.accept("(") {
+		if depth >= maxPredicateDepth {
+			return nil, p.errorf("predicate nested too deeply")
+		}
+		pred, err := p.or(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.errorf("expected \")\"")
+		}
+		return pred, nil
+	}
+	P, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("=") {
+		return nil, p.errorf("expected \"=\"")
+	}
+	var sb []string
+	for {
+		S, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept("*") {
+			return nil, p.errorf("expected \"*\"")
+		}
+		B, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		sb = append(sb, S, B)
+		if !p.accept("+") {
+			return Rep(P, sb...), nil
+		}
+	}
+}
+
+////////// Binary form //////////
+
+// MarshalPredicate encodes a valid predicate (see Validate) in binary form.
+// Every predicate starts with a tag byte: a Rep predicate is followed
+// by the name of P and its number of terms as a big-endian uint16,
+// and then by the names of the Scalar and the base of each term,
+// whereas an And or Or predicate is followed by its number of
+// sub-predicates as a big-endian uint16 and the sub-predicates.
+// Each name is encoded as its length in a byte followed by its bytes.
+func MarshalPredicate(pred Predicate) ([]byte, error) {
+	if err := Validate(pred); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	marshal(&buf, pred)
+	if buf.Len() > maxPredicateSize {
+		return nil, errors.New("predicate too long")
+	}
+	return buf.Bytes(), nil
+}
+
+func marshal(buf *bytes.Buffer, pred Predicate) {
+	writeName := func(name string) {
+		buf.WriteByte(byte(len(name)))
+		buf.WriteString(name)
+	}
+	switch p := pred.(type) {
+	case *repPred:
+		buf.WriteByte(tagRep)
+		writeName(p.P)
+		binary.Write(buf, binary.BigEndian, uint16(len(p.T)))
+		for _, t := range p.T {
+			writeName(t.S)
+			writeName(t.B)
+		}
+	case *andPred:
+		buf.WriteByte(tagAnd)
+		binary.Write(buf, binary.BigEndian, uint16(len(*p)))
+		for _, sub := range *p {
+			marshal(buf, sub)
+		}
+	case *orPred:
+		buf.WriteByte(tagOr)
+		binary.Write(buf, binary.BigEndian, uint16(len(*p)))
+		for _, sub := range *p {
+			marshal(buf, sub)
+		}
+	}
+}
+
+// UnmarshalPredicate decodes a predicate encoded by MarshalPredicate,
+// and validates it (see Validate).
+func UnmarshalPredicate(buf []byte) (Predicate, error) {
+	if len(buf) > maxPredicateSize {
+		return nil, errors.New("predicate too long")
+	}
+	r := bytes.NewReader(buf)
+	pred, err := unmarshal(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("trailing bytes after predicate")
+	}
+	if err := Validate(pred); err != nil {
+		return nil, err
+	}
+	return pred, nil
+}
+
+var errShortPredicate = errors.New("predicate buffer too short")
+
+func unmarshal(r *bytes.Reader, depth int) (Predicate, error) {
+	if depth > maxPredicateDepth {
+		return nil, errors.New("predicate nested too deeply")
+	}
+	readName := func() (string, error) {
+		l, err := r.ReadByte()
+		if err != nil || int(l) > r.Len() {
+			return "", errShortPredicate
+		}
+		name := make([]byte, l)
+		r.Read(name)
+		return string(name), nil
+	}
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, errShortPredicate
+	}
+	var n uint16
+	switch tag {
+	case tagRep:
+		P, err := readName()
+		if err != nil {
+			return nil, err
+		}
+		if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+			return nil, errShortPredicate
+		}
+		if int(n) > r.Len()/4 { // each term takes at least 4 bytes
+			return nil, errShortPredicate
+		}
+		sb := make([]string, 2*n)
+		for i := range sb {
+			if sb[i], err = readName(); err != nil {
+				return nil, err
+			}
+		}
+		return Rep(P, sb...), nil
+	case tagAnd, tagOr:
+		if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+			return nil, errShortPredicate
+		}
+		if int(n) > r.Len() { // each predicate takes at least a byte
+			return nil, errShortPredicate
+		}
+		sub := make([]Predicate, n)
+		for i := range sub {
+			if sub[i], err = unmarshal(r, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		if tag == tagAnd {
+			return And(sub...), nil
+		}
+		return Or(sub...), nil
+	}
+	return nil, errors.New("unknown predicate tag")
+}
@@ -0,0 +1,144 @@
+package proof
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/nist"
+)
+
+func TestPredicateEncoding(t *testing.T) {
+	preds := []Predicate{
+		Rep("X", "x", "B"),
+		Rep("C", "m", "G", "r", "H"),
+		And(Rep("X", "x", "B"), Rep("T", "x", "BT")),
+		Or(And(Rep("X[0]", "x", "B"), Rep("T", "x", "BT")),
+			And(Rep("X[1]", "x", "B"), Rep("T", "x", "BT"))),
+		// nesting the same operator is not the same statement
+		Or(Or(Rep("X", "x", "B"), Rep("Y", "y", "B")), Rep("Z", "z", "B")),
+		Or(Rep("X", "x", "B"), Or(Rep("Y", "y", "B"), Rep("Z", "z", "B"))),
+		And(And(Rep("X", "x", "B"), Rep("Y", "y", "B")), Rep("Z", "z", "B")),
+	}
+	for i, pred := range preds {
+		parsed, err := Parse(pred.String())
+		if err != nil {
+			t.Fatal(pred.String(), err)
+		}
+		if parsed.String() != pred.String() || !EqualPredicates(parsed, pred) {
+			t.Error("Text form does not round trip:", pred.String(),
+				parsed.String())
+		}
+		buf, err := MarshalPredicate(pred)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := UnmarshalPredicate(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !EqualPredicates(decoded, pred) {
+			t.Error("Binary form does not round trip:", pred.String())
+		}
+		for j := range preds {
+			if i != j && EqualPredicates(pred, preds[j]) {
+				t.Error("Distinct predicates are equal:", i, j)
+			}
+		}
+
+		// Truncated and extended buffers are rejected
+		for k := 0; k < len(buf); k++ {
+			if _, err := UnmarshalPredicate(buf[:k]); err == nil {
+				t.Error("Truncated predicate accepted:", k)
+			}
+		}
+		if _, err := UnmarshalPredicate(append(buf, 0)); err == nil {
+			t.Error("Predicate with trailing bytes accepted")
+		}
+	}
+
+	// The text form admits spaces and redundant parentheses
+	parsed, err := Parse(" ((X = x * B) && T=x*BT)\n||Y=y*B ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.String() != "(X=x*B && T=x*BT) || Y=y*B" {
+		t.Error("Unexpected predicate:", parsed.String())
+	}
+}
+
+func TestPredicateValidation(t *testing.T) {
+	bad := []string{
+		"",
+		"X",
+		"X=x",
+		"X=x*",
+		"X=x*B+",
+		"X=x*B &&",
+		"X=x*B || (Y=y*B",
+		"X=x*B Y=y*B",
+		"X=x*B && (Y=y*B || Z=z*B)", // Or within And
+		"X=x*B && B=X*Y",            // X used as Scalar and Point
+		strings.Repeat("(", 40) + "X=x*B" + strings.Repeat(")", 40),
+	}
+	for _, s := range bad {
+		if _, err := Parse(s); err == nil {
+			t.Error("Invalid predicate accepted:", s)
+		}
+	}
+
+	invalid := []Predicate{
+		And(Rep("X", "x", "B")),
+		Or(),
+		Rep("X"),
+		Rep("X Y", "x", "B"),
+		Rep("X", "x", "B=C"),
+	}
+	for _, pred := range invalid {
+		if _, err := MarshalPredicate(pred); err == nil {
+			t.Error("Invalid predicate encoded")
+		}
+	}
+	if _, err := UnmarshalPredicate([]byte{tagOr, 0, 1, tagRep}); err == nil {
+		t.Error("Invalid binary predicate accepted")
+	}
+	if _, err := UnmarshalPredicate([]byte{9}); err == nil {
+		t.Error("Unknown tag accepted")
+	}
+}
+
+// A verifier checks a proof against a statement received over the wire,
+// after checking that it is the statement it expects.
+func TestPredicateOverTheWire(t *testing.T) {
+	suite := nist.NewAES128SHA256P256()
+	rand := suite.Cipher(abstract.RandomKey)
+	B := suite.Point().Base()
+	H, _ := suite.Point().Pick(nil, suite.Cipher([]byte("H")))
+	x := suite.Scalar().Pick(rand)
+	pval := map[string]abstract.Point{"B": B, "H": H,
+		"X": suite.Point().Mul(nil, x),
+		"Y": suite.Point().Mul(H, x)}
+
+	pred := And(Rep("X", "x", "B"), Rep("Y", "x", "H"))
+	statement, _ := MarshalPredicate(pred)
+	name := "TEST " + pred.String()
+	prover := pred.Prover(suite, map[string]abstract.Scalar{"x": x}, pval, nil)
+	prf, err := HashProve(suite, name, rand, prover)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received, err := UnmarshalPredicate(statement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := Parse("X=x*B && Y=x*H")
+	if !EqualPredicates(received, expected) {
+		t.Fatal("Unexpected statement:", received.String())
+	}
+	verifier := received.Verifier(suite, pval)
+	if err := HashVerify(suite, "TEST "+received.String(), verifier,
+		prf); err != nil {
+		t.Error("Proof should verify for the received statement:", err)
+	}
+}
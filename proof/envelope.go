@@ -0,0 +1,102 @@
+package proof
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+// EnvelopeVersion is the current version of the Envelope encoding.
+const EnvelopeVersion byte = 1
+
+var errorEnvelopeShort = errors.New("envelope: buffer too short")
+
+// Envelope wraps a non-interactive proof produced by HashProve together with
+// the metadata a verifier needs to check before running the Sigma-protocol
+// verifier: the encoding version, the name of the suite and the protocol
+// label the proof was produced for.
+type Envelope struct {
+	Version  byte   // Version of the envelope encoding
+	Suite    string // Name of the suite used to produce the proof
+	Protocol string // Protocol label given to HashProve
+	Body     []byte // Proof as returned by HashProve
+}
+
+// HashProveEnvelope runs HashProve and wraps the resulting proof in an
+// Envelope bound to the given suite and protocol name.
+func HashProveEnvelope(suite abstract.Suite, protocolName string,
+	random abstract.Cipher, prover Prover) (*Envelope, error) {
+	body, err := HashProve(suite, protocolName, random, prover)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{EnvelopeVersion, suite.String(), protocolName, body}, nil
+}
+
+// Verify checks that the envelope was produced with the current version,
+// the given suite and protocol name, and then verifies the enclosed proof
+// with HashVerify.
+func (e *Envelope) Verify(suite abstract.Suite, protocolName string,
+	verifier Verifier) error {
+	if e.Version != EnvelopeVersion {
+		return fmt.Errorf("envelope: unsupported version %d", e.Version)
+	}
+	if e.Suite != suite.String() {
+		return fmt.Errorf("envelope: proof for suite %s, expected %s",
+			e.Suite, suite.String())
+	}
+	if e.Protocol != protocolName {
+		return fmt.Errorf("envelope: proof for protocol %q, expected %q",
+			e.Protocol, protocolName)
+	}
+	return HashVerify(suite, protocolName, verifier, e.Body)
+}
+
+// MarshalBinary encodes the envelope as
+//
+//	||Version||SuiteLen||Suite||ProtocolLen||Protocol||BodyLen||Body||
+//
+// where all lengths are big-endian uint32 values.
+func (e *Envelope) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 1, 1+3*4+len(e.Suite)+len(e.Protocol)+len(e.Body))
+	buf[0] = e.Version
+	for _, field := range [][]byte{[]byte(e.Suite), []byte(e.Protocol), e.Body} {
+		var l [4]byte
+		binary.BigEndian.PutUint32(l[:], uint32(len(field)))
+		buf = append(buf, l[:]...)
+		buf = append(buf, field...)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes an envelope produced by MarshalBinary.
+func (e *Envelope) UnmarshalBinary(buf []byte) error {
+	if len(buf) < 1 {
+		return errorEnvelopeShort
+	}
+	version := buf[0]
+	buf = buf[1:]
+	var fields [3][]byte
+	for i := range fields {
+		if len(buf) < 4 {
+			return errorEnvelopeShort
+		}
+		l := binary.BigEndian.Uint32(buf)
+		buf = buf[4:]
+		if uint64(len(buf)) < uint64(l) {
+			return errorEnvelopeShort
+		}
+		fields[i] = buf[:l]
+		buf = buf[l:]
+	}
+	if len(buf) != 0 {
+		return errors.New("envelope: trailing data")
+	}
+	e.Version = version
+	e.Suite = string(fields[0])
+	e.Protocol = string(fields[1])
+	e.Body = append([]byte(nil), fields[2]...)
+	return nil
+}
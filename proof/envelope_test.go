@@ -0,0 +1,47 @@
+package proof
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/nist"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelope(t *testing.T) {
+	suite := nist.NewAES128SHA256P256()
+	rand := suite.Cipher(abstract.RandomKey)
+
+	x := suite.Scalar().Pick(rand)
+	X := suite.Point().Mul(nil, x)
+
+	pred := Rep("X", "x", "B")
+	sval := map[string]abstract.Scalar{"x": x}
+	pval := map[string]abstract.Point{"B": suite.Point().Base(), "X": X}
+	prover := pred.Prover(suite, sval, pval, nil)
+
+	env, err := HashProveEnvelope(suite, "TEST", rand, prover)
+	require.Nil(t, err)
+
+	buf, err := env.MarshalBinary()
+	require.Nil(t, err)
+	dec := new(Envelope)
+	require.Nil(t, dec.UnmarshalBinary(buf))
+	require.Equal(t, env, dec)
+
+	verifier := pred.Verifier(suite, pval)
+	require.Nil(t, dec.Verify(suite, "TEST", verifier))
+
+	// Wrong protocol label, suite and version
+	require.NotNil(t, dec.Verify(suite, "OTHER", verifier))
+	other := edwards.NewAES128SHA256Ed25519(false)
+	require.NotNil(t, dec.Verify(other, "TEST", verifier))
+	dec.Version++
+	require.NotNil(t, dec.Verify(suite, "TEST", verifier))
+
+	// Truncated and padded encodings
+	require.NotNil(t, dec.UnmarshalBinary(buf[:len(buf)-1]))
+	require.NotNil(t, dec.UnmarshalBinary(append(buf, 0)))
+	require.NotNil(t, dec.UnmarshalBinary(nil))
+}
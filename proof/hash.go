@@ -2,6 +2,7 @@ package proof
 
 import (
 	"bytes"
+	"io"
 
 	"github.com/dedis/crypto/abstract"
 )
@@ -66,6 +67,7 @@ type hashVerifier struct {
 	proof   bytes.Buffer // Buffer with which to read the proof
 	prbuf   []byte       // Byte-slice underlying proof buffer
 	pubrand abstract.Cipher
+	direct  bool // Decode Points and Scalars without suite.Read
 }
 
 func newHashVerifier(suite abstract.Suite, protoName string,
@@ -80,6 +82,24 @@ func newHashVerifier(suite abstract.Suite, protoName string,
 	return &c
 }
 
+// Prepare the verifier context for another proof, reusing its buffer.
+func (c *hashVerifier) reset(pubrand abstract.Cipher, proof []byte) {
+	c.proof.Reset()
+	c.proof.Write(proof)
+	c.prbuf = c.proof.Bytes()
+	c.pubrand = pubrand
+}
+
+// Read an object from r, directly if it is a Point or Scalar and
+// the context is configured to do so.
+func (c *hashVerifier) read(r io.Reader, obj interface{}) error {
+	if m, ok := obj.(abstract.Marshaling); ok && c.direct {
+		_, err := m.UnmarshalFrom(r)
+		return err
+	}
+	return c.suite.Read(r, obj)
+}
+
 func (c *hashVerifier) consumeMsg() {
 	l := len(c.prbuf) - c.proof.Len() // How many bytes read?
 	if l > 0 {
@@ -93,13 +113,18 @@ func (c *hashVerifier) consumeMsg() {
 
 // Read structured data from the proof
 func (c *hashVerifier) Get(message interface{}) error {
-	return c.suite.Read(&c.proof, message)
+	return c.read(&c.proof, message)
 }
 
 // Get public randomness that depends on every bit in the proof so far.
 func (c *hashVerifier) PubRand(data ...interface{}) error {
 	c.consumeMsg() // Stir in newly-read data
-	return c.suite.Read(c.pubrand, data...)
+	for _, obj := range data {
+		if err := c.read(c.pubrand, obj); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // HashProve runs a given Sigma-protocol prover with a ProverContext
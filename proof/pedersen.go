@@ -0,0 +1,182 @@
+package proof
+
+import (
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+////////// Pedersen commitment predicates //////////
+
+// Opening creates a predicate stating that the prover knows an opening
+// of the Pedersen commitment C, i.e., a value x and a blinding factor r
+// such that C=x*G+r*H, where G and H are independent generators.
+//
+//	Opening(C,x,r,G,H)
+//
+// It is the representation Rep(C,x,G,r,H),
+// and may be combined with other predicates in the same way.
+func Opening(C, x, r, G, H string) Predicate {
+	return Rep(C, x, G, r, H)
+}
+
+// A LinearTerm is a term A*Xi of a LinearRelation predicate,
+// where A is a public coefficient and Xi is the value committed to in Ci.
+type LinearTerm struct {
+	A abstract.Scalar // Public coefficient
+	C string          // Pedersen commitment to the value Xi
+	X string          // Value committed to in C
+	R string          // Blinding factor of C
+}
+
+type linearPred struct {
+	C, X, R string       // Commitment to the combined value, and its opening
+	T       []LinearTerm // Terms of the linear combination
+	G, H    string       // Generators of the commitments
+
+	// The relation is proven as the openings of all the commitments and
+	// the representation D=d*H, where D=C-A1*C1-...-An*Cn and
+	// d=R-A1*R1-...-An*Rn are derived from the other variables.
+	D, d string
+	and  Predicate
+}
+
+// LinearRelation creates a predicate stating that the prover knows
+// openings of the Pedersen commitment C=x*G+r*H
+// and of the Pedersen commitments Ci=xi*G+ri*H of the given terms,
+// such that x=A1*x1+...+An*xn for the public coefficients Ai of the terms.
+//
+// The proof consists of the Openings of all the commitments,
+// and of a representation of C-A1*C1-...-An*Cn with respect to H alone,
+// which the commitment to x-A1*x1-...-An*xn=0 reduces to.
+// The value of this point and of its discrete logarithm are derived
+// from the other variables, and need not be provided to Prover or Verifier.
+func LinearRelation(C, x, r string, terms []LinearTerm, G, H string) Predicate {
+	lp := &linearPred{C: C, X: x, R: r, T: terms, G: G, H: H,
+		D: "lin(" + C + ")", d: "lin(" + r + ")"}
+	sub := []Predicate{Opening(C, x, r, G, H)}
+	for _, t := range terms {
+		sub = append(sub, Opening(t.C, t.X, t.R, G, H))
+	}
+	sub = append(sub, Rep(lp.D, lp.d, H))
+	lp.and = And(sub...)
+	return lp
+}
+
+// Return a string representation of this linear relation predicate,
+// mainly for debugging.
+func (lp *linearPred) String() string {
+	return lp.precString(precNone)
+}
+
+func (lp *linearPred) precString(prec int) string {
+	s := lp.and.precString(precAnd) + " && " + lp.X + "="
+	for i, t := range lp.T {
+		if i > 0 {
+			s += "+"
+		}
+		s += t.A.String() + "*" + t.X
+	}
+	if prec != precNone && prec != precAnd {
+		s = "(" + s + ")"
+	}
+	return s
+}
+
+func (lp *linearPred) enumVars(prf *proof) {
+	// The derived point is not enumerated,
+	// as its value is never provided by the caller.
+	prf.enumPointVar(lp.C)
+	prf.enumScalarVar(lp.X)
+	prf.enumScalarVar(lp.R)
+	prf.enumPointVar(lp.G)
+	prf.enumPointVar(lp.H)
+	for _, t := range lp.T {
+		prf.enumPointVar(t.C)
+		prf.enumScalarVar(t.X)
+		prf.enumScalarVar(t.R)
+	}
+	prf.enumScalarVar(lp.d)
+}
+
+// Compute the values of the derived variables: the point D always,
+// and its discrete logarithm d if the blinding factors are known.
+// The caller's maps are copied rather than modified.
+func (lp *linearPred) derive(prf *proof) error {
+	D := prf.s.Point()
+	if C := prf.pval[lp.C]; C != nil {
+		D.Set(C)
+	} else {
+		return errors.New("missing value for point " + lp.C)
+	}
+	P := prf.s.Point()
+	for _, t := range lp.T {
+		Ci := prf.pval[t.C]
+		if Ci == nil {
+			return errors.New("missing value for point " + t.C)
+		}
+		D.Sub(D, P.Mul(Ci, t.A))
+	}
+	pval := make(map[string]abstract.Point, len(prf.pval)+1)
+	for name, P := range prf.pval {
+		pval[name] = P
+	}
+	pval[lp.D] = D
+	prf.pval = pval
+
+	// Secrets are only known to the prover,
+	// and only on the proof-obligated branches.
+	r := prf.sval[lp.R]
+	if r == nil {
+		return nil
+	}
+	d := prf.s.Scalar().Set(r)
+	s := prf.s.Scalar()
+	for _, t := range lp.T {
+		ri := prf.sval[t.R]
+		if ri == nil {
+			return nil
+		}
+		d.Sub(d, s.Mul(ri, t.A))
+	}
+	sval := make(map[string]abstract.Scalar, len(prf.sval)+1)
+	for name, x := range prf.sval {
+		sval[name] = x
+	}
+	sval[lp.d] = d
+	prf.sval = sval
+	return nil
+}
+
+func (lp *linearPred) commit(prf *proof, w abstract.Scalar, pv []abstract.Scalar) error {
+	if e := lp.derive(prf); e != nil {
+		return e
+	}
+	return lp.and.commit(prf, w, pv)
+}
+
+func (lp *linearPred) respond(prf *proof, c abstract.Scalar, pr []abstract.Scalar) error {
+	return lp.and.respond(prf, c, pr)
+}
+
+func (lp *linearPred) getCommits(prf *proof, pr []abstract.Scalar) error {
+	if e := lp.derive(prf); e != nil {
+		return e
+	}
+	return lp.and.getCommits(prf, pr)
+}
+
+func (lp *linearPred) verify(prf *proof, c abstract.Scalar, pr []abstract.Scalar) error {
+	return lp.and.verify(prf, c, pr)
+}
+
+func (lp *linearPred) Prover(suite abstract.Suite, secrets map[string]abstract.Scalar,
+	points map[string]abstract.Point,
+	choice map[Predicate]int) Prover {
+	return proof{}.init(suite, lp).prover(lp, secrets, points, choice)
+}
+
+func (lp *linearPred) Verifier(suite abstract.Suite,
+	points map[string]abstract.Point) Verifier {
+	return proof{}.init(suite, lp).verifier(lp, points)
+}
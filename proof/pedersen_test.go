@@ -0,0 +1,119 @@
+package proof
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/nist"
+)
+
+func pedersenCommit(suite abstract.Suite, G, H abstract.Point,
+	x, r abstract.Scalar) abstract.Point {
+	C := suite.Point().Mul(G, x)
+	return C.Add(C, suite.Point().Mul(H, r))
+}
+
+func TestOpening(t *testing.T) {
+	suite := nist.NewAES128SHA256P256()
+	rand := suite.Cipher(abstract.RandomKey)
+
+	G := suite.Point().Base()
+	H, _ := suite.Point().Pick(nil, suite.Cipher([]byte("H")))
+	x := suite.Scalar().Pick(rand)
+	r := suite.Scalar().Pick(rand)
+	C := pedersenCommit(suite, G, H, x, r)
+
+	pred := Opening("C", "x", "r", "G", "H")
+	sval := map[string]abstract.Scalar{"x": x, "r": r}
+	pval := map[string]abstract.Point{"C": C, "G": G, "H": H}
+	prf, err := HashProve(suite, "TEST", rand,
+		pred.Prover(suite, sval, pval, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := HashVerify(suite, "TEST", pred.Verifier(suite, pval), prf); err != nil {
+		t.Fatal(err)
+	}
+
+	pval["C"] = pedersenCommit(suite, G, H, x, x)
+	if HashVerify(suite, "TEST", pred.Verifier(suite, pval), prf) == nil {
+		t.Fatal("proof verified for another commitment")
+	}
+}
+
+func TestLinearRelation(t *testing.T) {
+	suite := nist.NewAES128SHA256P256()
+	rand := suite.Cipher(abstract.RandomKey)
+
+	G := suite.Point().Base()
+	H, _ := suite.Point().Pick(nil, suite.Cipher([]byte("H")))
+	a := suite.Scalar().SetInt64(3)
+	b := suite.Scalar().SetInt64(-5)
+	x1 := suite.Scalar().Pick(rand)
+	x2 := suite.Scalar().Pick(rand)
+	x := suite.Scalar().Mul(a, x1)
+	x.Add(x, suite.Scalar().Mul(b, x2))
+	r := suite.Scalar().Pick(rand)
+	r1 := suite.Scalar().Pick(rand)
+	r2 := suite.Scalar().Pick(rand)
+
+	pred := LinearRelation("C", "x", "r", []LinearTerm{
+		{a, "C1", "x1", "r1"},
+		{b, "C2", "x2", "r2"},
+	}, "G", "H")
+	sval := map[string]abstract.Scalar{"x": x, "r": r,
+		"x1": x1, "r1": r1, "x2": x2, "r2": r2}
+	pval := map[string]abstract.Point{
+		"C":  pedersenCommit(suite, G, H, x, r),
+		"C1": pedersenCommit(suite, G, H, x1, r1),
+		"C2": pedersenCommit(suite, G, H, x2, r2),
+		"G":  G, "H": H}
+
+	prf, err := HashProve(suite, "TEST", rand,
+		pred.Prover(suite, sval, pval, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := HashVerify(suite, "TEST", pred.Verifier(suite, pval), prf); err != nil {
+		t.Fatal(err)
+	}
+	if len(sval) != 6 || len(pval) != 5 {
+		t.Fatal("caller's maps modified")
+	}
+
+	// Compiled predicates need no value for the derived variables either
+	cp, err := Compile(suite, pred, map[string]abstract.Point{"G": G, "H": H})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inst := map[string]abstract.Point{"C": pval["C"], "C1": pval["C1"],
+		"C2": pval["C2"]}
+	if err := cp.HashVerify("TEST", inst, prf); err != nil {
+		t.Fatal(err)
+	}
+
+	// A false relation cannot be proven
+	x.Add(x, suite.Scalar().One())
+	pval["C"] = pedersenCommit(suite, G, H, x, r)
+	prf, err = HashProve(suite, "TEST", rand,
+		pred.Prover(suite, sval, pval, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if HashVerify(suite, "TEST", pred.Verifier(suite, pval), prf) == nil {
+		t.Fatal("proof of a false relation verified")
+	}
+
+	// The relation may be a branch of an Or predicate
+	or := Or(Rep("C", "x", "G"), pred)
+	x.Sub(x, suite.Scalar().One())
+	pval["C"] = pedersenCommit(suite, G, H, x, r)
+	prf, err = HashProve(suite, "TEST", rand,
+		or.Prover(suite, sval, pval, map[Predicate]int{or: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := HashVerify(suite, "TEST", or.Verifier(suite, pval), prf); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -111,6 +111,7 @@ type proof struct {
 	// verifier-specific state
 	vc VerifierContext
 	vp map[Predicate]*verifierPred // per-predicate verifier state
+	c  abstract.Scalar             // top-level challenge
 }
 type proverPred struct {
 	w  abstract.Scalar   // secret pre-challenge
@@ -120,6 +121,8 @@ type proverPred struct {
 type verifierPred struct {
 	V abstract.Point    // public commitment produced by verifier
 	r []abstract.Scalar // per-variable responses produced by verifier
+
+	U, T abstract.Point // Rep predicates: scratch points for verify
 }
 
 ////////// Rep predicate //////////
@@ -260,11 +263,17 @@ func (rp *repPred) respond(prf *proof, c abstract.Scalar,
 
 func (rp *repPred) getCommits(prf *proof, pr []abstract.Scalar) error {
 
-	// Create per-predicate verifier state
-	V := prf.s.Point()
-	r := prf.makeScalars(pr)
-	vp := &verifierPred{V, r}
-	prf.vp[rp] = vp
+	// Create per-predicate verifier state, unless reusing the state
+	// of a previous verification
+	vp := prf.vp[rp]
+	if vp == nil {
+		vp = &verifierPred{V: prf.s.Point(), r: prf.makeScalars(pr),
+			U: prf.s.Point(), T: prf.s.Point()}
+		prf.vp[rp] = vp
+	} else if pr != nil {
+		vp.r = pr
+	}
+	r := vp.r
 
 	// Get the commitment for this representation
 	if e := prf.vc.Get(vp.V); e != nil {
@@ -292,9 +301,9 @@ func (rp *repPred) verify(prf *proof, c abstract.Scalar, pr []abstract.Scalar) e
 	}
 
 	// Recompute commit V=cY+r1G1+...+rkGk
-	V := prf.s.Point()
+	V := vp.U
 	V.Mul(prf.pval[rp.P], c)
-	P := prf.s.Point()
+	P := vp.T
 	for i := 0; i < len(rp.T); i++ {
 		t := rp.T[i] // current term
 		s := prf.sidx[t.S]
@@ -341,7 +350,7 @@ func (ap *andPred) precString(prec int) string {
 	for i := 1; i < len(sub); i++ {
 		s = s + " && " + sub[i].precString(precAnd)
 	}
-	if prec != precNone && prec != precAnd {
+	if prec != precNone {
 		s = "(" + s + ")"
 	}
 	return s
@@ -389,10 +398,16 @@ func (ap *andPred) respond(prf *proof, c abstract.Scalar, pr []abstract.Scalar)
 func (ap *andPred) getCommits(prf *proof, pr []abstract.Scalar) error {
 	sub := []Predicate(*ap)
 
-	// Create per-predicate verifier state
-	r := prf.makeScalars(pr)
-	vp := &verifierPred{nil, r}
-	prf.vp[ap] = vp
+	// Create per-predicate verifier state, unless reusing the state
+	// of a previous verification
+	vp := prf.vp[ap]
+	if vp == nil {
+		vp = &verifierPred{r: prf.makeScalars(pr)}
+		prf.vp[ap] = vp
+	} else if pr != nil {
+		vp.r = pr
+	}
+	r := vp.r
 
 	for i := range sub {
 		if e := sub[i].getCommits(prf, r); e != nil {
@@ -453,7 +468,7 @@ func (op *orPred) precString(prec int) string {
 	for i := 1; i < len(sub); i++ {
 		s = s + " || " + sub[i].precString(precOr)
 	}
-	if prec != precNone && prec != precOr {
+	if prec != precNone {
 		s = "(" + s + ")"
 	}
 	return s
@@ -613,21 +628,6 @@ func (op *orPred) Verifier(suite abstract.Suite,
 	return proof{}.init(suite, op).verifier(op, points)
 }
 
-/*
-type lin struct {
-	a1,a2,b abstract.Scalar
-	x1,x2 PriVar
-}
-*/
-
-// Construct a predicate asserting a linear relationship a1x1+a2x2=b,
-// where a1,a2,b are public values and x1,x2 are secrets.
-/*
-func (p *Prover) Linear(a1,a2,b abstract.Scalar, x1,x2 PriVar) {
-	return &lin{a1,a2,b,x1,x2}
-}
-*/
-
 func (prf proof) init(suite abstract.Suite, pred Predicate) *proof {
 	prf.s = suite
 
@@ -725,7 +725,9 @@ func (prf *proof) verify(p Predicate, pval map[string]abstract.Point,
 	vc VerifierContext) error {
 	prf.vc = vc
 	prf.pval = pval
-	prf.vp = make(map[Predicate]*verifierPred)
+	if prf.vp == nil {
+		prf.vp = make(map[Predicate]*verifierPred)
+	}
 
 	// Get the commitments from the verifier,
 	// and calculate the sets of responses we'll need for each OR-domain.
@@ -734,7 +736,10 @@ func (prf *proof) verify(p Predicate, pval map[string]abstract.Point,
 	}
 
 	// Produce the top-level challenge
-	c := prf.s.Scalar()
+	if prf.c == nil {
+		prf.c = prf.s.Scalar()
+	}
+	c := prf.c
 	if e := vc.PubRand(c); e != nil {
 		return e
 	}
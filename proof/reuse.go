@@ -0,0 +1,85 @@
+package proof
+
+import (
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+// VerifierOption configures a HashVerifier.
+type VerifierOption int
+
+const (
+	// NoReflection makes a HashVerifier decode the Points and Scalars of
+	// proofs with their UnmarshalFrom method, instead of through the
+	// reflection-based suite.Read. This is equivalent for every suite whose
+	// Read method decodes Points and Scalars with UnmarshalFrom, as is the
+	// case for all the suites of this library.
+	NoReflection VerifierOption = iota
+)
+
+// A HashVerifier verifies hash-based non-interactive proofs of a
+// CompiledPredicate, as CompiledPredicate.HashVerify does, reusing its
+// internal state from one proof to the next: the map of point values,
+// the commitments, responses and scratch points of every sub-predicate,
+// the proof buffer and the initial state of the public randomness are all
+// allocated once. This relieves the garbage collector when verifying many
+// proofs per second.
+//
+// A HashVerifier is not safe for concurrent use. Goroutines should each use
+// their own, e.g., by getting them from a sync.Pool whose New function calls
+// CompiledPredicate.NewHashVerifier.
+type HashVerifier struct {
+	cp      *CompiledPredicate
+	prf     proof
+	pval    map[string]abstract.Point
+	ctx     hashVerifier
+	pubrand abstract.Cipher // keyed with the protocol name, cloned per proof
+}
+
+// NewHashVerifier creates a reusable verifier for the proofs of the
+// predicate produced by HashProve with the given protocol name.
+func (cp *CompiledPredicate) NewHashVerifier(protocolName string,
+	options ...VerifierOption) *HashVerifier {
+
+	hv := &HashVerifier{
+		cp:      cp,
+		prf:     cp.prf,
+		pval:    make(map[string]abstract.Point, cp.prf.npvars),
+		pubrand: cp.prf.s.Cipher([]byte(protocolName)),
+	}
+	hv.ctx.suite = cp.prf.s
+	for _, o := range options {
+		switch o {
+		case NoReflection:
+			hv.ctx.direct = true
+		}
+	}
+	return hv
+}
+
+// Verify checks a proof of one instance of the statement, given the points
+// specific to this instance. It returns nil iff the proof is valid.
+func (hv *HashVerifier) Verify(points map[string]abstract.Point,
+	proof []byte) error {
+
+	cp := hv.cp
+	for name := range points {
+		if _, ok := cp.gens[name]; ok {
+			return errors.New("point " + name +
+				" redefines a compiled generator")
+		}
+	}
+	for _, name := range cp.prf.pvar[1:] {
+		P, ok := cp.gens[name]
+		if !ok {
+			P = points[name]
+		}
+		if P == nil {
+			return errors.New("missing value for point " + name)
+		}
+		hv.pval[name] = P
+	}
+	hv.ctx.reset(hv.pubrand.Clone(), proof)
+	return hv.prf.verify(cp.pred, hv.pval, &hv.ctx)
+}
@@ -0,0 +1,194 @@
+// Package venc implements verifiable encryption: an ElGamal encryption of a
+// secret scalar together with a non-interactive zero-knowledge proof, built on
+// the proof package, that the ciphertext encrypts the value hidden in a public
+// Pedersen commitment or in a public share of a secret sharing polynomial.
+// Anyone can check the proof without learning the encrypted value.
+//
+// The value m is encrypted "in the exponent" for the public key Y = yB:
+//
+//	K = kB, C = kY + mB
+//
+// so that decryption recovers the point mB rather than m itself. This is what
+// publicly verifiable secret sharing and blame flows need, since a share can
+// be checked and used for reconstruction in the exponent.
+package venc
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/proof"
+	"github.com/dedis/crypto/random"
+)
+
+var errorShort = errors.New("venc: buffer too short")
+
+// Protocol names given to proof.HashProve
+const (
+	protocolCommitted = "venc.Committed"
+	protocolShare     = "venc.Share"
+)
+
+// Ciphertext is an ElGamal encryption in the exponent.
+type Ciphertext struct {
+	K abstract.Point // Ephemeral key kB
+	C abstract.Point // Encrypted value kY + mB
+}
+
+// Decrypt returns the point mB encrypted by the ciphertext,
+// using the private key y matching the public key Y = yB.
+func (c *Ciphertext) Decrypt(suite abstract.Suite, y abstract.Scalar) abstract.Point {
+	S := suite.Point().Mul(c.K, y)
+	return suite.Point().Sub(c.C, S)
+}
+
+// Proof is a verifiable encryption, i.e., a ciphertext together with a proof
+// of what it encrypts.
+type Proof struct {
+	Ciphertext
+	Body []byte // Proof produced by proof.HashProve
+}
+
+// MarshalBinary encodes the proof as K || C || Body.
+func (p *Proof) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := abstract.WritePoints(&buf, p.K, p.C); err != nil {
+		return nil, err
+	}
+	buf.Write(p.Body)
+	return buf.Bytes(), nil
+}
+
+// Decode decodes a proof encoded with MarshalBinary.
+func Decode(suite abstract.Suite, buf []byte) (*Proof, error) {
+	if len(buf) < 2*suite.PointLen() {
+		return nil, errorShort
+	}
+	r := bytes.NewReader(buf)
+	points, err := abstract.ReadPoints(r, suite, 2)
+	if err != nil {
+		return nil, err
+	}
+	body := buf[2*suite.PointLen():]
+	return &Proof{Ciphertext{points[0], points[1]}, append([]byte{}, body...)}, nil
+}
+
+// label binds a proof to its statement by deriving the protocol name given to
+// proof.HashProve from the encoding of all public points.
+func label(protocol string, points ...abstract.Point) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteString(protocol)
+	if err := abstract.WritePoints(&buf, points...); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// encrypt encrypts m for Y and runs the prover of pred, which may refer to the
+// secrets k and m and to the points B, Y, K and C in addition to the given ones.
+func encrypt(suite abstract.Suite, protocol string, pred proof.Predicate,
+	Y abstract.Point, m abstract.Scalar, secrets map[string]abstract.Scalar,
+	points map[string]abstract.Point) (*Proof, error) {
+
+	k := suite.Scalar().Pick(random.Stream)
+	K := suite.Point().Mul(nil, k)
+	C := suite.Point().Mul(Y, k)
+	C.Add(C, suite.Point().Mul(nil, m))
+
+	secrets["k"] = k
+	secrets["m"] = m
+	points["B"] = suite.Point().Base()
+	points["Y"] = Y
+	points["K"] = K
+	points["C"] = C
+	name, err := label(protocol, statement(points)...)
+	if err != nil {
+		return nil, err
+	}
+
+	prover := pred.Prover(suite, secrets, points, map[proof.Predicate]int{})
+	rand := suite.Cipher(abstract.RandomKey)
+	body, err := proof.HashProve(suite, name, rand, prover)
+	if err != nil {
+		return nil, err
+	}
+	return &Proof{Ciphertext{K, C}, body}, nil
+}
+
+// verify checks the proof of pred for the ciphertext of p.
+func (p *Proof) verify(suite abstract.Suite, protocol string,
+	pred proof.Predicate, Y abstract.Point,
+	points map[string]abstract.Point) error {
+
+	points["B"] = suite.Point().Base()
+	points["Y"] = Y
+	points["K"] = p.K
+	points["C"] = p.C
+	name, err := label(protocol, statement(points)...)
+	if err != nil {
+		return err
+	}
+	verifier := pred.Verifier(suite, points)
+	return proof.HashVerify(suite, name, verifier, p.Body)
+}
+
+// statement lists the public points of a statement in a fixed order.
+func statement(points map[string]abstract.Point) []abstract.Point {
+	var list []abstract.Point
+	for _, name := range []string{"Y", "K", "C", "H", "V", "S"} {
+		if P, ok := points[name]; ok {
+			list = append(list, P)
+		}
+	}
+	return list
+}
+
+// committedPredicate states that (K, C) encrypts for Y
+// the value committed in V = mB + rH.
+var committedPredicate = proof.And(
+	proof.Rep("K", "k", "B"),
+	proof.Rep("C", "k", "Y", "m", "B"),
+	proof.Rep("V", "m", "B", "r", "H"))
+
+// EncryptCommitted encrypts m for the public key Y and proves that the
+// ciphertext encrypts the value committed in the Pedersen commitment
+// V = mB + rH, where B is the standard base.
+func EncryptCommitted(suite abstract.Suite, Y, H abstract.Point,
+	m, r abstract.Scalar) (*Proof, error) {
+	V := suite.Point().Mul(nil, m)
+	V.Add(V, suite.Point().Mul(H, r))
+	return encrypt(suite, protocolCommitted, committedPredicate, Y, m,
+		map[string]abstract.Scalar{"r": r},
+		map[string]abstract.Point{"H": H, "V": V})
+}
+
+// VerifyCommitted checks that p encrypts for Y the value committed in the
+// Pedersen commitment V with respect to the bases B and H.
+func (p *Proof) VerifyCommitted(suite abstract.Suite, Y, H, V abstract.Point) error {
+	return p.verify(suite, protocolCommitted, committedPredicate, Y,
+		map[string]abstract.Point{"H": H, "V": V})
+}
+
+// sharePredicate states that (K, C) encrypts for Y the discrete logarithm
+// of the public share S.
+var sharePredicate = proof.And(
+	proof.Rep("K", "k", "B"),
+	proof.Rep("C", "k", "Y", "m", "B"),
+	proof.Rep("S", "m", "B"))
+
+// EncryptShare encrypts the private share s for the public key Y and proves
+// that the ciphertext encrypts the value of the public share S = sB, such as
+// the evaluation of the public commitment polynomial of a Deal.
+func EncryptShare(suite abstract.Suite, Y abstract.Point, s abstract.Scalar) (*Proof, error) {
+	S := suite.Point().Mul(nil, s)
+	return encrypt(suite, protocolShare, sharePredicate, Y, s,
+		map[string]abstract.Scalar{}, map[string]abstract.Point{"S": S})
+}
+
+// VerifyShare checks that p encrypts for Y the private share matching the
+// public share S.
+func (p *Proof) VerifyShare(suite abstract.Suite, Y, S abstract.Point) error {
+	return p.verify(suite, protocolShare, sharePredicate, Y,
+		map[string]abstract.Point{"S": S})
+}
@@ -0,0 +1,56 @@
+package venc
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/share"
+	"github.com/stretchr/testify/require"
+)
+
+var suite = edwards.NewAES128SHA256Ed25519(false)
+
+func TestCommitted(t *testing.T) {
+	key := config.NewKeyPair(suite)
+	H, _ := suite.Point().Pick(nil, suite.Cipher([]byte("H")))
+	m := suite.Scalar().Pick(random.Stream)
+	r := suite.Scalar().Pick(random.Stream)
+	V := suite.Point().Mul(nil, m)
+	V.Add(V, suite.Point().Mul(H, r))
+
+	p, err := EncryptCommitted(suite, key.Public, H, m, r)
+	require.Nil(t, err)
+	require.Nil(t, p.VerifyCommitted(suite, key.Public, H, V))
+	require.True(t, p.Decrypt(suite, key.Secret).Equal(suite.Point().Mul(nil, m)))
+
+	// Serialization
+	buf, err := p.MarshalBinary()
+	require.Nil(t, err)
+	q, err := Decode(suite, buf)
+	require.Nil(t, err)
+	require.Nil(t, q.VerifyCommitted(suite, key.Public, H, V))
+	_, err = Decode(suite, buf[:suite.PointLen()])
+	require.Equal(t, errorShort, err)
+
+	// Wrong statements
+	other := config.NewKeyPair(suite)
+	require.NotNil(t, p.VerifyCommitted(suite, other.Public, H, V))
+	require.NotNil(t, p.VerifyCommitted(suite, key.Public, H, suite.Point().Add(V, H)))
+	p.C.Add(p.C, H)
+	require.NotNil(t, p.VerifyCommitted(suite, key.Public, H, V))
+}
+
+func TestShare(t *testing.T) {
+	key := config.NewKeyPair(suite)
+	poly := share.NewPriPoly(suite, 3, nil, random.Stream)
+	pub := poly.Commit(nil)
+
+	s := poly.Eval(2)
+	p, err := EncryptShare(suite, key.Public, s.V)
+	require.Nil(t, err)
+	require.Nil(t, p.VerifyShare(suite, key.Public, pub.Eval(2).V))
+	require.NotNil(t, p.VerifyShare(suite, key.Public, pub.Eval(3).V))
+	require.True(t, p.Decrypt(suite, key.Secret).Equal(pub.Eval(2).V))
+}
@@ -10,6 +10,7 @@ import (
 	"crypto/rand"
 	"encoding/binary"
 	"math/big"
+	"math/bits"
 )
 
 // Choose a uniform random BigInt with a given maximum BitLen.
@@ -79,6 +80,38 @@ func Int(mod *big.Int, rand cipher.Stream) *big.Int {
 	}
 }
 
+// Choose a uniform random int in [0,n), which must be positive.
+// Values are drawn by rejection sampling and thus have no modulo bias.
+func Intn(n int, rand cipher.Stream) int {
+	if n <= 0 {
+		panic("random: invalid argument to Intn")
+	}
+	bitlen := uint(bits.Len64(uint64(n - 1)))
+	for {
+		var v uint64
+		for _, b := range Bits(bitlen, false, rand) {
+			v = v<<8 | uint64(b)
+		}
+		if v < uint64(n) {
+			return int(v)
+		}
+	}
+}
+
+// Choose a uniform random permutation of the ints in [0,n),
+// by a Fisher-Yates shuffle using Intn.
+func Perm(n int, rand cipher.Stream) []int {
+	pi := make([]int, n)
+	for i := range pi {
+		pi[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j := Intn(i+1, rand)
+		pi[i], pi[j] = pi[j], pi[i]
+	}
+	return pi
+}
+
 // Choose a random n-byte slice
 func Bytes(n int, rand cipher.Stream) []byte {
 	b := make([]byte, n)
@@ -112,6 +145,10 @@ func (r *randstream) XORKeyStream(dst, src []byte) {
 	if len(src) != l {
 		panic("XORKeyStream: mismatched buffer lengths")
 	}
+	if s := overridden(); s != nil {
+		s.XORKeyStream(dst, src)
+		return
+	}
 
 	buf := make([]byte, l)
 	n, err := rand.Read(buf)
@@ -128,5 +165,6 @@ func (r *randstream) XORKeyStream(dst, src []byte) {
 }
 
 // Standard virtual "stream cipher" that just generates
-// fresh cryptographically strong random bits.
+// fresh cryptographically strong random bits,
+// unless another source was set with SetStream.
 var Stream cipher.Stream = new(randstream)
@@ -0,0 +1,129 @@
+package random
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"sync"
+)
+
+// The source of random bits behind Stream, nil for crypto/rand.
+var source struct {
+	sync.RWMutex
+	stream cipher.Stream
+}
+
+// SetStream makes Stream draw its bits from the given stream
+// instead of crypto/rand, so that code using the package-level Stream
+// can be made reproducible in tests, e.g., with NewDeterministicStream.
+// A nil stream restores crypto/rand.
+// SetStream returns a function restoring the previous source,
+// suitable for use with defer:
+//
+//	defer random.SetStream(random.NewDeterministicStream(seed))()
+//
+// SetStream must never be used outside of tests.
+func SetStream(s cipher.Stream) (restore func()) {
+	source.Lock()
+	prev := source.stream
+	source.stream = s
+	source.Unlock()
+	return func() {
+		source.Lock()
+		source.stream = prev
+		source.Unlock()
+	}
+}
+
+// Returns the stream set with SetStream, if any.
+func overridden() cipher.Stream {
+	source.RLock()
+	defer source.RUnlock()
+	return source.stream
+}
+
+// NewDeterministicStream returns a pseudorandom stream
+// whose output is entirely determined by the given seed,
+// for reproducible tests and test vectors.
+// The stream is AES-256 in counter mode,
+// keyed with the SHA-256 hash of the seed.
+func NewDeterministicStream(seed []byte) cipher.Stream {
+	key := sha256.Sum256(seed)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		panic(err)
+	}
+	return cipher.NewCTR(block, make([]byte, aes.BlockSize))
+}
+
+// A Recorder wraps a stream and records all the random bits drawn from it,
+// so that a failing protocol run can be replayed exactly.
+// A Recorder is safe for concurrent use,
+// although the order in which concurrent goroutines draw bits
+// is then itself not reproducible.
+type Recorder struct {
+	mu     sync.Mutex
+	stream cipher.Stream
+	log    []byte
+}
+
+// NewRecorder creates a Recorder drawing its bits from the given stream.
+func NewRecorder(s cipher.Stream) *Recorder {
+	return &Recorder{stream: s}
+}
+
+// XORKeyStream draws len(src) bits from the underlying stream,
+// records them and XORs them with src into dst.
+func (r *Recorder) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("XORKeyStream: output smaller than input")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := make([]byte, len(src))
+	r.stream.XORKeyStream(key, key)
+	r.log = append(r.log, key...)
+	for i := range src {
+		dst[i] = src[i] ^ key[i]
+	}
+}
+
+// Bytes returns a copy of all the bits drawn so far.
+func (r *Recorder) Bytes() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]byte{}, r.log...)
+}
+
+// Replay returns a stream producing exactly the bits drawn so far,
+// which panics if more bits are drawn from it than were recorded.
+func (r *Recorder) Replay() cipher.Stream {
+	return NewReplay(r.Bytes())
+}
+
+type replay struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewReplay returns a stream producing the given bits, e.g.,
+// as saved from Recorder.Bytes,
+// which panics if more bits are drawn from it than were given.
+func NewReplay(bits []byte) cipher.Stream {
+	return &replay{buf: bits}
+}
+
+func (r *replay) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("XORKeyStream: output smaller than input")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(src) > len(r.buf) {
+		panic("replayed random stream exhausted")
+	}
+	for i := range src {
+		dst[i] = src[i] ^ r.buf[i]
+	}
+	r.buf = r.buf[len(src):]
+}
@@ -0,0 +1,87 @@
+package random
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDeterministicStream(t *testing.T) {
+	a := Bytes(64, NewDeterministicStream([]byte("seed")))
+	b := Bytes(64, NewDeterministicStream([]byte("seed")))
+	c := Bytes(64, NewDeterministicStream([]byte("other")))
+	if !bytes.Equal(a, b) {
+		t.Fatal("Same seed gave different streams")
+	}
+	if bytes.Equal(a, c) {
+		t.Fatal("Different seeds gave the same stream")
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	rec := NewRecorder(Stream)
+	a := Bytes(10, rec)
+	n := Uint64(rec)
+	if len(rec.Bytes()) != 18 {
+		t.Fatal("Wrong number of recorded bytes", len(rec.Bytes()))
+	}
+
+	replay := rec.Replay()
+	if !bytes.Equal(Bytes(10, replay), a) || Uint64(replay) != n {
+		t.Fatal("Replay differs from the recording")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Exhausted replay did not panic")
+		}
+	}()
+	Byte(replay)
+}
+
+func TestSetStream(t *testing.T) {
+	restore := SetStream(NewDeterministicStream([]byte("seed")))
+	a := Bytes(32, Stream)
+	restore()
+	if !bytes.Equal(a, Bytes(32, NewDeterministicStream([]byte("seed")))) {
+		t.Fatal("Stream did not use the stream set")
+	}
+	if bytes.Equal(a, Bytes(32, Stream)) {
+		t.Fatal("Stream not restored")
+	}
+}
+
+func TestIntn(t *testing.T) {
+	stream := NewDeterministicStream([]byte("seed"))
+	for _, n := range []int{1, 2, 3, 7, 256, 1000, 1 << 40} {
+		for i := 0; i < 100; i++ {
+			if v := Intn(n, stream); v < 0 || v >= n {
+				t.Fatal("Intn out of range", n, v)
+			}
+		}
+	}
+
+	// All the values of a small range are drawn
+	var seen [3]int
+	for i := 0; i < 300; i++ {
+		seen[Intn(3, stream)]++
+	}
+	for v, c := range seen {
+		if c == 0 {
+			t.Fatal("Intn never drew", v)
+		}
+	}
+}
+
+func TestPerm(t *testing.T) {
+	stream := NewDeterministicStream([]byte("seed"))
+	pi := Perm(10, stream)
+	seen := make(map[int]bool)
+	for _, v := range pi {
+		if v < 0 || v >= 10 || seen[v] {
+			t.Fatal("Not a permutation", pi)
+		}
+		seen[v] = true
+	}
+	if len(Perm(0, stream)) != 0 {
+		t.Fatal("Wrong empty permutation")
+	}
+}
@@ -0,0 +1,317 @@
+// Package release lets the insurers of a poly.Deal reveal their shares only
+// once a release condition holds: after a deadline, or upon a release token
+// signed by k out of m members of a release committee.
+//
+// The Dealer binds a condition to its Deal with a Descriptor, which it signs
+// with its long-term key and sends to the insurers along with the Deal. The
+// Dealer, the insurers and the clients then set a Policy enforcing the
+// Descriptor on their Deal (see poly.Deal.SetReleaseCondition) before
+// producing or verifying responses: the signatures of the insurers cover the
+// Descriptor, and poly.State.RevealShare checks its condition before
+// unwrapping the share. The conditions are enforced by honest insurers: as
+// with any threshold scheme, t colluding insurers can always pool their
+// shares.
+package release
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/poly"
+	"github.com/dedis/crypto/sign"
+)
+
+const (
+	descriptorDomain = "release.Descriptor"
+	tokenDomain      = "release.Token"
+)
+
+// Types of conditions in the encoding of a Descriptor
+const (
+	deadlineCondition byte = iota + 1
+	committeeCondition
+)
+
+var errorNotYet = errors.New("release: deadline not reached")
+var errorToken = errors.New("release: not enough valid release signatures")
+var errorDeal = errors.New("release: descriptor is for another deal")
+var errorEncoding = errors.New("release: malformed descriptor")
+
+// A Condition restricts the release of the shares of a Deal.
+type Condition interface {
+
+	// Check returns nil iff the condition holds for the Deal of the
+	// given id at time now, given the release token, which may be nil.
+	Check(dealID [abstract.PointKeySize]byte, now time.Time, token *Token) error
+
+	// marshal appends the encoding of the condition to buf.
+	marshal(buf *bytes.Buffer) error
+}
+
+// Deadline is a Condition holding from a given time on.
+type Deadline struct {
+	After time.Time
+}
+
+// Check returns nil iff now is not before the deadline.
+func (d *Deadline) Check(dealID [abstract.PointKeySize]byte, now time.Time, token *Token) error {
+	if now.Before(d.After) {
+		return errorNotYet
+	}
+	return nil
+}
+
+func (d *Deadline) marshal(buf *bytes.Buffer) error {
+	var b [9]byte
+	b[0] = deadlineCondition
+	binary.BigEndian.PutUint64(b[1:], uint64(d.After.UnixNano()))
+	buf.Write(b[:])
+	return nil
+}
+
+// Committee is a Condition holding once K of its Members signed a release
+// token for the Deal, see SignRelease.
+type Committee struct {
+	Suite   abstract.Suite
+	Members []abstract.Point
+	K       int
+}
+
+// A Token gathers the release signatures of committee members, indexed by
+// their position in Committee.Members.
+type Token struct {
+	Signatures map[int][]byte
+}
+
+// SignRelease returns the signature of a committee member releasing the
+// shares of the Deal of the given id, to be added to a Token.
+func SignRelease(key *config.KeyPair, dealID [abstract.PointKeySize]byte) ([]byte, error) {
+	return sign.SignWithDomain(key.Suite, key.Secret, tokenDomain,
+		dealID[:])
+}
+
+// Check returns nil iff the token holds valid release signatures of at least
+// K distinct members for the Deal. Members listed several times count once.
+func (c *Committee) Check(dealID [abstract.PointKeySize]byte, now time.Time, token *Token) error {
+	if token == nil {
+		return errorToken
+	}
+	valid := make(map[[abstract.PointKeySize]byte]bool)
+	for i, sig := range token.Signatures {
+		if i < 0 || i >= len(c.Members) {
+			continue
+		}
+		err := sign.VerifyWithDomain(c.Suite, c.Members[i],
+			tokenDomain, dealID[:], sig)
+		if err == nil {
+			valid[abstract.PointKey(c.Members[i])] = true
+		}
+	}
+	if c.K < 1 || len(valid) < c.K {
+		return errorToken
+	}
+	return nil
+}
+
+func (c *Committee) marshal(buf *bytes.Buffer) error {
+	var b [9]byte
+	b[0] = committeeCondition
+	binary.BigEndian.PutUint32(b[1:5], uint32(c.K))
+	binary.BigEndian.PutUint32(b[5:9], uint32(len(c.Members)))
+	buf.Write(b[:])
+	for _, m := range c.Members {
+		if _, err := m.MarshalTo(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A Descriptor binds a release Condition to a Deal, and is signed by the
+// Dealer.
+type Descriptor struct {
+	DealID    [abstract.PointKeySize]byte // Id of the Deal, see poly.Deal.Id
+	Condition Condition
+	Signature []byte // Signature of the Dealer's long-term key
+}
+
+// NewDescriptor creates a Descriptor binding the condition to the Deal,
+// signed with the long-term key of the Dealer.
+func NewDescriptor(deal *poly.Deal, cond Condition,
+	dealer *config.KeyPair) (*Descriptor, error) {
+
+	d := &Descriptor{DealID: deal.Id(), Condition: cond}
+	msg, err := d.message()
+	if err != nil {
+		return nil, err
+	}
+	d.Signature, err = sign.SignWithDomain(dealer.Suite, dealer.Secret,
+		descriptorDomain, msg)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// message returns the encoding of the Descriptor without its signature.
+func (d *Descriptor) message() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(d.DealID[:])
+	if err := d.Condition.marshal(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Verify checks that the Descriptor is signed by the Dealer of the Deal and
+// is for that Deal.
+func (d *Descriptor) Verify(deal *poly.Deal) error {
+	if d.DealID != deal.Id() {
+		return errorDeal
+	}
+	return d.verifySignature(deal)
+}
+
+// verifySignature checks that the Descriptor is signed by the Dealer of the
+// Deal.
+func (d *Descriptor) verifySignature(deal *poly.Deal) error {
+	msg, err := d.message()
+	if err != nil {
+		return err
+	}
+	return sign.VerifyWithDomain(deal.Suite(), deal.DealerKey(),
+		descriptorDomain, msg, d.Signature)
+}
+
+// Digest returns a digest of the Descriptor without its signature, which
+// the Deal covers once a Policy enforces the Descriptor.
+func (d *Descriptor) Digest() ([]byte, error) {
+	msg, err := d.message()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	h.Write([]byte(descriptorDomain))
+	h.Write(msg)
+	return h.Sum(nil), nil
+}
+
+// MarshalBinary encodes the Descriptor, followed by its signature.
+func (d *Descriptor) MarshalBinary() ([]byte, error) {
+	msg, err := d.message()
+	if err != nil {
+		return nil, err
+	}
+	return append(msg, d.Signature...), nil
+}
+
+// UnmarshalDescriptor decodes a Descriptor whose points and signature are of
+// the given suite.
+func UnmarshalDescriptor(suite abstract.Suite, buf []byte) (*Descriptor, error) {
+	r := bytes.NewReader(buf)
+	d := new(Descriptor)
+	if _, err := io.ReadFull(r, d.DealID[:]); err != nil {
+		return nil, errorEncoding
+	}
+
+	var hdr [9]byte
+	if _, err := r.Read(hdr[:]); err != nil {
+		return nil, errorEncoding
+	}
+	switch hdr[0] {
+	case deadlineCondition:
+		nanos := int64(binary.BigEndian.Uint64(hdr[1:]))
+		d.Condition = &Deadline{time.Unix(0, nanos)}
+	case committeeCondition:
+		c := &Committee{Suite: suite, K: int(binary.BigEndian.Uint32(hdr[1:5]))}
+		m := int64(binary.BigEndian.Uint32(hdr[5:9]))
+		if m*int64(suite.PointLen()) > int64(r.Len()) {
+			return nil, errorEncoding
+		}
+		c.Members = make([]abstract.Point, m)
+		for i := range c.Members {
+			c.Members[i] = suite.Point()
+			if _, err := c.Members[i].UnmarshalFrom(r); err != nil {
+				return nil, err
+			}
+		}
+		d.Condition = c
+	default:
+		return nil, errorEncoding
+	}
+
+	sigSize := suite.PointLen() + suite.ScalarLen()
+	if r.Len() != sigSize {
+		return nil, errorEncoding
+	}
+	d.Signature = make([]byte, sigSize)
+	r.Read(d.Signature)
+	return d, nil
+}
+
+// A Policy enforces a Descriptor in the reveal path of its Deal, see
+// poly.Deal.SetReleaseCondition. It implements poly.ReleaseCondition. Insurers
+// add the release token with SetToken once committee members signed it.
+type Policy struct {
+	desc  *Descriptor
+	token *Token
+	now   func() time.Time
+}
+
+// NewPolicy returns a Policy enforcing the Descriptor, which must be checked
+// against the Deal with Descriptor.Verify first.
+func NewPolicy(desc *Descriptor) *Policy {
+	return &Policy{desc: desc, now: time.Now}
+}
+
+// SetToken sets the release token used to check the condition, which may be
+// nil.
+func (p *Policy) SetToken(token *Token) *Policy {
+	p.token = token
+	return p
+}
+
+// SetClock sets the function returning the time at which the condition is
+// checked, time.Now by default.
+func (p *Policy) SetClock(now func() time.Time) *Policy {
+	p.now = now
+	return p
+}
+
+// Digest returns the digest of the Descriptor, see Descriptor.Digest.
+func (p *Policy) Digest() ([]byte, error) {
+	return p.desc.Digest()
+}
+
+// Check returns nil iff the Descriptor is signed by the Dealer of the Deal
+// and its condition holds. The Deal may be a recertified version of the Deal
+// named by the Descriptor: the digests of the Deal bind it to the Descriptor.
+func (p *Policy) Check(deal *poly.Deal) error {
+	if err := p.desc.verifySignature(deal); err != nil {
+		return err
+	}
+	return p.desc.Condition.Check(p.desc.DealID, p.now(), p.token)
+}
+
+// Reveal reveals the share of insurer i of the Deal tracked by the State, as
+// poly.State.RevealShare does, provided that the Descriptor is valid for the
+// Deal and its condition holds at time now given the token, which may be nil.
+// It is meant for Deals without a Policy, whose insurers did not approve the
+// Descriptor.
+func Reveal(state *poly.State, i int, key *config.KeyPair, desc *Descriptor,
+	now time.Time, token *Token) (abstract.Scalar, error) {
+
+	if err := desc.Verify(&state.Deal); err != nil {
+		return nil, err
+	}
+	if err := desc.Condition.Check(desc.DealID, now, token); err != nil {
+		return nil, err
+	}
+	return state.RevealShare(i, key)
+}
@@ -0,0 +1,189 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/poly"
+	"github.com/stretchr/testify/require"
+)
+
+var suite = edwards.NewAES128SHA256Ed25519(false)
+
+// Returns a Deal certified by its insurers, and the keys of the Dealer and
+// insurers.
+func certifiedDeal(t *testing.T) (*poly.State, *config.KeyPair, []*config.KeyPair) {
+	dealer := config.NewKeyPair(suite)
+	keys := make([]*config.KeyPair, 5)
+	insurers := make([]abstract.Point, len(keys))
+	for i := range keys {
+		keys[i] = config.NewKeyPair(suite)
+		insurers[i] = keys[i].Public
+	}
+	deal := new(poly.Deal).ConstructDeal(config.NewKeyPair(suite), dealer,
+		3, 4, insurers)
+	state := new(poly.State).Init(*deal)
+	for i := range keys {
+		response, err := deal.ProduceResponse(i, keys[i])
+		require.Nil(t, err)
+		require.Nil(t, state.AddResponse(i, response))
+	}
+	return state, dealer, keys
+}
+
+func TestDeadline(t *testing.T) {
+	state, dealer, keys := certifiedDeal(t)
+	deadline := time.Unix(1700000000, 0)
+	desc, err := NewDescriptor(&state.Deal, &Deadline{deadline}, dealer)
+	require.Nil(t, err)
+
+	_, err = Reveal(state, 0, keys[0], desc, deadline.Add(-time.Second), nil)
+	require.Equal(t, errorNotYet, err)
+	share, err := Reveal(state, 0, keys[0], desc, deadline, nil)
+	require.Nil(t, err)
+	require.Nil(t, state.Deal.VerifyRevealedShare(0, share))
+
+	// Marshalling round trip
+	buf, err := desc.MarshalBinary()
+	require.Nil(t, err)
+	desc2, err := UnmarshalDescriptor(suite, buf)
+	require.Nil(t, err)
+	require.Nil(t, desc2.Verify(&state.Deal))
+	require.True(t, desc2.Condition.(*Deadline).After.Equal(deadline))
+
+	// A descriptor not signed by the Dealer is rejected
+	desc2.Condition = &Deadline{deadline.Add(-time.Hour)}
+	_, err = Reveal(state, 0, keys[0], desc2, deadline.Add(-time.Second), nil)
+	require.NotNil(t, err)
+
+	// A descriptor for another Deal is rejected
+	other, _, _ := certifiedDeal(t)
+	require.Equal(t, errorDeal, desc.Verify(&other.Deal))
+}
+
+func TestCommittee(t *testing.T) {
+	state, dealer, keys := certifiedDeal(t)
+	members := make([]*config.KeyPair, 3)
+	c := &Committee{Suite: suite, K: 2}
+	for i := range members {
+		members[i] = config.NewKeyPair(suite)
+		c.Members = append(c.Members, members[i].Public)
+	}
+	desc, err := NewDescriptor(&state.Deal, c, dealer)
+	require.Nil(t, err)
+	buf, err := desc.MarshalBinary()
+	require.Nil(t, err)
+	desc, err = UnmarshalDescriptor(suite, buf)
+	require.Nil(t, err)
+
+	id := state.Deal.Id()
+	token := &Token{make(map[int][]byte)}
+	now := time.Now()
+	_, err = Reveal(state, 1, keys[1], desc, now, nil)
+	require.Equal(t, errorToken, err)
+
+	sig, err := SignRelease(members[2], id)
+	require.Nil(t, err)
+	token.Signatures[2] = sig
+	_, err = Reveal(state, 1, keys[1], desc, now, token)
+	require.Equal(t, errorToken, err)
+
+	// A signature from a non-member does not count
+	sig, err = SignRelease(config.NewKeyPair(suite), id)
+	require.Nil(t, err)
+	token.Signatures[0] = sig
+	_, err = Reveal(state, 1, keys[1], desc, now, token)
+	require.Equal(t, errorToken, err)
+
+	sig, err = SignRelease(members[0], id)
+	require.Nil(t, err)
+	token.Signatures[0] = sig
+	share, err := Reveal(state, 1, keys[1], desc, now, token)
+	require.Nil(t, err)
+	require.Nil(t, state.Deal.VerifyRevealedShare(1, share))
+
+	_, err = UnmarshalDescriptor(suite, buf[:len(buf)-1])
+	require.Equal(t, errorEncoding, err)
+}
+
+func TestCommitteeDuplicateMembers(t *testing.T) {
+	state, _, _ := certifiedDeal(t)
+	member := config.NewKeyPair(suite)
+	c := &Committee{Suite: suite, K: 2,
+		Members: []abstract.Point{member.Public, member.Public}}
+	id := state.Deal.Id()
+	sig, err := SignRelease(member, id)
+	require.Nil(t, err)
+	token := &Token{map[int][]byte{0: sig, 1: sig}}
+	require.Equal(t, errorToken, c.Check(id, time.Now(), token))
+}
+
+func TestPolicy(t *testing.T) {
+	dealer := config.NewKeyPair(suite)
+	keys := make([]*config.KeyPair, 5)
+	insurers := make([]abstract.Point, len(keys))
+	for i := range keys {
+		keys[i] = config.NewKeyPair(suite)
+		insurers[i] = keys[i].Public
+	}
+	deal := new(poly.Deal).ConstructDeal(config.NewKeyPair(suite), dealer,
+		3, 4, insurers)
+	deadline := time.Unix(1700000000, 0)
+	desc, err := NewDescriptor(deal, &Deadline{deadline}, dealer)
+	require.Nil(t, err)
+	now := deadline.Add(-time.Second)
+	policy := NewPolicy(desc).SetClock(func() time.Time { return now })
+	deal.SetReleaseCondition(policy)
+
+	// Insurers approve the Deal together with the Descriptor
+	state := new(poly.State).Init(*deal)
+	for i := range keys {
+		response, err := deal.ProduceResponse(i, keys[i])
+		require.Nil(t, err)
+		require.Nil(t, state.AddResponse(i, response))
+	}
+	require.Nil(t, state.DealCertified())
+
+	// A signature approving the Deal without the Descriptor is rejected
+	bare := new(poly.Deal).ConstructDeal(config.NewKeyPair(suite), dealer,
+		3, 4, insurers)
+	response, err := bare.ProduceResponse(0, keys[0])
+	require.Nil(t, err)
+	bareDesc, err := NewDescriptor(bare, &Deadline{deadline}, dealer)
+	require.Nil(t, err)
+	bareState := new(poly.State).Init(*bare.SetReleaseCondition(NewPolicy(bareDesc)))
+	require.NotNil(t, bareState.AddResponse(0, response))
+
+	// The condition is enforced by State.RevealShare itself
+	_, err = state.RevealShare(0, keys[0])
+	require.Equal(t, errorNotYet, err)
+	now = deadline
+	share, err := state.RevealShare(0, keys[0])
+	require.Nil(t, err)
+	require.Nil(t, state.Deal.VerifyRevealedShare(0, share))
+
+	// Recertified Deals keep the condition
+	now = deadline.Add(-time.Second)
+	recertified, err := state.Recertify(5)
+	require.Nil(t, err)
+	for i := range keys {
+		response, err := recertified.Deal.ProduceResponse(i, keys[i])
+		require.Nil(t, err)
+		require.Nil(t, recertified.AddResponse(i, response))
+	}
+	_, err = recertified.RevealShare(0, keys[0])
+	require.Equal(t, errorNotYet, err)
+	now = deadline
+	_, err = recertified.RevealShare(0, keys[0])
+	require.Nil(t, err)
+
+	// A Descriptor not signed by the Dealer does not release the shares
+	forged := *desc
+	forged.Condition = &Deadline{deadline.Add(-time.Hour)}
+	state.Deal.SetReleaseCondition(NewPolicy(&forged))
+	_, err = state.RevealShare(0, keys[0])
+	require.NotNil(t, err)
+}
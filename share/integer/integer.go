@@ -0,0 +1,164 @@
+// Package integer implements Shamir secret sharing of integers, such as RSA
+// private exponents, which unlike group scalars are not elements of a prime
+// field of fixed size.
+//
+// An integer secret 0 <= s < 2^bits is shared over the prime field Z_p, where
+// p is a prime of bits+1 bits chosen for the size of the secrets, so that the
+// secret is recovered exactly by Lagrange interpolation modulo p. The prime is
+// public and must be known to all parties; it is typically generated once
+// per modulus size with NewParams, e.g., with bits = N.BitLen() to share the
+// private exponent d < N of an RSA key of modulus N.
+package integer
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"math/big"
+
+	"github.com/dedis/crypto/random"
+)
+
+// Some error definitions
+var errorThreshold = errors.New("invalid threshold")
+var errorSecret = errors.New("secret out of range")
+var errorShares = errors.New("not enough shares to recover secret")
+var errorParams = errors.New("invalid field modulus")
+var errorShareCount = errors.New("more shares than the field modulus allows")
+
+// Params holds the prime field the shares live in.
+type Params struct {
+	P *big.Int // Prime modulus of the field
+}
+
+// NewParams returns parameters for sharing integers of at most the given
+// number of bits, with a random prime modulus of bits+1 bits.
+func NewParams(bits int, rand cipher.Stream) (*Params, error) {
+	p, err := primeFrom(rand, bits+1)
+	if err != nil {
+		return nil, err
+	}
+	return &Params{p}, nil
+}
+
+// MaxSecret returns the bound on the secrets that can be shared, which are
+// the integers 0 <= s < MaxSecret.
+func (p *Params) MaxSecret() *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(p.P.BitLen()-1))
+}
+
+// PriShare represents a private share of an integer secret.
+type PriShare struct {
+	I int      // Index of the private share
+	V *big.Int // Value of the private share
+}
+
+// Split shares a secret 0 <= secret < params.MaxSecret() into n shares, any t
+// of which recover it, using a random polynomial of degree t-1 over Z_p. The
+// shares are evaluations at 1..n, which must be distinct and non-zero modulo
+// p, so that n must be smaller than p.
+func Split(params *Params, secret *big.Int, t, n int, rand cipher.Stream) ([]*PriShare, error) {
+	if params == nil || params.P == nil || params.P.Sign() <= 0 {
+		return nil, errorParams
+	}
+	if t < 1 || t > n {
+		return nil, errorThreshold
+	}
+	if big.NewInt(int64(n)).Cmp(params.P) >= 0 {
+		return nil, errorShareCount
+	}
+	if secret.Sign() < 0 || secret.Cmp(params.MaxSecret()) >= 0 {
+		return nil, errorSecret
+	}
+	coeffs := make([]*big.Int, t)
+	coeffs[0] = new(big.Int).Set(secret)
+	for j := 1; j < t; j++ {
+		coeffs[j] = random.Int(params.P, rand)
+	}
+	shares := make([]*PriShare, n)
+	for i := range shares {
+		x := big.NewInt(int64(i + 1))
+		v := new(big.Int)
+		for j := t - 1; j >= 0; j-- {
+			v.Mul(v, x)
+			v.Add(v, coeffs[j])
+			v.Mod(v, params.P)
+		}
+		shares[i] = &PriShare{i, v}
+	}
+	return shares, nil
+}
+
+// Recover reconstructs the secret from at least t shares with distinct
+// indices. Nil shares and shares with an index already seen are ignored.
+func Recover(params *Params, shares []*PriShare, t int) (*big.Int, error) {
+	if params == nil || params.P == nil || params.P.Sign() <= 0 {
+		return nil, errorParams
+	}
+	if t < 1 {
+		return nil, errorThreshold
+	}
+	P := params.P
+	xs := make([]*big.Int, 0, t)
+	ys := make([]*big.Int, 0, t)
+	seen := make(map[int]bool)
+	for _, s := range shares {
+		if s == nil || s.V == nil || s.I < 0 || seen[s.I] {
+			continue
+		}
+		seen[s.I] = true
+		xs = append(xs, big.NewInt(int64(s.I+1)))
+		ys = append(ys, s.V)
+		if len(xs) == t {
+			break
+		}
+	}
+	if len(xs) < t {
+		return nil, errorShares
+	}
+
+	// Lagrange interpolation at 0
+	acc := new(big.Int)
+	num := new(big.Int)
+	den := new(big.Int)
+	tmp := new(big.Int)
+	for i, xi := range xs {
+		num.SetInt64(1)
+		den.SetInt64(1)
+		for j, xj := range xs {
+			if i == j {
+				continue
+			}
+			num.Mul(num, xj)
+			num.Mod(num, P)
+			den.Mul(den, tmp.Sub(xj, xi))
+			den.Mod(den, P)
+		}
+		if den.ModInverse(den, P) == nil {
+			return nil, errorParams
+		}
+		num.Mul(num, den)
+		num.Mul(num, ys[i])
+		acc.Add(acc, num)
+		acc.Mod(acc, P)
+	}
+	return acc, nil
+}
+
+// primeFrom returns a prime of the given number of bits drawn from a stream.
+func primeFrom(stream cipher.Stream, bits int) (*big.Int, error) {
+	return rand.Prime(streamReader{stream}, bits)
+}
+
+// streamReader reads the key stream of a cipher.Stream.
+type streamReader struct {
+	s cipher.Stream
+}
+
+func (r streamReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	r.s.XORKeyStream(p, p)
+	return len(p), nil
+}
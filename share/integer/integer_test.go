@@ -0,0 +1,67 @@
+package integer
+
+import (
+	"crypto/rsa"
+	"math/big"
+	"testing"
+
+	"github.com/dedis/crypto/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitRecover(t *testing.T) {
+	params, err := NewParams(256, random.Stream)
+	require.Nil(t, err)
+	require.Equal(t, 257, params.P.BitLen())
+	require.True(t, params.P.ProbablyPrime(20))
+
+	n, th := 7, 4
+	secret := random.Int(params.MaxSecret(), random.Stream)
+	shares, err := Split(params, secret, th, n, random.Stream)
+	require.Nil(t, err)
+
+	// Any t shares, including duplicates and missing ones, recover the secret
+	sub := []*PriShare{shares[6], nil, shares[2], shares[6], shares[0], shares[4]}
+	rec, err := Recover(params, sub, th)
+	require.Nil(t, err)
+	require.Equal(t, 0, secret.Cmp(rec))
+
+	_, err = Recover(params, shares[:th-1], th)
+	require.Equal(t, errorShares, err)
+	rec, err = Recover(params, shares[:th-1], th-1)
+	require.Nil(t, err)
+	require.NotEqual(t, 0, secret.Cmp(rec))
+
+	_, err = Split(params, params.MaxSecret(), th, n, random.Stream)
+	require.Equal(t, errorSecret, err)
+	_, err = Split(params, secret, n+1, n, random.Stream)
+	require.Equal(t, errorThreshold, err)
+
+	// Share n would be at x = 0 for n = p
+	small := &Params{big.NewInt(7)}
+	_, err = Split(small, big.NewInt(3), 2, 7, random.Stream)
+	require.Equal(t, errorShareCount, err)
+	shares, err = Split(small, big.NewInt(3), 2, 6, random.Stream)
+	require.Nil(t, err)
+	rec, err = Recover(small, shares[4:], 2)
+	require.Nil(t, err)
+	require.Equal(t, int64(3), rec.Int64())
+}
+
+func TestRSAExponent(t *testing.T) {
+	key, err := rsa.GenerateKey(streamReader{random.Stream}, 1024)
+	require.Nil(t, err)
+	params, err := NewParams(key.N.BitLen(), random.Stream)
+	require.Nil(t, err)
+
+	shares, err := Split(params, key.D, 3, 5, random.Stream)
+	require.Nil(t, err)
+	d, err := Recover(params, shares[2:], 3)
+	require.Nil(t, err)
+	require.Equal(t, 0, d.Cmp(key.D))
+
+	// The recovered exponent decrypts
+	m := big.NewInt(42)
+	c := new(big.Int).Exp(m, big.NewInt(int64(key.E)), key.N)
+	require.Equal(t, 0, m.Cmp(new(big.Int).Exp(c, d, key.N)))
+}
@@ -0,0 +1,212 @@
+// Package kzg implements the polynomial commitment scheme of Kate, Zaverucha
+// and Goldberg in "Constant-Size Commitments to Polynomials and Their
+// Applications" for secret sharing polynomials. Whereas a share.PubPoly
+// commits to a polynomial of threshold t with t points, a kzg.PubPoly is a
+// single point, whatever t, and every private share comes with an evaluation
+// proof, also a single point, against which it is checked:
+//  1. The public parameters are generated once for the largest threshold in
+//     use, by a trusted party or a ceremony, see Setup and NewParams.
+//  2. The dealer commits to its secret sharing polynomial with Commit, and
+//     sends every share with its proof from Prove or ProveShares.
+//  3. Each share holder checks its share with PubPoly.Check.
+//
+// The scheme needs a pairing, see Pairing. The commitments and proofs are
+// binding only if nobody knows the trapdoor of the parameters.
+//
+// This package only provides the commitment scheme: Deals of package poly
+// still commit to their polynomial with a poly.PubPoly of t points, since no
+// pairing suite is available to them without cgo. Selecting KZG commitments
+// when constructing Deals is a separate change.
+package kzg
+
+import (
+	"crypto/cipher"
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/share"
+)
+
+// Some error definitions.
+var errorThreshold = errors.New("threshold exceeds the parameters")
+var errorIndex = errors.New("invalid share index")
+var errorEmpty = errors.New("polynomial without coefficients")
+
+// Pairing is a bilinear map e from G1 x G2 to GT, whose groups have the same
+// prime order, e.g., a pbc.Pairing through NewPBCPairing (build tag pbc).
+type Pairing interface {
+	G1() abstract.Group
+	G2() abstract.Group
+	GT() abstract.Group
+
+	// Pair returns e(p1, p2) for p1 in G1 and p2 in G2.
+	Pair(p1, p2 abstract.Point) abstract.Point
+}
+
+// Params are the public parameters of the scheme for polynomials of
+// threshold up to t: the points tau^k*G1 for k < t and tau*G2, for a trapdoor
+// tau that nobody may know, as it allows to forge proofs.
+type Params struct {
+	pairing Pairing
+	powers  []abstract.Point // tau^k*G1
+	tau     abstract.Point   // tau*G2
+}
+
+// Setup generates the parameters for polynomials of threshold up to t, picking
+// the trapdoor tau from rand and discarding it. The caller learns tau, so the
+// parameters are only sound if the caller is trusted by everyone; otherwise,
+// use NewParams with the output of a multi-party ceremony.
+func Setup(p Pairing, t int, rand cipher.Stream) *Params {
+	tau := p.G1().Scalar().Pick(rand)
+	x := p.G1().Scalar().One()
+	powers := make([]abstract.Point, t)
+	for k := range powers {
+		powers[k] = p.G1().Point().Mul(nil, x)
+		x.Mul(x, tau)
+	}
+	return &Params{p, powers, p.G2().Point().Mul(nil, tau)}
+}
+
+// NewParams returns the parameters made of the points powers[k] = tau^k*G1
+// and tau2 = tau*G2, e.g., from a ceremony.
+func NewParams(p Pairing, powers []abstract.Point, tau2 abstract.Point) *Params {
+	return &Params{p, powers, tau2}
+}
+
+// Pairing returns the pairing of the parameters. Polynomials and shares must
+// use scalars of its group G1.
+func (par *Params) Pairing() Pairing {
+	return par.pairing
+}
+
+// Threshold returns the largest threshold of the polynomials supported by the
+// parameters.
+func (par *Params) Threshold() int {
+	return len(par.powers)
+}
+
+// PubPoly represents a constant-size public commitment to a secret sharing
+// polynomial f, C = f(tau)*G1.
+type PubPoly struct {
+	par    *Params
+	commit abstract.Point
+}
+
+// NewPubPoly creates a new public commitment from its point.
+func NewPubPoly(par *Params, commit abstract.Point) *PubPoly {
+	return &PubPoly{par, commit}
+}
+
+// Commit creates the public commitment to the polynomial p, whose threshold
+// must not exceed the one of the parameters.
+func Commit(par *Params, p *share.PriPoly) (*PubPoly, error) {
+	coeffs := p.Coefficients()
+	if len(coeffs) > par.Threshold() {
+		return nil, errorThreshold
+	}
+	return &PubPoly{par, par.combine(coeffs)}, nil
+}
+
+// combine returns sum(coeffs[k]*tau^k*G1).
+func (par *Params) combine(coeffs []abstract.Scalar) abstract.Point {
+	g1 := par.pairing.G1()
+	v := g1.Point().Null()
+	tmp := g1.Point()
+	for k, c := range coeffs {
+		v.Add(v, tmp.Mul(par.powers[k], c))
+	}
+	return v
+}
+
+// Commit returns the commitment point C.
+func (p *PubPoly) Commit() abstract.Point {
+	return p.commit
+}
+
+// Threshold returns the largest threshold of the committed polynomial, which
+// is the one of the parameters: a commitment binds the degree of the
+// polynomial only to the number of powers of tau available.
+func (p *PubPoly) Threshold() int {
+	return p.par.Threshold()
+}
+
+// Equal checks equality of two public commitments p and q.
+func (p *PubPoly) Equal(q *PubPoly) bool {
+	return p.commit.Equal(q.commit)
+}
+
+// xCoord returns the x-coordinate of a share, see share.PriShare.
+func xCoord(g abstract.Group, i int, x abstract.Scalar) abstract.Scalar {
+	if x != nil {
+		return g.Scalar().Set(x)
+	}
+	return g.Scalar().SetInt64(1 + int64(i))
+}
+
+// Prove computes the evaluation proof of the share of index i of the
+// polynomial p, i.e., W = q(tau)*G1 for the quotient q(X) = (p(X) -
+// p(x))/(X - x) at the x-coordinate x = i+1 of the share.
+func Prove(par *Params, p *share.PriPoly, i int) (abstract.Point, error) {
+	return ProveAt(par, p, i, nil)
+}
+
+// ProveAt computes the evaluation proof of the share of index i at the
+// explicit x-coordinate x, or at i+1 if x is nil, see share.PriPoly.EvalAt.
+func ProveAt(par *Params, p *share.PriPoly, i int, x abstract.Scalar) (abstract.Point, error) {
+	if x == nil && i < 0 {
+		return nil, errorIndex
+	}
+	coeffs := p.Coefficients()
+	if len(coeffs) < 1 {
+		return nil, errorEmpty
+	}
+	if len(coeffs) > par.Threshold() {
+		return nil, errorThreshold
+	}
+	xi := xCoord(par.pairing.G1(), i, x)
+
+	// Synthetic division of p by (X - x), dropping the remainder p(x)
+	t := len(coeffs)
+	quotient := make([]abstract.Scalar, t-1)
+	if t > 1 {
+		quotient[t-2] = coeffs[t-1]
+	}
+	for k := t - 2; k > 0; k-- {
+		quotient[k-1] = par.pairing.G1().Scalar().Mul(quotient[k], xi)
+		quotient[k-1].Add(quotient[k-1], coeffs[k])
+	}
+	return par.combine(quotient), nil
+}
+
+// ProveShares computes the evaluation proofs of the n shares p(1),...,p(n),
+// see share.PriPoly.Shares.
+func ProveShares(par *Params, p *share.PriPoly, n int) ([]abstract.Point, error) {
+	proofs := make([]abstract.Point, n)
+	for i := range proofs {
+		proof, err := Prove(par, p, i)
+		if err != nil {
+			return nil, err
+		}
+		proofs[i] = proof
+	}
+	return proofs, nil
+}
+
+// Check a private share against the public commitment with its evaluation
+// proof W, i.e., e(C - v*G1, G2) == e(W, tau*G2 - x*G2) for the value v and
+// the x-coordinate x of the share.
+func (p *PubPoly) Check(s *share.PriShare, proof abstract.Point) bool {
+	if s.X == nil && s.I < 0 || proof == nil {
+		return false
+	}
+	par := p.par
+	g1, g2 := par.pairing.G1(), par.pairing.G2()
+	xi := xCoord(g1, s.I, s.X)
+
+	left := g1.Point().Mul(nil, s.V)
+	left.Sub(p.commit, left)
+	right := g2.Point().Mul(nil, xi)
+	right.Sub(par.tau, right)
+	return par.pairing.Pair(left, g2.Point().Base()).Equal(
+		par.pairing.Pair(proof, right))
+}
@@ -0,0 +1,139 @@
+package kzg
+
+import (
+	"crypto/cipher"
+	"io"
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/ed25519"
+	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/share"
+	"github.com/stretchr/testify/assert"
+)
+
+var scalars = ed25519.NewAES128SHA256Ed25519(false)
+
+// toyGroup is an insecure group for tests, whose points are represented by
+// their discrete logarithms with respect to the base point. The toy pairing
+// multiplies them, which is bilinear, so that the scheme can be tested without
+// the PBC library.
+type toyGroup struct{}
+
+type toyPoint struct {
+	s abstract.Scalar
+}
+
+type toyPairing struct{}
+
+func (toyPairing) G1() abstract.Group { return toyGroup{} }
+func (toyPairing) G2() abstract.Group { return toyGroup{} }
+func (toyPairing) GT() abstract.Group { return toyGroup{} }
+func (toyPairing) Pair(p1, p2 abstract.Point) abstract.Point {
+	return &toyPoint{scalars.Scalar().Mul(p1.(*toyPoint).s, p2.(*toyPoint).s)}
+}
+
+func (toyGroup) String() string           { return "toy" }
+func (toyGroup) ScalarLen() int           { return scalars.ScalarLen() }
+func (toyGroup) Scalar() abstract.Scalar  { return scalars.Scalar() }
+func (toyGroup) PointLen() int            { return scalars.ScalarLen() }
+func (toyGroup) Point() abstract.Point    { return &toyPoint{scalars.Scalar()} }
+func (toyGroup) PrimeOrder() bool         { return true }
+func (p *toyPoint) String() string        { return p.s.String() }
+func (p *toyPoint) MarshalSize() int      { return p.s.MarshalSize() }
+func (p *toyPoint) PickLen() int          { return 0 }
+func (p *toyPoint) Data() ([]byte, error) { return nil, nil }
+
+func (p *toyPoint) MarshalBinary() ([]byte, error)         { return p.s.MarshalBinary() }
+func (p *toyPoint) UnmarshalBinary(buf []byte) error       { return p.s.UnmarshalBinary(buf) }
+func (p *toyPoint) MarshalTo(w io.Writer) (int, error)     { return p.s.MarshalTo(w) }
+func (p *toyPoint) UnmarshalFrom(r io.Reader) (int, error) { return p.s.UnmarshalFrom(r) }
+
+func (p *toyPoint) Equal(q abstract.Point) bool { return p.s.Equal(q.(*toyPoint).s) }
+func (p *toyPoint) Null() abstract.Point        { p.s.Zero(); return p }
+func (p *toyPoint) Base() abstract.Point        { p.s.One(); return p }
+func (p *toyPoint) Set(q abstract.Point) abstract.Point {
+	p.s.Set(q.(*toyPoint).s)
+	return p
+}
+func (p *toyPoint) Clone() abstract.Point { return &toyPoint{p.s.Clone()} }
+func (p *toyPoint) Pick(data []byte, rand cipher.Stream) (abstract.Point, []byte) {
+	p.s.Pick(rand)
+	return p, data
+}
+func (p *toyPoint) Add(a, b abstract.Point) abstract.Point {
+	p.s.Add(a.(*toyPoint).s, b.(*toyPoint).s)
+	return p
+}
+func (p *toyPoint) Sub(a, b abstract.Point) abstract.Point {
+	p.s.Sub(a.(*toyPoint).s, b.(*toyPoint).s)
+	return p
+}
+func (p *toyPoint) Neg(a abstract.Point) abstract.Point {
+	p.s.Neg(a.(*toyPoint).s)
+	return p
+}
+func (p *toyPoint) Mul(a abstract.Point, s abstract.Scalar) abstract.Point {
+	if a == nil {
+		p.s.Set(s)
+	} else {
+		p.s.Mul(a.(*toyPoint).s, s)
+	}
+	return p
+}
+
+func TestKZG(t *testing.T) {
+	g := toyGroup{}
+	n, th := 10, 4
+	par := Setup(toyPairing{}, th, random.Stream)
+	assert.Equal(t, th, par.Threshold())
+	poly := share.NewPriPoly(g, th, nil, random.Stream)
+	pub, err := Commit(par, poly)
+	assert.Nil(t, err)
+	assert.Equal(t, th, pub.Threshold())
+
+	proofs, err := ProveShares(par, poly, n)
+	assert.Nil(t, err)
+	shares := poly.Shares(n)
+	for i, s := range shares {
+		assert.True(t, pub.Check(s, proofs[i]))
+	}
+
+	// wrong value, wrong proof or wrong commitment
+	bad := &share.PriShare{I: 2, V: g.Scalar().Add(shares[2].V, g.Scalar().One())}
+	assert.False(t, pub.Check(bad, proofs[2]))
+	assert.False(t, pub.Check(shares[2], proofs[3]))
+	assert.False(t, pub.Check(shares[2], nil))
+	other, _ := Commit(par, share.NewPriPoly(g, th, nil, random.Stream))
+	assert.False(t, other.Check(shares[2], proofs[2]))
+	assert.False(t, other.Equal(pub))
+
+	// the commitment is a single point, whatever the threshold
+	decoded := NewPubPoly(par, g.Point())
+	buf, err := pub.Commit().MarshalBinary()
+	assert.Nil(t, err)
+	assert.Nil(t, decoded.Commit().UnmarshalBinary(buf))
+	assert.True(t, decoded.Equal(pub))
+	assert.True(t, decoded.Check(shares[5], proofs[5]))
+
+	// explicit x-coordinates
+	x := g.Scalar().Pick(random.Stream)
+	s, err := poly.EvalAt(7, x)
+	assert.Nil(t, err)
+	proof, err := ProveAt(par, poly, 7, x)
+	assert.Nil(t, err)
+	assert.True(t, pub.Check(s, proof))
+	assert.False(t, pub.Check(shares[7], proof))
+
+	// polynomials of lower threshold also verify, not larger ones
+	low := share.NewPriPoly(g, 1, nil, random.Stream)
+	lowPub, err := Commit(par, low)
+	assert.Nil(t, err)
+	proof, err = Prove(par, low, 3)
+	assert.Nil(t, err)
+	assert.True(t, lowPub.Check(low.Eval(3), proof))
+	_, err = Commit(par, share.NewPriPoly(g, th+1, nil, random.Stream))
+	assert.Error(t, err)
+	_, err = Prove(par, poly, -1)
+	assert.Error(t, err)
+}
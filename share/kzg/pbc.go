@@ -0,0 +1,35 @@
+// +build pbc
+
+package kzg
+
+import (
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/pbc"
+)
+
+// pbcPairing adapts a pbc.Pairing to the Pairing interface.
+type pbcPairing struct {
+	p *pbc.Pairing
+}
+
+// NewPBCPairing returns the Pairing of a pairing of the PBC library, e.g.,
+// new(pbc.Pairing).InitD224().
+func NewPBCPairing(p *pbc.Pairing) Pairing {
+	return &pbcPairing{p}
+}
+
+func (p *pbcPairing) G1() abstract.Group {
+	return p.p.G1()
+}
+
+func (p *pbcPairing) G2() abstract.Group {
+	return p.p.G2()
+}
+
+func (p *pbcPairing) GT() abstract.Group {
+	return p.p.GT()
+}
+
+func (p *pbcPairing) Pair(p1, p2 abstract.Point) abstract.Point {
+	return p.p.GT().PairingPoint().Pairing(p1, p2)
+}
@@ -0,0 +1,164 @@
+// Package mul implements the multiplication of two verifiably shared secrets
+// without reconstructing them, following the degree reduction of Gennaro,
+// Rabin and Rabin in "Simplified VSS and Fast-track Multiparty Computations
+// with Applications to Threshold Cryptography". The secrets a and b are shared
+// with polynomials f and g of threshold t, and committed to with the public
+// polynomials A and B. The product f(i)*g(i) of the shares of participant i
+// lies on the polynomial f*g of threshold 2t-1, which is brought back to
+// threshold t in three steps:
+//  1. Each participant i reshares the product of its shares with Reshare(),
+//     publishes the returned Resharing and sends the j-th returned share to
+//     participant j over a private channel.
+//  2. Each participant verifies the Resharings with VerifyResharing() and
+//     the participants agree on a set of at least 2t-1 valid ones.
+//  3. Each participant combines the shares it received from this set with
+//     Combine(), which returns its share of a*b and the public commitment
+//     polynomial of the new sharing.
+//
+// All participants must combine the same set of Resharings, as the shares
+// obtained from different sets do not belong to the same polynomial.
+// For a concrete example see mul_test.go.
+package mul
+
+import (
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/proof"
+	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/share"
+)
+
+// Some error definitions.
+var errorShareIndex = errors.New("shares of different indices")
+var errorTooFewResharings = errors.New("not enough resharings to reduce the degree")
+var errorDifferentLengths = errors.New("inputs of different lengths")
+var errorResharingIndex = errors.New("invalid or duplicate resharing index")
+var errorThreshold = errors.New("resharing of wrong threshold")
+var errorProduct = errors.New("verification of resharing product failed")
+var errorShareCheck = errors.New("verification of reshared share failed")
+
+// Resharing is the public part of the resharing of the product of the shares
+// of a participant.
+type Resharing struct {
+	I    int             // Index of the participant
+	Poly *share.PubPoly  // Commitment to the resharing polynomial h, with h(0) = f(I)*g(I)
+	P    proof.DLEQProof // Proof that log_{G}(f(I)*G) == log_{g(I)*G}(h(0)*G)
+}
+
+// Reshare creates a resharing of threshold t of the product of the shares a
+// and b of the participant for the n participants. The function returns the
+// public Resharing together with the n shares to distribute.
+func Reshare(suite abstract.Suite, a, b *share.PriShare, t, n int) (*Resharing, []*share.PriShare, error) {
+	if a.I != b.I {
+		return nil, nil, errorShareIndex
+	}
+	c := suite.Scalar().Mul(a.V, b.V)
+	priPoly := share.NewPriPoly(suite, t, c, random.Stream)
+	pubPoly := priPoly.Commit(nil)
+
+	// Prove that the commitment to c = a*b is consistent with the
+	// commitments to a and b: a*G and a*(b*G) have the same discrete
+	// logarithm with respect to G and b*G.
+	bG := suite.Point().Mul(nil, b.V)
+	P, _, _, err := proof.NewDLEQProof(suite, suite.Point().Base(), bG, a.V)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Resharing{a.I, pubPoly, *P}, priPoly.Shares(n), nil
+}
+
+// VerifyResharing checks that the resharing r has the same threshold as the
+// public commitment polynomials A and B of the factors, and that it reshares
+// the product of the shares committed to in A and B.
+func VerifyResharing(suite abstract.Suite, A, B *share.PubPoly, r *Resharing) error {
+	if r.Poly.Threshold() != A.Threshold() {
+		return errorThreshold
+	}
+	aG := A.Eval(r.I).V
+	bG := B.Eval(r.I).V
+	if err := r.P.Verify(suite, suite.Point().Base(), bG, aG, r.Poly.Commit()); err != nil {
+		return errorProduct
+	}
+	return nil
+}
+
+// Combine computes the share of the product of the two secrets from the
+// shares received from a set of at least 2t-1 Resharings, where shares[k] is
+// the share of the participant dealt by resharings[k]. Every resharing and
+// share is verified, and a single invalid one makes the function fail. The
+// function returns the share of the product together with the public
+// commitment polynomial of the new sharing, which is the same for all the
+// participants combining the same resharings.
+func Combine(suite abstract.Suite, A, B *share.PubPoly, resharings []*Resharing, shares []*share.PriShare, n int) (*share.PriShare, *share.PubPoly, error) {
+	if len(resharings) != len(shares) {
+		return nil, nil, errorDifferentLengths
+	}
+	t := A.Threshold()
+	if len(resharings) < 2*t-1 {
+		return nil, nil, errorTooFewResharings
+	}
+	seen := make(map[int]bool)
+	for k, r := range resharings {
+		if r.I < 0 || n <= r.I || seen[r.I] {
+			return nil, nil, errorResharingIndex
+		}
+		seen[r.I] = true
+		if err := VerifyResharing(suite, A, B, r); err != nil {
+			return nil, nil, err
+		}
+		if shares[k].I != shares[0].I {
+			return nil, nil, errorShareIndex
+		}
+		if !r.Poly.Check(shares[k]) {
+			return nil, nil, errorShareCheck
+		}
+	}
+
+	// The product is the constant term of the polynomial of threshold 2t-1
+	// through the points (r.I, h(0)), and so is the new sharing polynomial
+	// the same linear combination of the resharing polynomials.
+	v := suite.Scalar().Zero()
+	commits := make([]abstract.Point, t)
+	for j := range commits {
+		commits[j] = suite.Point().Null()
+	}
+	tmp := suite.Scalar()
+	P := suite.Point()
+	for k, l := range lagrange(suite, resharings) {
+		v.Add(v, tmp.Mul(l, shares[k].V))
+		_, rc := resharings[k].Poly.Info()
+		for j := range commits {
+			commits[j].Add(commits[j], P.Mul(rc[j], l))
+		}
+	}
+	return &share.PriShare{I: shares[0].I, V: v},
+		share.NewPubPoly(suite, nil, commits), nil
+}
+
+// Returns the Lagrange coefficients for evaluation at 0 of the polynomials
+// through the indices of the resharings.
+func lagrange(suite abstract.Suite, resharings []*Resharing) []abstract.Scalar {
+	x := make([]abstract.Scalar, len(resharings))
+	for k, r := range resharings {
+		x[k] = suite.Scalar().SetInt64(1 + int64(r.I))
+	}
+	nums := make([]abstract.Scalar, len(x))
+	dens := make([]abstract.Scalar, len(x))
+	tmp := suite.Scalar()
+	for i, xi := range x {
+		nums[i] = suite.Scalar().One()
+		dens[i] = suite.Scalar().One()
+		for j, xj := range x {
+			if i == j {
+				continue
+			}
+			nums[i].Mul(nums[i], xj)
+			dens[i].Mul(dens[i], tmp.Sub(xj, xi))
+		}
+	}
+	for i, inv := range abstract.BatchInvert(dens) {
+		nums[i].Mul(nums[i], inv)
+	}
+	return nums
+}
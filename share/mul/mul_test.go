@@ -0,0 +1,110 @@
+package mul
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/share"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMul(test *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	n := 7
+	t := 3
+
+	// Shared secrets a and b
+	a := suite.Scalar().Pick(random.Stream)
+	b := suite.Scalar().Pick(random.Stream)
+	f := share.NewPriPoly(suite, t, a, random.Stream)
+	g := share.NewPriPoly(suite, t, b, random.Stream)
+	A := f.Commit(nil)
+	B := g.Commit(nil)
+	fShares := f.Shares(n)
+	gShares := g.Shares(n)
+
+	// (1) Resharing of the products of the shares
+	resharings := make([]*Resharing, n)
+	dealt := make([][]*share.PriShare, n) // dealt[i][j]: share of participant j from i
+	for i := 0; i < n; i++ {
+		r, s, err := Reshare(suite, fShares[i], gShares[i], t, n)
+		require.Equal(test, err, nil)
+		resharings[i] = r
+		dealt[i] = s
+	}
+
+	// (2) Verification of the resharings
+	for _, r := range resharings {
+		require.Equal(test, VerifyResharing(suite, A, B, r), nil)
+	}
+
+	// (3) Combination of the first 2t-1 resharings
+	m := 2*t - 1
+	shares := make([]*share.PriShare, n)
+	var pubPoly *share.PubPoly
+	for j := 0; j < n; j++ {
+		received := make([]*share.PriShare, m)
+		for i := 0; i < m; i++ {
+			received[i] = dealt[i][j]
+		}
+		s, p, err := Combine(suite, A, B, resharings[:m], received, n)
+		require.Equal(test, err, nil)
+		require.True(test, p.Check(s))
+		if pubPoly != nil {
+			require.True(test, p.Equal(pubPoly))
+		}
+		shares[j] = s
+		pubPoly = p
+	}
+
+	ab := suite.Scalar().Mul(a, b)
+	recovered, err := share.RecoverSecret(suite, shares[n-t:], t, n)
+	require.Equal(test, err, nil)
+	require.True(test, recovered.Equal(ab))
+	require.True(test, pubPoly.Commit().Equal(suite.Point().Mul(nil, ab)))
+}
+
+func TestMulInvalid(test *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	n := 5
+	t := 2
+
+	f := share.NewPriPoly(suite, t, nil, random.Stream)
+	g := share.NewPriPoly(suite, t, nil, random.Stream)
+	A := f.Commit(nil)
+	B := g.Commit(nil)
+	fShares := f.Shares(n)
+	gShares := g.Shares(n)
+
+	// Resharing of a wrong product
+	wrong := &share.PriShare{I: 0, V: suite.Scalar().Pick(random.Stream)}
+	r, _, err := Reshare(suite, fShares[0], wrong, t, n)
+	require.Equal(test, err, nil)
+	require.Equal(test, VerifyResharing(suite, A, B, r), errorProduct)
+
+	// Resharing of a wrong threshold
+	r, _, err = Reshare(suite, fShares[0], gShares[0], t+1, n)
+	require.Equal(test, err, nil)
+	require.Equal(test, VerifyResharing(suite, A, B, r), errorThreshold)
+
+	// Too few resharings and invalid shares
+	resharings := make([]*Resharing, n)
+	received := make([]*share.PriShare, n)
+	for i := 0; i < n; i++ {
+		r, s, err := Reshare(suite, fShares[i], gShares[i], t, n)
+		require.Equal(test, err, nil)
+		resharings[i] = r
+		received[i] = s[0]
+	}
+	_, _, err = Combine(suite, A, B, resharings[:2*t-2], received[:2*t-2], n)
+	require.Equal(test, err, errorTooFewResharings)
+
+	received[1] = &share.PriShare{I: 0, V: suite.Scalar().Add(received[1].V, suite.Scalar().One())}
+	_, _, err = Combine(suite, A, B, resharings, received, n)
+	require.Equal(test, err, errorShareCheck)
+
+	_, _, err = Combine(suite, A, B, []*Resharing{resharings[0], resharings[0], resharings[2]},
+		[]*share.PriShare{received[0], received[0], received[2]}, n)
+	require.Equal(test, err, errorResharingIndex)
+}
@@ -0,0 +1,118 @@
+// Package oprf implements a threshold oblivious pseudo-random function (OPRF),
+// following the 2HashDH construction of Jarecki, Kiayias, Krawczyk and Xu in
+// "TOPPSS: Cost-minimal Password-Protected Secret Sharing based on Threshold
+// OPRF". The PRF key k is shared among n servers with a secret sharing
+// polynomial of threshold t, e.g. by a distributed key generation, and the
+// PRF output for a password pw is H(pw, k*H'(pw)), where H' hashes to a point.
+// A client obtains it from any t servers without revealing pw to them:
+//  1. The client blinds the password with Blind() and sends the blinded point
+//     to the servers.
+//  2. Each server evaluates the blinded point with its share of k using
+//     Evaluate(), and returns the Evaluation, which carries a proof that the
+//     server used the share committed to in the public polynomial of k.
+//  3. Once it received at least t Evaluations, the client verifies them,
+//     combines the valid ones and derives the PRF output with Finalize().
+//
+// The output is a key of the requested length, e.g. to decrypt an envelope
+// holding the secrets of the user. For a concrete example see oprf_test.go.
+package oprf
+
+import (
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/kdf"
+	"github.com/dedis/crypto/proof"
+	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/share"
+)
+
+// Some error definitions.
+var errorEvalVerification = errors.New("verification of evaluation failed")
+var errorTooFewEvaluations = errors.New("not enough valid evaluations to finalize")
+var errorInvalidPoint = errors.New("point outside of the prime-order subgroup")
+
+// Domain separation tag of the hash of passwords to points.
+const hashToPointTag = "oprf.HashToPoint"
+
+// Evaluation is the evaluation of a blinded password by a server.
+type Evaluation struct {
+	S share.PubShare  // Blinded point multiplied by the key share of the server
+	P proof.DLEQProof // Proof of correct evaluation
+}
+
+// HashToPoint deterministically maps a password to a point of the suite,
+// whose discrete logarithm is unknown, see abstract.MapToPoint.
+func HashToPoint(suite abstract.Suite, password []byte) abstract.Point {
+	return abstract.MapToPoint(suite, append([]byte(hashToPointTag), password...))
+}
+
+// Blind hashes the password to a point and blinds it with a random scalar r.
+// The function returns the blinding scalar, to be kept by the client until
+// Finalize, and the blinded point to send to the servers.
+func Blind(suite abstract.Suite, password []byte) (abstract.Scalar, abstract.Point) {
+	r := suite.Scalar().Pick(random.Stream)
+	for r.Equal(suite.Scalar().Zero()) {
+		r.Pick(random.Stream)
+	}
+	return r, suite.Point().Mul(HashToPoint(suite, password), r)
+}
+
+// Evaluate multiplies the blinded point B by the key share of the server, and
+// proves that log_{G}(K) == log_{B}(k*B), where K is the public key share of
+// the server. B must lie in the prime-order subgroup: otherwise, on curves
+// with a cofactor, k*B would leak the key share modulo the cofactor.
+func Evaluate(suite abstract.Suite, key *share.PriShare, B abstract.Point) (*Evaluation, error) {
+	if !abstract.IsInCorrectSubgroup(B) {
+		return nil, errorInvalidPoint
+	}
+	P, _, kB, err := proof.NewDLEQProof(suite, suite.Point().Base(), B, key.V)
+	if err != nil {
+		return nil, err
+	}
+	return &Evaluation{share.PubShare{I: key.I, V: kB}, *P}, nil
+}
+
+// VerifyEvaluation checks the evaluation of the blinded point B by the server
+// of public key share K, as obtained by evaluating the public commitment
+// polynomial of the PRF key at the index of the server.
+func VerifyEvaluation(suite abstract.Suite, B, K abstract.Point, eval *Evaluation) error {
+	if err := eval.P.Verify(suite, suite.Point().Base(), B, K, eval.S.V); err != nil {
+		return errorEvalVerification
+	}
+	return nil
+}
+
+// Finalize verifies the evaluations of the blinded point B against the public
+// commitment polynomial pub of the PRF key shared among n servers, combines
+// the valid ones, removes the blinding r and derives a key of length bytes
+// for the given label from the PRF output. Invalid evaluations are ignored, as
+// long as at least a threshold of them are valid.
+func Finalize(suite abstract.Suite, password []byte, r abstract.Scalar, B abstract.Point, pub *share.PubPoly, evals []*Evaluation, n int, label string, length int) ([]byte, error) {
+	t := pub.Threshold()
+	var good []*share.PubShare
+	seen := make(map[int]bool)
+	for _, e := range evals {
+		if e == nil || e.S.I < 0 || n <= e.S.I || seen[e.S.I] {
+			continue
+		}
+		if VerifyEvaluation(suite, B, pub.Eval(e.S.I).V, e) == nil {
+			seen[e.S.I] = true
+			good = append(good, &share.PubShare{I: e.S.I, V: e.S.V})
+		}
+	}
+	if len(good) < t {
+		return nil, errorTooFewEvaluations
+	}
+	kB, err := share.RecoverCommit(suite, good[:t], t, n)
+	if err != nil {
+		return nil, err
+	}
+	kP := suite.Point().Mul(kB, suite.Scalar().Inv(r))
+	buf, err := kP.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	prk := kdf.Extract(suite, password, buf)
+	return kdf.Expand(suite, prk, []byte(label), length)
+}
@@ -0,0 +1,95 @@
+package oprf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/kdf"
+	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/share"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOPRF(test *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	n := 5
+	t := 3
+	password := []byte("correct horse battery staple")
+
+	// PRF key shared among the servers
+	priPoly := share.NewPriPoly(suite, t, nil, random.Stream)
+	keys := priPoly.Shares(n)
+	pub := priPoly.Commit(nil)
+
+	// (1) Blinding (client)
+	r, B := Blind(suite, password)
+
+	// (2) Evaluation (servers)
+	evals := make([]*Evaluation, n)
+	for i := 0; i < n; i++ {
+		e, err := Evaluate(suite, keys[i], B)
+		require.Equal(test, err, nil)
+		require.Equal(test, VerifyEvaluation(suite, B, pub.Eval(i).V, e), nil)
+		evals[i] = e
+	}
+
+	// (3) Finalization (client), with a corrupted evaluation
+	evals[0].S.V = suite.Point().Add(evals[0].S.V, suite.Point().Base())
+	key, err := Finalize(suite, password, r, B, pub, evals, n, "envelope", 32)
+	require.Equal(test, err, nil)
+	require.Equal(test, len(key), 32)
+
+	// The output does not depend on the blinding nor on the servers
+	r2, B2 := Blind(suite, password)
+	evals2 := make([]*Evaluation, 0, t)
+	for i := n - t; i < n; i++ {
+		e, _ := Evaluate(suite, keys[i], B2)
+		evals2 = append(evals2, e)
+	}
+	key2, err := Finalize(suite, password, r2, B2, pub, evals2, n, "envelope", 32)
+	require.Equal(test, err, nil)
+	require.True(test, bytes.Equal(key, key2))
+
+	// It is the PRF of the password under the shared key
+	kP := suite.Point().Mul(HashToPoint(suite, password), priPoly.Secret())
+	buf, _ := kP.MarshalBinary()
+	expected, _ := kdf.Expand(suite, kdf.Extract(suite, password, buf), []byte("envelope"), 32)
+	require.True(test, bytes.Equal(key, expected))
+
+	// Too few valid evaluations
+	_, err = Finalize(suite, password, r, B, pub, evals[:t], n, "envelope", 32)
+	require.Equal(test, err, errorTooFewEvaluations)
+
+	// Another password gives another output
+	r3, B3 := Blind(suite, []byte("wrong password"))
+	evals3 := make([]*Evaluation, t)
+	for i := range evals3 {
+		evals3[i], _ = Evaluate(suite, keys[i], B3)
+	}
+	key3, err := Finalize(suite, []byte("wrong password"), r3, B3, pub, evals3, n, "envelope", 32)
+	require.Equal(test, err, nil)
+	require.True(test, !bytes.Equal(key, key3))
+}
+
+// A blinded point with a torsion component is rejected, as its evaluation
+// would leak the key share modulo the cofactor.
+func TestEvaluateTorsion(test *testing.T) {
+	suite := edwards.NewAES128SHA256Ed25519(false)
+	priPoly := share.NewPriPoly(suite, 3, nil, random.Stream)
+	key := priPoly.Shares(5)[0]
+
+	// (0, -1), the point of order 2 of Ed25519
+	buf := bytes.Repeat([]byte{0xff}, 32)
+	buf[0], buf[31] = 0xec, 0x7f
+	T := suite.Point()
+	require.Nil(test, T.UnmarshalBinary(buf))
+
+	_, B := Blind(suite, []byte("password"))
+	_, err := Evaluate(suite, key, suite.Point().Add(B, T))
+	require.Equal(test, errorInvalidPoint, err)
+	_, err = Evaluate(suite, key, T)
+	require.Equal(test, errorInvalidPoint, err)
+	_, err = Evaluate(suite, key, B)
+	require.Nil(test, err)
+}
@@ -0,0 +1,104 @@
+package share
+
+import (
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+var errorPedersenShares = errors.New("private shares have different indices")
+
+// PedersenPubPoly represents a public dual-base (Pedersen) commitment
+// polynomial to a pair of secret sharing polynomials f and g. Each
+// coefficient is committed to as C_k = f_k*B + g_k*H, so that the commitments
+// hide the coefficients of f even for polynomials of low entropy. The base
+// points B and H must be independent, i.e., nobody may know the discrete
+// logarithm of H with respect to B.
+type PedersenPubPoly struct {
+	g       abstract.Group   // Cryptographic group
+	b       abstract.Point   // Base point of f, nil for standard base
+	h       abstract.Point   // Base point of g
+	commits []abstract.Point // Commitments to coefficients of f and g
+}
+
+// NewPedersenPubPoly creates a new public dual-base commitment polynomial.
+func NewPedersenPubPoly(g abstract.Group, b, h abstract.Point, commits []abstract.Point) *PedersenPubPoly {
+	return &PedersenPubPoly{g, b, h, commits}
+}
+
+// CommitPedersen creates a public dual-base commitment polynomial to p and q,
+// using the base point b, or the standard base if b == nil, for p and the base
+// point h for q. Both polynomials must have the same group and threshold.
+func (p *PriPoly) CommitPedersen(b, h abstract.Point, q *PriPoly) (*PedersenPubPoly, error) {
+	if p.g.String() != q.g.String() {
+		return nil, errorGroups
+	}
+	if p.Threshold() != q.Threshold() {
+		return nil, errorCoeffs
+	}
+	commits := make([]abstract.Point, p.Threshold())
+	tmp := p.g.Point()
+	for i := range commits {
+		commits[i] = p.g.Point().Mul(b, p.coeffs[i])
+		commits[i].Add(commits[i], tmp.Mul(h, q.coeffs[i]))
+	}
+	return &PedersenPubPoly{p.g, b, h, commits}, nil
+}
+
+// Info returns the base points and the commitments to the polynomial
+// coefficients.
+func (p *PedersenPubPoly) Info() (abstract.Point, abstract.Point, []abstract.Point) {
+	return p.b, p.h, p.commits
+}
+
+// Threshold returns the secret sharing threshold.
+func (p *PedersenPubPoly) Threshold() int {
+	return len(p.commits)
+}
+
+// Commit returns the commitment to the secrets f(0) and g(0), i.e., the
+// constant term of the polynomial.
+func (p *PedersenPubPoly) Commit() abstract.Point {
+	return p.commits[0]
+}
+
+// Eval computes the public share v = f(i)*B + g(i)*H.
+func (p *PedersenPubPoly) Eval(i int) *PubShare {
+	return (&PubPoly{g: p.g, b: p.b, commits: p.commits}).Eval(i)
+}
+
+// Shares creates a list of n public commitment shares.
+func (p *PedersenPubPoly) Shares(n int) []*PubShare {
+	return (&PubPoly{g: p.g, b: p.b, commits: p.commits}).Shares(n)
+}
+
+// Equal checks equality of two public dual-base commitment polynomials p and
+// q, which must also have the same base points.
+func (p *PedersenPubPoly) Equal(q *PedersenPubPoly) bool {
+	if !p.h.Equal(q.h) {
+		return false
+	}
+	if p.b == nil || q.b == nil {
+		if p.b != q.b {
+			return false
+		}
+	} else if !p.b.Equal(q.b) {
+		return false
+	}
+	if p.Threshold() != q.Threshold() {
+		return false
+	}
+	return (&PubPoly{g: p.g, b: p.b, commits: p.commits}).Equal(&PubPoly{g: q.g, b: q.b, commits: q.commits})
+}
+
+// Check a pair of private shares s of f and t of g against a public dual-base
+// commitment polynomial. Both shares must have the same index.
+func (p *PedersenPubPoly) Check(s, t *PriShare) (bool, error) {
+	if s.I != t.I {
+		return false, errorPedersenShares
+	}
+	pv := (&PubPoly{g: p.g, b: p.b, commits: p.commits}).eval(p.g.Point(), s.I, s.X)
+	ps := p.g.Point().Mul(p.b, s.V)
+	ps.Add(ps, p.g.Point().Mul(p.h, t.V))
+	return pv.Equal(ps), nil
+}
@@ -10,8 +10,10 @@
 package share
 
 import (
+	"bytes"
 	"crypto/cipher"
 	"crypto/subtle"
+	"encoding/json"
 	"errors"
 
 	"github.com/dedis/crypto/abstract"
@@ -20,11 +22,130 @@ import (
 // Some error definitions
 var errorGroups = errors.New("non-matching groups")
 var errorCoeffs = errors.New("different number of coefficients")
-
-// PriShare represents a private share.
+var errorShareJSON = errors.New("invalid JSON share")
+var errorShareInit = errors.New("share value must be initialized before decoding")
+var errorZeroX = errors.New("share at x-coordinate 0 would reveal the secret")
+var errorDuplicateX = errors.New("shares with duplicate x-coordinates")
+
+// PriShare represents a private share. Shares are evaluations of the
+// polynomial at x = I+1, unless they carry an explicit x-coordinate X, e.g.,
+// to interoperate with other secret sharing implementations.
 type PriShare struct {
 	I int             // Index of the private share
 	V abstract.Scalar // Value of the private share
+	X abstract.Scalar // x-coordinate of the share, nil for I+1
+}
+
+// xCoord returns the x-coordinate of the share of index i and explicit
+// x-coordinate x, which may be nil. It fails for the x-coordinate 0, at which
+// the polynomial evaluates to the secret.
+func xCoord(g abstract.Group, i int, x abstract.Scalar) (abstract.Scalar, error) {
+	xi := g.Scalar()
+	if x != nil {
+		xi.Set(x)
+	} else {
+		xi.SetInt64(1 + int64(i))
+	}
+	if xi.Equal(g.Scalar().Zero()) {
+		return nil, errorZeroX
+	}
+	return xi, nil
+}
+
+// xCoords returns the x-coordinates of shares of the given indices and
+// explicit x-coordinates, which may be nil. It fails for the x-coordinate 0
+// and for duplicate x-coordinates, for which interpolation is undefined.
+func xCoords(g abstract.Group, idx []int, xs []abstract.Scalar) ([]abstract.Scalar, error) {
+	x := make([]abstract.Scalar, len(idx))
+	seen := make(map[string]bool, len(idx))
+	for k, i := range idx {
+		xi, err := xCoord(g, i, xs[k])
+		if err != nil {
+			return nil, err
+		}
+		buf, err := xi.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		if seen[string(buf)] {
+			return nil, errorDuplicateX
+		}
+		seen[string(buf)] = true
+		x[k] = xi
+	}
+	return x, nil
+}
+
+// shareJSON is the JSON representation of private and public shares.
+type shareJSON struct {
+	I int    `json:"i"`
+	V []byte `json:"v"`
+	X []byte `json:"x,omitempty"`
+}
+
+// decodeShareJSON strictly decodes a JSON share into the value v,
+// which must be initialized to an element of the right group. It returns
+// the index and the encoding of the x-coordinate of the share, if explicit.
+func decodeShareJSON(buf []byte, v encoding) (int, []byte, error) {
+	var s shareJSON
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return 0, nil, err
+	}
+	if dec.More() || s.I < 0 || s.V == nil {
+		return 0, nil, errorShareJSON
+	}
+	return s.I, s.X, v.UnmarshalBinary(s.V)
+}
+
+// encodeShareJSON encodes a share of index i, value v and explicit
+// x-coordinate x, which may be nil.
+func encodeShareJSON(i int, v encoding, x abstract.Scalar) ([]byte, error) {
+	s := shareJSON{I: i}
+	var err error
+	if s.V, err = v.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	if x != nil {
+		if s.X, err = x.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(&s)
+}
+
+// encoding is the part of abstract.Scalar and abstract.Point used by
+// the JSON encoding of shares.
+type encoding interface {
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary([]byte) error
+}
+
+// MarshalJSON encodes the private share in JSON, with its value and its
+// explicit x-coordinate, if any, in base64.
+func (p *PriShare) MarshalJSON() ([]byte, error) {
+	return encodeShareJSON(p.I, p.V, p.X)
+}
+
+// UnmarshalJSON decodes a private share from JSON. Since a scalar does not
+// record its group, p.V must be set to a scalar of the right group
+// beforehand, e.g., with g.Scalar().
+func (p *PriShare) UnmarshalJSON(buf []byte) error {
+	if p.V == nil {
+		return errorShareInit
+	}
+	i, x, err := decodeShareJSON(buf, p.V)
+	if err != nil {
+		return err
+	}
+	p.I = i
+	p.X = nil
+	if x != nil {
+		p.X = p.V.Clone()
+		return p.X.UnmarshalBinary(x)
+	}
+	return nil
 }
 
 // PriPoly represents a secret sharing polynomial.
@@ -47,6 +168,18 @@ func NewPriPoly(g abstract.Group, t int, s abstract.Scalar, rand cipher.Stream)
 	return &PriPoly{g, coeffs}
 }
 
+// CoefficientsToPriPoly returns a secret sharing polynomial built from the
+// given coefficients, where coeffs[0] is the shared secret. This is mostly
+// useful to reproduce test vectors across implementations; the coefficients
+// of a polynomial used in production must be picked at random.
+func CoefficientsToPriPoly(g abstract.Group, coeffs []abstract.Scalar) *PriPoly {
+	cs := make([]abstract.Scalar, len(coeffs))
+	for i, c := range coeffs {
+		cs[i] = g.Scalar().Set(c)
+	}
+	return &PriPoly{g, cs}
+}
+
 // Threshold returns the secret sharing threshold.
 func (p *PriPoly) Threshold() int {
 	return len(p.coeffs)
@@ -57,26 +190,105 @@ func (p *PriPoly) Secret() abstract.Scalar {
 	return p.coeffs[0]
 }
 
+// Coefficients returns a copy of the coefficients of the polynomial. Anyone
+// knowing them can compute every share and thus the shared secret, so they
+// must be handled with the same care as the secret itself.
+func (p *PriPoly) Coefficients() []abstract.Scalar {
+	coeffs := make([]abstract.Scalar, len(p.coeffs))
+	for i, c := range p.coeffs {
+		coeffs[i] = p.g.Scalar().Set(c)
+	}
+	return coeffs
+}
+
 // Eval computes the private share v = p(i).
 func (p *PriPoly) Eval(i int) *PriShare {
-	xi := p.g.Scalar().SetInt64(1 + int64(i))
+	return &PriShare{i, p.eval(p.g.Scalar().SetInt64(1 + int64(i))), nil}
+}
+
+// EvalAt computes the private share of index i at the explicit x-coordinate
+// x, i.e., v = p(x). It fails for x = 0, at which p evaluates to the secret.
+func (p *PriPoly) EvalAt(i int, x abstract.Scalar) (*PriShare, error) {
+	xi, err := xCoord(p.g, i, x)
+	if err != nil {
+		return nil, err
+	}
+	return &PriShare{i, p.eval(xi), xi}, nil
+}
+
+// eval computes p(x).
+func (p *PriPoly) eval(x abstract.Scalar) abstract.Scalar {
 	v := p.g.Scalar().Zero()
 	for j := p.Threshold() - 1; j >= 0; j-- {
-		v.Mul(v, xi)
+		v.Mul(v, x)
 		v.Add(v, p.coeffs[j])
 	}
-	return &PriShare{i, v}
+	return v
 }
 
 // Shares creates a list of n private shares p(1),...,p(n).
 func (p *PriPoly) Shares(n int) []*PriShare {
-	shares := make([]*PriShare, n)
+	return p.SharesRange(0, n)
+}
+
+// SharesRange creates the list of private shares with indices from,...,to-1,
+// i.e., p(from+1),...,p(to). Large ranges are evaluated incrementally, see
+// PriShareIterator.
+func (p *PriPoly) SharesRange(from, to int) []*PriShare {
+	if to <= from {
+		return nil
+	}
+	shares := make([]*PriShare, to-from)
+	if len(shares) <= p.Threshold() {
+		for i := range shares {
+			shares[i] = p.Eval(from + i)
+		}
+		return shares
+	}
+	it := p.Iterator(from)
 	for i := range shares {
-		shares[i] = p.Eval(i)
+		shares[i] = it.Next()
 	}
 	return shares
 }
 
+// PriShareIterator evaluates a secret sharing polynomial at consecutive
+// indices using forward differences: once initialized, each share costs
+// t-1 scalar additions instead of the t multiplications and additions of
+// Eval, and no scratch scalars are allocated. This makes it suitable to
+// stream a very large number of shares.
+type PriShareIterator struct {
+	i     int               // Index of the next share
+	diffs []abstract.Scalar // Forward differences of the polynomial at i
+}
+
+// Iterator returns a PriShareIterator whose first share has index i.
+// Initialization evaluates the polynomial t times.
+func (p *PriPoly) Iterator(i int) *PriShareIterator {
+	t := p.Threshold()
+	diffs := make([]abstract.Scalar, t)
+	for k := range diffs {
+		diffs[k] = p.Eval(i + k).V
+	}
+	// Turn the values into forward differences in place
+	for k := 1; k < t; k++ {
+		for j := t - 1; j >= k; j-- {
+			diffs[j].Sub(diffs[j], diffs[j-1])
+		}
+	}
+	return &PriShareIterator{i, diffs}
+}
+
+// Next returns the current private share and advances to the next index.
+func (it *PriShareIterator) Next() *PriShare {
+	share := &PriShare{it.i, it.diffs[0].Clone(), nil}
+	for k := 0; k < len(it.diffs)-1; k++ {
+		it.diffs[k].Add(it.diffs[k], it.diffs[k+1])
+	}
+	it.i++
+	return share
+}
+
 // Add computes the component-wise sum of the polynomials p and q and returns it
 // as a new polynomial.
 func (p *PriPoly) Add(q *PriPoly) (*PriPoly, error) {
@@ -114,49 +326,91 @@ func (p *PriPoly) Commit(b abstract.Point) *PubPoly {
 	for i := range commits {
 		commits[i] = p.g.Point().Mul(b, p.coeffs[i])
 	}
-	return &PubPoly{p.g, b, commits}
+	return NewPubPoly(p.g, b, commits)
 }
 
 // RecoverSecret reconstructs the shared secret p(0) from a list of private
-// shares using Lagrange interpolation.
+// shares using Lagrange interpolation. Shares may have explicit
+// x-coordinates, which must be distinct and non-zero.
 func RecoverSecret(g abstract.Group, shares []*PriShare, t, n int) (abstract.Scalar, error) {
-	x := make(map[int]abstract.Scalar)
+	var idx, is []int
+	var xs []abstract.Scalar
 	for i, s := range shares {
 		if s == nil || s.V == nil || s.I < 0 || n <= s.I {
 			continue
 		}
-		x[i] = g.Scalar().SetInt64(1 + int64(s.I))
+		idx = append(idx, i)
+		is = append(is, s.I)
+		xs = append(xs, s.X)
+	}
+	x, err := xCoords(g, is, xs)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(x) < t {
 		return nil, errors.New("not enough good private shares to reconstruct shared secret")
 	}
 
-	acc := g.Scalar().Zero()
-	num := g.Scalar()
-	den := g.Scalar()
+	// Compute all Lagrange numerators and denominators first so that the
+	// denominators can be inverted in a single batch.
+	nums := make([]abstract.Scalar, len(x))
+	dens := make([]abstract.Scalar, len(x))
 	tmp := g.Scalar()
-
 	for i, xi := range x {
-		num.Set(shares[i].V)
-		den.One()
+		nums[i] = g.Scalar().Set(shares[idx[i]].V)
+		dens[i] = g.Scalar().One()
 		for j, xj := range x {
 			if i == j {
 				continue
 			}
-			num.Mul(num, xj)
-			den.Mul(den, tmp.Sub(xj, xi))
+			nums[i].Mul(nums[i], xj)
+			dens[i].Mul(dens[i], tmp.Sub(xj, xi))
 		}
-		acc.Add(acc, num.Div(num, den))
+	}
+
+	acc := g.Scalar().Zero()
+	for i, inv := range abstract.BatchInvert(dens) {
+		acc.Add(acc, tmp.Mul(nums[i], inv))
 	}
 
 	return acc, nil
 }
 
-// PubShare represents a public share.
+// PubShare represents a public share, see PriShare for its x-coordinate.
 type PubShare struct {
-	I int            // Index of the public share
-	V abstract.Point // Value of the public share
+	I int             // Index of the public share
+	V abstract.Point  // Value of the public share
+	X abstract.Scalar // x-coordinate of the share, nil for I+1
+}
+
+// MarshalJSON encodes the public share in JSON, with its value and its
+// explicit x-coordinate, if any, in base64.
+func (p *PubShare) MarshalJSON() ([]byte, error) {
+	return encodeShareJSON(p.I, p.V, p.X)
+}
+
+// UnmarshalJSON decodes a public share from JSON. Since a point does not
+// record its group, p.V must be set to a point of the right group
+// beforehand, e.g., with g.Point(), and so must p.X be set to a scalar if
+// the share has an explicit x-coordinate.
+func (p *PubShare) UnmarshalJSON(buf []byte) error {
+	if p.V == nil {
+		return errorShareInit
+	}
+	i, x, err := decodeShareJSON(buf, p.V)
+	if err != nil {
+		return err
+	}
+	p.I = i
+	if x == nil {
+		p.X = nil
+		return nil
+	}
+	if p.X == nil {
+		return errorShareInit
+	}
+	return p.X.UnmarshalBinary(x)
 }
 
 // PubPoly represents a public commitment polynomial to a secret sharing polynomial.
@@ -164,11 +418,47 @@ type PubPoly struct {
 	g       abstract.Group   // Cryptographic group
 	b       abstract.Point   // Base point, nil for standard base
 	commits []abstract.Point // Commitments to coefficients of the secret sharing polynomial
+	tmp     *pools           // Pools of temporaries, nil to allocate them
+}
+
+// pools holds the pools of the temporary Points and Scalars of the evaluations
+// of a polynomial, which verifiers typically evaluate once per share.
+type pools struct {
+	points  *abstract.PointPool
+	scalars *abstract.ScalarPool
 }
 
 // NewPubPoly creates a new public commitment polynomial.
 func NewPubPoly(g abstract.Group, b abstract.Point, commits []abstract.Point) *PubPoly {
-	return &PubPoly{g, b, commits}
+	tmp := &pools{abstract.NewPointPool(g), abstract.NewScalarPool(g)}
+	return &PubPoly{g, b, commits, tmp}
+}
+
+// Returns a temporary Scalar, to be released with release.
+func (p *PubPoly) scalar() abstract.Scalar {
+	if p.tmp == nil {
+		return p.g.Scalar()
+	}
+	return p.tmp.scalars.Get()
+}
+
+// Returns a temporary Point, to be released with release.
+func (p *PubPoly) point() abstract.Point {
+	if p.tmp == nil {
+		return p.g.Point()
+	}
+	return p.tmp.points.Get()
+}
+
+// Releases temporaries obtained from scalar and point.
+func (p *PubPoly) release(s abstract.Scalar, P ...abstract.Point) {
+	if p.tmp == nil {
+		return
+	}
+	p.tmp.scalars.Release(s)
+	for _, Q := range P {
+		p.tmp.points.Release(Q)
+	}
 }
 
 // Info returns the base point and the commitments to the polynomial coefficients.
@@ -188,24 +478,99 @@ func (p *PubPoly) Commit() abstract.Point {
 
 // Eval computes the public share v = p(i).
 func (p *PubPoly) Eval(i int) *PubShare {
-	xi := p.g.Scalar().SetInt64(1 + int64(i)) // x-coordinate of this share
-	v := p.g.Point().Null()
+	return &PubShare{i, p.eval(p.g.Point(), i, nil), nil}
+}
+
+// EvalAt computes the public share of index i at the explicit x-coordinate
+// x, i.e., v = p(x). It fails for x = 0, at which p evaluates to the
+// commitment to the secret.
+func (p *PubPoly) EvalAt(i int, x abstract.Scalar) (*PubShare, error) {
+	xi, err := xCoord(p.g, i, x)
+	if err != nil {
+		return nil, err
+	}
+	return &PubShare{i, p.eval(p.g.Point(), i, xi), xi}, nil
+}
+
+// eval computes into v the evaluation of p at the x-coordinate of the share
+// of index i and explicit x-coordinate x, which may be nil, and returns v.
+func (p *PubPoly) eval(v abstract.Point, i int, x abstract.Scalar) abstract.Point {
+	xi := p.scalar() // x-coordinate of this share
+	if x != nil {
+		xi.Set(x)
+	} else {
+		xi.SetInt64(1 + int64(i))
+	}
+	v.Null()
 	for j := p.Threshold() - 1; j >= 0; j-- {
 		v.Mul(v, xi)
 		v.Add(v, p.commits[j])
 	}
-	return &PubShare{i, v}
+	p.release(xi)
+	return v
 }
 
 // Shares creates a list of n public commitment shares p(1),...,p(n).
 func (p *PubPoly) Shares(n int) []*PubShare {
-	shares := make([]*PubShare, n)
+	return p.SharesRange(0, n)
+}
+
+// SharesRange creates the list of public shares with indices from,...,to-1,
+// i.e., p(from+1),...,p(to). Large ranges are evaluated incrementally, see
+// PubShareIterator.
+func (p *PubPoly) SharesRange(from, to int) []*PubShare {
+	if to <= from {
+		return nil
+	}
+	shares := make([]*PubShare, to-from)
+	if len(shares) <= p.Threshold() {
+		for i := range shares {
+			shares[i] = p.Eval(from + i)
+		}
+		return shares
+	}
+	it := p.Iterator(from)
 	for i := range shares {
-		shares[i] = p.Eval(i)
+		shares[i] = it.Next()
 	}
 	return shares
 }
 
+// PubShareIterator evaluates a public commitment polynomial at consecutive
+// indices using forward differences: once initialized, each share costs t-1
+// point additions instead of the t point multiplications of Eval.
+type PubShareIterator struct {
+	g     abstract.Group   // Cryptographic group
+	i     int              // Index of the next share
+	diffs []abstract.Point // Forward differences of the polynomial at i
+}
+
+// Iterator returns a PubShareIterator whose first share has index i.
+// Initialization evaluates the polynomial t times.
+func (p *PubPoly) Iterator(i int) *PubShareIterator {
+	t := p.Threshold()
+	diffs := make([]abstract.Point, t)
+	for k := range diffs {
+		diffs[k] = p.Eval(i + k).V
+	}
+	for k := 1; k < t; k++ {
+		for j := t - 1; j >= k; j-- {
+			diffs[j].Sub(diffs[j], diffs[j-1])
+		}
+	}
+	return &PubShareIterator{p.g, i, diffs}
+}
+
+// Next returns the current public share and advances to the next index.
+func (it *PubShareIterator) Next() *PubShare {
+	share := &PubShare{it.i, it.g.Point().Set(it.diffs[0]), nil}
+	for k := 0; k < len(it.diffs)-1; k++ {
+		it.diffs[k].Add(it.diffs[k], it.diffs[k+1])
+	}
+	it.i++
+	return share
+}
+
 // Add computes the component-wise sum of the polynomials p and q and returns it
 // as a new polynomial. NOTE: If the base points p.b and q.b are different then the
 // base point of the resulting PubPoly cannot be computed without knowing the
@@ -226,7 +591,7 @@ func (p *PubPoly) Add(q *PubPoly) (*PubPoly, error) {
 		commits[i] = p.g.Point().Add(p.commits[i], q.commits[i])
 	}
 
-	return &PubPoly{p.g, p.b, commits}, nil
+	return NewPubPoly(p.g, p.b, commits), nil
 }
 
 // Equal checks equality of two public commitment polynomials p and q.
@@ -243,22 +608,40 @@ func (p *PubPoly) Equal(q *PubPoly) bool {
 	return b == 1
 }
 
-// Check a private share against a public commitment polynomial.
+// Check a private share against a public commitment polynomial. Shares at
+// the x-coordinate 0 never check.
 func (p *PubPoly) Check(s *PriShare) bool {
-	pv := p.Eval(s.I)
-	ps := p.g.Point().Mul(p.b, s.V)
-	return pv.V.Equal(ps)
+	if _, err := xCoord(p.g, s.I, s.X); err != nil {
+		return false
+	}
+	pv := p.eval(p.point(), s.I, s.X)
+	ps := p.point().Mul(p.b, s.V)
+	ok := pv.Equal(ps)
+	p.release(nil, pv, ps)
+	return ok
 }
 
 // RecoverCommit reconstructs the secret commitment p(0) from a list of public
-// shares using Lagrange interpolation.
+// shares using Lagrange interpolation. Shares may have explicit
+// x-coordinates, which must be distinct and non-zero.
 func RecoverCommit(g abstract.Group, shares []*PubShare, t, n int) (abstract.Point, error) {
-	x := make(map[int]abstract.Scalar)
+	var idx, is []int
+	var xs []abstract.Scalar
 	for i, s := range shares {
 		if s == nil || s.V == nil || s.I < 0 || n <= s.I {
 			continue
 		}
-		x[i] = g.Scalar().SetInt64(1 + int64(s.I))
+		idx = append(idx, i)
+		is = append(is, s.I)
+		xs = append(xs, s.X)
+	}
+	coords, err := xCoords(g, is, xs)
+	if err != nil {
+		return nil, err
+	}
+	x := make(map[int]abstract.Scalar, len(coords))
+	for k, i := range idx {
+		x[i] = coords[k]
 	}
 
 	if len(x) < t {
@@ -1,10 +1,14 @@
 package share
 
 import (
+	"encoding/json"
 	"testing"
 
+	"github.com/dedis/crypto/abstract"
 	"github.com/dedis/crypto/edwards"
+	"github.com/dedis/crypto/nist"
 	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/test"
 )
 
 func TestSecretRecovery(test *testing.T) {
@@ -251,3 +255,423 @@ func TestPublicPolyEqual(test *testing.T) {
 		test.Fatal("public polynomials not equal")
 	}
 }
+
+func TestPriPolyCoefficients(test *testing.T) {
+	g := new(edwards.ExtendedCurve).Init(edwards.Param25519(), false)
+	n := 10
+	t := n/2 + 1
+
+	coeffs := make([]abstract.Scalar, t)
+	for i := range coeffs {
+		coeffs[i] = g.Scalar().SetInt64(int64(i + 1))
+	}
+	p := CoefficientsToPriPoly(g, coeffs)
+	if p.Threshold() != t {
+		test.Fatal("wrong threshold")
+	}
+	if !p.Secret().Equal(coeffs[0]) {
+		test.Fatal("secret is not the constant coefficient")
+	}
+
+	// p(1) = 1 + 2 + ... + t
+	if !p.Eval(0).V.Equal(g.Scalar().SetInt64(int64(t * (t + 1) / 2))) {
+		test.Fatal("evaluation of explicit polynomial failed")
+	}
+
+	q := CoefficientsToPriPoly(g, p.Coefficients())
+	if !p.Equal(q) {
+		test.Fatal("polynomials built from the same coefficients differ")
+	}
+
+	// Mutating the returned coefficients must not affect the polynomial.
+	p.Coefficients()[0].Zero()
+	if !p.Secret().Equal(coeffs[0]) {
+		test.Fatal("coefficients are not copied")
+	}
+}
+
+func TestSharesRange(test *testing.T) {
+	g := new(edwards.ExtendedCurve).Init(edwards.Param25519(), false)
+	t := 5
+	priPoly := NewPriPoly(g, t, nil, random.Stream)
+	pubPoly := priPoly.Commit(nil)
+
+	from, to := 3, 3+4*t
+	priShares := priPoly.SharesRange(from, to)
+	pubShares := pubPoly.SharesRange(from, to)
+	if len(priShares) != to-from || len(pubShares) != to-from {
+		test.Fatal("wrong number of shares")
+	}
+	for k := range priShares {
+		i := from + k
+		if priShares[k].I != i || !priShares[k].V.Equal(priPoly.Eval(i).V) {
+			test.Fatal("private share mismatch at index", i)
+		}
+		if pubShares[k].I != i || !pubShares[k].V.Equal(pubPoly.Eval(i).V) {
+			test.Fatal("public share mismatch at index", i)
+		}
+	}
+
+	if priPoly.SharesRange(to, from) != nil || pubPoly.SharesRange(to, from) != nil {
+		test.Fatal("empty range should produce no shares")
+	}
+}
+
+func BenchmarkPriPolyShares(b *testing.B) {
+	g := new(edwards.ExtendedCurve).Init(edwards.Param25519(), false)
+	poly := NewPriPoly(g, 50, nil, random.Stream)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		poly.Shares(1000)
+	}
+}
+
+// The configurations of the threshold benchmarks
+var benchParams = test.ThresholdConfigs([]abstract.Suite{
+	nist.NewAES128SHA256P256(),
+	edwards.NewAES128SHA256Ed25519(false),
+}, [][2]int{{3, 5}, {10, 20}, {34, 100}})
+
+func benchRecoverSecret(b *testing.B, p test.ThresholdParams) {
+	shares := NewPriPoly(p.Suite, p.T, nil, random.Stream).Shares(p.N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := RecoverSecret(p.Suite, shares, p.T, p.N); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchRecoverCommit(b *testing.B, p test.ThresholdParams) {
+	poly := NewPriPoly(p.Suite, p.T, nil, random.Stream)
+	shares := poly.Commit(nil).Shares(p.N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := RecoverCommit(p.Suite, shares, p.T, p.N); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRecoverSecret(b *testing.B) {
+	test.RunThresholdBench(b, benchParams, benchRecoverSecret)
+}
+
+func BenchmarkRecoverCommit(b *testing.B) {
+	test.RunThresholdBench(b, benchParams, benchRecoverCommit)
+}
+
+func TestShareJSON(test *testing.T) {
+	g := new(edwards.ExtendedCurve).Init(edwards.Param25519(), false)
+	poly := NewPriPoly(g, 3, nil, random.Stream)
+	pri := poly.Eval(4)
+	pub := poly.Commit(nil).Eval(4)
+
+	buf, err := json.Marshal(pri)
+	if err != nil {
+		test.Fatal(err)
+	}
+	pri2 := &PriShare{V: g.Scalar()}
+	if err := json.Unmarshal(buf, pri2); err != nil {
+		test.Fatal(err)
+	}
+	if pri2.I != pri.I || !pri2.V.Equal(pri.V) {
+		test.Fatal("private share differs after JSON round trip")
+	}
+	if err := json.Unmarshal(buf, &PriShare{}); err == nil {
+		test.Fatal("uninitialized share value accepted")
+	}
+
+	buf, err = json.Marshal(pub)
+	if err != nil {
+		test.Fatal(err)
+	}
+	pub2 := &PubShare{V: g.Point()}
+	if err := json.Unmarshal(buf, pub2); err != nil {
+		test.Fatal(err)
+	}
+	if pub2.I != pub.I || !pub2.V.Equal(pub.V) {
+		test.Fatal("public share differs after JSON round trip")
+	}
+	if err := json.Unmarshal([]byte(`{"i":1,"v":"AA==","z":2}`), pub2); err == nil {
+		test.Fatal("unknown field accepted")
+	}
+}
+
+func TestExplicitX(test *testing.T) {
+	g := new(edwards.ExtendedCurve).Init(edwards.Param25519(), false)
+	n := 6
+	t := 4
+	poly := NewPriPoly(g, t, nil, random.Stream)
+	pubPoly := poly.Commit(nil)
+
+	// Shares at random x-coordinates, mixed with shares at the default ones
+	priShares := make([]*PriShare, n)
+	pubShares := make([]*PubShare, n)
+	for i := 0; i < n; i++ {
+		if i%2 == 0 {
+			priShares[i] = poly.Eval(i)
+			pubShares[i] = pubPoly.Eval(i)
+			continue
+		}
+		x := g.Scalar().Pick(random.Stream)
+		var err error
+		if priShares[i], err = poly.EvalAt(i, x); err != nil {
+			test.Fatal(err)
+		}
+		if pubShares[i], err = pubPoly.EvalAt(i, x); err != nil {
+			test.Fatal(err)
+		}
+		if !pubPoly.Check(priShares[i]) {
+			test.Fatal("share at explicit x-coordinate fails the check")
+		}
+		if pubPoly.Check(&PriShare{i, priShares[i].V, nil}) {
+			test.Fatal("share checked at the wrong x-coordinate")
+		}
+	}
+
+	secret, err := RecoverSecret(g, priShares[n-t:], t, n)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !secret.Equal(poly.Secret()) {
+		test.Fatal("recovered secret does not match initial value")
+	}
+	commit, err := RecoverCommit(g, pubShares[:t], t, n)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !commit.Equal(pubPoly.Commit()) {
+		test.Fatal("recovered commit does not match initial value")
+	}
+
+	// JSON round trip of the explicit x-coordinates
+	buf, err := json.Marshal(priShares[1])
+	if err != nil {
+		test.Fatal(err)
+	}
+	pri := &PriShare{V: g.Scalar()}
+	if err := json.Unmarshal(buf, pri); err != nil {
+		test.Fatal(err)
+	}
+	if pri.X == nil || !pri.X.Equal(priShares[1].X) {
+		test.Fatal("x-coordinate differs after JSON round trip")
+	}
+	buf, err = json.Marshal(pubShares[1])
+	if err != nil {
+		test.Fatal(err)
+	}
+	if err := json.Unmarshal(buf, &PubShare{V: g.Point()}); err != errorShareInit {
+		test.Fatal("uninitialized x-coordinate accepted")
+	}
+	pub := &PubShare{V: g.Point(), X: g.Scalar()}
+	if err := json.Unmarshal(buf, pub); err != nil {
+		test.Fatal(err)
+	}
+	if !pub.X.Equal(pubShares[1].X) {
+		test.Fatal("x-coordinate differs after JSON round trip")
+	}
+}
+
+func TestInvalidX(test *testing.T) {
+	g := new(edwards.ExtendedCurve).Init(edwards.Param25519(), false)
+	n := 5
+	t := 3
+	poly := NewPriPoly(g, t, nil, random.Stream)
+	pubPoly := poly.Commit(nil)
+
+	// The polynomial evaluates to the secret at x = 0
+	if _, err := poly.EvalAt(0, g.Scalar().Zero()); err != errorZeroX {
+		test.Fatal("private share at x = 0 accepted")
+	}
+	if _, err := pubPoly.EvalAt(0, g.Scalar().Zero()); err != errorZeroX {
+		test.Fatal("public share at x = 0 accepted")
+	}
+	zero := &PriShare{0, poly.Secret(), g.Scalar().Zero()}
+	if pubPoly.Check(zero) {
+		test.Fatal("secret checked as a share at x = 0")
+	}
+
+	priShares := poly.Shares(n)
+	pubShares := pubPoly.Shares(n)
+	priShares[0] = zero
+	if _, err := RecoverSecret(g, priShares, t, n); err != errorZeroX {
+		test.Fatal("private share at x = 0 used for recovery")
+	}
+	pubShares[0] = &PubShare{0, pubPoly.Commit(), g.Scalar().Zero()}
+	if _, err := RecoverCommit(g, pubShares, t, n); err != errorZeroX {
+		test.Fatal("public share at x = 0 used for recovery")
+	}
+
+	// Explicit x-coordinates colliding with another share
+	priShares = poly.Shares(n)
+	pubShares = pubPoly.Shares(n)
+	x := g.Scalar().SetInt64(2)
+	priShares[0], _ = poly.EvalAt(0, x)
+	pubShares[0], _ = pubPoly.EvalAt(0, x)
+	if _, err := RecoverSecret(g, priShares, t, n); err != errorDuplicateX {
+		test.Fatal("private shares with duplicate x-coordinates accepted")
+	}
+	if _, err := RecoverCommit(g, pubShares, t, n); err != errorDuplicateX {
+		test.Fatal("public shares with duplicate x-coordinates accepted")
+	}
+	priShares = poly.Shares(n)
+	priShares = append(priShares, priShares[1])
+	if _, err := RecoverSecret(g, priShares, t, n); err != errorDuplicateX {
+		test.Fatal("duplicate private share accepted")
+	}
+}
+
+func TestShareSet(test *testing.T) {
+	g := new(edwards.ExtendedCurve).Init(edwards.Param25519(), false)
+	n := 10
+	t := n/2 + 1
+	poly := NewPriPoly(g, t, nil, random.Stream)
+	shares := poly.Shares(n)
+
+	if _, err := NewShareSet(g, poly.Commit(nil), t-1); err == nil {
+		test.Fatal("threshold above the number of participants accepted")
+	}
+	set, err := NewShareSet(g, poly.Commit(nil), n)
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// Add shares in reverse order, some of them twice
+	for i := n - 1; i >= n-t+1; i-- {
+		if err := set.AddShare(shares[i]); err != nil {
+			test.Fatal(err)
+		}
+		if err := set.AddShare(shares[i]); err != nil {
+			test.Fatal("duplicate share rejected:", err)
+		}
+	}
+	if set.Has(t) || set.Len() != t-1 {
+		test.Fatal("wrong number of shares")
+	}
+	if _, err := set.Recover(); err == nil {
+		test.Fatal("secret recovered from too few shares")
+	}
+
+	// Invalid and conflicting shares
+	bad := &PriShare{0, g.Scalar().Pick(random.Stream), nil}
+	if set.AddShare(bad) == nil {
+		test.Fatal("invalid share accepted")
+	}
+	bad.I = n - 1
+	if set.AddShare(bad) == nil {
+		test.Fatal("conflicting share accepted")
+	}
+	if set.AddShare(&PriShare{n, shares[0].V, nil}) == nil {
+		test.Fatal("share with out of range index accepted")
+	}
+
+	if err := set.AddShare(shares[0]); err != nil {
+		test.Fatal(err)
+	}
+	idx := set.Indices()
+	if len(idx) != t || idx[0] != 0 || idx[1] != n-t+1 {
+		test.Fatal("wrong indices", idx)
+	}
+	secret, err := set.Recover()
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !secret.Equal(poly.Secret()) {
+		test.Fatal("recovered secret does not match initial value")
+	}
+}
+
+func TestSecretRecoveryWeighted(test *testing.T) {
+	g := new(edwards.ExtendedCurve).Init(edwards.Param25519(), false)
+	weights := []int{3, 1, 0, 2, 4}
+	t := 6
+	poly := NewPriPoly(g, t, nil, random.Stream)
+	groups, err := SplitWeighted(poly, weights)
+	if err != nil {
+		test.Fatal(err)
+	}
+	next := 0
+	for j, group := range groups {
+		if len(group) != weights[j] {
+			test.Fatal("wrong number of shares for participant", j)
+		}
+		for _, s := range group {
+			if s.I != next || !s.V.Equal(poly.Eval(next).V) {
+				test.Fatal("wrong share", s.I)
+			}
+			next++
+		}
+	}
+
+	// Participants 0, 3 and 1 reach the threshold
+	responses := [][]*PriShare{groups[0], groups[1], nil, groups[3]}
+	secret, err := RecoverSecretWeighted(g, responses, t, weights)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !secret.Equal(poly.Secret()) {
+		test.Fatal("recovered secret does not match initial value")
+	}
+
+	// A participant cannot contribute shares of others
+	responses = [][]*PriShare{groups[0], append(groups[1], groups[3]...)}
+	if _, err := RecoverSecretWeighted(g, responses, t, weights); err == nil {
+		test.Fatal("shares outside of the participant's range accepted")
+	}
+
+	if _, err := SplitWeighted(poly, []int{1, -1}); err == nil {
+		test.Fatal("negative weight accepted")
+	}
+}
+
+func TestPedersenCheck(test *testing.T) {
+	g := new(edwards.ExtendedCurve).Init(edwards.Param25519(), false)
+	n := 10
+	t := n/2 + 1
+	h, _ := g.Point().Pick([]byte("H"), random.Stream)
+
+	f := NewPriPoly(g, t, nil, random.Stream)
+	r := NewPriPoly(g, t, nil, random.Stream)
+	pubPoly, err := f.CommitPedersen(nil, h, r)
+	if err != nil {
+		test.Fatal(err)
+	}
+	fShares := f.Shares(n)
+	rShares := r.Shares(n)
+
+	for i := range fShares {
+		ok, err := pubPoly.Check(fShares[i], rShares[i])
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !ok {
+			test.Fatalf("private shares %v not valid with respect to the public commitment polynomial", i)
+		}
+	}
+
+	commit := g.Point().Mul(nil, f.Secret())
+	commit.Add(commit, g.Point().Mul(h, r.Secret()))
+	if !pubPoly.Commit().Equal(commit) {
+		test.Fatal("wrong commitment to the secrets")
+	}
+
+	if ok, _ := pubPoly.Check(fShares[0], rShares[0]); !ok {
+		test.Fatal("valid shares rejected")
+	}
+	if ok, _ := pubPoly.Check(rShares[0], fShares[0]); ok {
+		test.Fatal("swapped shares accepted")
+	}
+	if _, err := pubPoly.Check(fShares[0], rShares[1]); err == nil {
+		test.Fatal("shares of different indices accepted")
+	}
+
+	b, H, commits := pubPoly.Info()
+	if !NewPedersenPubPoly(g, b, H, commits).Equal(pubPoly) {
+		test.Fatal("polynomials not equal")
+	}
+	other, _ := r.CommitPedersen(nil, h, f)
+	if other.Equal(pubPoly) {
+		test.Fatal("different polynomials equal")
+	}
+}
@@ -68,7 +68,7 @@ func EncShares(suite abstract.Suite, H abstract.Point, X []abstract.Point, secre
 	}
 
 	for i := 0; i < n; i++ {
-		ps := &share.PubShare{indices[i], sX[i]}
+		ps := &share.PubShare{indices[i], sX[i], nil}
 		encShares[i] = &PubVerShare{*ps, *proofs[i]}
 	}
 
@@ -112,7 +112,7 @@ func DecShare(suite abstract.Suite, H abstract.Point, X abstract.Point, sH abstr
 	}
 	G := suite.Point().Base()
 	V := suite.Point().Mul(encShare.S.V, suite.Scalar().Inv(x)) // decryption: x^{-1} * (xS)
-	ps := &share.PubShare{encShare.S.I, V}
+	ps := &share.PubShare{encShare.S.I, V, nil}
 	P, _, _, err := proof.NewDLEQProof(suite, G, V, x)
 	if err != nil {
 		return nil, err
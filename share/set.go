@@ -0,0 +1,94 @@
+package share
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+// Some error definitions
+var errorShareIndex = errors.New("share index out of range")
+var errorShareCheck = errors.New("share does not match the public commitment polynomial")
+var errorShareConflict = errors.New("conflicting shares for the same index")
+
+// ShareSet collects the private shares of a secret, one per index, for a
+// group of n participants. Every share is checked against the public
+// commitment polynomial of the secret when added, so that the secret can be
+// recovered as soon as the threshold of the polynomial is reached.
+type ShareSet struct {
+	g      abstract.Group
+	pub    *PubPoly
+	n      int
+	shares map[int]*PriShare
+}
+
+// NewShareSet creates an empty set for the shares of the n participants
+// of the secret committed to by pub.
+func NewShareSet(g abstract.Group, pub *PubPoly, n int) (*ShareSet, error) {
+	if t := pub.Threshold(); t > n {
+		return nil, fmt.Errorf("share: threshold %d exceeds the %d participants", t, n)
+	}
+	return &ShareSet{g, pub, n, make(map[int]*PriShare)}, nil
+}
+
+// AddShare verifies a share against the public commitment polynomial and
+// adds it to the set. Adding a share equal to one already in the set has
+// no effect, while adding a different valid share for the same index,
+// which cannot happen, returns an error.
+func (s *ShareSet) AddShare(share *PriShare) error {
+	if share == nil || share.V == nil || share.I < 0 || share.I >= s.n {
+		return errorShareIndex
+	}
+	if old, ok := s.shares[share.I]; ok {
+		if !old.V.Equal(share.V) {
+			return errorShareConflict
+		}
+		return nil
+	}
+	if !s.pub.Check(share) {
+		return errorShareCheck
+	}
+	s.shares[share.I] = &PriShare{share.I, s.g.Scalar().Set(share.V), abstract.CloneScalar(share.X)}
+	return nil
+}
+
+// Len returns the number of shares in the set.
+func (s *ShareSet) Len() int {
+	return len(s.shares)
+}
+
+// Has returns whether the set holds at least t shares.
+func (s *ShareSet) Has(t int) bool {
+	return len(s.shares) >= t
+}
+
+// Indices returns the indices of the shares in the set, in increasing order.
+func (s *ShareSet) Indices() []int {
+	idx := make([]int, 0, len(s.shares))
+	for i := range s.shares {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	return idx
+}
+
+// Shares returns the shares in the set, in increasing order of index.
+func (s *ShareSet) Shares() []*PriShare {
+	shares := make([]*PriShare, len(s.shares))
+	for k, i := range s.Indices() {
+		shares[k] = s.shares[i]
+	}
+	return shares
+}
+
+// Recover reconstructs the shared secret, as soon as the set holds as many
+// shares as the threshold of the public commitment polynomial.
+func (s *ShareSet) Recover() (abstract.Scalar, error) {
+	t := s.pub.Threshold()
+	if !s.Has(t) {
+		return nil, fmt.Errorf("share: %d shares, %d needed to recover the secret", len(s.shares), t)
+	}
+	return RecoverSecret(s.g, s.Shares()[:t], t, s.n)
+}
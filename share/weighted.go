@@ -0,0 +1,65 @@
+package share
+
+import (
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+var errorWeights = errors.New("invalid participant weights")
+
+// weightRanges returns, for each participant, the first index of its shares,
+// with the total number of shares as last element.
+func weightRanges(weights []int) ([]int, error) {
+	offsets := make([]int, len(weights)+1)
+	for j, w := range weights {
+		if w < 0 {
+			return nil, errorWeights
+		}
+		offsets[j+1] = offsets[j] + w
+	}
+	return offsets, nil
+}
+
+// SplitWeighted evaluates the polynomial for participants holding several
+// shares each: participant j, of weight weights[j], receives the weights[j]
+// consecutive shares following those of participants 0 to j-1. The secret can
+// be recovered by participants whose total weight reaches the threshold.
+func SplitWeighted(p *PriPoly, weights []int) ([][]*PriShare, error) {
+	offsets, err := weightRanges(weights)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([][]*PriShare, len(weights))
+	for j := range weights {
+		groups[j] = p.SharesRange(offsets[j], offsets[j+1])
+	}
+	return groups, nil
+}
+
+// RecoverSecretWeighted reconstructs the shared secret from the shares
+// returned by participants with the given weights, as dealt by SplitWeighted:
+// shares[j] holds the shares of participant j, or nil if it did not respond.
+// Shares outside of the range of indices of the participant that returned
+// them are ignored, so that no participant can contribute more than its
+// weight.
+func RecoverSecretWeighted(g abstract.Group, shares [][]*PriShare, t int,
+	weights []int) (abstract.Scalar, error) {
+
+	offsets, err := weightRanges(weights)
+	if err != nil || len(shares) > len(weights) {
+		return nil, errorWeights
+	}
+	seen := make(map[int]bool)
+	var flat []*PriShare
+	for j, group := range shares {
+		for _, s := range group {
+			if s == nil || s.I < offsets[j] || s.I >= offsets[j+1] || seen[s.I] {
+				continue
+			}
+			seen[s.I] = true
+			flat = append(flat, s)
+		}
+	}
+	return RecoverSecret(g, flat, t, offsets[len(weights)])
+}
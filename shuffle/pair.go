@@ -321,18 +321,7 @@ func Shuffle(group abstract.Group, g, h abstract.Point, X, Y []abstract.Point,
 	ps.Init(group, k)
 
 	// Pick a random permutation
-	pi := make([]int, k)
-	for i := 0; i < k; i++ { // Initialize a trivial permutation
-		pi[i] = i
-	}
-	for i := k - 1; i > 0; i-- { // Shuffle by random swaps
-		j := int(random.Uint64(rand) % uint64(i+1))
-		if j != i {
-			t := pi[j]
-			pi[j] = pi[i]
-			pi[i] = t
-		}
-	}
+	pi := random.Perm(k, rand)
 
 	// Pick a fresh ElGamal blinding factor for each pair
 	beta := make([]abstract.Scalar, k)
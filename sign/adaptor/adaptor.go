@@ -0,0 +1,105 @@
+// Package adaptor implements Schnorr adaptor signatures, i.e., pre-signatures
+// bound to a point T = t*G whose discrete logarithm t is hidden from the
+// verifier. A pre-signature is not a valid signature by itself, but anyone
+// knowing t can adapt it into a regular Schnorr signature, verifiable with
+// sign.VerifySchnorr, and anyone seeing both the pre-signature and the final
+// signature learns t. This is the building block of atomic swaps and escrows:
+//  1. The signer creates a pre-signature of msg for the point T with
+//     PreSign() and sends it to the holder of t.
+//  2. The holder of t checks it with VerifyPreSig() and, once it wants the
+//     signature, completes it with Adapt() and publishes the signature.
+//  3. The signer recovers t from the published signature with
+//     ExtractSecret().
+//
+// Pre-signatures must not be reused: a signer must create a new one for each
+// message and point.
+package adaptor
+
+import (
+	"crypto/sha512"
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/sign"
+)
+
+var errorPreSig = errors.New("adaptor: invalid pre-signature")
+var errorMismatch = errors.New("adaptor: signature does not complete the pre-signature")
+
+// PreSignature is a Schnorr pre-signature, whose commitment R is the one of
+// the final signature and whose response S is missing the hidden secret t.
+type PreSignature struct {
+	R abstract.Point
+	S abstract.Scalar
+}
+
+// PreSign creates a pre-signature of msg with the private key, bound to the
+// point T. It picks a nonce k and computes R = k*G + T and S = k + c*x,
+// where c = H(R || X || msg) is the challenge of the final signature.
+func PreSign(suite abstract.Suite, private abstract.Scalar, T abstract.Point, msg []byte) (*PreSignature, error) {
+	k := suite.Scalar().Pick(random.Stream)
+	R := suite.Point().Mul(nil, k)
+	R.Add(R, T)
+	public := suite.Point().Mul(nil, private)
+	c, err := hash(suite, public, R, msg)
+	if err != nil {
+		return nil, err
+	}
+	s := suite.Scalar().Mul(private, c)
+	s.Add(s, k)
+	return &PreSignature{R, s}, nil
+}
+
+// VerifyPreSig checks that the pre-signature of msg by the public key is
+// bound to T, i.e., that S*G == R - T + c*X. It returns nil iff the
+// pre-signature is valid, in which case adapting it with the discrete
+// logarithm of T yields a valid signature.
+func VerifyPreSig(suite abstract.Suite, public, T abstract.Point, msg []byte, pre *PreSignature) error {
+	c, err := hash(suite, public, pre.R, msg)
+	if err != nil {
+		return err
+	}
+	right := suite.Point().Mul(public, c)
+	right.Add(right, pre.R)
+	right.Sub(right, T)
+	if !suite.Point().Mul(nil, pre.S).Equal(right) {
+		return errorPreSig
+	}
+	return nil
+}
+
+// Adapt completes the pre-signature with the discrete logarithm t of its
+// point T, and returns the signature R || S+t in the format of sign.Schnorr.
+func Adapt(suite abstract.Suite, pre *PreSignature, t abstract.Scalar) ([]byte, error) {
+	s := suite.Scalar().Add(pre.S, t)
+	return (&sign.SchnorrSig{R: pre.R, S: s}).MarshalBinary()
+}
+
+// ExtractSecret recovers the discrete logarithm t of the point of the
+// pre-signature from the signature it was adapted into.
+func ExtractSecret(suite abstract.Suite, pre *PreSignature, sig []byte) (abstract.Scalar, error) {
+	sc, err := sign.ParseSchnorr(suite, sig)
+	if err != nil {
+		return nil, err
+	}
+	if !sc.R.Equal(pre.R) {
+		return nil, errorMismatch
+	}
+	return suite.Scalar().Sub(sc.S, pre.S), nil
+}
+
+// hash computes the Schnorr challenge exactly as sign.VerifySchnorr does.
+func hash(suite abstract.Suite, public, r abstract.Point, msg []byte) (abstract.Scalar, error) {
+	h := sha512.New()
+	if _, err := r.MarshalTo(h); err != nil {
+		return nil, err
+	}
+	if _, err := public.MarshalTo(h); err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(msg); err != nil {
+		return nil, err
+	}
+	return suite.Scalar().SetBytes(h.Sum(nil)), nil
+}
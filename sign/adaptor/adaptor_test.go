@@ -0,0 +1,62 @@
+package adaptor
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/ed25519"
+	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/sign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptor(t *testing.T) {
+	msg := []byte("Hello Adaptor Schnorr")
+	suite := ed25519.NewAES128SHA256Ed25519(false)
+	kp := config.NewKeyPair(suite)
+	secret := suite.Scalar().Pick(random.Stream)
+	T := suite.Point().Mul(nil, secret)
+
+	pre, err := PreSign(suite, kp.Secret, T, msg)
+	require.Nil(t, err)
+	require.Nil(t, VerifyPreSig(suite, kp.Public, T, msg, pre))
+
+	// The pre-signature is not a signature
+	presig, err := (&sign.SchnorrSig{R: pre.R, S: pre.S}).MarshalBinary()
+	require.Nil(t, err)
+	assert.Error(t, sign.VerifySchnorr(suite, kp.Public, msg, presig))
+
+	sig, err := Adapt(suite, pre, secret)
+	require.Nil(t, err)
+	assert.Nil(t, sign.VerifySchnorr(suite, kp.Public, msg, sig))
+
+	extracted, err := ExtractSecret(suite, pre, sig)
+	require.Nil(t, err)
+	assert.True(t, extracted.Equal(secret))
+}
+
+func TestAdaptorInvalid(t *testing.T) {
+	msg := []byte("Hello Adaptor Schnorr")
+	suite := ed25519.NewAES128SHA256Ed25519(false)
+	kp := config.NewKeyPair(suite)
+	T := suite.Point().Mul(nil, suite.Scalar().Pick(random.Stream))
+	pre, err := PreSign(suite, kp.Secret, T, msg)
+	require.Nil(t, err)
+
+	other := suite.Point().Mul(nil, suite.Scalar().Pick(random.Stream))
+	assert.Equal(t, VerifyPreSig(suite, kp.Public, other, msg, pre), errorPreSig)
+	assert.Equal(t, VerifyPreSig(suite, kp.Public, T, []byte("other"), pre), errorPreSig)
+	assert.Equal(t, VerifyPreSig(suite, other, T, msg, pre), errorPreSig)
+
+	// A wrong secret gives an invalid signature
+	sig, err := Adapt(suite, pre, suite.Scalar().Pick(random.Stream))
+	require.Nil(t, err)
+	assert.Error(t, sign.VerifySchnorr(suite, kp.Public, msg, sig))
+
+	// Unrelated signatures reveal nothing
+	unrelated, err := sign.Schnorr(suite, kp.Secret, msg)
+	require.Nil(t, err)
+	_, err = ExtractSecret(suite, pre, unrelated)
+	assert.Equal(t, err, errorMismatch)
+}
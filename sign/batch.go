@@ -0,0 +1,207 @@
+package sign
+
+import (
+	"crypto/cipher"
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+// SchnorrItem is a Schnorr signature on a message under a public key, as an
+// element of a batch to verify.
+type SchnorrItem struct {
+	Public abstract.Point
+	Msg    []byte
+	Sig    []byte
+}
+
+// BatchVerifier verifies batches of Schnorr signatures at once, by checking a
+// random linear combination of their verification equations: a batch of n
+// valid signatures costs a single multi-scalar check instead of n
+// verifications, while a batch containing an invalid signature fails except
+// with negligible probability.
+//
+// The verifier checks the Cofactorless equation by default, see SetMode. In
+// either mode, it accepts a batch iff every signature of the batch verifies
+// individually with the same mode, except with negligible probability.
+type BatchVerifier struct {
+	suite abstract.Suite
+	tag   []byte
+	rand  cipher.Stream
+	mode  VerifyMode
+}
+
+// NewBatchVerifier returns a verifier for signatures created by Schnorr,
+// drawing the coefficients of the linear combinations from rand.
+func NewBatchVerifier(suite abstract.Suite, rand cipher.Stream) *BatchVerifier {
+	return &BatchVerifier{suite, nil, rand, Cofactorless}
+}
+
+// NewBatchVerifierWithDomain returns a verifier for signatures created by
+// SignWithDomain for the given domain, which must not be empty.
+func NewBatchVerifierWithDomain(suite abstract.Suite, domain string,
+	rand cipher.Stream) (*BatchVerifier, error) {
+	if domain == "" {
+		return nil, ErrEmptyDomain
+	}
+	return &BatchVerifier{suite, contextTag(domain), rand, Cofactorless}, nil
+}
+
+// NewBatchVerifierWithContext returns a verifier for signatures created by
+// SchnorrWithContext for the given context.
+//
+// Deprecated: use NewBatchVerifierWithDomain.
+func NewBatchVerifierWithContext(suite abstract.Suite, context string,
+	rand cipher.Stream) *BatchVerifier {
+	return &BatchVerifier{suite, contextTag(context), rand, Cofactorless}
+}
+
+// SetMode selects the verification equation of the verifier, and returns
+// the verifier.
+//
+// In Cofactored mode, the linear combination is multiplied by the cofactor.
+// In Cofactorless mode, a random linear combination would accept or reject
+// signatures with small-order components at random, so the signatures whose
+// commitment or public key is not in the prime-order subgroup are verified
+// individually instead. The subgroup checks cost about as much as the batch
+// itself in groups with a cofactor, so prefer Cofactored mode where peers
+// allow it.
+func (bv *BatchVerifier) SetMode(mode VerifyMode) *BatchVerifier {
+	bv.mode = mode
+	return bv
+}
+
+// A decoded batch item.
+type batchItem struct {
+	public abstract.Point
+	R      abstract.Point
+	s, h   abstract.Scalar
+}
+
+// Verify returns nil iff all the signatures of the batch are valid.
+func (bv *BatchVerifier) Verify(sigs []SchnorrItem) error {
+	items, invalid := bv.decode(sigs)
+	if len(invalid) > 0 || !bv.check(items) {
+		return errors.New("schnorr: invalid signature in batch")
+	}
+	return nil
+}
+
+// FindInvalid returns the indices, in increasing order, of the invalid
+// signatures of the batch. It bisects the batch, verifying both halves of
+// every failing part, so that k invalid signatures among n are found with
+// O(k log n) batch verifications instead of n individual ones. Single
+// signatures are verified individually.
+func (bv *BatchVerifier) FindInvalid(sigs []SchnorrItem) []int {
+	items, invalid := bv.decode(sigs)
+	idx := make([]int, 0, len(sigs))
+	for i, item := range items {
+		if item != nil {
+			idx = append(idx, i)
+		}
+	}
+	bad := bv.bisect(sigs, items, idx)
+
+	// Merge both sorted lists of invalid indices
+	res := make([]int, 0, len(invalid)+len(bad))
+	for len(invalid) > 0 || len(bad) > 0 {
+		if len(bad) == 0 || len(invalid) > 0 && invalid[0] < bad[0] {
+			res, invalid = append(res, invalid[0]), invalid[1:]
+		} else {
+			res, bad = append(res, bad[0]), bad[1:]
+		}
+	}
+	return res
+}
+
+// bisect returns the indices of the invalid signatures among the given ones.
+func (bv *BatchVerifier) bisect(sigs []SchnorrItem, items []*batchItem,
+	idx []int) []int {
+
+	switch len(idx) {
+	case 0:
+		return nil
+	case 1:
+		s := sigs[idx[0]]
+		if verifySchnorr(bv.suite, s.Public, bv.tag, s.Msg, s.Sig, bv.mode) != nil {
+			return idx
+		}
+		return nil
+	}
+	part := make([]*batchItem, len(idx))
+	for k, i := range idx {
+		part[k] = items[i]
+	}
+	if bv.check(part) {
+		return nil
+	}
+	half := len(idx) / 2
+	return append(bv.bisect(sigs, items, idx[:half]),
+		bv.bisect(sigs, items, idx[half:])...)
+}
+
+// decode decodes the signatures of a batch, returning nil items for, and the
+// indices of, the malformed ones. In Cofactorless mode, the signatures that
+// cannot be batched are verified individually: they get nil items too, and
+// their indices are returned if they are invalid.
+func (bv *BatchVerifier) decode(sigs []SchnorrItem) ([]*batchItem, []int) {
+	items := make([]*batchItem, len(sigs))
+	var invalid []int
+	strict := bv.mode == Cofactorless && abstract.Cofactor(bv.suite).Int64() != 1
+	publics := make(map[[abstract.PointKeySize]byte]bool)
+	for i, sig := range sigs {
+		R, s, h, err := decodeSchnorr(bv.suite, sig.Public, bv.tag, sig.Msg, sig.Sig)
+		if err != nil {
+			invalid = append(invalid, i)
+			continue
+		}
+		if strict && !(inSubgroup(publics, sig.Public) &&
+			abstract.IsInCorrectSubgroup(R)) {
+			if verifySchnorr(bv.suite, sig.Public, bv.tag, sig.Msg, sig.Sig,
+				bv.mode) != nil {
+				invalid = append(invalid, i)
+			}
+			continue
+		}
+		items[i] = &batchItem{sig.Public, R, s, h}
+	}
+	return items, invalid
+}
+
+// inSubgroup returns whether the public key is in the prime-order subgroup,
+// caching the results in known, as public keys often sign several messages
+// of a batch.
+func inSubgroup(known map[[abstract.PointKeySize]byte]bool, public abstract.Point) bool {
+	key := abstract.PointKey(public)
+	ok, found := known[key]
+	if !found {
+		ok = abstract.IsInCorrectSubgroup(public)
+		known[key] = ok
+	}
+	return ok
+}
+
+// check verifies sum(z_i*s_i)*G == sum(z_i*R_i + z_i*h_i*A_i) for random z_i,
+// both sides multiplied by the cofactor in Cofactored mode. Nil items are
+// skipped.
+func (bv *BatchVerifier) check(items []*batchItem) bool {
+	suite := bv.suite
+	s := suite.Scalar().Zero()
+	right := suite.Point().Null()
+	z := suite.Scalar()
+	zh := suite.Scalar()
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		z.Pick(bv.rand)
+		s.Add(s, zh.Mul(z, item.s))
+		right.Add(right, suite.Point().Mul(item.R, z))
+		right.Add(right, suite.Point().Mul(item.public, zh.Mul(z, item.h)))
+	}
+	left := suite.Point().Mul(nil, s)
+	if bv.mode == Cofactored {
+		return mulCofactor(suite, left.Sub(left, right)).Equal(suite.Point().Null())
+	}
+	return left.Equal(right)
+}
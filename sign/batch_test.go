@@ -0,0 +1,78 @@
+package sign
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/ed25519"
+	"github.com/dedis/crypto/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func batch(t *testing.T, n int) []SchnorrItem {
+	suite := ed25519.NewAES128SHA256Ed25519(false)
+	sigs := make([]SchnorrItem, n)
+	for i := range sigs {
+		kp := config.NewKeyPair(suite)
+		msg := []byte{byte(i)}
+		s, err := Schnorr(suite, kp.Secret, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs[i] = SchnorrItem{kp.Public, msg, s}
+	}
+	return sigs
+}
+
+func TestBatchVerifier(t *testing.T) {
+	suite := ed25519.NewAES128SHA256Ed25519(false)
+	bv := NewBatchVerifier(suite, random.Stream)
+	sigs := batch(t, 20)
+	assert.Nil(t, bv.Verify(sigs))
+	assert.Equal(t, 0, len(bv.FindInvalid(sigs)))
+
+	// Wrong message, malformed signature and wrong public key
+	sigs[3].Msg = []byte("other")
+	sigs[11].Sig = sigs[11].Sig[1:]
+	sigs[12].Public = sigs[13].Public
+	assert.Error(t, bv.Verify(sigs))
+	assert.Equal(t, []int{3, 11, 12}, bv.FindInvalid(sigs))
+
+	// Signatures without a domain do not verify in a domain
+	bv, err := NewBatchVerifierWithDomain(suite, "test", random.Stream)
+	assert.Nil(t, err)
+	assert.Error(t, bv.Verify(sigs[:2]))
+	assert.Equal(t, []int{0, 1}, bv.FindInvalid(sigs[:2]))
+
+	// and signatures in the domain do
+	kp := config.NewKeyPair(suite)
+	s, err := SignWithDomain(suite, kp.Secret, "test", sigs[0].Msg)
+	assert.Nil(t, err)
+	assert.Nil(t, bv.Verify([]SchnorrItem{{kp.Public, sigs[0].Msg, s}}))
+
+	_, err = NewBatchVerifierWithDomain(suite, "", random.Stream)
+	assert.Equal(t, ErrEmptyDomain, err)
+}
+
+func TestBatchVerifierMode(t *testing.T) {
+	suite := ed25519.NewAES128SHA256Ed25519(false)
+	sigs := batch(t, 10)
+	kp := config.NewKeyPair(suite)
+	msg := []byte("torsion")
+	sigs[4] = SchnorrItem{kp.Public, msg, torsionSchnorr(t, suite, kp, msg)}
+
+	// The batch agrees with the individual verifications in both modes,
+	// whatever the random coefficients.
+	strict := NewBatchVerifier(suite, random.Stream)
+	permissive := NewBatchVerifier(suite, random.Stream).SetMode(Cofactored)
+	for k := 0; k < 8; k++ {
+		assert.Error(t, strict.Verify(sigs))
+		assert.Equal(t, []int{4}, strict.FindInvalid(sigs))
+		assert.Nil(t, permissive.Verify(sigs))
+		assert.Equal(t, 0, len(permissive.FindInvalid(sigs)))
+	}
+	sigs[7].Msg = []byte("other")
+	assert.Error(t, permissive.Verify(sigs))
+	assert.Equal(t, []int{7}, permissive.FindInvalid(sigs))
+	assert.Equal(t, []int{4, 7}, strict.FindInvalid(sigs))
+}
@@ -0,0 +1,150 @@
+// Package blind implements a three-move blind Schnorr signature protocol. The
+// issuer signs a message chosen by the user without learning it, and the
+// resulting signature can be verified with sign.VerifySchnorr.
+//
+// The protocol runs as follows:
+//  1. The issuer creates a fresh session with NewIssuer and sends the
+//     commitment R returned by Commit to the user.
+//  2. The user creates a User for its message and blinds the commitment with
+//     Blind, obtaining a challenge c that is sent back to the issuer.
+//  3. The issuer answers with Respond(c), and the user turns the response
+//     into a regular Schnorr signature with Unblind.
+//
+// Each Issuer and User object must only be used for a single signature. Note
+// that the security of blind Schnorr signatures degrades when an issuer runs
+// many sessions concurrently (ROS attack), so issuers should limit the number
+// of open sessions.
+package blind
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/random"
+)
+
+var errorSessionUsed = errors.New("blind: session already used")
+var errorNoCommit = errors.New("blind: no commitment")
+var errorInvalidResponse = errors.New("blind: invalid issuer response")
+
+// Issuer holds the signer side state of a single blind signing session.
+type Issuer struct {
+	suite   abstract.Suite
+	private abstract.Scalar
+	k       abstract.Scalar
+	done    bool
+}
+
+// NewIssuer returns a new signing session for the given private key.
+func NewIssuer(suite abstract.Suite, private abstract.Scalar) *Issuer {
+	return &Issuer{suite: suite, private: private}
+}
+
+// Commit picks the session nonce k and returns the commitment R = kG to be
+// sent to the user.
+func (i *Issuer) Commit() (abstract.Point, error) {
+	if i.k != nil || i.done {
+		return nil, errorSessionUsed
+	}
+	i.k = i.suite.Scalar().Pick(random.Stream)
+	return i.suite.Point().Mul(nil, i.k), nil
+}
+
+// Respond computes the response s = k + c*x to the blinded challenge c. The
+// nonce is erased afterwards so that a session can never answer twice.
+func (i *Issuer) Respond(c abstract.Scalar) (abstract.Scalar, error) {
+	if i.done {
+		return nil, errorSessionUsed
+	}
+	if i.k == nil {
+		return nil, errorNoCommit
+	}
+	s := i.suite.Scalar().Mul(i.private, c)
+	s.Add(s, i.k)
+	i.k.Zero()
+	i.k = nil
+	i.done = true
+	return s, nil
+}
+
+// User holds the receiver side state of a single blind signing session.
+type User struct {
+	suite  abstract.Suite
+	public abstract.Point
+	msg    []byte
+	alpha  abstract.Scalar // blinding factor for the nonce
+	beta   abstract.Scalar // blinding factor for the challenge
+	c      abstract.Scalar // blinded challenge sent to the issuer
+	r      abstract.Point  // unblinded commitment R' of the final signature
+}
+
+// NewUser returns a new session for obtaining a signature on msg under the
+// issuer public key.
+func NewUser(suite abstract.Suite, public abstract.Point, msg []byte) *User {
+	return &User{suite: suite, public: public, msg: msg}
+}
+
+// Blind takes the issuer commitment R, computes the blinded commitment
+// R' = R + alpha*G + beta*X and returns the blinded challenge
+// c = H(R' || X || msg) + beta to be sent to the issuer.
+func (u *User) Blind(R abstract.Point) (abstract.Scalar, error) {
+	if u.c != nil {
+		return nil, errorSessionUsed
+	}
+	u.alpha = u.suite.Scalar().Pick(random.Stream)
+	u.beta = u.suite.Scalar().Pick(random.Stream)
+
+	u.r = u.suite.Point().Mul(nil, u.alpha)
+	u.r.Add(u.r, R)
+	u.r.Add(u.r, u.suite.Point().Mul(u.public, u.beta))
+
+	h, err := hash(u.suite, u.public, u.r, u.msg)
+	if err != nil {
+		return nil, err
+	}
+	u.c = u.suite.Scalar().Add(h, u.beta)
+	return u.c, nil
+}
+
+// Unblind checks the issuer response s and returns the Schnorr signature
+// R' || s' with s' = s + alpha, verifiable with sign.VerifySchnorr.
+func (u *User) Unblind(s abstract.Scalar) ([]byte, error) {
+	if u.c == nil {
+		return nil, errorNoCommit
+	}
+
+	// sG must equal R + c*X = R' - alpha*G - beta*X + c*X
+	sp := u.suite.Scalar().Add(s, u.alpha)
+	h := u.suite.Scalar().Sub(u.c, u.beta)
+	left := u.suite.Point().Mul(nil, sp)
+	right := u.suite.Point().Add(u.r, u.suite.Point().Mul(u.public, h))
+	if !left.Equal(right) {
+		return nil, errorInvalidResponse
+	}
+
+	var b bytes.Buffer
+	if _, err := u.r.MarshalTo(&b); err != nil {
+		return nil, err
+	}
+	if _, err := sp.MarshalTo(&b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// hash computes the Schnorr challenge exactly as sign.VerifySchnorr does.
+func hash(suite abstract.Suite, public, r abstract.Point, msg []byte) (abstract.Scalar, error) {
+	h := sha512.New()
+	if _, err := r.MarshalTo(h); err != nil {
+		return nil, err
+	}
+	if _, err := public.MarshalTo(h); err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(msg); err != nil {
+		return nil, err
+	}
+	return suite.Scalar().SetBytes(h.Sum(nil)), nil
+}
@@ -0,0 +1,62 @@
+package blind
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/ed25519"
+	"github.com/dedis/crypto/sign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlindSchnorr(t *testing.T) {
+	msg := []byte("Hello Blind Schnorr")
+	suite := ed25519.NewAES128SHA256Ed25519(false)
+	kp := config.NewKeyPair(suite)
+
+	issuer := NewIssuer(suite, kp.Secret)
+	user := NewUser(suite, kp.Public, msg)
+
+	R, err := issuer.Commit()
+	require.Nil(t, err)
+	c, err := user.Blind(R)
+	require.Nil(t, err)
+	s, err := issuer.Respond(c)
+	require.Nil(t, err)
+	sig, err := user.Unblind(s)
+	require.Nil(t, err)
+
+	assert.Nil(t, sign.VerifySchnorr(suite, kp.Public, msg, sig))
+	assert.Error(t, sign.VerifySchnorr(suite, kp.Public, []byte("other"), sig))
+
+	// sessions are single use
+	_, err = issuer.Commit()
+	assert.Error(t, err)
+	_, err = issuer.Respond(c)
+	assert.Error(t, err)
+	_, err = user.Blind(R)
+	assert.Error(t, err)
+}
+
+func TestBlindSchnorrBadResponse(t *testing.T) {
+	msg := []byte("Hello Blind Schnorr")
+	suite := ed25519.NewAES128SHA256Ed25519(false)
+	kp := config.NewKeyPair(suite)
+	wrong := config.NewKeyPair(suite)
+
+	issuer := NewIssuer(suite, wrong.Secret)
+	user := NewUser(suite, kp.Public, msg)
+
+	_, err := issuer.Respond(suite.Scalar().One())
+	assert.Error(t, err)
+
+	R, err := issuer.Commit()
+	require.Nil(t, err)
+	c, err := user.Blind(R)
+	require.Nil(t, err)
+	s, err := issuer.Respond(c)
+	require.Nil(t, err)
+	_, err = user.Unblind(s)
+	assert.Error(t, err)
+}
@@ -0,0 +1,33 @@
+package sign
+
+import (
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+// ErrEmptyDomain is returned when signing or verifying with an empty domain.
+var ErrEmptyDomain = errors.New("sign: empty domain")
+
+// SignWithDomain creates a Schnorr signature of msg in the given domain, which
+// names the protocol and the kind of message, such as "poly.Deal.Approve".
+// Protocols should give each kind of signed message its own domain rather than
+// prefixing the messages with a constant: a signature is then only valid in its
+// domain, whatever the messages of the other domains look like. The domain
+// must not be empty. The signature is the one of SchnorrWithContext, with the
+// domain as context, and can be verified with VerifyWithDomain.
+func SignWithDomain(suite abstract.Suite, secret abstract.Scalar, domain string, msg []byte) ([]byte, error) {
+	if domain == "" {
+		return nil, ErrEmptyDomain
+	}
+	return schnorr(suite, secret, contextTag(domain), msg)
+}
+
+// VerifyWithDomain verifies a signature created by SignWithDomain for the same
+// domain. It returns nil iff the given signature is valid.
+func VerifyWithDomain(suite abstract.Suite, public abstract.Point, domain string, msg, sig []byte) error {
+	if domain == "" {
+		return ErrEmptyDomain
+	}
+	return verifySchnorr(suite, public, contextTag(domain), msg, sig, Cofactorless)
+}
@@ -0,0 +1,67 @@
+package sign
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/config"
+	"github.com/dedis/crypto/ed25519"
+	"github.com/dedis/crypto/nist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignWithDomain(t *testing.T) {
+	msg := []byte("Hello Domain")
+	for _, suite := range []abstract.Suite{
+		ed25519.NewAES128SHA256Ed25519(false),
+		nist.NewAES128SHA256P256(),
+	} {
+		kp := config.NewKeyPair(suite)
+		s, err := SignWithDomain(suite, kp.Secret, "test.Approve", msg)
+		if err != nil {
+			t.Fatalf("Couldn't sign msg: %s: %v", msg, err)
+		}
+		assert.Nil(t, VerifyWithDomain(suite, kp.Public, "test.Approve", msg, s))
+
+		// wrong domain, message or key
+		assert.Error(t, VerifyWithDomain(suite, kp.Public, "test.Blame", msg, s))
+		assert.Error(t, VerifyWithDomain(suite, kp.Public, "test.Approve", []byte("other"), s))
+		wrKp := config.NewKeyPair(suite)
+		assert.Error(t, VerifyWithDomain(suite, wrKp.Public, "test.Approve", msg, s))
+
+		// domain-bound and plain signatures are not interchangeable
+		assert.Error(t, VerifySchnorr(suite, kp.Public, msg, s))
+		plain, err := Schnorr(suite, kp.Secret, msg)
+		assert.Nil(t, err)
+		assert.Error(t, VerifyWithDomain(suite, kp.Public, "test.Approve", msg, plain))
+	}
+}
+
+func TestSignWithDomainSeparation(t *testing.T) {
+	suite := ed25519.NewAES128SHA256Ed25519(false)
+	kp := config.NewKeyPair(suite)
+
+	// Unlike constant prefixes, domains cannot be shifted into the message.
+	s, err := SignWithDomain(suite, kp.Secret, "test", []byte(".Approve"))
+	assert.Nil(t, err)
+	assert.Error(t, VerifyWithDomain(suite, kp.Public, "test.Approve", nil, s))
+	assert.Error(t, VerifyWithDomain(suite, kp.Public, "test.", []byte("Approve"), s))
+
+	// The same message gets distinct signatures in distinct domains.
+	s1, err := SignWithDomain(suite, kp.Secret, "test.Approve", []byte("msg"))
+	assert.Nil(t, err)
+	s2, err := SignWithDomain(suite, kp.Secret, "test.Blame", []byte("msg"))
+	assert.Nil(t, err)
+	assert.NotEqual(t, s1, s2)
+}
+
+func TestSignWithEmptyDomain(t *testing.T) {
+	suite := ed25519.NewAES128SHA256Ed25519(false)
+	kp := config.NewKeyPair(suite)
+	_, err := SignWithDomain(suite, kp.Secret, "", []byte("msg"))
+	assert.Equal(t, ErrEmptyDomain, err)
+
+	plain, err := Schnorr(suite, kp.Secret, []byte("msg"))
+	assert.Nil(t, err)
+	assert.Equal(t, ErrEmptyDomain, VerifyWithDomain(suite, kp.Public, "", []byte("msg"), plain))
+}
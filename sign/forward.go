@@ -0,0 +1,282 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/random"
+)
+
+// ForwardSecureKey is the private key of a key-evolving, forward-secure
+// signature scheme: its lifetime is divided into 2^depth periods, and
+// UpdateKey irreversibly evolves the key into a later period. A key compromised
+// in some period cannot be used to forge signatures for any earlier period,
+// so that signatures produced by a long-term key, such as the certifications
+// of an insurer, remain valid even after the key leaks.
+//
+// The scheme is the tree-based construction of Bellare and Miner, "A
+// Forward-Secure Digital Signature Scheme", CRYPTO 1999, instantiated with
+// Schnorr signatures. Each node of a binary tree of the given depth has a
+// Schnorr key pair derived from a seed, and the seeds of the two children of a
+// node are derived from its own seed. Every node key certifies the public keys
+// of its children, and the leaves sign messages, one leaf per period. The
+// public key is the one of the root. The private key of a period holds the leaf
+// key and the certification path of that period, and the seeds of the right
+// siblings along that path, from which all later periods are derived; seeds of
+// earlier periods are erased. A signature carries its period, the
+// certification path and the leaf signature, for a size linear in the depth.
+type ForwardSecureKey struct {
+	suite  abstract.Suite
+	depth  int
+	period int
+	public abstract.Point // public key of the root
+
+	leaf abstract.Scalar // private key of the leaf of the current period
+	path []fsNode        // certified nodes from the root's children to the leaf
+
+	// future[k] is the right sibling of path[k] if path[k] is a left child,
+	// and the zero fsNode otherwise.
+	future []fsNode
+}
+
+// A certified node of the tree, with the seed of its subtree if it is a
+// right sibling kept for later periods.
+type fsNode struct {
+	public abstract.Point
+	cert   []byte // Schnorr signature of the parent, see fsCertMessage
+	seed   []byte
+}
+
+const fsSeedSize = 32
+
+var fsCertTag = contextTag("sign.ForwardSecure certificate")
+var fsSignTag = contextTag("sign.ForwardSecure")
+
+var errorFSPeriod = errors.New("forward-secure: invalid period")
+
+// NewForwardSecureKey creates a forward-secure private key valid for 2^depth
+// periods, starting at period 0, from a seed drawn from the given stream.
+func NewForwardSecureKey(suite abstract.Suite, depth int,
+	rand cipher.Stream) (*ForwardSecureKey, error) {
+
+	if depth < 1 || depth > 31 {
+		return nil, fmt.Errorf("forward-secure: invalid depth %d", depth)
+	}
+	seed := random.Bytes(fsSeedSize, rand)
+	secret, _, _ := fsDerive(suite, seed)
+	k := &ForwardSecureKey{
+		suite:  suite,
+		depth:  depth,
+		public: suite.Point().Mul(nil, secret),
+		path:   make([]fsNode, depth),
+		future: make([]fsNode, depth),
+	}
+	secret.Zero()
+	if err := k.descend(0, seed, 0); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Public returns the public key, which is the same for all periods.
+func (k *ForwardSecureKey) Public() abstract.Point {
+	return k.public
+}
+
+// Period returns the current period of the key.
+func (k *ForwardSecureKey) Period() int {
+	return k.period
+}
+
+// Periods returns the number of periods of the key.
+func (k *ForwardSecureKey) Periods() int {
+	return 1 << uint(k.depth)
+}
+
+// UpdateKey evolves the key into the given period, which must not be earlier
+// than the current one, and erases all the secrets allowing to sign for
+// earlier periods.
+func (k *ForwardSecureKey) UpdateKey(period int) error {
+	if period < k.period || period >= k.Periods() {
+		return errorFSPeriod
+	}
+	if period == k.period {
+		return nil
+	}
+
+	// Find the first level where the paths of both periods differ: the
+	// current period goes left and the new one right.
+	level := 0
+	for fsBit(k.period, k.depth, level) == fsBit(period, k.depth, level) {
+		level++
+	}
+	next := k.future[level]
+	seed := append([]byte{}, next.seed...)
+	next.seed = nil
+	for l := level; l < k.depth; l++ {
+		k.erase(&k.future[l])
+	}
+	k.leaf.Zero()
+	k.path[level] = next
+	k.period = period
+	return k.descend(level+1, seed, period)
+}
+
+// descend derives the path of the given period from the seed of its node at
+// the given level, certifying the children of each node along the way. The
+// seed is erased once used.
+func (k *ForwardSecureKey) descend(level int, seed []byte, period int) error {
+	index := period >> uint(k.depth-level)
+	for ; level < k.depth; level++ {
+		secret, left, right := fsDerive(k.suite, seed)
+		fsErase(seed)
+
+		// Certify both children
+		var children [2]fsNode
+		for b, s := range [][]byte{left, right} {
+			cs, _, _ := fsDerive(k.suite, s)
+			pub := k.suite.Point().Mul(nil, cs)
+			cs.Zero()
+			msg, err := fsCertMessage(level+1, 2*index+b, pub)
+			if err != nil {
+				return err
+			}
+			cert, err := schnorr(k.suite, secret, fsCertTag, msg)
+			if err != nil {
+				return err
+			}
+			children[b] = fsNode{pub, cert, s}
+		}
+		secret.Zero()
+
+		index = 2*index + fsBit(period, k.depth, level)
+		if index&1 == 0 {
+			k.future[level] = children[1]
+		} else {
+			fsErase(children[0].seed)
+		}
+		k.path[level] = children[index&1]
+		seed = k.path[level].seed
+		k.path[level].seed = nil
+	}
+	k.leaf, _, _ = fsDerive(k.suite, seed)
+	fsErase(seed)
+	return nil
+}
+
+// erase erases the seed of a node and clears it.
+func (k *ForwardSecureKey) erase(n *fsNode) {
+	fsErase(n.seed)
+	*n = fsNode{}
+}
+
+// Sign signs a message under the current period of the key.
+func (k *ForwardSecureKey) Sign(msg []byte) ([]byte, error) {
+	var b bytes.Buffer
+	var period [4]byte
+	binary.BigEndian.PutUint32(period[:], uint32(k.period))
+	b.Write(period[:])
+	for _, n := range k.path {
+		if _, err := n.public.MarshalTo(&b); err != nil {
+			return nil, err
+		}
+		b.Write(n.cert)
+	}
+	sig, err := schnorr(k.suite, k.leaf, fsSignTag, fsSignMessage(k.period, msg))
+	if err != nil {
+		return nil, err
+	}
+	b.Write(sig)
+	return b.Bytes(), nil
+}
+
+// VerifyForwardSecure verifies a signature created by a ForwardSecureKey of
+// the given depth. It returns the period under which the message was signed,
+// and a nil error iff the signature is valid. Callers must check that the
+// period is the expected one, e.g., that it is not later than the period
+// in which a key compromise was reported.
+func VerifyForwardSecure(suite abstract.Suite, public abstract.Point,
+	depth int, msg, sig []byte) (int, error) {
+
+	pointSize := suite.Point().MarshalSize()
+	sigSize := pointSize + suite.Scalar().MarshalSize()
+	if depth < 1 || depth > 31 || len(sig) != 4+depth*(pointSize+sigSize)+sigSize {
+		return 0, errors.New("forward-secure: signature of invalid length")
+	}
+	period := int(binary.BigEndian.Uint32(sig))
+	if period >= 1<<uint(depth) {
+		return 0, errorFSPeriod
+	}
+	sig = sig[4:]
+
+	// Follow the certification path from the root to the leaf
+	parent := public
+	for level := 1; level <= depth; level++ {
+		pub := suite.Point()
+		if err := pub.UnmarshalBinary(sig[:pointSize]); err != nil {
+			return 0, err
+		}
+		certMsg, err := fsCertMessage(level, period>>uint(depth-level), pub)
+		if err != nil {
+			return 0, err
+		}
+		cert := sig[pointSize : pointSize+sigSize]
+		if err := verifySchnorr(suite, parent, fsCertTag, certMsg, cert, Cofactorless); err != nil {
+			return 0, err
+		}
+		parent = pub
+		sig = sig[pointSize+sigSize:]
+	}
+	err := verifySchnorr(suite, parent, fsSignTag, fsSignMessage(period, msg), sig,
+		Cofactorless)
+	if err != nil {
+		return 0, err
+	}
+	return period, nil
+}
+
+// fsDerive derives the private key of a node and the seeds of its children
+// from the seed of the node.
+func fsDerive(suite abstract.Suite, seed []byte) (abstract.Scalar, []byte, []byte) {
+	c := suite.Cipher(seed)
+	left := random.Bytes(fsSeedSize, c)
+	right := random.Bytes(fsSeedSize, c)
+	return suite.Scalar().Pick(c), left, right
+}
+
+// fsBit returns the branch taken at the given level by the path of a period:
+// 0 for left and 1 for right.
+func fsBit(period, depth, level int) int {
+	return (period >> uint(depth-level-1)) & 1
+}
+
+// fsCertMessage returns the message certifying the public key of the node of
+// the given index at the given level.
+func fsCertMessage(level, index int, public abstract.Point) ([]byte, error) {
+	var b bytes.Buffer
+	var pos [8]byte
+	binary.BigEndian.PutUint32(pos[:4], uint32(level))
+	binary.BigEndian.PutUint32(pos[4:], uint32(index))
+	b.Write(pos[:])
+	if _, err := public.MarshalTo(&b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// fsSignMessage binds a message to the period it is signed in.
+func fsSignMessage(period int, msg []byte) []byte {
+	b := make([]byte, 4, 4+len(msg))
+	binary.BigEndian.PutUint32(b, uint32(period))
+	return append(b, msg...)
+}
+
+func fsErase(seed []byte) {
+	for i := range seed {
+		seed[i] = 0
+	}
+}
@@ -0,0 +1,52 @@
+package sign
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/ed25519"
+	"github.com/dedis/crypto/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardSecure(t *testing.T) {
+	suite := ed25519.NewAES128SHA256Ed25519(false)
+	depth := 4
+	key, err := NewForwardSecureKey(suite, depth, random.Stream)
+	require.Nil(t, err)
+	require.Equal(t, 16, key.Periods())
+	msg := []byte("Hello forward security")
+
+	// Sign in a few periods, possibly skipping some
+	sigs := make(map[int][]byte)
+	for _, p := range []int{0, 1, 2, 5, 6, 7, 8, 15} {
+		require.Nil(t, key.UpdateKey(p))
+		require.Equal(t, p, key.Period())
+		sig, err := key.Sign(msg)
+		require.Nil(t, err)
+		sigs[p] = sig
+	}
+	for p, sig := range sigs {
+		period, err := VerifyForwardSecure(suite, key.Public(), depth, msg, sig)
+		require.Nil(t, err)
+		assert.Equal(t, p, period)
+	}
+
+	// The key cannot go back in time nor past its last period
+	assert.Error(t, key.UpdateKey(14))
+	assert.Error(t, key.UpdateKey(16))
+
+	// Wrong message, public key, depth and tampered period
+	sig := sigs[5]
+	_, err = VerifyForwardSecure(suite, key.Public(), depth, []byte("other"), sig)
+	assert.Error(t, err)
+	other, _ := NewForwardSecureKey(suite, depth, random.Stream)
+	_, err = VerifyForwardSecure(suite, other.Public(), depth, msg, sig)
+	assert.Error(t, err)
+	_, err = VerifyForwardSecure(suite, key.Public(), depth+1, msg, sig)
+	assert.Error(t, err)
+	tampered := append([]byte{}, sig...)
+	tampered[3] = 4
+	_, err = VerifyForwardSecure(suite, key.Public(), depth, msg, tampered)
+	assert.Error(t, err)
+}
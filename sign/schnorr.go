@@ -1,26 +1,46 @@
 package sign
 
 import (
-	"bytes"
 	"crypto/sha512"
 	"errors"
-	"fmt"
 
 	"github.com/dedis/crypto/abstract"
-	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/kdf"
 )
 
 // Schnorr creates a Schnorr signature from a msg and a private key. This
 // signature can be verified with VerifySchnorr. It's also a valid EdDSA
-// signature.
+// signature. The nonce is derived deterministically from the private key and
+// the message with kdf.Nonce, so that no randomness is needed to sign.
 func Schnorr(suite abstract.Suite, private abstract.Scalar, msg []byte) ([]byte, error) {
-	// create random secret k and public point commitment R
-	k := suite.Scalar().Pick(random.Stream)
+	return schnorr(suite, private, nil, msg)
+}
+
+// SchnorrWithContext creates a Schnorr signature bound to a context string,
+// such as an application or protocol name. The challenge is computed as a
+// tagged hash H(H(context) || H(context) || R || public || msg), in the style
+// of BIP340, so that a signature produced for one context never verifies for
+// another one nor with VerifySchnorr. It can be verified with
+// VerifySchnorrWithContext.
+//
+// Deprecated: use SignWithDomain, which produces the same signatures for
+// non-empty contexts and refuses the empty one.
+func SchnorrWithContext(suite abstract.Suite, private abstract.Scalar, context string, msg []byte) ([]byte, error) {
+	return schnorr(suite, private, contextTag(context), msg)
+}
+
+func schnorr(suite abstract.Suite, private abstract.Scalar, tag, msg []byte) ([]byte, error) {
+	// derive secret k from the private key, the tag and the message, and
+	// create public point commitment R
+	k, err := kdf.Nonce(suite, private, nonceInput(tag, msg), 0, "sign.Schnorr")
+	if err != nil {
+		return nil, err
+	}
 	R := suite.Point().Mul(nil, k)
 
 	// create hash(public || R || message)
 	public := suite.Point().Mul(nil, private)
-	h, err := hash(suite, public, R, msg)
+	h, err := taggedHash(suite, tag, public, R, msg)
 	if err != nil {
 		return nil, err
 	}
@@ -30,37 +50,53 @@ func Schnorr(suite abstract.Suite, private abstract.Scalar, msg []byte) ([]byte,
 	s := suite.Scalar().Add(k, xh)
 
 	// return R || s
-	var b bytes.Buffer
-	if _, err := R.MarshalTo(&b); err != nil {
-		return nil, err
-	}
-	if _, err := s.MarshalTo(&b); err != nil {
-		return nil, err
-	}
-	return b.Bytes(), nil
+	return (&SchnorrSig{R, s}).MarshalBinary()
 }
 
+// VerifyMode selects the equation checked to verify Schnorr signatures. Both
+// equations agree on honestly generated signatures, but in groups with a
+// cofactor, such as Ed25519, they disagree on signatures whose commitment or
+// public key has a small-order component, so that nodes verifying signatures
+// for consensus must all use the same mode.
+type VerifyMode int
+
+const (
+	// Cofactorless checks s*G == R + h*A. It is the strict mode, used by
+	// VerifySchnorr.
+	Cofactorless VerifyMode = iota
+
+	// Cofactored checks c*s*G == c*R + c*h*A for the cofactor c of the
+	// group, which ignores the small-order components of R and A. It is the
+	// permissive mode, and the one for which batch verification is cheapest.
+	Cofactored
+)
+
 // VerifySchnorr verifies a given Schnorr signature. It returns nil iff the
 // given signature is valid.  NOTE: this signature scheme is malleable because
 // the response's unmarshalling is done directly into a big.Int modulo (see
 // nist.Int).
 func VerifySchnorr(suite abstract.Suite, public abstract.Point, msg, sig []byte) error {
-	R := suite.Point()
-	s := suite.Scalar()
-	pointSize := R.MarshalSize()
-	scalarSize := s.MarshalSize()
-	sigSize := scalarSize + pointSize
-	if len(sig) != sigSize {
-		return fmt.Errorf("schnorr: signature of invalid length %d instead of %d", len(sig), sigSize)
-	}
-	if err := R.UnmarshalBinary(sig[:pointSize]); err != nil {
-		return err
-	}
-	if err := s.UnmarshalBinary(sig[pointSize:]); err != nil {
-		return err
-	}
-	// recompute hash(public || R || msg)
-	h, err := hash(suite, public, R, msg)
+	return verifySchnorr(suite, public, nil, msg, sig, Cofactorless)
+}
+
+// VerifySchnorrWithMode verifies a given Schnorr signature with the
+// verification equation selected by mode. Both modes are equivalent in groups
+// of prime order.
+func VerifySchnorrWithMode(suite abstract.Suite, public abstract.Point, msg, sig []byte, mode VerifyMode) error {
+	return verifySchnorr(suite, public, nil, msg, sig, mode)
+}
+
+// VerifySchnorrWithContext verifies a Schnorr signature created by
+// SchnorrWithContext for the same context. It returns nil iff the given
+// signature is valid.
+//
+// Deprecated: use VerifyWithDomain.
+func VerifySchnorrWithContext(suite abstract.Suite, public abstract.Point, context string, msg, sig []byte) error {
+	return verifySchnorr(suite, public, contextTag(context), msg, sig, Cofactorless)
+}
+
+func verifySchnorr(suite abstract.Suite, public abstract.Point, tag, msg, sig []byte, mode VerifyMode) error {
+	R, s, h, err := decodeSchnorr(suite, public, tag, msg, sig)
 	if err != nil {
 		return err
 	}
@@ -71,6 +107,11 @@ func VerifySchnorr(suite abstract.Suite, public abstract.Point, msg, sig []byte)
 	Ah := suite.Point().Mul(public, h)
 	RAs := suite.Point().Add(R, Ah)
 
+	if mode == Cofactored {
+		// compare c*(S - RAh) to the identity
+		S = mulCofactor(suite, S.Sub(S, RAs))
+		RAs.Null()
+	}
 	if !S.Equal(RAs) {
 		return errors.New("schnorr: invalid signature")
 	}
@@ -78,8 +119,53 @@ func VerifySchnorr(suite abstract.Suite, public abstract.Point, msg, sig []byte)
 	return nil
 }
 
-func hash(suite abstract.Suite, public, r abstract.Point, msg []byte) (abstract.Scalar, error) {
+// mulCofactor returns P multiplied by the cofactor of the group.
+func mulCofactor(suite abstract.Suite, P abstract.Point) abstract.Point {
+	c := suite.Scalar().SetInt64(abstract.Cofactor(suite).Int64())
+	return suite.Point().Mul(P, c)
+}
+
+// decodeSchnorr decodes the commitment R and response s of a signature, and
+// recomputes its challenge h.
+func decodeSchnorr(suite abstract.Suite, public abstract.Point, tag, msg, sig []byte) (
+	abstract.Point, abstract.Scalar, abstract.Scalar, error) {
+
+	sc, err := ParseSchnorr(suite, sig)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// recompute hash(public || R || msg)
+	h, err := taggedHash(suite, tag, public, sc.R, msg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return sc.R, sc.S, h, nil
+}
+
+// nonceInput encodes the tag and the message into the input of kdf.Nonce, as
+// len(tag) || tag || msg. Tags are either nil or hashes, so the encoding is
+// unambiguous, and two distinct challenges never share a nonce.
+func nonceInput(tag, msg []byte) []byte {
+	buf := make([]byte, 0, 1+len(tag)+len(msg))
+	buf = append(buf, byte(len(tag)))
+	buf = append(buf, tag...)
+	return append(buf, msg...)
+}
+
+// contextTag returns the hash of a context string used to prefix challenges.
+func contextTag(context string) []byte {
+	t := sha512.Sum512([]byte(context))
+	return t[:]
+}
+
+// taggedHash computes hash(tag || tag || R || public || msg). A nil tag gives
+// the plain EdDSA-compatible challenge.
+func taggedHash(suite abstract.Suite, tag []byte, public, r abstract.Point, msg []byte) (abstract.Scalar, error) {
 	h := sha512.New()
+	if tag != nil {
+		h.Write(tag)
+		h.Write(tag)
+	}
 	if _, err := r.MarshalTo(h); err != nil {
 		return nil, err
 	}
@@ -1,11 +1,13 @@
 package sign
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/dedis/crypto/abstract"
 	"github.com/dedis/crypto/config"
 	"github.com/dedis/crypto/ed25519"
-	"github.com/dedis/crypto/eddsa"
+	"github.com/dedis/crypto/random"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -47,18 +49,118 @@ func TestSchnorrSignature(t *testing.T) {
 	assert.Error(t, VerifySchnorr(suite, wrKp.Public, msg, s))
 }
 
-func TestEdDSACompatibility(t *testing.T) {
+func TestSchnorrWithContext(t *testing.T) {
 	msg := []byte("Hello Schnorr")
 	suite := ed25519.NewAES128SHA256Ed25519(false)
 	kp := config.NewKeyPair(suite)
 
-	s, err := Schnorr(suite, kp.Secret, msg)
+	s, err := SchnorrWithContext(suite, kp.Secret, "test/context", msg)
 	if err != nil {
 		t.Fatalf("Couldn't sign msg: %s: %v", msg, err)
 	}
-	err = eddsa.Verify(kp.Public, msg, s)
-	if err != nil {
-		t.Fatalf("Couldn't verify signature: \n%+v\nfor msg:'%s'. Error:\n%v", s, msg, err)
-	}
+	assert.Nil(t, VerifySchnorrWithContext(suite, kp.Public, "test/context", msg, s))
+
+	// wrong context, message or key
+	assert.Error(t, VerifySchnorrWithContext(suite, kp.Public, "other/context", msg, s))
+	assert.Error(t, VerifySchnorrWithContext(suite, kp.Public, "test/context", []byte("other"), s))
+	wrKp := config.NewKeyPair(suite)
+	assert.Error(t, VerifySchnorrWithContext(suite, wrKp.Public, "test/context", msg, s))
+
+	// context-bound and plain signatures are not interchangeable
+	assert.Error(t, VerifySchnorr(suite, kp.Public, msg, s))
+	plain, err := Schnorr(suite, kp.Secret, msg)
+	assert.Nil(t, err)
+	assert.Error(t, VerifySchnorrWithContext(suite, kp.Public, "", msg, plain))
+}
+
+func TestSchnorrDeterministic(t *testing.T) {
+	msg := []byte("Hello Schnorr")
+	suite := ed25519.NewAES128SHA256Ed25519(false)
+	kp := config.NewKeyPair(suite)
+
+	s1, err := Schnorr(suite, kp.Secret, msg)
+	assert.Nil(t, err)
+	s2, err := Schnorr(suite, kp.Secret, msg)
+	assert.Nil(t, err)
+	assert.Equal(t, s1, s2)
+
+	// other messages and contexts get other nonces
+	s3, err := Schnorr(suite, kp.Secret, []byte("Hello Schnorr!"))
+	assert.Nil(t, err)
+	assert.False(t, bytes.Equal(s1[:32], s3[:32]))
+	s4, err := SchnorrWithContext(suite, kp.Secret, "", msg)
+	assert.Nil(t, err)
+	assert.False(t, bytes.Equal(s1[:32], s4[:32]))
+}
+
+func TestSchnorrSig(t *testing.T) {
+	msg := []byte("Hello Schnorr")
+	suite := ed25519.NewAES128SHA256Ed25519(false)
+	kp := config.NewKeyPair(suite)
+
+	sig, err := Schnorr(suite, kp.Secret, msg)
+	assert.Nil(t, err)
+	s, err := ParseSchnorr(suite, sig)
+	assert.Nil(t, err)
+	assert.Equal(t, len(sig), s.MarshalSize())
+	assert.Nil(t, s.Verify(suite, kp.Public, msg))
+
+	// the parts of the signature
+	R := suite.Point()
+	assert.Nil(t, R.UnmarshalBinary(sig[:R.MarshalSize()]))
+	assert.True(t, R.Equal(s.R))
+
+	// round trip to the legacy format
+	buf, err := s.MarshalBinary()
+	assert.Nil(t, err)
+	assert.Equal(t, sig, buf)
+	s2 := NewSchnorrSig(suite)
+	assert.Nil(t, s2.UnmarshalBinary(buf))
+	assert.True(t, s2.R.Equal(s.R))
+	assert.True(t, s2.S.Equal(s.S))
+
+	s3, err := SchnorrStruct(suite, kp.Secret, msg)
+	assert.Nil(t, err)
+	assert.Nil(t, s3.Verify(suite, kp.Public, msg))
+	assert.Error(t, s3.Verify(suite, kp.Public, []byte("other")))
+
+	_, err = ParseSchnorr(suite, sig[1:])
+	assert.Error(t, err)
+}
+
+// torsionSchnorr returns a signature whose commitment R has a component of
+// order 2, which is only valid for the Cofactored equation.
+func torsionSchnorr(t *testing.T, suite abstract.Suite, kp *config.KeyPair, msg []byte) []byte {
+	// (0, -1), the point of order 2 of Ed25519
+	buf := bytes.Repeat([]byte{0xff}, 32)
+	buf[0], buf[31] = 0xec, 0x7f
+	T := suite.Point()
+	assert.Nil(t, T.UnmarshalBinary(buf))
+
+	k := suite.Scalar().Pick(random.Stream)
+	R := suite.Point().Add(suite.Point().Mul(nil, k), T)
+	h, err := taggedHash(suite, nil, kp.Public, R, msg)
+	assert.Nil(t, err)
+	s := suite.Scalar().Add(k, suite.Scalar().Mul(kp.Secret, h))
+	sig, err := (&SchnorrSig{R, s}).MarshalBinary()
+	assert.Nil(t, err)
+	return sig
+}
+
+func TestSchnorrVerifyMode(t *testing.T) {
+	msg := []byte("Hello Schnorr")
+	suite := ed25519.NewAES128SHA256Ed25519(false)
+	kp := config.NewKeyPair(suite)
+
+	sig, err := Schnorr(suite, kp.Secret, msg)
+	assert.Nil(t, err)
+	assert.Nil(t, VerifySchnorrWithMode(suite, kp.Public, msg, sig, Cofactorless))
+	assert.Nil(t, VerifySchnorrWithMode(suite, kp.Public, msg, sig, Cofactored))
+	assert.Error(t, VerifySchnorrWithMode(suite, kp.Public, []byte("other"), sig, Cofactored))
 
+	// only the cofactored equation ignores small-order components
+	sig = torsionSchnorr(t, suite, kp, msg)
+	assert.Error(t, VerifySchnorr(suite, kp.Public, msg, sig))
+	assert.Error(t, VerifySchnorrWithMode(suite, kp.Public, msg, sig, Cofactorless))
+	assert.Nil(t, VerifySchnorrWithMode(suite, kp.Public, msg, sig, Cofactored))
 }
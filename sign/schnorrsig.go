@@ -0,0 +1,91 @@
+package sign
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+// SchnorrSig is a Schnorr signature split into its commitment R and its
+// response S, for callers that need both parts separately, e.g. to aggregate
+// signatures. Its binary encoding R || S is the one of the signatures
+// returned by Schnorr and SignWithDomain.
+type SchnorrSig struct {
+	R abstract.Point
+	S abstract.Scalar
+}
+
+// NewSchnorrSig returns an empty SchnorrSig of the given suite, ready to be
+// unmarshalled.
+func NewSchnorrSig(suite abstract.Suite) *SchnorrSig {
+	return &SchnorrSig{suite.Point(), suite.Scalar()}
+}
+
+// ParseSchnorr converts a signature in the byte format of Schnorr into a
+// SchnorrSig.
+func ParseSchnorr(suite abstract.Suite, sig []byte) (*SchnorrSig, error) {
+	s := NewSchnorrSig(suite)
+	if err := s.UnmarshalBinary(sig); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SchnorrStruct creates a Schnorr signature as Schnorr does, but returns it
+// as a SchnorrSig.
+func SchnorrStruct(suite abstract.Suite, private abstract.Scalar, msg []byte) (*SchnorrSig, error) {
+	sig, err := Schnorr(suite, private, msg)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSchnorr(suite, sig)
+}
+
+// Verify checks the signature as VerifySchnorr does.
+func (s *SchnorrSig) Verify(suite abstract.Suite, public abstract.Point, msg []byte) error {
+	sig, err := s.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return VerifySchnorr(suite, public, msg, sig)
+}
+
+// MarshalSize returns the length of the binary encoding of the signature.
+func (s *SchnorrSig) MarshalSize() int {
+	return s.R.MarshalSize() + s.S.MarshalSize()
+}
+
+// MarshalBinary encodes the signature in the byte format of Schnorr.
+func (s *SchnorrSig) MarshalBinary() ([]byte, error) {
+	var b bytes.Buffer
+	if _, err := s.MarshalTo(&b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// MarshalTo writes the binary encoding of the signature to w.
+func (s *SchnorrSig) MarshalTo(w io.Writer) (int, error) {
+	n, err := s.R.MarshalTo(w)
+	if err != nil {
+		return n, err
+	}
+	m, err := s.S.MarshalTo(w)
+	return n + m, err
+}
+
+// UnmarshalBinary decodes a signature in the byte format of Schnorr. R and S
+// must have been initialized, e.g. with NewSchnorrSig.
+func (s *SchnorrSig) UnmarshalBinary(buf []byte) error {
+	pointSize := s.R.MarshalSize()
+	sigSize := s.MarshalSize()
+	if len(buf) != sigSize {
+		return fmt.Errorf("schnorr: signature of invalid length %d instead of %d", len(buf), sigSize)
+	}
+	if err := s.R.UnmarshalBinary(buf[:pointSize]); err != nil {
+		return err
+	}
+	return s.S.UnmarshalBinary(buf[pointSize:])
+}
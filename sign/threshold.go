@@ -0,0 +1,87 @@
+package sign
+
+import (
+	"errors"
+
+	"github.com/dedis/crypto/abstract"
+	"github.com/dedis/crypto/share"
+)
+
+// This file implements threshold Schnorr signatures, whose private key x and
+// nonce k are both shared among n signers with polynomials of threshold t, as
+// produced by a distributed key generation. The public key X = x*G and the
+// commitment R = k*G of the signature are the constant terms of the public
+// polynomials of x and k. Each signer i reveals its partial signature
+// s_i = k_i + h*x_i, where h is the challenge of the signature, and any t
+// valid partial signatures recover the signature R || s, which verifies with
+// VerifySchnorr as any other Schnorr signature. A new nonce must be shared
+// for every signature.
+
+var errorPartialSig = errors.New("schnorr: invalid partial signature")
+var errorTooFewPartials = errors.New("schnorr: not enough valid partial signatures")
+
+// thresholdChallenge computes the challenge of a threshold signature of msg.
+func thresholdChallenge(suite abstract.Suite, pubPoly, noncePoly *share.PubPoly, msg []byte) (abstract.Scalar, error) {
+	return taggedHash(suite, nil, pubPoly.Commit(), noncePoly.Commit(), msg)
+}
+
+// PartialSchnorr returns the partial signature of msg of the signer holding
+// the share key of the private key and the share nonce of the nonce, whose
+// public polynomials are pubPoly and noncePoly.
+func PartialSchnorr(suite abstract.Suite, key, nonce *share.PriShare, pubPoly, noncePoly *share.PubPoly, msg []byte) (*share.PriShare, error) {
+	if key.I != nonce.I {
+		return nil, errors.New("schnorr: key and nonce shares of different indices")
+	}
+	h, err := thresholdChallenge(suite, pubPoly, noncePoly, msg)
+	if err != nil {
+		return nil, err
+	}
+	s := suite.Scalar().Mul(key.V, h)
+	s.Add(s, nonce.V)
+	return &share.PriShare{I: key.I, V: s}, nil
+}
+
+// VerifyPartial checks the partial signature of msg of the signer of the given
+// index, i.e., that partialSig*G == R_i + h*X_i, where X_i and R_i are the
+// evaluations of the public polynomials of the private key and of the nonce at
+// the index of the signer. It returns nil iff the partial signature is valid.
+func VerifyPartial(suite abstract.Suite, pubPoly, noncePoly *share.PubPoly, index int, msg []byte, partialSig abstract.Scalar) error {
+	h, err := thresholdChallenge(suite, pubPoly, noncePoly, msg)
+	if err != nil {
+		return err
+	}
+	right := suite.Point().Mul(pubPoly.Eval(index).V, h)
+	right.Add(right, noncePoly.Eval(index).V)
+	if !suite.Point().Mul(nil, partialSig).Equal(right) {
+		return errorPartialSig
+	}
+	return nil
+}
+
+// RecoverSchnorr verifies the partial signatures of msg of n signers and
+// recovers the signature from t valid ones, where t is the threshold of
+// pubPoly. Invalid partial signatures are ignored. The signature is in the
+// format of Schnorr and verifies with VerifySchnorr against the public key
+// pubPoly.Commit().
+func RecoverSchnorr(suite abstract.Suite, pubPoly, noncePoly *share.PubPoly, msg []byte, partials []*share.PriShare, n int) ([]byte, error) {
+	t := pubPoly.Threshold()
+	var good []*share.PriShare
+	seen := make(map[int]bool)
+	for _, p := range partials {
+		if p == nil || p.I < 0 || n <= p.I || seen[p.I] {
+			continue
+		}
+		if VerifyPartial(suite, pubPoly, noncePoly, p.I, msg, p.V) == nil {
+			seen[p.I] = true
+			good = append(good, p)
+		}
+	}
+	if len(good) < t {
+		return nil, errorTooFewPartials
+	}
+	s, err := share.RecoverSecret(suite, good[:t], t, n)
+	if err != nil {
+		return nil, err
+	}
+	return (&SchnorrSig{noncePoly.Commit(), s}).MarshalBinary()
+}
@@ -0,0 +1,48 @@
+package sign
+
+import (
+	"testing"
+
+	"github.com/dedis/crypto/ed25519"
+	"github.com/dedis/crypto/random"
+	"github.com/dedis/crypto/share"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThresholdSchnorr(t *testing.T) {
+	msg := []byte("Hello threshold Schnorr")
+	suite := ed25519.NewAES128SHA256Ed25519(false)
+	n, th := 7, 4
+
+	key := share.NewPriPoly(suite, th, nil, random.Stream)
+	nonce := share.NewPriPoly(suite, th, nil, random.Stream)
+	pubPoly := key.Commit(nil)
+	noncePoly := nonce.Commit(nil)
+	keys := key.Shares(n)
+	nonces := nonce.Shares(n)
+
+	partials := make([]*share.PriShare, n)
+	for i := range partials {
+		p, err := PartialSchnorr(suite, keys[i], nonces[i], pubPoly, noncePoly, msg)
+		assert.Nil(t, err)
+		assert.Nil(t, VerifyPartial(suite, pubPoly, noncePoly, i, msg, p.V))
+		partials[i] = p
+	}
+
+	// A partial signature is only valid for its index and message
+	assert.Error(t, VerifyPartial(suite, pubPoly, noncePoly, 1, msg, partials[0].V))
+	assert.Error(t, VerifyPartial(suite, pubPoly, noncePoly, 0, []byte("other"), partials[0].V))
+	_, err := PartialSchnorr(suite, keys[0], nonces[1], pubPoly, noncePoly, msg)
+	assert.Error(t, err)
+
+	// The recovered signature is a plain Schnorr signature, even with some
+	// invalid partial signatures
+	partials[0] = &share.PriShare{I: 0, V: suite.Scalar().Pick(random.Stream)}
+	partials[2] = nil
+	sig, err := RecoverSchnorr(suite, pubPoly, noncePoly, msg, partials, n)
+	assert.Nil(t, err)
+	assert.Nil(t, VerifySchnorr(suite, pubPoly.Commit(), msg, sig))
+
+	_, err = RecoverSchnorr(suite, pubPoly, noncePoly, msg, partials[:th], n)
+	assert.Error(t, err)
+}
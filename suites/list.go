@@ -20,6 +20,8 @@ func All() Suites {
 	s.add(nist.NewAES128SHA256P256())
 	s.add(nist.NewAES128SHA256QR512())
 	s.add(ed25519.NewAES128SHA256Ed25519(false))
+	s.add(ed25519.NewSHA3Ed25519())
+	s.add(ed25519.NewRistretto255())
 	s.add(edwards.NewAES128SHA256Ed25519(false))
 	return s
 }
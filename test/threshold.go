@@ -0,0 +1,87 @@
+package test
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/dedis/crypto/abstract"
+)
+
+// ThresholdParams is one configuration of a threshold benchmark:
+// a t-out-of-n sharing over a given suite.
+type ThresholdParams struct {
+	Suite abstract.Suite
+	T, N  int
+}
+
+func (p ThresholdParams) String() string {
+	return fmt.Sprintf("%s/t=%d/n=%d", p.Suite.String(), p.T, p.N)
+}
+
+// A ThresholdBench benchmarks an operation for a given configuration.
+// Any setup must be done before calling b.ResetTimer.
+type ThresholdBench func(b *testing.B, p ThresholdParams)
+
+// ThresholdConfigs returns the configurations for all the given suites
+// and (t,n) sizes, e.g., {{3,5},{10,20},{34,100}}.
+func ThresholdConfigs(suites []abstract.Suite, sizes [][2]int) []ThresholdParams {
+	var params []ThresholdParams
+	for _, s := range suites {
+		for _, sz := range sizes {
+			params = append(params, ThresholdParams{s, sz[0], sz[1]})
+		}
+	}
+	return params
+}
+
+// RunThresholdBench runs a ThresholdBench as a sub-benchmark of b
+// for each configuration, reporting memory allocations.
+func RunThresholdBench(b *testing.B, params []ThresholdParams, f ThresholdBench) {
+	for _, p := range params {
+		p := p
+		b.Run(p.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			f(b, p)
+		})
+	}
+}
+
+// ThresholdCSV runs named ThresholdBenchs outside of 'go test -bench'
+// and writes their results as CSV, for regression tracking dashboards.
+// Each row holds the benchmark name, suite, t, n, iterations,
+// and the time, bytes and allocations per operation.
+type ThresholdCSV struct {
+	w *csv.Writer
+}
+
+// NewThresholdCSV creates a ThresholdCSV writing to w, and writes the header.
+func NewThresholdCSV(w io.Writer) (*ThresholdCSV, error) {
+	c := &ThresholdCSV{csv.NewWriter(w)}
+	err := c.w.Write([]string{"benchmark", "suite", "t", "n", "iterations",
+		"ns_per_op", "bytes_per_op", "allocs_per_op"})
+	return c, err
+}
+
+// Run runs a ThresholdBench for each configuration and writes a row for each.
+func (c *ThresholdCSV) Run(name string, params []ThresholdParams, f ThresholdBench) error {
+	for _, p := range params {
+		p := p
+		r := testing.Benchmark(func(b *testing.B) {
+			b.ReportAllocs()
+			f(b, p)
+		})
+		err := c.w.Write([]string{name, p.Suite.String(),
+			strconv.Itoa(p.T), strconv.Itoa(p.N), strconv.Itoa(r.N),
+			strconv.FormatInt(r.NsPerOp(), 10),
+			strconv.FormatInt(r.AllocedBytesPerOp(), 10),
+			strconv.FormatInt(r.AllocsPerOp(), 10)})
+		if err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}